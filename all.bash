@@ -16,6 +16,12 @@ fi
 
 echo "platform = $platform"
 
+# build tags enabling the wrappers to UMFPACK and MUMPS (see la/README.md)
+tags='umfpack'
+if [[ $platform == 'linux' ]]; then
+    tags='umfpack mumps'
+fi
+
 install_and_test(){
     HERE=`pwd`
     PKG=$1
@@ -29,9 +35,9 @@ install_and_test(){
         bash xgenflagsfile.bash
     fi
     touch *.go
-    go install
+    go install -tags "$tags"
     if [ "$DOTEST" -eq 1 ]; then
-        go test
+        go test -tags "$tags"
     fi
     cd $HERE
 }
@@ -63,7 +69,7 @@ if [[ $platform != 'windows' ]]; then
     install_and_test rnd/dsfmt 1
 fi
 
-for p in rnd opt ml/imgd ml ode pde tsr; do
+for p in rnd opt ml/imgd ml ode sde pde tsr; do
     install_and_test $p 1
 done
 
@@ -89,12 +89,12 @@ func main() {
 	}
 
 	// allocate solver
-	o := la.NewSparseSolverC("umfpack")
+	o := la.NewSparseSolverC(la.SpDefaultKind()) // "umfpack" if available or "splu" otherwise
 	defer o.Free()
 
 	// initialise solver
 	symmetric, verbose := false, false
-	o.Init(A, &la.SpArgs{symmetric, verbose, "", "", nil, nil})
+	o.Init(A, &la.SpArgs{Symmetric: symmetric, Verbose: verbose})
 
 	// factorise
 	o.Fact()
@@ -35,12 +35,12 @@ func main() {
 	b := []float64{8.0, 45.0, -3.0, 3.0, 19.0}
 
 	// allocate solver
-	o := la.NewSparseSolver("umfpack")
+	o := la.NewSparseSolver(la.SpDefaultKind()) // "umfpack" if available or "splu" otherwise
 	defer o.Free()
 
 	// initialise solver
 	symmetric, verbose := false, false
-	o.Init(A, &la.SpArgs{symmetric, verbose, "", "", nil, nil})
+	o.Init(A, &la.SpArgs{Symmetric: symmetric, Verbose: verbose})
 
 	// factorise
 	o.Fact()
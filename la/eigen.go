@@ -107,6 +107,126 @@ func EigenVecLR(u, v *MatrixC, w VectorC, A *Matrix, preserveA bool) {
 	oblas.EigenvecsBuildBoth(u.Data, v.Data, wr, wi, uu, vv)
 }
 
+// EigenValSym computes eigenvalues of symmetric matrix
+//
+//   A ⋅ v[j] = λ[j] ⋅ v[j]
+//
+//   INPUT:
+//     A -- symmetric matrix (only the lower triangle is used)
+//
+//   OUTPUT:
+//     w -- eigenvalues in ascending order [pre-allocated]
+//
+func EigenValSym(w Vector, A *Matrix, preserveA bool) {
+	a := A
+	if preserveA {
+		a = A.GetCopy()
+	}
+	oblas.Dsyev(false, false, a.M, a.Data, a.M, w)
+}
+
+// EigenVecSym computes eigenvalues and eigenvectors of symmetric matrix
+//
+//   A ⋅ v[j] = λ[j] ⋅ v[j]
+//
+//   INPUT:
+//     A -- symmetric matrix (only the lower triangle is used) [not modified]
+//
+//   OUTPUT:
+//     v -- matrix with the orthonormal eigenvectors; each column contains one eigenvector [pre-allocated]
+//     w -- eigenvalues in ascending order [pre-allocated]
+//
+func EigenVecSym(v *Matrix, w Vector, A *Matrix) {
+	copy(v.Data, A.Data)
+	oblas.Dsyev(true, false, A.M, v.Data, A.M, w)
+}
+
+// EigenValSymGen computes eigenvalues of symmetric-definite generalized problem
+//
+//   A ⋅ v[j] = λ[j] ⋅ B ⋅ v[j]
+//
+//   INPUT:
+//     A -- symmetric matrix (only the lower triangle is used)
+//     B -- symmetric positive-definite matrix (only the lower triangle is used)
+//
+//   OUTPUT:
+//     w -- eigenvalues in ascending order [pre-allocated]
+//
+func EigenValSymGen(w Vector, A, B *Matrix, preserveAB bool) {
+	a, b := A, B
+	if preserveAB {
+		a, b = A.GetCopy(), B.GetCopy()
+	}
+	oblas.Dsygv(1, false, false, a.M, a.Data, a.M, b.Data, b.M, w)
+}
+
+// EigenVecSymGen computes eigenvalues and eigenvectors of symmetric-definite generalized problem
+// (e.g. the vibration problem K ⋅ v = ω² ⋅ M ⋅ v with stiffness K and mass M)
+//
+//   A ⋅ v[j] = λ[j] ⋅ B ⋅ v[j]
+//
+//   INPUT:
+//     A -- symmetric matrix (only the lower triangle is used) [not modified]
+//     B -- symmetric positive-definite matrix (only the lower triangle is used) [not modified]
+//
+//   OUTPUT:
+//     v -- matrix with the eigenvectors; each column contains one eigenvector [pre-allocated]
+//          the eigenvectors are B-orthonormal; i.e. vᵀ ⋅ B ⋅ v = I
+//     w -- eigenvalues in ascending order [pre-allocated]
+//
+func EigenVecSymGen(v *Matrix, w Vector, A, B *Matrix) {
+	copy(v.Data, A.Data)
+	b := B.GetCopy()
+	oblas.Dsygv(1, true, false, A.M, v.Data, A.M, b.Data, b.M, w)
+}
+
+// EigenValGen computes eigenvalues of general generalized problem
+//
+//   A ⋅ v[j] = λ[j] ⋅ B ⋅ v[j]    with    λ[j] = α[j] / β[j]
+//
+//   INPUT:
+//     A, B -- general matrices
+//
+//   OUTPUT:
+//     α -- numerators of eigenvalues [pre-allocated]
+//     β -- denominators of eigenvalues [pre-allocated]; β[j] = 0 corresponds to an infinite
+//          eigenvalue, which happens if B is singular
+//
+func EigenValGen(α VectorC, β Vector, A, B *Matrix, preserveAB bool) {
+	a, b := A, B
+	if preserveAB {
+		a, b = A.GetCopy(), B.GetCopy()
+	}
+	αr, αi := make([]float64, a.M), make([]float64, a.M)
+	oblas.Dggev(false, false, a.M, a.Data, a.M, b.Data, b.M, αr, αi, β, nil, 0, nil, 0)
+	oblas.JoinComplex(α, αr, αi)
+}
+
+// EigenVecGen computes eigenvalues and RIGHT eigenvectors of general generalized problem
+//
+//   A ⋅ v[j] = λ[j] ⋅ B ⋅ v[j]    with    λ[j] = α[j] / β[j]
+//
+//   INPUT:
+//     A, B -- general matrices
+//
+//   OUTPUT:
+//     v -- matrix with the eigenvectors; each column contains one eigenvector [pre-allocated]
+//     α -- numerators of eigenvalues [pre-allocated]
+//     β -- denominators of eigenvalues [pre-allocated]; β[j] = 0 corresponds to an infinite
+//          eigenvalue, which happens if B is singular
+//
+func EigenVecGen(v *MatrixC, α VectorC, β Vector, A, B *Matrix, preserveAB bool) {
+	a, b := A, B
+	if preserveAB {
+		a, b = A.GetCopy(), B.GetCopy()
+	}
+	αr, αi := make([]float64, a.M), make([]float64, a.M)
+	vr := make([]float64, a.M*a.M)
+	oblas.Dggev(false, true, a.M, a.Data, a.M, b.Data, b.M, αr, αi, β, nil, 0, vr, a.M)
+	oblas.JoinComplex(α, αr, αi)
+	oblas.EigenvecsBuild(v.Data, αr, αi, vr)
+}
+
 // CheckEigenVecL checks left eigenvector:
 //
 //    H                  H
@@ -139,3 +259,38 @@ func CheckEigenVecR(tst *testing.T, A *Matrix, λ VectorC, v *MatrixC, tol float
 		chk.ArrayC(tst, io.Sf("λ[%d]⋅v[%d]", i, i), tol, res, λv)
 	}
 }
+
+// CheckEigenVecSym checks eigenvectors of symmetric (generalized) problem:
+//
+//   A ⋅ v[j] = λ[j] ⋅ B ⋅ v[j]    (B = I if nil)
+//
+func CheckEigenVecSym(tst *testing.T, A, B *Matrix, λ Vector, v *Matrix, tol float64) {
+	res := NewVector(A.M)
+	λBv := NewVector(A.M)
+	for i := 0; i < A.M; i++ {
+		vi := v.GetCol(i)
+		if B == nil {
+			λBv.Apply(λ[i], vi)
+		} else {
+			MatVecMul(λBv, λ[i], B, vi)
+		}
+		MatVecMul(res, 1, A, vi)
+		chk.Array(tst, io.Sf("λ[%d]⋅B⋅v[%d]", i, i), tol, res, λBv)
+	}
+}
+
+// CheckEigenVecGen checks right eigenvectors of general generalized problem:
+//
+//   β[j] ⋅ A ⋅ v[j] = α[j] ⋅ B ⋅ v[j]
+//
+func CheckEigenVecGen(tst *testing.T, A, B *Matrix, α VectorC, β Vector, v *MatrixC, tol float64) {
+	Ac, Bc := A.GetComplex(), B.GetComplex()
+	βAv := NewVectorC(A.M)
+	αBv := NewVectorC(A.M)
+	for i := 0; i < A.M; i++ {
+		vi := v.GetCol(i)
+		MatVecMulC(βAv, complex(β[i], 0), Ac, vi)
+		MatVecMulC(αBv, α[i], Bc, vi)
+		chk.ArrayC(tst, io.Sf("α[%d]⋅B⋅v[%d]", i, i), tol, βAv, αBv)
+	}
+}
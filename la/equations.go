@@ -250,6 +250,22 @@ func (o *Equations) GetAmat() (A *Triplet) {
 	return
 }
 
+// StructInfo returns structural information about the assembled Auu matrix; e.g. to diagnose
+// singular systems before calling a solver
+//  NOTE: the empty and unmatched rows/columns in info are given as full-system equation numbers
+func (o *Equations) StructInfo() (info *SpInfo) {
+	if o.Auu == nil {
+		chk.Panic("Auu must be allocated and assembled first\n")
+	}
+	info = SpGetInfo(o.Auu.ToMatrix(nil))
+	for _, list := range [][]int{info.EmptyRows, info.EmptyCols, info.Unmatched} {
+		for k, i := range list {
+			list[k] = o.UtoF[i]
+		}
+	}
+	return
+}
+
 // JoinVector joins uknown with known parts of vector
 //  INPUT:
 //   bu, bk -- partitioned vectors; e.g. o.Bu, and o.Bk or o.Xu, o.Xk
@@ -383,9 +399,9 @@ func (o *Equations) Solve(solver SparseSolver, t float64, calcXk, calcBu func(I
 }
 
 // SolveOnce solves linear system just once; thus allocating and discarding a linear solver
-// (umfpack) internally. See method Solve() for more details
+// (umfpack if available or splu otherwise) internally. See method Solve() for more details
 func (o *Equations) SolveOnce(calcXk, calcBu func(I int, t float64) float64) {
-	s := NewSparseSolver("umfpack")
+	s := NewSparseSolver(SpDefaultKind())
 	defer s.Free()
 	s.Init(o.Auu, nil)
 	s.Fact()
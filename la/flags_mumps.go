@@ -0,0 +1,13 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build mumps,!windows,!darwin
+
+package la
+
+/*
+#cgo linux LDFLAGS: -L/usr/lib -L/usr/local/lib
+#cgo linux LDFLAGS: -ldmumps -lzmumps -lmumps_common -lpord -lopenblas -lgfortran
+*/
+import "C"
@@ -2,13 +2,14 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build umfpack
+
 package la
 
 /*
 #cgo linux CFLAGS: -O2 -I/usr/include/suitesparse -I/usr/local/include/suitesparse
 #cgo linux LDFLAGS: -L/usr/lib -L/usr/local/lib
 #cgo linux LDFLAGS: -lumfpack -lamd -lcholmod -lcolamd -lsuitesparseconfig -lopenblas -lgfortran
-#cgo linux LDFLAGS: -ldmumps -lzmumps -lmumps_common -lpord
 
 #cgo windows CFLAGS: -O2
 #cgo windows LDFLAGS: -lumfpack -lamd -lcholmod -lcolamd -lsuitesparseconfig -lopenblas -lgfortran
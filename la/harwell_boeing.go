@@ -0,0 +1,329 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+)
+
+// Harwell-Boeing files (.hb, .rua, .rsa, ...) //////////////////////////////////////////////////
+//
+//   line 1: TITLE (A72), KEY (A8)
+//   line 2: TOTCRD, PTRCRD, INDCRD, VALCRD, RHSCRD (5I14)
+//   line 3: MXTYPE (A3), blank (11X), NROW, NCOL, NNZERO, NELTVL (4I14)
+//   line 4: PTRFMT (A16), INDFMT (A16), VALFMT (A20), RHSFMT (A20)
+//   line 5: RHSTYP (A3), blank (11X), NRHS, NRHSIX (2I14)     [only if RHSCRD > 0]
+//   column pointers, row indices and values (1-based; column-compressed; Fortran formats)
+//
+//   MXTYPE -- 1st char: R real, C complex, P pattern
+//             2nd char: U unsymmetric, S symmetric, H hermitian, Z skew-symmetric, R rectangular
+//             3rd char: A assembled (elemental matrices are not supported)
+//
+//   NOTE: (1) only the lower triangle is stored for symmetric, hermitian and skew-symmetric matrices
+//         (2) right-hand sides are ignored by the readers
+//         (3) assembled Rutherford-Boeing files (e.g. .rb files from the SuiteSparse collection)
+//             have the same layout without RHSCRD and line 5; thus they can be read as well
+//
+//   See https://math.nist.gov/MatrixMarket/formats.html#hb
+
+// ReadHB reads a Harwell-Boeing file
+//
+//   NOTE: (1) symmetric and skew-symmetric matrices are expanded to the full matrix
+//         (2) pattern ("P") matrices produce unit values
+//         (3) complex files must be read with TripletC
+//
+func (o *Triplet) ReadHB(filename string) {
+	d := hbRead(filename)
+	if d.cplx {
+		chk.Panic("cannot read complex matrix into real triplet. use TripletC instead. file = <%s>\n", filename)
+	}
+	d.expand()
+	o.Init(d.m, d.n, len(d.x))
+	for k, x := range d.x {
+		o.Put(d.i[k], d.j[k], real(x))
+	}
+}
+
+// WriteHB writes a Harwell-Boeing file
+//
+//  NOTE: this method will create a CCMatrix first because
+//        duplicates must be added before saving the file
+//
+//  dirout   -- directory for output. will be created
+//  fnkey    -- filename key (filename without extension). ".hb" will be added
+//  symmetry -- "general" (or ""), "symmetric" or "skew-symmetric"
+func (o *Triplet) WriteHB(dirout, fnkey, symmetry string) (cmat *CCMatrix) {
+	cmat = o.ToMatrix(nil)
+	cmat.WriteHB(dirout, fnkey, symmetry)
+	return
+}
+
+// WriteHB writes a Harwell-Boeing file
+//
+//  NOTE: the matrix is assumed to have the given symmetry; i.e. only the
+//        lower triangle is written if symmetry is not "general"
+//
+//  dirout   -- directory for output. will be created
+//  fnkey    -- filename key (filename without extension). ".hb" will be added
+//  symmetry -- "general" (or ""), "symmetric" or "skew-symmetric"
+func (o *CCMatrix) WriteHB(dirout, fnkey, symmetry string) {
+	x := make([]complex128, len(o.x))
+	for k, v := range o.x {
+		x[k] = complex(v, 0)
+	}
+	hbWrite(dirout, fnkey, spIoSymmetry(symmetry, false), false, o.m, o.n, o.p, o.i, x)
+}
+
+// ReadHB reads a Harwell-Boeing file
+//
+//   NOTE: (1) symmetric, skew-symmetric and hermitian matrices are expanded to the full matrix
+//         (2) pattern ("P") matrices produce unit values
+//
+func (o *TripletC) ReadHB(filename string) {
+	d := hbRead(filename)
+	d.expand()
+	o.Init(d.m, d.n, len(d.x))
+	for k, x := range d.x {
+		o.Put(d.i[k], d.j[k], x)
+	}
+}
+
+// WriteHB writes a Harwell-Boeing file (complex values)
+//
+//  NOTE: this method will create a CCMatrixC first because
+//        duplicates must be added before saving the file
+//
+//  dirout   -- directory for output. will be created
+//  fnkey    -- filename key (filename without extension). ".hb" will be added
+//  symmetry -- "general" (or ""), "symmetric", "skew-symmetric" or "hermitian"
+func (o *TripletC) WriteHB(dirout, fnkey, symmetry string) (cmat *CCMatrixC) {
+	cmat = o.ToMatrix(nil)
+	cmat.WriteHB(dirout, fnkey, symmetry)
+	return
+}
+
+// WriteHB writes a Harwell-Boeing file (complex values)
+//
+//  NOTE: the matrix is assumed to have the given symmetry; i.e. only the
+//        lower triangle is written if symmetry is not "general"
+//
+//  dirout   -- directory for output. will be created
+//  fnkey    -- filename key (filename without extension). ".hb" will be added
+//  symmetry -- "general" (or ""), "symmetric", "skew-symmetric" or "hermitian"
+func (o *CCMatrixC) WriteHB(dirout, fnkey, symmetry string) {
+	hbWrite(dirout, fnkey, spIoSymmetry(symmetry, true), true, o.m, o.n, o.p, o.i, o.x)
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// hbFormatRegex matches Fortran edit descriptors such as 13I6, 1P,4D20.12 or 5E16.8
+var hbFormatRegex = regexp.MustCompile(`(\d*)([IDEFG])(\d+)`)
+
+// hbSymmetryCodes maps symmetry keywords to the 2nd character of MXTYPE
+var hbSymmetryCodes = map[string]byte{"general": 'U', "symmetric": 'S', "hermitian": 'H', "skew-symmetric": 'Z'}
+
+// hbFormat parses a Fortran format returning the number of fields per line and the field width
+func hbFormat(format string) (count, width int) {
+	f := strings.ToUpper(strings.TrimSpace(format))
+	f = strings.Replace(f, "1P", "", -1) // scale factor
+	res := hbFormatRegex.FindStringSubmatch(f)
+	if res == nil {
+		chk.Panic("cannot parse Fortran format %q\n", format)
+	}
+	count = 1
+	if res[1] != "" {
+		count = io.Atoi(res[1])
+	}
+	width = io.Atoi(res[3])
+	return
+}
+
+// hbFields extracts nvals fixed-width fields from lines
+func hbFields(lines []string, nvals int, format string) (fields []string, rest []string) {
+	if nvals == 0 {
+		return nil, lines
+	}
+	count, width := hbFormat(format)
+	fields = make([]string, 0, nvals)
+	for len(fields) < nvals {
+		if len(lines) == 0 {
+			chk.Panic("Harwell-Boeing file has fewer values than expected (%d < %d)\n", len(fields), nvals)
+		}
+		line := lines[0]
+		lines = lines[1:]
+		for k := 0; k < count && len(fields) < nvals; k++ {
+			a, b := k*width, (k+1)*width
+			if a >= len(line) {
+				break
+			}
+			if b > len(line) {
+				b = len(line)
+			}
+			fields = append(fields, strings.TrimSpace(line[a:b]))
+		}
+	}
+	return fields, lines
+}
+
+// hbAtof converts Fortran real numbers such as 1.5D+02 or 1.5+002 to float64
+func hbAtof(s string) float64 {
+	s = strings.Replace(strings.ToUpper(s), "D", "E", 1)
+	if !strings.Contains(s, "E") {
+		if k := strings.LastIndexAny(s, "+-"); k > 0 {
+			s = s[:k] + "E" + s[k:]
+		}
+	}
+	return io.Atof(s)
+}
+
+// hbRead reads a Harwell-Boeing file
+func hbRead(filename string) (d *spIoData) {
+
+	// lines
+	lines := strings.Split(strings.Replace(string(io.ReadFile(filename)), "\r", "", -1), "\n")
+	if len(lines) < 4 {
+		chk.Panic("Harwell-Boeing file <%s> must have at least 4 header lines\n", filename)
+	}
+
+	// header
+	r := strings.Fields(lines[1])
+	if len(r) < 4 {
+		chk.Panic("line 2 of Harwell-Boeing file must have at least 4 integers (TOTCRD,PTRCRD,INDCRD,VALCRD)\n")
+	}
+	rhscrd := 0
+	if len(r) > 4 {
+		rhscrd = io.Atoi(r[4])
+	}
+	r = strings.Fields(lines[2])
+	if len(r) < 4 {
+		chk.Panic("line 3 of Harwell-Boeing file must have MXTYPE, NROW, NCOL and NNZERO\n")
+	}
+	mxtype := strings.ToUpper(r[0])
+	if len(mxtype) != 3 || mxtype[2] != 'A' {
+		chk.Panic("Harwell-Boeing matrix type %q is not available. only assembled matrices are supported\n", r[0])
+	}
+	d = &spIoData{m: io.Atoi(r[1]), n: io.Atoi(r[2])}
+	nnz := io.Atoi(r[3])
+	nvals := nnz
+	switch mxtype[0] {
+	case 'R':
+	case 'C':
+		d.cplx = true
+		nvals = 2 * nnz
+	case 'P':
+		nvals = 0
+	default:
+		chk.Panic("Harwell-Boeing value type '%c' is not available\n", mxtype[0])
+	}
+	d.symmetry = ""
+	for key, code := range hbSymmetryCodes {
+		if code == mxtype[1] {
+			d.symmetry = key
+		}
+	}
+	if mxtype[1] == 'R' {
+		d.symmetry = "general"
+	}
+	if d.symmetry == "" || (d.symmetry == "hermitian" && !d.cplx) {
+		chk.Panic("Harwell-Boeing matrix type %q is not available\n", mxtype)
+	}
+	formats := regexp.MustCompile(`\([^)]*\)`).FindAllString(lines[3], -1)
+	if len(formats) < 2 || (nvals > 0 && len(formats) < 3) {
+		chk.Panic("cannot find Fortran formats in line 4 of Harwell-Boeing file <%s>\n", filename)
+	}
+	body := lines[4:]
+	if rhscrd > 0 {
+		body = lines[5:]
+	}
+
+	// data
+	var fp, fi, fx []string
+	fp, body = hbFields(body, d.n+1, formats[0])
+	fi, body = hbFields(body, nnz, formats[1])
+	if nvals > 0 {
+		fx, _ = hbFields(body, nvals, formats[2])
+	}
+	d.i = make([]int, 0, 2*nnz)
+	d.j = make([]int, 0, 2*nnz)
+	d.x = make([]complex128, 0, 2*nnz)
+	for j := 0; j < d.n; j++ {
+		for p := io.Atoi(fp[j]) - 1; p < io.Atoi(fp[j+1])-1; p++ {
+			var x complex128 = 1
+			if d.cplx {
+				x = complex(hbAtof(fx[2*p]), hbAtof(fx[2*p+1]))
+			} else if nvals > 0 {
+				x = complex(hbAtof(fx[p]), 0)
+			}
+			d.put(io.Atoi(fi[p])-1, j, x)
+		}
+	}
+	if len(d.x) != nnz {
+		chk.Panic("number of entries in Harwell-Boeing file is inconsistent. %d != %d\n", len(d.x), nnz)
+	}
+	return
+}
+
+// hbWrite writes a Harwell-Boeing file with column-compressed data
+func hbWrite(dirout, fnkey, symmetry string, cplx bool, m, n int, Ap, Ai []int, Ax []complex128) {
+
+	// select entries
+	p := make([]int, n+1)
+	var ii []int
+	var xx []float64
+	for j := 0; j < n; j++ {
+		for k := Ap[j]; k < Ap[j+1]; k++ {
+			if spIoKeep(symmetry, Ai[k], j) {
+				ii = append(ii, Ai[k]+1)
+				xx = append(xx, real(Ax[k]))
+				if cplx {
+					xx = append(xx, imag(Ax[k]))
+				}
+			}
+		}
+		p[j+1] = len(ii)
+	}
+
+	// type
+	mxtype := []byte("RUA")
+	if cplx {
+		mxtype[0] = 'C'
+	}
+	mxtype[1] = hbSymmetryCodes[symmetry]
+	if m != n && symmetry == "general" {
+		mxtype[1] = 'R'
+	}
+
+	// header
+	nlines := func(nvals, count int) int { return (nvals + count - 1) / count }
+	ptrcrd, indcrd, valcrd := nlines(n+1, 8), nlines(len(ii), 8), nlines(len(xx), 3)
+	key := fnkey
+	if len(key) > 8 {
+		key = key[:8]
+	}
+	var buf bytes.Buffer
+	io.Ff(&buf, "%-72s%-8s\n", fnkey, key)
+	io.Ff(&buf, "%14d%14d%14d%14d%14d\n", ptrcrd+indcrd+valcrd, ptrcrd, indcrd, valcrd, 0)
+	io.Ff(&buf, "%-3s%11s%14d%14d%14d%14d\n", mxtype, "", m, n, len(ii), 0)
+	io.Ff(&buf, "%-16s%-16s%-20s%-20s\n", "(8I10)", "(8I10)", "(3E26.16)", "")
+
+	// data
+	write := func(nvals, count int, f func(k int)) {
+		for k := 0; k < nvals; k++ {
+			f(k)
+			if (k+1)%count == 0 || k == nvals-1 {
+				io.Ff(&buf, "\n")
+			}
+		}
+	}
+	write(n+1, 8, func(k int) { io.Ff(&buf, "%10d", p[k]+1) })
+	write(len(ii), 8, func(k int) { io.Ff(&buf, "%10d", ii[k]) })
+	write(len(xx), 3, func(k int) { io.Ff(&buf, "%26.16E", xx[k]) })
+	io.WriteFileVD(dirout, fnkey+".hb", &buf)
+}
@@ -0,0 +1,347 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// BandMatrix implements a square banded matrix using the LAPACK band storage (column-major)
+//
+//  General matrix with Kl sub-diagonals and Ku super-diagonals (Ldab = Kl + Ku + 1):
+//
+//     Data[Ku+i-j + j*Ldab] = A[i][j]   for   max(0,j-Ku) ≤ i ≤ min(N-1,j+Kl)
+//
+//  Symmetric matrix with K = Kl = Ku off-diagonals (only the upper band is stored; Ldab = K + 1):
+//
+//     Data[K+i-j + j*Ldab] = A[i][j]   for   max(0,j-K) ≤ i ≤ j
+//
+//  Example (N = 5, Kl = 1, Ku = 2):
+//
+//         a00 a01 a02  .   .                   *   *  a02 a13 a24
+//         a10 a11 a12 a13  .                   *  a01 a12 a23 a34
+//     A =  .  a21 a22 a23 a24    ⇒   Data:   a00 a11 a22 a33 a44
+//          .   .  a32 a33 a34                a10 a21 a32 a43  *
+//          .   .   .  a43 a44
+//
+//  NOTE: Factorize computes the LU (general) or Cholesky (symmetric) factorisation which is
+//        then used by Solve; the factors are stored separately and Data is not modified
+//
+type BandMatrix struct {
+	N      int       // dimension
+	Kl, Ku int       // number of sub-diagonals and super-diagonals
+	Sym    bool      // symmetric matrix; only the upper band is stored
+	Ldab   int       // leading dimension of Data
+	Data   []float64 // band storage (column-major)
+
+	// factorisation
+	fact []float64 // LU (Ldab = 2⋅Kl + Ku + 1) or Cholesky (Ldab = Ku + 1) factors
+	ipiv []int     // pivot indices of LU factorisation
+}
+
+// NewBandMatrix allocates a new general banded (n x n) matrix with kl sub-diagonals and ku
+// super-diagonals
+func NewBandMatrix(n, kl, ku int) (o *BandMatrix) {
+	if kl < 0 || ku < 0 {
+		chk.Panic("number of sub- and super-diagonals must be non-negative. kl=%d, ku=%d\n", kl, ku)
+	}
+	o = new(BandMatrix)
+	o.N, o.Kl, o.Ku = n, kl, ku
+	o.Ldab = kl + ku + 1
+	o.Data = make([]float64, o.Ldab*n)
+	return
+}
+
+// NewBandMatrixSym allocates a new symmetric banded (n x n) matrix with k off-diagonals
+func NewBandMatrixSym(n, k int) (o *BandMatrix) {
+	if k < 0 {
+		chk.Panic("number of off-diagonals must be non-negative. k=%d\n", k)
+	}
+	o = new(BandMatrix)
+	o.N, o.Kl, o.Ku = n, k, k
+	o.Sym = true
+	o.Ldab = k + 1
+	o.Data = make([]float64, o.Ldab*n)
+	return
+}
+
+// NewBandMatrixTriplet allocates a new banded matrix with the bandwidths and values of a
+// square sparse matrix given in triplet format (repeated entries are added)
+//
+//   sym -- the matrix is symmetric: only the entries in the upper triangle are used
+//
+func NewBandMatrixTriplet(t *Triplet, sym bool) (o *BandMatrix) {
+	if t.m != t.n {
+		chk.Panic("matrix must be square. %d != %d\n", t.m, t.n)
+	}
+	var kl, ku int
+	for k := 0; k < t.pos; k++ {
+		if sym && t.i[k] > t.j[k] {
+			continue
+		}
+		kl = utl.Imax(kl, t.i[k]-t.j[k])
+		ku = utl.Imax(ku, t.j[k]-t.i[k])
+	}
+	if sym {
+		o = NewBandMatrixSym(t.m, ku)
+	} else {
+		o = NewBandMatrix(t.m, kl, ku)
+	}
+	for k := 0; k < t.pos; k++ {
+		if sym && t.i[k] > t.j[k] {
+			continue
+		}
+		o.Add(t.i[k], t.j[k], t.x[k])
+	}
+	return
+}
+
+// InBand returns whether (i,j) is within the stored band or not
+//  NOTE: (i,j) in the lower triangle of symmetric matrices is checked as (j,i)
+func (o *BandMatrix) InBand(i, j int) bool {
+	if o.Sym && i > j {
+		i, j = j, i
+	}
+	return i-j <= o.Kl && j-i <= o.Ku && i >= 0 && j >= 0 && i < o.N && j < o.N
+}
+
+// Get gets value; returns zero if (i,j) is outside the band
+func (o *BandMatrix) Get(i, j int) float64 {
+	if !o.InBand(i, j) {
+		return 0
+	}
+	return o.Data[o.index(i, j)]
+}
+
+// Set sets value
+//  NOTE: (1) (i,j) must be within the band
+//        (2) the factorisation is discarded
+func (o *BandMatrix) Set(i, j int, val float64) {
+	if !o.InBand(i, j) {
+		chk.Panic("(%d,%d) is outside the band of matrix\n", i, j)
+	}
+	o.Data[o.index(i, j)] = val
+	o.fact = nil
+}
+
+// Add adds value to (i,j) component
+//  NOTE: (1) (i,j) must be within the band
+//        (2) the factorisation is discarded
+func (o *BandMatrix) Add(i, j int, val float64) {
+	if !o.InBand(i, j) {
+		chk.Panic("(%d,%d) is outside the band of matrix\n", i, j)
+	}
+	o.Data[o.index(i, j)] += val
+	o.fact = nil
+}
+
+// Fill fills all components within the band with a constant value
+//  NOTE: the factorisation is discarded
+func (o *BandMatrix) Fill(val float64) {
+	for j := 0; j < o.N; j++ {
+		i0, i1 := o.rowRange(j)
+		for i := i0; i <= i1; i++ {
+			o.Data[o.index(i, j)] = val
+		}
+	}
+	o.fact = nil
+}
+
+// ToDense converts banded matrix to dense matrix
+func (o *BandMatrix) ToDense() (res *Matrix) {
+	res = NewMatrix(o.N, o.N)
+	for j := 0; j < o.N; j++ {
+		i0, i1 := o.rowRange(j)
+		for i := i0; i <= i1; i++ {
+			res.Set(i, j, o.Data[o.index(i, j)])
+			if o.Sym {
+				res.Set(j, i, o.Data[o.index(i, j)])
+			}
+		}
+	}
+	return
+}
+
+// Factorize computes the LU factorisation with partial pivoting (general matrix) or the
+// Cholesky factorisation A = Uᵀ⋅U (symmetric positive-definite matrix)
+//
+//   NOTE: (1) the algorithms are equivalent to LAPACK dgbtf2 and dpbtf2
+//         (2) the LU factors of general matrices have Kl additional super-diagonals due to pivoting
+//
+func (o *BandMatrix) Factorize() {
+	if o.Sym {
+		o.factorizeCholesky()
+		return
+	}
+	o.factorizeLU()
+}
+
+// Solve solves the linear system A ⋅ x = b using the factorisation computed by Factorize
+//
+//   NOTE: x and b may be the same vector
+//
+func (o *BandMatrix) Solve(x, b Vector) {
+	if o.fact == nil {
+		chk.Panic("Factorize must be called before Solve\n")
+	}
+	copy(x, b)
+	n := o.N
+	if o.Sym {
+		k, ld := o.Ku, o.Ku+1
+		for i := 0; i < n; i++ { // Uᵀ⋅y = b
+			sum := x[i]
+			for l := utl.Imax(0, i-k); l < i; l++ {
+				sum -= o.fact[k+l-i+i*ld] * x[l]
+			}
+			x[i] = sum / o.fact[k+i*ld]
+		}
+		for i := n - 1; i >= 0; i-- { // U⋅x = y
+			sum := x[i]
+			for l := i + 1; l <= utl.Imin(n-1, i+k); l++ {
+				sum -= o.fact[k+i-l+l*ld] * x[l]
+			}
+			x[i] = sum / o.fact[k+i*ld]
+		}
+		return
+	}
+	kv, ld := o.Kl+o.Ku, 2*o.Kl+o.Ku+1
+	for j := 0; j < n-1; j++ { // L⋅y = P⋅b
+		if l := o.ipiv[j]; l != j {
+			x[l], x[j] = x[j], x[l]
+		}
+		for p := 1; p <= utl.Imin(o.Kl, n-1-j); p++ {
+			x[j+p] -= o.fact[kv+p+j*ld] * x[j]
+		}
+	}
+	for j := n - 1; j >= 0; j-- { // U⋅x = y
+		x[j] /= o.fact[kv+j*ld]
+		for i := utl.Imax(0, j-kv); i < j; i++ {
+			x[i] -= o.fact[kv+i-j+j*ld] * x[j]
+		}
+	}
+}
+
+// BandMatVecMul returns the matrix-vector multiplication with a banded matrix
+//
+//  v = α⋅a⋅u    ⇒    vi = α * aij * uj
+//
+func BandMatVecMul(v Vector, α float64, a *BandMatrix, u Vector) {
+	for i := 0; i < a.N; i++ {
+		v[i] = 0
+	}
+	for j := 0; j < a.N; j++ {
+		i0, i1 := a.rowRange(j)
+		for i := i0; i <= i1; i++ {
+			aij := a.Data[a.index(i, j)]
+			v[i] += α * aij * u[j]
+			if a.Sym && i != j {
+				v[j] += α * aij * u[i]
+			}
+		}
+	}
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// index returns the position of (i,j) in Data; (i,j) must be within the band
+func (o *BandMatrix) index(i, j int) int {
+	if o.Sym && i > j {
+		i, j = j, i
+	}
+	return o.Ku + i - j + j*o.Ldab
+}
+
+// rowRange returns the first and last rows of the stored band of column j
+func (o *BandMatrix) rowRange(j int) (i0, i1 int) {
+	i0 = utl.Imax(0, j-o.Ku)
+	if o.Sym {
+		return i0, j
+	}
+	return i0, utl.Imin(o.N-1, j+o.Kl)
+}
+
+// factorizeLU computes the LU factorisation with partial pivoting (dgbtf2)
+func (o *BandMatrix) factorizeLU() {
+	n, kl, ku := o.N, o.Kl, o.Ku
+	kv := kl + ku
+	ld := 2*kl + ku + 1
+	o.fact = make([]float64, ld*n)
+	o.ipiv = make([]int, n)
+	for j := 0; j < n; j++ {
+		for i := utl.Imax(0, j-ku); i <= utl.Imin(n-1, j+kl); i++ {
+			o.fact[kv+i-j+j*ld] = o.Data[ku+i-j+j*o.Ldab]
+		}
+	}
+	a := func(i, j int) *float64 { return &o.fact[kv+i-j+j*ld] }
+	ju := 0 // last column affected by the current stage
+	for j := 0; j < n; j++ {
+
+		// find pivot
+		km := utl.Imin(kl, n-1-j)
+		jp := 0
+		for p := 1; p <= km; p++ {
+			if math.Abs(*a(j+p, j)) > math.Abs(*a(j+jp, j)) {
+				jp = p
+			}
+		}
+		o.ipiv[j] = j + jp
+		piv := *a(j+jp, j)
+		if piv == 0 {
+			o.fact = nil
+			chk.Panic("LU factorisation failed: matrix is singular (zero pivot at column %d)\n", j)
+		}
+		ju = utl.Imax(ju, utl.Imin(j+ku+jp, n-1))
+
+		// swap rows
+		if jp != 0 {
+			for c := j; c <= ju; c++ {
+				*a(j, c), *a(j+jp, c) = *a(j+jp, c), *a(j, c)
+			}
+		}
+
+		// compute multipliers and update trailing submatrix
+		for p := 1; p <= km; p++ {
+			*a(j+p, j) /= piv
+		}
+		for c := j + 1; c <= ju; c++ {
+			ajc := *a(j, c)
+			if ajc == 0 {
+				continue
+			}
+			for p := 1; p <= km; p++ {
+				*a(j+p, c) -= *a(j+p, j) * ajc
+			}
+		}
+	}
+}
+
+// factorizeCholesky computes the Cholesky factorisation A = Uᵀ⋅U (dpbtf2)
+func (o *BandMatrix) factorizeCholesky() {
+	n, k, ld := o.N, o.Ku, o.Ldab
+	o.fact = make([]float64, len(o.Data))
+	copy(o.fact, o.Data)
+	u := o.fact
+	for j := 0; j < n; j++ {
+		l0 := utl.Imax(0, j-k)
+		for i := l0; i <= j; i++ {
+			sum := u[k+i-j+j*ld]
+			for l := l0; l < i; l++ {
+				sum -= u[k+l-i+i*ld] * u[k+l-j+j*ld]
+			}
+			if i < j {
+				u[k+i-j+j*ld] = sum / u[k+i*ld]
+				continue
+			}
+			if sum <= 0 {
+				o.fact = nil
+				chk.Panic("Cholesky factorisation failed: matrix is not positive-definite (column %d)\n", j)
+			}
+			u[k+j*ld] = math.Sqrt(sum)
+		}
+	}
+}
@@ -0,0 +1,452 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/la/oblas"
+)
+
+// MatExp computes the matrix exponential expA := exp(A) using the scaling-and-squaring method
+// with Padé approximants
+//
+//   Input:
+//     A -- square matrix [not modified]
+//   Output:
+//     expA -- exp(A) [pre-allocated]
+//
+//   Reference:
+//     [1] Higham NJ (2005) The scaling and squaring method for the matrix exponential revisited.
+//         SIAM J. Matrix Anal. Appl. 26(4):1179-1193
+//
+func MatExp(expA, A *Matrix) {
+
+	// check
+	n := A.M
+	if A.N != n {
+		chk.Panic("matrix must be square. %d != %d\n", A.M, A.N)
+	}
+
+	// low degree Padé approximants
+	nrm := matNorm1(A)
+	for k := 0; k < 4; k++ {
+		if nrm <= expPadeTheta[k] {
+			expPade(expA, A, expPadeCoef[k])
+			return
+		}
+	}
+
+	// scaling
+	s := 0
+	if nrm > expPadeTheta[4] {
+		s = int(math.Ceil(math.Log2(nrm / expPadeTheta[4])))
+	}
+	As := A.GetCopy()
+	if s > 0 {
+		As.Apply(math.Pow(2, float64(-s)), As)
+	}
+
+	// Padé approximant of degree 13
+	b := expPadeCoef[4]
+	I := NewMatrix(n, n)
+	I.SetDiag(1)
+	A2 := NewMatrix(n, n)
+	A4 := NewMatrix(n, n)
+	A6 := NewMatrix(n, n)
+	MatMatMul(A2, 1, As, As)
+	MatMatMul(A4, 1, A2, A2)
+	MatMatMul(A6, 1, A4, A2)
+	t := NewMatrix(n, n)
+	u := NewMatrix(n, n)
+	v := NewMatrix(n, n)
+	matLinComb(t, []float64{b[13], b[11], b[9]}, A6, A4, A2)
+	MatMatMul(u, 1, A6, t)
+	matAddLinComb(u, []float64{b[7], b[5], b[3], b[1]}, A6, A4, A2, I)
+	U := NewMatrix(n, n)
+	MatMatMul(U, 1, As, u)
+	matLinComb(t, []float64{b[12], b[10], b[8]}, A6, A4, A2)
+	MatMatMul(v, 1, A6, t)
+	matAddLinComb(v, []float64{b[6], b[4], b[2], b[0]}, A6, A4, A2, I)
+	expRational(expA, U, v)
+
+	// squaring
+	for k := 0; k < s; k++ {
+		MatMatMul(t, 1, expA, expA)
+		copy(expA.Data, t.Data)
+	}
+}
+
+// MatSqrt computes the principal square root sqrtA := √A; i.e. sqrtA ⋅ sqrtA = A
+//
+//   Input:
+//     A -- square matrix with no eigenvalues on the closed negative real axis [not modified]
+//   Output:
+//     sqrtA -- √A [pre-allocated]
+//
+//   NOTE: the scaled Denman-Beavers iteration is used
+//
+//   Reference:
+//     [1] Higham NJ (2008) Functions of Matrices: Theory and Computation. SIAM. 425p
+//
+func MatSqrt(sqrtA, A *Matrix) {
+
+	// check
+	n := A.M
+	if A.N != n {
+		chk.Panic("matrix must be square. %d != %d\n", A.M, A.N)
+	}
+
+	// Y₀ = A and Z₀ = I
+	Y := sqrtA
+	copy(Y.Data, A.Data)
+	Z := NewMatrix(n, n)
+	Z.SetDiag(1)
+	Yi := NewMatrix(n, n)
+	Zi := NewMatrix(n, n)
+	Yold := NewMatrix(n, n)
+
+	// iterations
+	// NOTE: the relative change of Y stagnates at the level of rounding errors (amplified by the
+	//       condition number of A); thus, the iterations also stop when the change (already small)
+	//       does not decrease after the scaling phase
+	maxIt := 100
+	tol := 1e3 * float64(n) * machEps
+	var rel, relOld float64
+	for it := 0; it < maxIt; it++ {
+		copy(Yold.Data, Y.Data)
+		detY := MatInv(Yi, Y, true)
+		detZ := MatInv(Zi, Z, true)
+		μ := 1.0
+		if it < 5 { // determinantal scaling
+			μ = math.Pow(math.Abs(detY*detZ), -1.0/float64(2*n))
+			if math.IsInf(μ, 0) || math.IsNaN(μ) || μ == 0 {
+				μ = 1
+			}
+		}
+		MatAdd(Y, 0.5/μ, Zi, 0.5*μ, Y) // Y := (μ⋅Y + Z⁻¹/μ) / 2
+		MatAdd(Z, 0.5/μ, Yi, 0.5*μ, Z) // Z := (μ⋅Z + Y⁻¹/μ) / 2
+		rel = Y.MaxDiff(Yold) / matNorm1(Y)
+		if it > 0 && (rel <= tol || (it > 5 && rel >= relOld && relOld <= math.Sqrt(tol))) {
+			return
+		}
+		relOld = rel
+	}
+	chk.Panic("Denman-Beavers iterations did not converge after %d iterations\n", maxIt)
+}
+
+// MatLog computes the principal logarithm logA := log(A); i.e. exp(logA) = A
+//
+//   Input:
+//     A -- square matrix with no eigenvalues on the closed negative real axis [not modified]
+//   Output:
+//     logA -- log(A) [pre-allocated]
+//
+//   NOTE: the inverse scaling and squaring method is used: square roots are taken until
+//         ‖A^(1/2ᵏ) - I‖ is small and then log(I + X) is computed with the diagonal Padé
+//         approximant in partial fraction form (Gauss-Legendre quadrature)
+//
+//   Reference:
+//     [1] Higham NJ (2008) Functions of Matrices: Theory and Computation. SIAM. 425p
+//
+func MatLog(logA, A *Matrix) {
+
+	// check
+	n := A.M
+	if A.N != n {
+		chk.Panic("matrix must be square. %d != %d\n", A.M, A.N)
+	}
+
+	// square roots until X = A^(1/2ᵏ) - I is small
+	R := A.GetCopy()
+	S := NewMatrix(n, n)
+	X := NewMatrix(n, n)
+	k := 0
+	for {
+		copy(X.Data, R.Data)
+		for i := 0; i < n; i++ {
+			X.Add(i, i, -1)
+		}
+		if matNorm1(X) <= 0.25 {
+			break
+		}
+		if k > 64 {
+			chk.Panic("cannot compute logarithm of matrix. too many square roots\n")
+		}
+		MatSqrt(S, R)
+		copy(R.Data, S.Data)
+		k++
+	}
+
+	// log(I + X) = ∫₀¹ X ⋅ (I + t⋅X)⁻¹ dt
+	logA.Fill(0)
+	M := NewMatrix(n, n)
+	ipiv := make([]int32, n)
+	for q, x := range logGaussX {
+		t := (1 + x) / 2
+		copy(M.Data, X.Data)
+		M.Apply(t, M)
+		for i := 0; i < n; i++ {
+			M.Add(i, i, 1)
+		}
+		copy(S.Data, X.Data)
+		oblas.Dgesv(n, n, M.Data, n, ipiv, S.Data, n) // S := (I + t⋅X)⁻¹ ⋅ X
+		MatAdd(logA, logGaussW[q]/2, S, 1, logA)
+	}
+	logA.Apply(math.Pow(2, float64(k)), logA)
+}
+
+// MatPhi computes the φ-functions of exponential integrators
+//
+//   φ₀(A) = exp(A)
+//   φₖ₊₁(A) = A⁻¹ ⋅ (φₖ(A) - I/k!)    i.e.   φₖ(z) = Σᵢ zⁱ / (i+k)!
+//
+//   Input:
+//     A -- square matrix [not modified]. may be singular
+//   Output:
+//     phi -- φₖ(A) for k = 0 … len(phi)-1 [pre-allocated]
+//
+//   NOTE: the φ-functions are extracted from the exponential of the augmented matrix
+//
+//               ┌                   ┐
+//               │ A  I  0  …  0     │
+//               │ 0  0  I  …  0     │          ┌                        ┐
+//         exp ( │ ⋮           ⋮     │ )  = first block row of │ φ₀(A)  φ₁(A)  …  φₚ(A) │
+//               │ 0  0  0  …  I     │          └                        ┘
+//               │ 0  0  0  …  0     │
+//               └                   ┘
+//
+//   Reference:
+//     [1] Sidje RB (1998) Expokit: A software package for computing matrix exponentials.
+//         ACM Trans. Math. Softw. 24(1):130-156
+//
+func MatPhi(phi []*Matrix, A *Matrix) {
+
+	// check
+	n := A.M
+	if A.N != n {
+		chk.Panic("matrix must be square. %d != %d\n", A.M, A.N)
+	}
+	p := len(phi) - 1
+	if p < 0 {
+		return
+	}
+	for k := 0; k <= p; k++ {
+		if phi[k].M != n || phi[k].N != n {
+			chk.Panic("phi[%d] must be (%d,%d). (%d,%d) is invalid\n", k, n, n, phi[k].M, phi[k].N)
+		}
+	}
+
+	// compute
+	if p == 0 {
+		MatExp(phi[0], A)
+		return
+	}
+	N := (p + 1) * n
+	W := NewMatrix(N, N)
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			W.Set(i, j, A.Get(i, j))
+		}
+	}
+	for k := 0; k < p; k++ {
+		for i := 0; i < n; i++ {
+			W.Set(k*n+i, (k+1)*n+i, 1)
+		}
+	}
+	E := NewMatrix(N, N)
+	MatExp(E, W)
+	for k := 0; k <= p; k++ {
+		for j := 0; j < n; j++ {
+			for i := 0; i < n; i++ {
+				phi[k].Set(i, j, E.Get(i, k*n+j))
+			}
+		}
+	}
+}
+
+// SpMatExpVec computes the action of the matrix exponential y := exp(t⋅A) ⋅ x without computing
+// exp(t⋅A); i.e. only matrix-vector products with the sparse matrix A are used
+//
+//   Input:
+//     t -- scalar multiplying A
+//     A -- square sparse matrix
+//     x -- vector
+//   Output:
+//     y -- exp(t⋅A) ⋅ x [pre-allocated]. y and x may be the same vector
+//
+//   NOTE: truncated Taylor series with scaling (s steps of size t/s) and shifting by trace(A)/n
+//
+//   Reference:
+//     [1] Al-Mohy AH and Higham NJ (2011) Computing the action of the matrix exponential, with an
+//         application to exponential integrators. SIAM J. Sci. Comput. 33(2):488-511
+//
+func SpMatExpVec(y Vector, t float64, A *CCMatrix, x Vector) {
+
+	// check
+	n := A.n
+	if A.m != n {
+		chk.Panic("matrix must be square. %d != %d\n", A.m, A.n)
+	}
+
+	// shift: B = A - μ⋅I with μ = trace(A)/n
+	var μ float64
+	for j := 0; j < n; j++ {
+		for p := A.p[j]; p < A.p[j+1]; p++ {
+			if A.i[p] == j {
+				μ += A.x[p]
+			}
+		}
+	}
+	μ /= float64(n)
+
+	// 1-norm of B
+	var nrm float64
+	for j := 0; j < n; j++ {
+		var s, d float64
+		for p := A.p[j]; p < A.p[j+1]; p++ {
+			if A.i[p] == j {
+				d += A.x[p]
+			} else {
+				s += math.Abs(A.x[p])
+			}
+		}
+		nrm = math.Max(nrm, s+math.Abs(d-μ))
+	}
+
+	// select degree m and number of steps s minimising m⋅s
+	tn := math.Abs(t) * nrm
+	m, s := 0, 1
+	if tn > 0 {
+		cost := math.MaxInt64
+		for k, θ := range expmvTheta {
+			sk := int(math.Ceil(tn / θ))
+			if mk := expmvDegree[k]; mk*sk < cost {
+				m, s, cost = mk, sk, mk*sk
+			}
+		}
+	}
+
+	// compute F = exp(t⋅A) ⋅ x
+	F := x.GetCopy()
+	b := x.GetCopy()
+	tmp := NewVector(n)
+	η := math.Exp(t * μ / float64(s))
+	tol := machEps / 2
+	for i := 0; i < s; i++ {
+		c1 := b.NormInf()
+		for j := 0; j < m; j++ {
+			SpMatVecMul(tmp, 1, A, b)
+			coef := t / float64(s*(j+1))
+			for k := 0; k < n; k++ {
+				b[k] = coef * (tmp[k] - μ*b[k])
+			}
+			c2 := b.NormInf()
+			for k := 0; k < n; k++ {
+				F[k] += b[k]
+			}
+			if c1+c2 <= tol*F.NormInf() {
+				break
+			}
+			c1 = c2
+		}
+		F.Apply(η, F)
+		copy(b, F)
+	}
+	copy(y, F)
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// expPadeTheta holds the maximum 1-norm for each Padé degree (3, 5, 7, 9 and 13)
+var expPadeTheta = []float64{1.495585217958292e-2, 2.539398330063230e-1, 9.504178996162932e-1, 2.097847961257068e0, 5.371920351148152e0}
+
+// expPadeCoef holds the coefficients of the Padé approximants of degree 3, 5, 7, 9 and 13
+var expPadeCoef = [][]float64{
+	{120, 60, 12, 1},
+	{30240, 15120, 3360, 420, 30, 1},
+	{17297280, 8648640, 1995840, 277200, 25200, 1512, 56, 1},
+	{17643225600, 8821612800, 2075673600, 302702400, 30270240, 2162160, 110880, 3960, 90, 1},
+	{64764752532480000, 32382376266240000, 7771770303897600, 1187353796428800, 129060195264000,
+		10559470521600, 670442572800, 33522128640, 1323241920, 40840800, 960960, 16380, 182, 1},
+}
+
+// expmvDegree and expmvTheta hold the degrees of the Taylor polynomials and corresponding
+// maximum values of ‖t⋅A‖₁ for double precision (Table 3.1 of Al-Mohy and Higham 2011)
+var (
+	expmvDegree = []int{5, 10, 15, 20, 25, 30, 35, 40, 45, 50, 55}
+	expmvTheta  = []float64{2.40e-3, 1.44e-1, 6.41e-1, 1.44, 2.43, 3.54, 4.7, 6.0, 7.2, 8.5, 9.9}
+)
+
+// logGaussX and logGaussW hold the 8-point Gauss-Legendre quadrature points and weights in [-1,1]
+var (
+	logGaussX = []float64{-0.9602898564975363, -0.7966664774136267, -0.5255324099163290, -0.1834346424956498,
+		0.1834346424956498, 0.5255324099163290, 0.7966664774136267, 0.9602898564975363}
+	logGaussW = []float64{0.1012285362903763, 0.2223810344533745, 0.3137066458778873, 0.3626837833783620,
+		0.3626837833783620, 0.3137066458778873, 0.2223810344533745, 0.1012285362903763}
+)
+
+// expPade computes the Padé approximant of low degree m = len(b)-1 of exp(A)
+func expPade(expA, A *Matrix, b []float64) {
+	n := A.M
+	m := len(b) - 1
+	U := NewMatrix(n, n)
+	V := NewMatrix(n, n)
+	P := NewMatrix(n, n) // A^(2k)
+	P.SetDiag(1)
+	A2 := NewMatrix(n, n)
+	MatMatMul(A2, 1, A, A)
+	t := NewMatrix(n, n)
+	for k := 0; 2*k <= m; k++ {
+		if k > 0 {
+			MatMatMul(t, 1, P, A2)
+			copy(P.Data, t.Data)
+		}
+		MatAdd(V, b[2*k], P, 1, V)
+		if 2*k+1 <= m {
+			MatAdd(U, b[2*k+1], P, 1, U)
+		}
+	}
+	MatMatMul(t, 1, A, U)
+	expRational(expA, t, V)
+}
+
+// expRational computes F := (V - U)⁻¹ ⋅ (V + U)
+func expRational(F, U, V *Matrix) {
+	n := U.M
+	D := NewMatrix(n, n)
+	MatAdd(D, 1, V, -1, U)
+	MatAdd(F, 1, V, 1, U)
+	ipiv := make([]int32, n)
+	oblas.Dgesv(n, n, D.Data, n, ipiv, F.Data, n)
+}
+
+// matLinComb computes res := Σ cᵢ⋅Mᵢ
+func matLinComb(res *Matrix, c []float64, M ...*Matrix) {
+	res.Fill(0)
+	matAddLinComb(res, c, M...)
+}
+
+// matAddLinComb computes res += Σ cᵢ⋅Mᵢ
+func matAddLinComb(res *Matrix, c []float64, M ...*Matrix) {
+	for i, m := range M {
+		for k := range res.Data {
+			res.Data[k] += c[i] * m.Data[k]
+		}
+	}
+}
+
+// matNorm1 returns the 1-norm (maximum absolute column sum) of a matrix
+func matNorm1(a *Matrix) (nrm float64) {
+	for j := 0; j < a.N; j++ {
+		var s float64
+		for i := 0; i < a.M; i++ {
+			s += math.Abs(a.Get(i, j))
+		}
+		nrm = math.Max(nrm, s)
+	}
+	return
+}
@@ -0,0 +1,375 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"bytes"
+	"math/cmplx"
+	"strings"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+)
+
+// Matrix Market files (.mtx) ////////////////////////////////////////////////////////////////////
+//
+//   %%MatrixMarket matrix <format> <field> <symmetry>
+//   % comments
+//   m n nnz            (coordinate)   or   m n   (array)
+//   i j x              (coordinate; 1-based indices)
+//   x                  (array; column-major)
+//
+//   format   -- "coordinate" or "array"
+//   field    -- "real", "integer", "complex" or "pattern"
+//   symmetry -- "general", "symmetric", "skew-symmetric" or "hermitian"
+//
+//   NOTE: only the lower triangle is stored in files with symmetry other than "general"
+//
+//   See https://math.nist.gov/MatrixMarket/formats.html
+
+// ReadMtx reads a Matrix Market file (coordinate or array format)
+//
+//   NOTE: (1) symmetric and skew-symmetric matrices are expanded to the full matrix
+//         (2) "pattern" files produce unit values
+//         (3) zero values of "array" files are not stored in the triplet
+//         (4) complex files must be read with TripletC
+//
+func (o *Triplet) ReadMtx(filename string) {
+	d := mtxRead(filename)
+	if d.cplx {
+		chk.Panic("cannot read complex matrix into real triplet. use TripletC instead. file = <%s>\n", filename)
+	}
+	d.expand()
+	o.Init(d.m, d.n, len(d.x))
+	for k, x := range d.x {
+		o.Put(d.i[k], d.j[k], real(x))
+	}
+}
+
+// WriteMtx writes a Matrix Market file (coordinate format)
+//
+//  NOTE: this method will create a CCMatrix first because
+//        duplicates must be added before saving the file
+//
+//  dirout   -- directory for output. will be created
+//  fnkey    -- filename key (filename without extension). ".mtx" will be added
+//  symmetry -- "general" (or ""), "symmetric" or "skew-symmetric"
+func (o *Triplet) WriteMtx(dirout, fnkey, symmetry string) (cmat *CCMatrix) {
+	cmat = o.ToMatrix(nil)
+	cmat.WriteMtx(dirout, fnkey, symmetry)
+	return
+}
+
+// WriteMtx writes a Matrix Market file (coordinate format)
+//
+//  NOTE: the matrix is assumed to have the given symmetry; i.e. only the
+//        lower triangle is written if symmetry is not "general"
+//
+//  dirout   -- directory for output. will be created
+//  fnkey    -- filename key (filename without extension). ".mtx" will be added
+//  symmetry -- "general" (or ""), "symmetric" or "skew-symmetric"
+func (o *CCMatrix) WriteMtx(dirout, fnkey, symmetry string) {
+	symmetry = spIoSymmetry(symmetry, false)
+	var bfa, bfb bytes.Buffer
+	var nnz int
+	for j := 0; j < o.n; j++ {
+		for p := o.p[j]; p < o.p[j+1]; p++ {
+			if spIoKeep(symmetry, o.i[p], j) {
+				io.Ff(&bfb, "%d %d %23.15e\n", o.i[p]+1, j+1, o.x[p])
+				nnz++
+			}
+		}
+	}
+	io.Ff(&bfa, "%%%%MatrixMarket matrix coordinate real %s\n", symmetry)
+	io.Ff(&bfa, "%d %d %d\n", o.m, o.n, nnz)
+	io.WriteFileVD(dirout, fnkey+".mtx", &bfa, &bfb)
+}
+
+// ReadMtx reads a Matrix Market file (coordinate or array format)
+//
+//   NOTE: (1) symmetric, skew-symmetric and hermitian matrices are expanded to the full matrix
+//         (2) "pattern" files produce unit values
+//         (3) zero values of "array" files are not stored in the triplet
+//
+func (o *TripletC) ReadMtx(filename string) {
+	d := mtxRead(filename)
+	d.expand()
+	o.Init(d.m, d.n, len(d.x))
+	for k, x := range d.x {
+		o.Put(d.i[k], d.j[k], x)
+	}
+}
+
+// WriteMtx writes a Matrix Market file (coordinate format; complex field)
+//
+//  NOTE: this method will create a CCMatrixC first because
+//        duplicates must be added before saving the file
+//
+//  dirout   -- directory for output. will be created
+//  fnkey    -- filename key (filename without extension). ".mtx" will be added
+//  symmetry -- "general" (or ""), "symmetric", "skew-symmetric" or "hermitian"
+func (o *TripletC) WriteMtx(dirout, fnkey, symmetry string) (cmat *CCMatrixC) {
+	cmat = o.ToMatrix(nil)
+	cmat.WriteMtx(dirout, fnkey, symmetry)
+	return
+}
+
+// WriteMtx writes a Matrix Market file (coordinate format; complex field)
+//
+//  NOTE: the matrix is assumed to have the given symmetry; i.e. only the
+//        lower triangle is written if symmetry is not "general"
+//
+//  dirout   -- directory for output. will be created
+//  fnkey    -- filename key (filename without extension). ".mtx" will be added
+//  symmetry -- "general" (or ""), "symmetric", "skew-symmetric" or "hermitian"
+func (o *CCMatrixC) WriteMtx(dirout, fnkey, symmetry string) {
+	symmetry = spIoSymmetry(symmetry, true)
+	var bfa, bfb bytes.Buffer
+	var nnz int
+	for j := 0; j < o.n; j++ {
+		for p := o.p[j]; p < o.p[j+1]; p++ {
+			if spIoKeep(symmetry, o.i[p], j) {
+				io.Ff(&bfb, "%d %d %23.15e %23.15e\n", o.i[p]+1, j+1, real(o.x[p]), imag(o.x[p]))
+				nnz++
+			}
+		}
+	}
+	io.Ff(&bfa, "%%%%MatrixMarket matrix coordinate complex %s\n", symmetry)
+	io.Ff(&bfa, "%d %d %d\n", o.m, o.n, nnz)
+	io.WriteFileVD(dirout, fnkey+".mtx", &bfa, &bfb)
+}
+
+// ReadMtx reads a Matrix Market file (array or coordinate format) into this dense matrix
+//
+//   NOTE: the matrix is re-allocated; symmetric and skew-symmetric matrices are expanded
+//
+func (o *Matrix) ReadMtx(filename string) {
+	d := mtxRead(filename)
+	if d.cplx {
+		chk.Panic("cannot read complex matrix into real dense matrix. file = <%s>\n", filename)
+	}
+	d.expand()
+	o.M, o.N = d.m, d.n
+	o.Data = make([]float64, d.m*d.n)
+	for k, x := range d.x {
+		o.Add(d.i[k], d.j[k], real(x))
+	}
+}
+
+// WriteMtx writes a Matrix Market file (array format; real general)
+//
+//  dirout -- directory for output. will be created
+//  fnkey  -- filename key (filename without extension). ".mtx" will be added
+func (o *Matrix) WriteMtx(dirout, fnkey string) {
+	var buf bytes.Buffer
+	io.Ff(&buf, "%%%%MatrixMarket matrix array real general\n")
+	io.Ff(&buf, "%d %d\n", o.M, o.N)
+	for _, x := range o.Data { // col-major
+		io.Ff(&buf, "%23.15e\n", x)
+	}
+	io.WriteFileVD(dirout, fnkey+".mtx", &buf)
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// spIoData holds the data read from Matrix Market or Harwell-Boeing files
+type spIoData struct {
+	m, n     int          // dimensions
+	symmetry string       // "general", "symmetric", "skew-symmetric" or "hermitian"
+	cplx     bool         // complex values
+	i, j     []int        // indices (0-based)
+	x        []complex128 // values
+}
+
+// put appends an entry
+func (o *spIoData) put(i, j int, x complex128) {
+	if i < 0 || i >= o.m || j < 0 || j >= o.n {
+		chk.Panic("index (%d,%d) is out of range. dimensions = (%d,%d)\n", i+1, j+1, o.m, o.n)
+	}
+	o.i = append(o.i, i)
+	o.j = append(o.j, j)
+	o.x = append(o.x, x)
+}
+
+// expand adds the upper triangle of symmetric, skew-symmetric and hermitian matrices
+func (o *spIoData) expand() {
+	if o.symmetry == "general" {
+		return
+	}
+	nnz := len(o.x)
+	for k := 0; k < nnz; k++ {
+		i, j, x := o.i[k], o.j[k], o.x[k]
+		if i == j {
+			continue
+		}
+		switch o.symmetry {
+		case "symmetric":
+			o.put(j, i, x)
+		case "skew-symmetric":
+			o.put(j, i, -x)
+		case "hermitian":
+			o.put(j, i, cmplx.Conj(x))
+		}
+	}
+	o.symmetry = "general"
+}
+
+// spIoSymmetry validates the symmetry keyword
+func spIoSymmetry(symmetry string, cplx bool) string {
+	switch strings.ToLower(symmetry) {
+	case "", "general":
+		return "general"
+	case "symmetric":
+		return "symmetric"
+	case "skew-symmetric":
+		return "skew-symmetric"
+	case "hermitian":
+		if cplx {
+			return "hermitian"
+		}
+	}
+	chk.Panic("symmetry %q is not available (complex = %v)\n", symmetry, cplx)
+	return ""
+}
+
+// spIoKeep tells whether the entry (i,j) is stored in files with the given symmetry
+func spIoKeep(symmetry string, i, j int) bool {
+	switch symmetry {
+	case "general":
+		return true
+	case "skew-symmetric":
+		return i > j
+	}
+	return i >= j
+}
+
+// mtxRead reads a Matrix Market file
+func mtxRead(filename string) (d *spIoData) {
+	d = new(spIoData)
+	var format, field string
+	var nnz, col, row int
+	sizeRead := false
+	nfields := 1
+	io.ReadLines(filename, func(idx int, line string) (stop bool) {
+
+		// header
+		if idx == 0 {
+			r := strings.Fields(strings.ToLower(line))
+			if len(r) != 5 || r[0] != "%%matrixmarket" || r[1] != "matrix" {
+				chk.Panic("invalid Matrix Market header. file = <%s>\n", filename)
+			}
+			format, field = r[2], r[3]
+			if format != "coordinate" && format != "array" {
+				chk.Panic("Matrix Market format %q is not available\n", format)
+			}
+			switch field {
+			case "real", "integer":
+			case "complex":
+				d.cplx = true
+				nfields = 2
+			case "pattern":
+				if format == "array" {
+					chk.Panic("pattern field is invalid with array format\n")
+				}
+				nfields = 0
+			default:
+				chk.Panic("Matrix Market field %q is not available\n", field)
+			}
+			d.symmetry = spIoSymmetry(r[4], d.cplx)
+			return
+		}
+
+		// skip comments and blank lines
+		r := strings.Fields(line)
+		if len(r) == 0 || strings.HasPrefix(r[0], "%") {
+			return
+		}
+
+		// size
+		if !sizeRead {
+			sizeRead = true
+			if len(r) < 2 {
+				chk.Panic("invalid size line in Matrix Market file <%s>\n", filename)
+			}
+			d.m, d.n = io.Atoi(r[0]), io.Atoi(r[1])
+			if format == "coordinate" {
+				if len(r) != 3 {
+					chk.Panic("size line of coordinate format must have 3 columns (m,n,nnz)\n")
+				}
+				nnz = io.Atoi(r[2])
+			} else {
+				nnz = d.m * d.n
+				if d.symmetry != "general" {
+					if d.m != d.n {
+						chk.Panic("matrix with %s symmetry must be square. %d != %d\n", d.symmetry, d.m, d.n)
+					}
+					nnz = d.n * (d.n + 1) / 2
+					if d.symmetry == "skew-symmetric" {
+						nnz -= d.n
+					}
+				}
+				row = spIoFirstRow(d.symmetry, 0)
+			}
+			d.i = make([]int, 0, 2*nnz)
+			d.j = make([]int, 0, 2*nnz)
+			d.x = make([]complex128, 0, 2*nnz)
+			return
+		}
+
+		// data
+		if len(d.x) >= nnz {
+			chk.Panic("Matrix Market file <%s> has more entries than declared (%d)\n", filename, nnz)
+		}
+		var i, j int
+		var v []string
+		if format == "coordinate" {
+			if len(r) != 2+nfields {
+				chk.Panic("data lines of %s matrix must have %d columns\n", field, 2+nfields)
+			}
+			i, j, v = io.Atoi(r[0])-1, io.Atoi(r[1])-1, r[2:]
+		} else {
+			if len(r) != nfields {
+				chk.Panic("data lines of %s array must have %d columns\n", field, nfields)
+			}
+			i, j, v = row, col, r
+			row++
+			if row == d.m {
+				col++
+				row = spIoFirstRow(d.symmetry, col)
+			}
+		}
+		var x complex128 = 1
+		switch nfields {
+		case 1:
+			x = complex(io.Atof(v[0]), 0)
+		case 2:
+			x = complex(io.Atof(v[0]), io.Atof(v[1]))
+		}
+		if format == "array" && x == 0 {
+			nnz--
+			return
+		}
+		if !spIoKeep(d.symmetry, i, j) {
+			chk.Panic("entry (%d,%d) is in the upper triangle of %s matrix\n", i+1, j+1, d.symmetry)
+		}
+		d.put(i, j, x)
+		return
+	})
+	if !sizeRead || len(d.x) != nnz {
+		chk.Panic("Matrix Market file <%s> has fewer entries (%d) than declared (%d)\n", filename, len(d.x), nnz)
+	}
+	return
+}
+
+// spIoFirstRow returns the first stored row of column j in array format
+func spIoFirstRow(symmetry string, j int) int {
+	switch symmetry {
+	case "general":
+		return 0
+	case "skew-symmetric":
+		return j + 1
+	}
+	return j
+}
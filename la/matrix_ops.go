@@ -12,6 +12,9 @@ import (
 	"github.com/dicksontsai/gosl/utl"
 )
 
+// machEps is the machine epsilon; i.e. the smallest number satisfying 1 + ϵ > 1
+var machEps = math.Nextafter(1, 2) - 1
+
 // MatInvSmall computes the inverse of small matrices of size 1x1, 2x2, or 3x3.
 // It also returns the determinant.
 //   Input:
@@ -0,0 +1,400 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// QR holds the Householder QR decomposition of a (M x N) matrix A
+//
+//   A ⋅ P = Q ⋅ R
+//
+//   where Q is an (M x M) orthogonal matrix, R is an (M x N) upper triangular (trapezoidal) matrix
+//   and P is a permutation matrix (identity if column pivoting is not used)
+//
+//   Q = H₀ ⋅ H₁ ⋯ Hₖ₋₁ with k = min(M,N) and Hⱼ = I - τⱼ ⋅ vⱼ ⋅ vⱼᵀ (Householder reflectors)
+//
+//   With column pivoting, |R₀₀| ≥ |R₁₁| ≥ … and the decomposition reveals the numerical rank of A
+//
+type QR struct {
+	M, N int   // dimensions of A
+	Perm []int // column permutation: column j of A⋅P is column Perm[j] of A [nil if not pivoted]
+
+	// factors
+	qr  *Matrix // R on and above the diagonal and Householder vectors below the diagonal
+	tau Vector  // scalar factors of the Householder reflectors
+}
+
+// NewQR computes the Householder QR decomposition of A
+//
+//   A        -- (M x N) matrix [not modified]
+//   pivoting -- use column pivoting (rank-revealing QR)
+//
+func NewQR(A *Matrix, pivoting bool) (o *QR) {
+
+	// allocate
+	m, n := A.M, A.N
+	k := utl.Imin(m, n)
+	o = &QR{M: m, N: n, qr: A.GetCopy(), tau: NewVector(k)}
+	a := o.qr
+
+	// column norms for pivoting
+	var vn1, vn2 Vector
+	if pivoting {
+		o.Perm = utl.IntRange(n)
+		vn1 = NewVector(n)
+		vn2 = NewVector(n)
+		for j := 0; j < n; j++ {
+			vn1[j] = qrNorm(a, 0, j)
+			vn2[j] = vn1[j]
+		}
+	}
+	tol3z := math.Sqrt(machEps)
+
+	// factorisation
+	for j := 0; j < k; j++ {
+
+		// bring column with largest norm to position j
+		if pivoting {
+			p := j
+			for l := j + 1; l < n; l++ {
+				if vn1[l] > vn1[p] {
+					p = l
+				}
+			}
+			if p != j {
+				for i := 0; i < m; i++ {
+					aij, aip := a.Get(i, j), a.Get(i, p)
+					a.Set(i, j, aip)
+					a.Set(i, p, aij)
+				}
+				o.Perm[j], o.Perm[p] = o.Perm[p], o.Perm[j]
+				vn1[p], vn2[p] = vn1[j], vn2[j]
+			}
+		}
+
+		// reflector
+		o.tau[j] = qrHouseholder(a, j, j)
+
+		// apply reflector to remaining columns
+		for l := j + 1; l < n; l++ {
+			qrReflect(a, j, j, o.tau[j], a.Data[l*m:(l+1)*m])
+		}
+
+		// update column norms (see LAPACK dlaqp2)
+		if pivoting {
+			for l := j + 1; l < n; l++ {
+				if vn1[l] == 0 {
+					continue
+				}
+				t := math.Abs(a.Get(j, l)) / vn1[l]
+				t = math.Max(0, 1-t*t)
+				t2 := t * (vn1[l] / vn2[l]) * (vn1[l] / vn2[l])
+				if t2 <= tol3z {
+					vn1[l] = qrNorm(a, j+1, l)
+					vn2[l] = vn1[l]
+				} else {
+					vn1[l] *= math.Sqrt(t)
+				}
+			}
+		}
+	}
+	return
+}
+
+// ApplyQ computes y := Q ⋅ x   (len(x) = len(y) = M; x and y may be the same vector)
+func (o *QR) ApplyQ(y, x Vector) {
+	copy(y, x)
+	for j := len(o.tau) - 1; j >= 0; j-- {
+		qrReflect(o.qr, j, j, o.tau[j], y)
+	}
+}
+
+// ApplyQt computes y := Qᵀ ⋅ x   (len(x) = len(y) = M; x and y may be the same vector)
+func (o *QR) ApplyQt(y, x Vector) {
+	copy(y, x)
+	for j := 0; j < len(o.tau); j++ {
+		qrReflect(o.qr, j, j, o.tau[j], y)
+	}
+}
+
+// ApplyQmat computes C := Q ⋅ C or C := Qᵀ ⋅ C (if transpose) where C is (M x ncol)
+func (o *QR) ApplyQmat(C *Matrix, transpose bool) {
+	if C.M != o.M {
+		chk.Panic("number of rows of C must be equal to %d. %d is incorrect\n", o.M, C.M)
+	}
+	for j := 0; j < C.N; j++ {
+		c := C.Data[j*C.M : (j+1)*C.M]
+		if transpose {
+			o.ApplyQt(c, c)
+		} else {
+			o.ApplyQ(c, c)
+		}
+	}
+}
+
+// GetQ returns the orthogonal matrix Q
+//   thin -- returns the (M x min(M,N)) matrix with the first columns of Q only;
+//           otherwise returns the full (M x M) matrix
+func (o *QR) GetQ(thin bool) (Q *Matrix) {
+	ncol := o.M
+	if thin {
+		ncol = len(o.tau)
+	}
+	Q = NewMatrix(o.M, ncol)
+	for j := 0; j < ncol; j++ {
+		Q.Set(j, j, 1)
+	}
+	o.ApplyQmat(Q, false)
+	return
+}
+
+// GetR returns the upper triangular (trapezoidal) matrix R
+//   thin -- returns the (min(M,N) x N) matrix; otherwise returns the full (M x N) matrix
+func (o *QR) GetR(thin bool) (R *Matrix) {
+	nrow := o.M
+	if thin {
+		nrow = len(o.tau)
+	}
+	R = NewMatrix(nrow, o.N)
+	for j := 0; j < o.N; j++ {
+		for i := 0; i <= j && i < nrow; i++ {
+			R.Set(i, j, o.qr.Get(i, j))
+		}
+	}
+	return
+}
+
+// Rank estimates the numerical rank of A; i.e. the number of diagonal entries of R with
+// |Rᵢᵢ| > tol ⋅ |R₀₀|
+//
+//   tol -- relative tolerance. use tol ≤ 0 for the default value max(M,N) ⋅ ϵ
+//
+//   NOTE: the estimate is only reliable if the decomposition was computed with column pivoting
+//
+func (o *QR) Rank(tol float64) (rank int) {
+	if len(o.tau) == 0 {
+		return 0
+	}
+	if tol <= 0 {
+		tol = float64(utl.Imax(o.M, o.N)) * machEps
+	}
+	r00 := math.Abs(o.qr.Get(0, 0))
+	for rank = 0; rank < len(o.tau); rank++ {
+		if math.Abs(o.qr.Get(rank, rank)) <= tol*r00 || r00 == 0 {
+			break
+		}
+	}
+	return
+}
+
+// Solve solves the least-squares problem min ‖A⋅x - b‖₂
+//
+//   x -- solution [len(x) = N]
+//   b -- right-hand side [len(b) = M]
+//
+//   NOTE: (1) without pivoting, A must have full column rank (M ≥ N)
+//         (2) with pivoting, rank deficient matrices are handled by computing the basic solution
+//             with N - rank zero components. The minimum-norm solution is computed by LeastSquares
+//
+func (o *QR) Solve(x, b Vector) (rank int) {
+	rank = o.Rank(0)
+	if o.Perm == nil && rank < o.N {
+		chk.Panic("matrix is rank deficient (rank = %d < %d). use column pivoting instead\n", rank, o.N)
+	}
+	c := NewVector(o.M)
+	o.ApplyQt(c, b)
+	z := NewVector(o.N)
+	qrBackSubst(z, o.qr, c, rank)
+	o.permute(x, z)
+	return
+}
+
+// permute computes x := P ⋅ z
+func (o *QR) permute(x, z Vector) {
+	if o.Perm == nil {
+		copy(x, z)
+		return
+	}
+	for j, p := range o.Perm {
+		x[p] = z[j]
+	}
+}
+
+// LQ holds the LQ decomposition of a (M x N) matrix A
+//
+//   A = L ⋅ Q
+//
+//   where L is an (M x N) lower triangular (trapezoidal) matrix and Q is an (N x N) orthogonal
+//   matrix. The decomposition is computed from the QR decomposition of Aᵀ = Qᵀ ⋅ Lᵀ
+//
+type LQ struct {
+	M, N int // dimensions of A
+	qr   *QR // QR decomposition of Aᵀ
+}
+
+// NewLQ computes the LQ decomposition of A
+//   A -- (M x N) matrix [not modified]
+func NewLQ(A *Matrix) (o *LQ) {
+	return &LQ{M: A.M, N: A.N, qr: NewQR(A.GetTranspose(), false)}
+}
+
+// ApplyQ computes y := Q ⋅ x   (len(x) = len(y) = N; x and y may be the same vector)
+func (o *LQ) ApplyQ(y, x Vector) { o.qr.ApplyQt(y, x) }
+
+// ApplyQt computes y := Qᵀ ⋅ x   (len(x) = len(y) = N; x and y may be the same vector)
+func (o *LQ) ApplyQt(y, x Vector) { o.qr.ApplyQ(y, x) }
+
+// GetQ returns the orthogonal matrix Q
+//   thin -- returns the (min(M,N) x N) matrix with the first rows of Q only;
+//           otherwise returns the full (N x N) matrix
+func (o *LQ) GetQ(thin bool) (Q *Matrix) {
+	return o.qr.GetQ(thin).GetTranspose()
+}
+
+// GetL returns the lower triangular (trapezoidal) matrix L
+//   thin -- returns the (M x min(M,N)) matrix; otherwise returns the full (M x N) matrix
+func (o *LQ) GetL(thin bool) (L *Matrix) {
+	return o.qr.GetR(thin).GetTranspose()
+}
+
+// Solve computes the minimum-norm solution of the underdetermined system A ⋅ x = b
+//
+//   x -- solution [len(x) = N]
+//   b -- right-hand side [len(b) = M]
+//
+//   NOTE: A must have full row rank (M ≤ N)
+//
+func (o *LQ) Solve(x, b Vector) {
+	if rank := o.qr.Rank(0); rank < o.M {
+		chk.Panic("matrix is rank deficient (rank = %d < %d)\n", rank, o.M)
+	}
+	y := NewVector(o.N)
+	qrForwardSubst(y, o.qr.qr, b, o.M) // L ⋅ y = b with L = Rᵀ
+	o.ApplyQt(x, y)                    // x = Qᵀ ⋅ [y, 0]
+}
+
+// LeastSquares computes the minimum-norm solution of the least-squares problem min ‖A⋅x - b‖₂
+// using a complete orthogonal decomposition (column-pivoted QR followed by LQ)
+//
+//   A ⋅ P = Q ⋅ [R₁₁ R₁₂]   ⇒   [R₁₁ R₁₂] = T ⋅ Z
+//               [ 0   0 ]
+//
+//   Input:
+//     A   -- (M x N) matrix [not modified]. may be rank deficient; M may be smaller than N
+//     b   -- right-hand side [len(b) = M]
+//     tol -- relative tolerance to estimate the rank. use tol ≤ 0 for the default value
+//   Output:
+//     x    -- solution [len(x) = N]
+//     rank -- numerical rank of A
+//
+func LeastSquares(x Vector, A *Matrix, b Vector, tol float64) (rank int) {
+	qr := NewQR(A, true)
+	rank = qr.Rank(tol)
+	z := NewVector(A.N)
+	if rank == 0 {
+		x.Fill(0)
+		return
+	}
+	c := NewVector(A.M)
+	qr.ApplyQt(c, b)
+	if rank == A.N {
+		qrBackSubst(z, qr.qr, c, rank)
+	} else {
+		R := NewMatrix(rank, A.N)
+		for j := 0; j < A.N; j++ {
+			for i := 0; i <= j && i < rank; i++ {
+				R.Set(i, j, qr.qr.Get(i, j))
+			}
+		}
+		NewLQ(R).Solve(z, c[:rank])
+	}
+	qr.permute(x, z)
+	return
+}
+
+// MatRank estimates the numerical rank of A using the column-pivoted QR decomposition
+//   tol -- relative tolerance. use tol ≤ 0 for the default value max(M,N) ⋅ ϵ
+func MatRank(A *Matrix, tol float64) int {
+	return NewQR(A, true).Rank(tol)
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// qrNorm returns the norm of column j of a starting at row i
+func qrNorm(a *Matrix, i, j int) (nrm float64) {
+	for _, v := range a.Data[j*a.M+i : (j+1)*a.M] {
+		nrm = math.Hypot(nrm, v)
+	}
+	return
+}
+
+// qrHouseholder computes the Householder reflector that annihilates a[i+1:,j] (see LAPACK dlarfg)
+// and returns τ. The vector v (with v[0] = 1 implicit) is stored in a[i+1:,j] and β in a[i,j]
+func qrHouseholder(a *Matrix, i, j int) (τ float64) {
+	col := a.Data[j*a.M+i : (j+1)*a.M]
+	α := col[0]
+	xnorm := qrNorm(a, i+1, j)
+	if xnorm == 0 {
+		return 0
+	}
+	β := -math.Copysign(math.Hypot(α, xnorm), α)
+	τ = (β - α) / β
+	s := 1 / (α - β)
+	for k := 1; k < len(col); k++ {
+		col[k] *= s
+	}
+	col[0] = β
+	return
+}
+
+// qrReflect applies the reflector stored in column j of a (starting at row i) to y[i:]
+func qrReflect(a *Matrix, i, j int, τ float64, y []float64) {
+	if τ == 0 {
+		return
+	}
+	v := a.Data[j*a.M+i : (j+1)*a.M]
+	w := y[i]
+	for k := 1; k < len(v); k++ {
+		w += v[k] * y[i+k]
+	}
+	w *= τ
+	y[i] -= w
+	for k := 1; k < len(v); k++ {
+		y[i+k] -= w * v[k]
+	}
+}
+
+// qrBackSubst solves R[:r,:r] ⋅ z[:r] = c[:r] and sets z[r:] = 0
+func qrBackSubst(z Vector, R *Matrix, c Vector, r int) {
+	for i := r; i < len(z); i++ {
+		z[i] = 0
+	}
+	for i := r - 1; i >= 0; i-- {
+		s := c[i]
+		for j := i + 1; j < r; j++ {
+			s -= R.Get(i, j) * z[j]
+		}
+		z[i] = s / R.Get(i, i)
+	}
+}
+
+// qrForwardSubst solves R[:r,:r]ᵀ ⋅ y[:r] = b[:r] and sets y[r:] = 0
+func qrForwardSubst(y Vector, R *Matrix, b Vector, r int) {
+	for i := r; i < len(y); i++ {
+		y[i] = 0
+	}
+	for i := 0; i < r; i++ {
+		s := b[i]
+		for j := 0; j < i; j++ {
+			s -= R.Get(j, i) * y[j]
+		}
+		y[i] = s / R.Get(i, i)
+	}
+}
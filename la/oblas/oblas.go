@@ -729,6 +729,127 @@ func Dgeev(calcVl, calcVr bool, n int, a []float64, lda int, wr []float64, wi, v
 	}
 }
 
+// Dsyev computes all eigenvalues and, optionally, eigenvectors of a real symmetric matrix A.
+//
+//  See: http://www.netlib.org/lapack/explore-html/dd/d4c/dsyev_8f.html
+//
+//  See: https://software.intel.com/en-us/mkl-developer-reference-c-syev
+//
+//  The eigenvalues are returned in w in ascending order. If calcV == true, on exit, a contains
+//  the orthonormal eigenvectors of the matrix A (each column is an eigenvector). Otherwise, on
+//  exit, the lower triangle (if up == false) or the upper triangle (if up == true) of a,
+//  including the diagonal, is destroyed.
+func Dsyev(calcV, up bool, n int, a []float64, lda int, w []float64) {
+	info := C.LAPACKE_dsyev(
+		C.int(lapackColMajor),
+		jobVlr(calcV),
+		lUplo(up),
+		C.lapack_int(n),
+		(*C.double)(unsafe.Pointer(&a[0])),
+		C.lapack_int(lda),
+		(*C.double)(unsafe.Pointer(&w[0])),
+	)
+	if info != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
+// Dsygv computes all the eigenvalues, and optionally, the eigenvectors of a real generalized
+// symmetric-definite eigenproblem, of the form
+//
+//   A*x=(lambda)*B*x,  A*Bx=(lambda)*x,  or B*A*x=(lambda)*x.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d5/d2e/dsygv_8f.html
+//
+//  See: https://software.intel.com/en-us/mkl-developer-reference-c-sygv
+//
+//  Here A and B are assumed to be symmetric and B is also positive definite.
+//
+//   itype = 1:  A*x = (lambda)*B*x
+//   itype = 2:  A*B*x = (lambda)*x
+//   itype = 3:  B*A*x = (lambda)*x
+//
+//  The eigenvalues are returned in w in ascending order. If calcV == true, on exit, a contains
+//  the matrix Z of eigenvectors normalized as follows: if itype = 1 or 2, Z**T*B*Z = I; if
+//  itype = 3, Z**T*inv(B)*Z = I. On exit, b contains the triangular factor U or L from the
+//  Cholesky factorization B = U**T*U or B = L*L**T.
+func Dsygv(itype int, calcV, up bool, n int, a []float64, lda int, b []float64, ldb int, w []float64) {
+	info := C.LAPACKE_dsygv(
+		C.int(lapackColMajor),
+		C.lapack_int(itype),
+		jobVlr(calcV),
+		lUplo(up),
+		C.lapack_int(n),
+		(*C.double)(unsafe.Pointer(&a[0])),
+		C.lapack_int(lda),
+		(*C.double)(unsafe.Pointer(&b[0])),
+		C.lapack_int(ldb),
+		(*C.double)(unsafe.Pointer(&w[0])),
+	)
+	if info != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
+// Dggev computes for a pair of N-by-N real nonsymmetric matrices (A,B) the generalized
+// eigenvalues, and optionally, the left and/or right generalized eigenvectors.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d9/d52/dggev_8f.html
+//
+//  See: https://software.intel.com/en-us/mkl-developer-reference-c-ggev
+//
+//  A generalized eigenvalue for a pair of matrices (A,B) is a scalar lambda or a ratio
+//  alpha/beta = lambda, such that A - lambda*B is singular. It is usually represented as the
+//  pair (alpha,beta), as there is a reasonable interpretation for beta=0, and even for both
+//  being zero.
+//
+//  The right eigenvector v(j) corresponding to the eigenvalue lambda(j) of (A,B) satisfies
+//
+//                   A * v(j) = lambda(j) * B * v(j).
+//
+//  The left eigenvector u(j) corresponding to the eigenvalue lambda(j) of (A,B) satisfies
+//
+//                   u(j)**H * A  = lambda(j) * u(j)**H * B .
+//
+//  where u(j)**H is the conjugate-transpose of u(j).
+//
+//  The eigenvalues are (alphar(j) + alphai(j)*i)/beta(j). Complex conjugate pairs of
+//  eigenvalues and eigenvectors are stored in the same way as in Dgeev. Each eigenvector is
+//  scaled so the largest component has abs(real part)+abs(imag. part)=1.
+func Dggev(calcVl, calcVr bool, n int, a []float64, lda int, b []float64, ldb int, alphar, alphai, beta []float64, vl []float64, ldvl int, vr []float64, ldvr int) {
+	var vvl, vvr *C.double
+	if calcVl {
+		vvl = (*C.double)(unsafe.Pointer(&vl[0]))
+	} else {
+		ldvl = 1
+	}
+	if calcVr {
+		vvr = (*C.double)(unsafe.Pointer(&vr[0]))
+	} else {
+		ldvr = 1
+	}
+	info := C.LAPACKE_dggev(
+		C.int(lapackColMajor),
+		jobVlr(calcVl),
+		jobVlr(calcVr),
+		C.lapack_int(n),
+		(*C.double)(unsafe.Pointer(&a[0])),
+		C.lapack_int(lda),
+		(*C.double)(unsafe.Pointer(&b[0])),
+		C.lapack_int(ldb),
+		(*C.double)(unsafe.Pointer(&alphar[0])),
+		(*C.double)(unsafe.Pointer(&alphai[0])),
+		(*C.double)(unsafe.Pointer(&beta[0])),
+		vvl,
+		C.lapack_int(ldvl),
+		vvr,
+		C.lapack_int(ldvr),
+	)
+	if info != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
 // auxiliary //////////////////////////////////////////////////////////////////////////////////////
 
 // constants
@@ -6,6 +6,7 @@ package oblas
 
 import (
 	"math"
+	"math/cmplx"
 	"testing"
 
 	"github.com/dicksontsai/gosl/chk"
@@ -1508,3 +1509,132 @@ func TestDgeev01(tst *testing.T) {
 	ww4 := GetJoinComplex(wr4, wi4)
 	chk.ArrayC(tst, "4: w", 1e-16, ww4, wRef)
 }
+
+func TestDsyev01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dsyev01")
+
+	// only the lower triangle is used
+	a := SliceToColMajor([][]float64{
+		{+2, +9, +9},
+		{-1, +2, +9},
+		{+0, -1, +2},
+	})
+	n := 3
+	lda := n
+	w := make([]float64, n)
+	calcV := true
+	up := false
+	Dsyev(calcV, up, n, a, lda, w)
+
+	// check eigenvalues
+	s2 := math.Sqrt2
+	chk.Array(tst, "w", 1e-14, w, []float64{2 - s2, 2, 2 + s2})
+
+	// check eigenvectors (up to sign)
+	vRef := [][]float64{
+		{0.5, s2 / 2, 0.5},
+		{s2 / 2, 0, -s2 / 2},
+		{0.5, -s2 / 2, 0.5},
+	}
+	for j := 0; j < n; j++ {
+		v := ExtractCol(j, n, n, a)
+		if v[0]*vRef[j][0]+v[1]*vRef[j][1]+v[2]*vRef[j][2] < 0 {
+			Dscal(n, -1, v, 1)
+		}
+		chk.Array(tst, "v", 1e-14, v, vRef[j])
+	}
+}
+
+func TestDsygv01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dsygv01")
+
+	// A ⋅ x = λ ⋅ B ⋅ x
+	adeep2 := [][]float64{
+		{+6, -2},
+		{-2, +4},
+	}
+	bdeep2 := [][]float64{
+		{2, 0},
+		{0, 1},
+	}
+	a := SliceToColMajor(adeep2)
+	b := SliceToColMajor(bdeep2)
+	n := 2
+	w := make([]float64, n)
+	Dsygv(1, true, true, n, a, n, b, n, w)
+	chk.Array(tst, "w", 1e-14, w, []float64{2, 5})
+
+	// check A ⋅ x = λ ⋅ B ⋅ x and xᵀ ⋅ B ⋅ x = 1
+	A := SliceToColMajor(adeep2)
+	B := SliceToColMajor(bdeep2)
+	Ax := make([]float64, n)
+	Bx := make([]float64, n)
+	for j := 0; j < n; j++ {
+		x := ExtractCol(j, n, n, a)
+		Dgemv(false, n, n, 1, A, n, x, 1, 0, Ax, 1)
+		Dgemv(false, n, n, w[j], B, n, x, 1, 0, Bx, 1)
+		chk.Array(tst, "A⋅x", 1e-14, Ax, Bx)
+		chk.Float64(tst, "xᵀ⋅B⋅x", 1e-14, Ddot(n, x, 1, Bx, 1)/w[j], 1)
+	}
+}
+
+func TestDggev01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dggev01")
+
+	// eigenvalues: ±i and 1.5
+	adeep2 := [][]float64{
+		{+0, 1, 0},
+		{-1, 0, 0},
+		{+0, 0, 3},
+	}
+	bdeep2 := [][]float64{
+		{1, 0, 0},
+		{0, 1, 0},
+		{0, 0, 2},
+	}
+	a := SliceToColMajor(adeep2)
+	b := SliceToColMajor(bdeep2)
+	n := 3
+	alphar := make([]float64, n)
+	alphai := make([]float64, n)
+	beta := make([]float64, n)
+	vr := make([]float64, n*n)
+	Dggev(false, true, n, a, n, b, n, alphar, alphai, beta, nil, 0, vr, n)
+
+	// check β ⋅ A ⋅ v = α ⋅ B ⋅ v
+	vv := make([]complex128, n*n)
+	EigenvecsBuild(vv, alphar, alphai, vr)
+	A := SliceToColMajorC([][]complex128{
+		{+0, 1, 0},
+		{-1, 0, 0},
+		{+0, 0, 3},
+	})
+	B := SliceToColMajorC([][]complex128{
+		{1, 0, 0},
+		{0, 1, 0},
+		{0, 0, 2},
+	})
+	βAv := make([]complex128, n)
+	αBv := make([]complex128, n)
+	nfound := 0
+	for j := 0; j < n; j++ {
+		α := complex(alphar[j], alphai[j])
+		λ := α / complex(beta[j], 0)
+		for _, λref := range []complex128{1i, -1i, 1.5} {
+			if cmplx.Abs(λ-λref) < 1e-14 {
+				nfound++
+			}
+		}
+		v := ExtractColC(j, n, n, vv)
+		Zgemv(false, n, n, complex(beta[j], 0), A, n, v, 1, 0, βAv, 1)
+		Zgemv(false, n, n, α, B, n, v, 1, 0, αBv, 1)
+		chk.ArrayC(tst, "β⋅A⋅v", 1e-14, βAv, αBv)
+	}
+	chk.Int(tst, "number of eigenvalues found", nfound, 3)
+}
@@ -0,0 +1,319 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// LinearOperator defines a (matrix-free) linear operator A of dimension m x n
+//
+//   MatVec:    y := A ⋅ x     with len(x) = n and len(y) = m
+//   MatTrVec:  y := Aᵀ ⋅ x    with len(x) = m and len(y) = n
+//
+//   NOTE: x and y must not be the same vector
+//
+//   Implemented by *Matrix, *CCMatrix, *Triplet and OperatorFunc; and by the composition helpers
+//   OpSum, OpProduct, OpScaled, OpTranspose and OpBlock.
+//   The MatVec method can be given to EigenLanczos and EigenArnoldi as EigOperator
+//
+type LinearOperator interface {
+	Dims() (m, n int)
+	MatVec(y, x Vector)
+	MatTrVec(y, x Vector)
+}
+
+// dense and sparse matrices //////////////////////////////////////////////////////////////////////
+
+// Dims returns the dimensions of the matrix
+func (o *Matrix) Dims() (m, n int) { return o.M, o.N }
+
+// MatVec computes y := A ⋅ x
+func (o *Matrix) MatVec(y, x Vector) { MatVecMul(y, 1, o, x) }
+
+// MatTrVec computes y := Aᵀ ⋅ x
+func (o *Matrix) MatTrVec(y, x Vector) { MatTrVecMul(y, 1, o, x) }
+
+// Dims returns the dimensions of the matrix
+func (o *CCMatrix) Dims() (m, n int) { return o.m, o.n }
+
+// MatVec computes y := A ⋅ x
+func (o *CCMatrix) MatVec(y, x Vector) { SpMatVecMul(y, 1, o, x) }
+
+// MatTrVec computes y := Aᵀ ⋅ x
+func (o *CCMatrix) MatTrVec(y, x Vector) { SpMatTrVecMul(y, 1, o, x) }
+
+// Dims returns the dimensions of the matrix
+func (o *Triplet) Dims() (m, n int) { return o.m, o.n }
+
+// MatVec computes y := A ⋅ x (repeated entries are added)
+func (o *Triplet) MatVec(y, x Vector) { SpTriMatVecMul(y, o, x) }
+
+// MatTrVec computes y := Aᵀ ⋅ x (repeated entries are added)
+func (o *Triplet) MatTrVec(y, x Vector) { SpTriMatTrVecMul(y, o, x) }
+
+// closures ////////////////////////////////////////////////////////////////////////////////////////
+
+// OperatorFunc implements a LinearOperator with functions (closures)
+type OperatorFunc struct {
+	M, N int               // dimensions
+	Fcn  func(y, x Vector) // computes y := A ⋅ x
+	FcnT func(y, x Vector) // computes y := Aᵀ ⋅ x [may be nil]
+}
+
+// NewOperatorFunc returns a new LinearOperator defined by functions
+//   m, n -- dimensions
+//   fcn  -- computes y := A ⋅ x
+//   fcnT -- computes y := Aᵀ ⋅ x [may be nil if MatTrVec is not used]
+func NewOperatorFunc(m, n int, fcn, fcnT func(y, x Vector)) (o *OperatorFunc) {
+	return &OperatorFunc{M: m, N: n, Fcn: fcn, FcnT: fcnT}
+}
+
+// Dims returns the dimensions of the operator
+func (o *OperatorFunc) Dims() (m, n int) { return o.M, o.N }
+
+// MatVec computes y := A ⋅ x
+func (o *OperatorFunc) MatVec(y, x Vector) { o.Fcn(y, x) }
+
+// MatTrVec computes y := Aᵀ ⋅ x
+func (o *OperatorFunc) MatTrVec(y, x Vector) {
+	if o.FcnT == nil {
+		chk.Panic("transpose function of operator is not available\n")
+	}
+	o.FcnT(y, x)
+}
+
+// composition /////////////////////////////////////////////////////////////////////////////////////
+
+// opSum implements α⋅A + β⋅B
+type opSum struct {
+	α, β float64
+	a, b LinearOperator
+	tmp  Vector
+}
+
+// OpSum returns the operator α⋅A + β⋅B
+func OpSum(α float64, A LinearOperator, β float64, B LinearOperator) LinearOperator {
+	m, n := A.Dims()
+	mb, nb := B.Dims()
+	if m != mb || n != nb {
+		chk.Panic("operators must have the same dimensions. (%d,%d) != (%d,%d)\n", m, n, mb, nb)
+	}
+	return &opSum{α: α, β: β, a: A, b: B, tmp: NewVector(utl.Imax(m, n))}
+}
+
+// Dims returns the dimensions of the operator
+func (o *opSum) Dims() (m, n int) { return o.a.Dims() }
+
+// MatVec computes y := A ⋅ x
+func (o *opSum) MatVec(y, x Vector) {
+	m, _ := o.a.Dims()
+	t := o.tmp[:m]
+	o.a.MatVec(y, x)
+	o.b.MatVec(t, x)
+	for i := 0; i < m; i++ {
+		y[i] = o.α*y[i] + o.β*t[i]
+	}
+}
+
+// MatTrVec computes y := Aᵀ ⋅ x
+func (o *opSum) MatTrVec(y, x Vector) {
+	_, n := o.a.Dims()
+	t := o.tmp[:n]
+	o.a.MatTrVec(y, x)
+	o.b.MatTrVec(t, x)
+	for i := 0; i < n; i++ {
+		y[i] = o.α*y[i] + o.β*t[i]
+	}
+}
+
+// opProduct implements A ⋅ B
+type opProduct struct {
+	a, b LinearOperator
+	tmp  Vector
+}
+
+// OpProduct returns the operator A ⋅ B; i.e. y := A ⋅ (B ⋅ x)
+func OpProduct(A, B LinearOperator) LinearOperator {
+	_, n := A.Dims()
+	mb, _ := B.Dims()
+	if n != mb {
+		chk.Panic("number of columns of A must be equal to the number of rows of B. %d != %d\n", n, mb)
+	}
+	return &opProduct{a: A, b: B, tmp: NewVector(n)}
+}
+
+// Dims returns the dimensions of the operator
+func (o *opProduct) Dims() (m, n int) {
+	m, _ = o.a.Dims()
+	_, n = o.b.Dims()
+	return
+}
+
+// MatVec computes y := A ⋅ x
+func (o *opProduct) MatVec(y, x Vector) {
+	o.b.MatVec(o.tmp, x)
+	o.a.MatVec(y, o.tmp)
+}
+
+// MatTrVec computes y := Aᵀ ⋅ x
+func (o *opProduct) MatTrVec(y, x Vector) {
+	o.a.MatTrVec(o.tmp, x)
+	o.b.MatTrVec(y, o.tmp)
+}
+
+// opScaled implements α⋅A
+type opScaled struct {
+	α float64
+	a LinearOperator
+}
+
+// OpScaled returns the operator α⋅A
+func OpScaled(α float64, A LinearOperator) LinearOperator {
+	return &opScaled{α: α, a: A}
+}
+
+// Dims returns the dimensions of the operator
+func (o *opScaled) Dims() (m, n int) { return o.a.Dims() }
+
+// MatVec computes y := A ⋅ x
+func (o *opScaled) MatVec(y, x Vector) {
+	o.a.MatVec(y, x)
+	y.Apply(o.α, y)
+}
+
+// MatTrVec computes y := Aᵀ ⋅ x
+func (o *opScaled) MatTrVec(y, x Vector) {
+	o.a.MatTrVec(y, x)
+	y.Apply(o.α, y)
+}
+
+// opTranspose implements Aᵀ
+type opTranspose struct {
+	a LinearOperator
+}
+
+// OpTranspose returns the operator Aᵀ
+func OpTranspose(A LinearOperator) LinearOperator {
+	return &opTranspose{a: A}
+}
+
+// Dims returns the dimensions of the operator
+func (o *opTranspose) Dims() (m, n int) {
+	n, m = o.a.Dims()
+	return
+}
+
+// MatVec computes y := A ⋅ x
+func (o *opTranspose) MatVec(y, x Vector) { o.a.MatTrVec(y, x) }
+
+// MatTrVec computes y := Aᵀ ⋅ x
+func (o *opTranspose) MatTrVec(y, x Vector) { o.a.MatVec(y, x) }
+
+// opBlock implements a block operator
+type opBlock struct {
+	blocks     [][]LinearOperator
+	rows, cols []int // offsets of block rows and columns
+	tmp        Vector
+}
+
+// OpBlock returns the block operator
+//
+//        ┌                     ┐
+//        │ A₀₀  A₀₁  …  A₀ₙ    │
+//   A =  │ A₁₀  A₁₁  …  A₁ₙ    │
+//        │  ⋮    ⋮        ⋮    │
+//        └                     ┘
+//
+//   NOTE: nil blocks are zero; however, each block row and column must have at least one block
+//
+func OpBlock(blocks [][]LinearOperator) LinearOperator {
+	nr := len(blocks)
+	if nr < 1 {
+		chk.Panic("block operator requires at least one block row\n")
+	}
+	nc := len(blocks[0])
+	o := &opBlock{blocks: blocks, rows: make([]int, nr+1), cols: make([]int, nc+1)}
+	rowSize := make([]int, nr)
+	colSize := make([]int, nc)
+	for i := 0; i < nr; i++ {
+		rowSize[i] = -1
+	}
+	for j := 0; j < nc; j++ {
+		colSize[j] = -1
+	}
+	for i := 0; i < nr; i++ {
+		if len(blocks[i]) != nc {
+			chk.Panic("all block rows must have the same number of blocks. %d != %d\n", len(blocks[i]), nc)
+		}
+		for j := 0; j < nc; j++ {
+			if blocks[i][j] == nil {
+				continue
+			}
+			m, n := blocks[i][j].Dims()
+			if rowSize[i] >= 0 && rowSize[i] != m {
+				chk.Panic("blocks in block row %d have inconsistent number of rows. %d != %d\n", i, m, rowSize[i])
+			}
+			if colSize[j] >= 0 && colSize[j] != n {
+				chk.Panic("blocks in block column %d have inconsistent number of columns. %d != %d\n", j, n, colSize[j])
+			}
+			rowSize[i], colSize[j] = m, n
+		}
+	}
+	for i := 0; i < nr; i++ {
+		if rowSize[i] < 0 {
+			chk.Panic("block row %d has only nil blocks\n", i)
+		}
+		o.rows[i+1] = o.rows[i] + rowSize[i]
+	}
+	for j := 0; j < nc; j++ {
+		if colSize[j] < 0 {
+			chk.Panic("block column %d has only nil blocks\n", j)
+		}
+		o.cols[j+1] = o.cols[j] + colSize[j]
+	}
+	o.tmp = NewVector(utl.Imax(o.rows[nr], o.cols[nc]))
+	return o
+}
+
+// Dims returns the dimensions of the operator
+func (o *opBlock) Dims() (m, n int) {
+	return o.rows[len(o.rows)-1], o.cols[len(o.cols)-1]
+}
+
+// MatVec computes y := A ⋅ x
+func (o *opBlock) MatVec(y, x Vector) {
+	for i := range o.blocks {
+		yi := y[o.rows[i]:o.rows[i+1]]
+		yi.Fill(0)
+		t := o.tmp[:len(yi)]
+		for j, b := range o.blocks[i] {
+			if b != nil {
+				b.MatVec(t, x[o.cols[j]:o.cols[j+1]])
+				for k := range yi {
+					yi[k] += t[k]
+				}
+			}
+		}
+	}
+}
+
+// MatTrVec computes y := Aᵀ ⋅ x
+func (o *opBlock) MatTrVec(y, x Vector) {
+	for j := range o.cols[:len(o.cols)-1] {
+		yj := y[o.cols[j]:o.cols[j+1]]
+		yj.Fill(0)
+		t := o.tmp[:len(yj)]
+		for i := range o.blocks {
+			if b := o.blocks[i][j]; b != nil {
+				b.MatTrVec(t, x[o.rows[i]:o.rows[i+1]])
+				for k := range yj {
+					yj[k] += t[k]
+				}
+			}
+		}
+	}
+}
@@ -4,25 +4,10 @@
 
 package la
 
-/*
-#ifdef WIN32
-#define LONG long long
-#else
-#define LONG long
-#endif
-#include <umfpack.h>
-*/
-import "C"
+import "github.com/dicksontsai/gosl/chk"
 
-import (
-	"unsafe"
-
-	"github.com/dicksontsai/gosl/chk"
-)
-
-// ToMatrix converts a sparse matrix in triplet form to column-compressed form using Umfpack's
-// routines. "realloc_a" indicates whether the internal "a" matrix must be reallocated or not,
-// for instance, in case the structure of the triplet has changed.
+// ToMatrix converts a sparse matrix in triplet form to column-compressed form. Duplicated entries
+// are summed up and the row indices of each column are sorted in increasing order.
 //  INPUT:
 //   a -- a previous CCMatrix to be filled in; otherwise, "nil" tells to allocate a new one
 //  OUTPUT:
@@ -33,27 +18,20 @@ func (t *Triplet) ToMatrix(a *CCMatrix) *CCMatrix {
 	}
 	if a == nil {
 		a = new(CCMatrix)
-		a.m, a.n, a.nnz = t.m, t.n, t.pos
-		a.p = make([]int, a.n+1)
-		a.i = make([]int, a.nnz)
-		a.x = make([]float64, a.nnz)
-	}
-	Ti := (*C.LONG)(unsafe.Pointer(&t.i[0]))
-	Tj := (*C.LONG)(unsafe.Pointer(&t.j[0]))
-	Tx := (*C.double)(unsafe.Pointer(&t.x[0]))
-	Ap := (*C.LONG)(unsafe.Pointer(&a.p[0]))
-	Ai := (*C.LONG)(unsafe.Pointer(&a.i[0]))
-	Ax := (*C.double)(unsafe.Pointer(&a.x[0]))
-	status := C.umfpack_dl_triplet_to_col(C.LONG(a.m), C.LONG(a.n), C.LONG(a.nnz), Ti, Tj, Tx, Ap, Ai, Ax, nil)
-	if status != C.UMFPACK_OK {
-		chk.Panic("umfpack_dl_triplet_to_col failed (UMFPACK error: %s)", umfErr(status))
+	}
+	var dest []int
+	a.m, a.n = t.m, t.n
+	a.p, a.i, dest = tripletToCC(t.m, t.n, t.i[:t.pos], t.j[:t.pos], a.p, a.i)
+	a.nnz = a.p[a.n]
+	a.x = resizeFloat64(a.x, a.nnz)
+	for k, q := range dest {
+		a.x[q] += t.x[k]
 	}
 	return a
 }
 
 // ToMatrix converts a sparse matrix in triplet form with complex numbers to column-compressed form.
-// "realloc_a" indicates whether the internal "a" matrix must be reallocated or not, for instance,
-// in case the structure of the triplet has changed.
+// Duplicated entries are summed up and the row indices of each column are sorted in increasing order.
 //  INPUT:
 //   a -- a previous CCMatrixC to be filled in; otherwise, "nil" tells to allocate a new one
 //  OUTPUT:
@@ -64,57 +42,122 @@ func (t *TripletC) ToMatrix(a *CCMatrixC) *CCMatrixC {
 	}
 	if a == nil {
 		a = new(CCMatrixC)
-		a.m, a.n, a.nnz = t.m, t.n, t.pos
-		a.p = make([]int, a.n+1)
-		a.i = make([]int, a.nnz)
-		a.x = make([]complex128, a.nnz)
-	}
-	Ap := (*C.LONG)(unsafe.Pointer(&a.p[0]))
-	Ai := (*C.LONG)(unsafe.Pointer(&a.i[0]))
-	Ax := (*C.double)(unsafe.Pointer(&a.x[0]))
-	Ti := (*C.LONG)(unsafe.Pointer(&t.i[0]))
-	Tj := (*C.LONG)(unsafe.Pointer(&t.j[0]))
-	Tx := (*C.double)(unsafe.Pointer(&t.x[0]))
-	status := C.umfpack_zl_triplet_to_col(C.LONG(a.m), C.LONG(a.n), C.LONG(a.nnz), Ti, Tj, Tx, nil, Ap, Ai, Ax, nil, nil)
-	if status != C.UMFPACK_OK {
-		chk.Panic("umfpack_zl_triplet_to_col failed (UMFPACK error: %s)", umfErr(status))
+	}
+	var dest []int
+	a.m, a.n = t.m, t.n
+	a.p, a.i, dest = tripletToCC(t.m, t.n, t.i[:t.pos], t.j[:t.pos], a.p, a.i)
+	a.nnz = a.p[a.n]
+	a.x = resizeComplex128(a.x, a.nnz)
+	for k, q := range dest {
+		a.x[q] += t.x[k]
 	}
 	return a
 }
 
-// umfErr returns UMFPACK error codes
-func umfErr(code C.LONG) string {
-	switch code {
-	case C.UMFPACK_ERROR_out_of_memory:
-		return "out_of_memory (-1)"
-	case C.UMFPACK_ERROR_invalid_Numeric_object:
-		return "invalid_Numeric_object (-3)"
-	case C.UMFPACK_ERROR_invalid_Symbolic_object:
-		return "invalid_Symbolic_object (-4)"
-	case C.UMFPACK_ERROR_argument_missing:
-		return "argument_missing (-5)"
-	case C.UMFPACK_ERROR_n_nonpositive:
-		return "n_nonpositive (-6)"
-	case C.UMFPACK_ERROR_invalid_matrix:
-		return "invalid_matrix (-8)"
-	case C.UMFPACK_ERROR_different_pattern:
-		return "different_pattern (-11)"
-	case C.UMFPACK_ERROR_invalid_system:
-		return "invalid_system (-13)"
-	case C.UMFPACK_ERROR_invalid_permutation:
-		return "invalid_permutation (-15)"
-	case C.UMFPACK_ERROR_internal_error:
-		return "internal_error (-911)"
-	case C.UMFPACK_ERROR_file_IO:
-		return "file_IO (-17)"
-	case -18:
-		return "ordering_failed (-18)"
-	case C.UMFPACK_WARNING_singular_matrix:
-		return "singular_matrix (1)"
-	case C.UMFPACK_WARNING_determinant_underflow:
-		return "determinant_underflow (2)"
-	case C.UMFPACK_WARNING_determinant_overflow:
-		return "determinant_overflow (3)"
-	}
-	return "unknown UMFPACK error"
+// tripletToCC computes the structure of the column-compressed form of a triplet
+//  INPUT:
+//   m, n -- dimensions of matrix
+//   ti, tj -- row and column indices of triplet entries (may contain duplicates)
+//   ap, ai -- previous pointers and row indices to be reused, if large enough [may be nil]
+//  OUTPUT:
+//   ap -- [n+1] pointers to columns
+//   ai -- [nnz] row indices, sorted in increasing order within each column, without duplicates
+//   dest -- [len(ti)] position of each triplet entry in ai; i.e. duplicates share the same position
+func tripletToCC(m, n int, ti, tj, ap, ai []int) (apOut, aiOut, dest []int) {
+
+	// sort entries by rows (counting sort)
+	nt := len(ti)
+	rp := make([]int, m+1)
+	for k := 0; k < nt; k++ {
+		rp[ti[k]+1]++
+	}
+	for i := 0; i < m; i++ {
+		rp[i+1] += rp[i]
+	}
+	byRow := make([]int, nt)
+	for k := 0; k < nt; k++ {
+		byRow[rp[ti[k]]] = k
+		rp[ti[k]]++
+	}
+
+	// count entries per column (including duplicates)
+	apOut = resizeInt(ap, n+1)
+	for k := 0; k < nt; k++ {
+		apOut[tj[k]+1]++
+	}
+	for j := 0; j < n; j++ {
+		apOut[j+1] += apOut[j]
+	}
+
+	// scatter entries into columns; since rows are visited in increasing order,
+	// duplicates end up next to each other and are merged
+	aiOut = resizeInt(ai, nt)
+	end := make([]int, n)
+	copy(end, apOut[:n])
+	dest = make([]int, nt)
+	for _, k := range byRow {
+		j := tj[k]
+		if end[j] > apOut[j] && aiOut[end[j]-1] == ti[k] {
+			dest[k] = end[j] - 1
+			continue
+		}
+		aiOut[end[j]] = ti[k]
+		dest[k] = end[j]
+		end[j]++
+	}
+
+	// compact columns
+	nnz := 0
+	shift := make([]int, nt)
+	for j := 0; j < n; j++ {
+		start := apOut[j]
+		apOut[j] = nnz
+		for q := start; q < end[j]; q++ {
+			aiOut[nnz] = aiOut[q]
+			shift[q] = q - nnz
+			nnz++
+		}
+	}
+	apOut[n] = nnz
+	for k := 0; k < nt; k++ {
+		dest[k] -= shift[dest[k]]
+	}
+	aiOut = aiOut[:nnz]
+	return
+}
+
+// resizeInt returns a zeroed slice with length n reusing s if possible
+func resizeInt(s []int, n int) []int {
+	if cap(s) < n {
+		return make([]int, n)
+	}
+	s = s[:n]
+	for k := range s {
+		s[k] = 0
+	}
+	return s
+}
+
+// resizeFloat64 returns a zeroed slice with length n reusing s if possible
+func resizeFloat64(s []float64, n int) []float64 {
+	if cap(s) < n {
+		return make([]float64, n)
+	}
+	s = s[:n]
+	for k := range s {
+		s[k] = 0
+	}
+	return s
+}
+
+// resizeComplex128 returns a zeroed slice with length n reusing s if possible
+func resizeComplex128(s []complex128, n int) []complex128 {
+	if cap(s) < n {
+		return make([]complex128, n)
+	}
+	s = s[:n]
+	for k := range s {
+		s[k] = 0
+	}
+	return s
 }
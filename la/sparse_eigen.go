@@ -0,0 +1,961 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"sort"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// EigOperator defines a function that computes y := Op(x) for the sparse eigenvalue solvers.
+// Op is usually a matrix-vector product; e.g. y := A⋅x
+type EigOperator func(y, x Vector)
+
+// SpEigArgs holds arguments to configure the sparse eigenvalue solvers
+//
+//   Which eigenvalues are computed (after the shift-invert transformation, if any):
+//     "LM" -- largest magnitude [default]
+//     "SM" -- smallest magnitude (slow convergence; shift-invert with Sigma=0 is preferred)
+//     "LA" -- largest algebraic (symmetric only)
+//     "SA" -- smallest algebraic (symmetric only)
+//     "LR" -- largest real part (general only)
+//     "SR" -- smallest real part (general only)
+//     "LI" -- largest imaginary part in magnitude (general only)
+//     "SI" -- smallest imaginary part in magnitude (general only)
+//
+//   NOTE: in shift-invert mode, "LM" selects the eigenvalues λ closest to Sigma
+//
+type SpEigArgs struct {
+	Ncv         int     // number of basis vectors: nev < Ncv ≤ n [default = min(n, max(2⋅nev+1, 20))]
+	Which       string  // which eigenvalues to compute [default = "LM"]
+	Tol         float64 // relative tolerance of Ritz values [default = 1e-10]
+	MaxIt       int     // max number of restarts [default = 300]
+	V0          Vector  // starting vector [may be nil]
+	ShiftInvert bool    // shift-invert mode: compute θ = 1/(λ-σ) using (A - σ⋅B)⁻¹ (SpEigen and SpEigenSym only)
+	Sigma       float64 // shift σ for the shift-invert mode
+	LinSol      string  // kind of SparseSolver for the shift-invert mode [default = "splu"]
+	Verbose     bool    // show messages
+}
+
+// SpEigStat holds statistics of the sparse eigenvalue solvers
+type SpEigStat struct {
+	NumIt  int // number of restarts
+	NumMv  int // number of operator (matrix-vector) applications
+	NumCnv int // number of converged eigenvalues
+}
+
+// SpEigenSym computes a few eigenvalues and eigenvectors of a symmetric sparse matrix using the
+// implicitly restarted Lanczos method
+//
+//   A ⋅ v[j] = λ[j] ⋅ v[j]         standard problem (B = nil)
+//   A ⋅ v[j] = λ[j] ⋅ B ⋅ v[j]     generalised problem (B symmetric positive-definite)
+//
+//   INPUT:
+//     A    -- symmetric matrix (all entries must be given)
+//     B    -- symmetric positive-definite matrix [may be nil]. Requires args.ShiftInvert
+//     args -- arguments [may be nil]
+//
+//   OUTPUT:
+//     w -- eigenvalues [pre-allocated]; nev = len(w) eigenvalues are computed
+//     v -- matrix with the eigenvectors; each column contains one eigenvector [pre-allocated] [may be nil]
+//     stat -- statistics such as the number of restarts
+//
+//   NOTE: the eigenvalues and eigenvectors of symmetric problems are real; the imaginary parts are zero.
+//         With B given, the eigenvectors are B-orthonormal
+//
+func SpEigenSym(v *MatrixC, w VectorC, A, B *CCMatrix, args *SpEigArgs) (stat SpEigStat) {
+	if args == nil {
+		args = new(SpEigArgs)
+	}
+	if B != nil && !args.ShiftInvert {
+		chk.Panic("generalised eigenproblem requires the shift-invert mode\n")
+	}
+	op, bop, free := spEigOperators(A, B, args)
+	defer free()
+	stat = spEigIra(v, w, A.n, op, bop, true, args)
+	spEigBackTransform(v, w, args)
+	return
+}
+
+// SpEigen computes a few eigenvalues and eigenvectors of a general sparse matrix using the
+// implicitly restarted Arnoldi method
+//
+//   A ⋅ v[j] = λ[j] ⋅ v[j]
+//
+//   INPUT:
+//     A    -- general matrix
+//     args -- arguments [may be nil]
+//
+//   OUTPUT:
+//     w -- eigenvalues [pre-allocated]; nev = len(w) eigenvalues are computed
+//     v -- matrix with the eigenvectors; each column contains one eigenvector [pre-allocated] [may be nil]
+//     stat -- statistics such as the number of restarts
+//
+func SpEigen(v *MatrixC, w VectorC, A *CCMatrix, args *SpEigArgs) (stat SpEigStat) {
+	if args == nil {
+		args = new(SpEigArgs)
+	}
+	op, _, free := spEigOperators(A, nil, args)
+	defer free()
+	stat = spEigIra(v, w, A.n, op, nil, false, args)
+	spEigBackTransform(v, w, args)
+	return
+}
+
+// EigenLanczos computes a few eigenvalues and eigenvectors of a symmetric linear operator using the
+// implicitly restarted Lanczos method
+//
+//   Op(v[j]) = λ[j] ⋅ v[j]
+//
+//   INPUT:
+//     n    -- dimension of the operator
+//     op   -- symmetric operator computing y := Op(x)
+//     args -- arguments [may be nil]. ShiftInvert is not used
+//
+//   OUTPUT:
+//     w -- eigenvalues [pre-allocated]; nev = len(w) eigenvalues are computed
+//     v -- matrix with the eigenvectors; each column contains one eigenvector [pre-allocated] [may be nil]
+//     stat -- statistics such as the number of restarts
+//
+func EigenLanczos(v *MatrixC, w VectorC, n int, op EigOperator, args *SpEigArgs) (stat SpEigStat) {
+	if args == nil {
+		args = new(SpEigArgs)
+	}
+	return spEigIra(v, w, n, op, nil, true, args)
+}
+
+// EigenArnoldi computes a few eigenvalues and eigenvectors of a general linear operator using the
+// implicitly restarted Arnoldi method
+//
+//   Op(v[j]) = λ[j] ⋅ v[j]
+//
+//   INPUT:
+//     n    -- dimension of the operator
+//     op   -- operator computing y := Op(x)
+//     args -- arguments [may be nil]. ShiftInvert is not used
+//
+//   OUTPUT:
+//     w -- eigenvalues [pre-allocated]; nev = len(w) eigenvalues are computed
+//     v -- matrix with the eigenvectors; each column contains one eigenvector [pre-allocated] [may be nil]
+//     stat -- statistics such as the number of restarts
+//
+func EigenArnoldi(v *MatrixC, w VectorC, n int, op EigOperator, args *SpEigArgs) (stat SpEigStat) {
+	if args == nil {
+		args = new(SpEigArgs)
+	}
+	return spEigIra(v, w, n, op, nil, false, args)
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// spEigOperators returns the operator (and B-product) for the standard or shift-invert modes
+func spEigOperators(A, B *CCMatrix, args *SpEigArgs) (op, bop EigOperator, free func()) {
+	if A.m != A.n {
+		chk.Panic("matrix must be square. %d != %d\n", A.m, A.n)
+	}
+	free = func() {}
+	if B != nil {
+		bop = func(y, x Vector) { SpMatVecMul(y, 1, B, x) }
+	}
+	if !args.ShiftInvert {
+		op = func(y, x Vector) { SpMatVecMul(y, 1, A, x) }
+		return
+	}
+
+	// factorise A - σ⋅B
+	t := new(Triplet)
+	t.Init(A.n, A.n, A.p[A.n]+A.n+spEigNnz(B))
+	for j := 0; j < A.n; j++ {
+		for p := A.p[j]; p < A.p[j+1]; p++ {
+			t.Put(A.i[p], j, A.x[p])
+		}
+	}
+	if B == nil {
+		for i := 0; i < A.n; i++ {
+			t.Put(i, i, -args.Sigma)
+		}
+	} else {
+		for j := 0; j < B.n; j++ {
+			for p := B.p[j]; p < B.p[j+1]; p++ {
+				t.Put(B.i[p], j, -args.Sigma*B.x[p])
+			}
+		}
+	}
+	kind := args.LinSol
+	if kind == "" {
+		kind = "splu"
+	}
+	solver := NewSparseSolver(kind)
+	solver.Init(t, &SpArgs{Verbose: args.Verbose})
+	solver.Fact()
+	free = solver.Free
+
+	// operator: y := (A - σ⋅B)⁻¹ ⋅ B ⋅ x
+	if B == nil {
+		op = func(y, x Vector) { solver.Solve(y, x, false) }
+		return
+	}
+	bx := NewVector(A.n)
+	op = func(y, x Vector) {
+		SpMatVecMul(bx, 1, B, x)
+		solver.Solve(y, bx, false)
+	}
+	return
+}
+
+// spEigNnz returns the number of nonzeros of a matrix that may be nil
+func spEigNnz(a *CCMatrix) int {
+	if a == nil {
+		return 0
+	}
+	return a.p[a.n]
+}
+
+// spEigBackTransform computes λ = σ + 1/θ in shift-invert mode. Complex conjugate pairs are
+// swapped such that the eigenvalue with positive imaginary part comes first
+func spEigBackTransform(v *MatrixC, w VectorC, args *SpEigArgs) {
+	if !args.ShiftInvert {
+		return
+	}
+	for i, θ := range w {
+		w[i] = complex(args.Sigma, 0) + 1/θ
+	}
+	for i := 0; i < len(w)-1; i++ {
+		if imag(w[i]) < 0 && w[i+1] == cmplx.Conj(w[i]) {
+			w[i], w[i+1] = w[i+1], w[i]
+			if v != nil {
+				for r := 0; r < v.M; r++ {
+					a, b := v.Get(r, i), v.Get(r, i+1)
+					v.Set(r, i, b)
+					v.Set(r, i+1, a)
+				}
+			}
+			i++
+		}
+	}
+}
+
+// spEigIra implements the implicitly restarted Arnoldi (or Lanczos, if sym) method with exact shifts
+//
+//   The Arnoldi factorisation  Op ⋅ V = V ⋅ H + f ⋅ eₘᵀ  with m = Ncv is computed with full
+//   reorthogonalisation (in the B inner product, if bop ≠ nil). The Ritz values of H not wanted are
+//   used as shifts of QR steps that compress the factorisation to the k wanted ones [1,2].
+//   Complex conjugate shifts are applied as a double-shift step; thus, only real arithmetic is
+//   used. In the symmetric case, H is tridiagonal and only real shifts appear.
+//
+//   References:
+//     [1] Sorensen DC (1992) Implicit application of polynomial filters in a k-step Arnoldi method.
+//         SIAM Journal on Matrix Analysis and Applications, 13(1):357-385
+//     [2] Lehoucq RB, Sorensen DC and Yang C (1998) ARPACK Users' Guide: Solution of Large-Scale
+//         Eigenvalue Problems with Implicitly Restarted Arnoldi Methods. SIAM. 142p
+//
+func spEigIra(vec *MatrixC, w VectorC, n int, op, bop EigOperator, sym bool, args *SpEigArgs) (stat SpEigStat) {
+
+	// arguments
+	nev := len(w)
+	if nev < 1 || nev >= n-1 {
+		chk.Panic("number of eigenvalues must satisfy 1 ≤ nev < n-1 (use a dense solver otherwise). nev = %d, n = %d\n", nev, n)
+	}
+	m := args.Ncv
+	if m == 0 {
+		m = utl.Imax(2*nev+1, 20)
+		if m > n {
+			m = n
+		}
+	}
+	if m <= nev+1 || m > n {
+		chk.Panic("number of basis vectors must satisfy nev+1 < ncv ≤ n. nev = %d, ncv = %d, n = %d\n", nev, m, n)
+	}
+	which := args.Which
+	if which == "" {
+		which = "LM"
+	}
+	spEigCheckWhich(which, sym)
+	tol := args.Tol
+	if tol <= 0 {
+		tol = 1e-10
+	}
+	maxIt := args.MaxIt
+	if maxIt <= 0 {
+		maxIt = 300
+	}
+
+	// Arnoldi factorisation
+	ar := newSpEigArnoldi(n, m, op, bop)
+	if args.V0 != nil {
+		copy(ar.f, args.V0)
+	} else {
+		ar.random(ar.f)
+	}
+	ar.β = ar.norm(ar.f)
+	if ar.β == 0 {
+		chk.Panic("starting vector must be non-zero\n")
+	}
+	ar.extend(0)
+
+	// restarts
+	var θ []complex128
+	var Y [][]complex128
+	var idx []int
+	eps23 := math.Pow(machEps, 2.0/3.0)
+	for it := 0; ; it++ {
+
+		// Ritz values and vectors
+		θ, Y = spEigHessenberg(ar.h, sym)
+		idx = spEigSort(θ, which)
+
+		// check convergence of wanted Ritz values
+		nconv := 0
+		for _, i := range idx[:nev] {
+			if ar.β*cmplx.Abs(Y[m-1][i]) <= tol*math.Max(eps23, cmplx.Abs(θ[i])) {
+				nconv++
+			}
+		}
+		stat.NumIt, stat.NumCnv = it, nconv
+		if args.Verbose {
+			io.Pf("ira: it = %4d  nconv = %d/%d  θ[0] = %v\n", it, nconv, nev, θ[idx[0]])
+		}
+		if nconv == nev {
+			break
+		}
+		if it == maxIt {
+			chk.Panic("eigenvalue solver did not converge after %d restarts. nconv = %d/%d\n", maxIt, nconv, nev)
+		}
+
+		// number of retained vectors: do not split complex conjugate pairs and keep a few extra
+		// vectors to speed up convergence (as in ARPACK)
+		k := nev + utl.Imin(nconv, (m-nev)/2)
+		if !sym && imag(θ[idx[k-1]]) != 0 && cmplx.Conj(θ[idx[k-1]]) == θ[idx[k]] {
+			k++
+		}
+		if k >= m-1 {
+			k = nev
+			if !sym && imag(θ[idx[k-1]]) != 0 {
+				k++
+			}
+		}
+
+		// apply unwanted Ritz values as shifts and extend the factorisation again
+		ar.restart(k, θ, idx[k:])
+		ar.extend(k)
+	}
+	stat.NumMv = ar.nmv
+
+	// results
+	for j := 0; j < nev; j++ {
+		i := idx[j]
+		w[j] = θ[i]
+		if sym {
+			w[j] = complex(real(θ[i]), 0)
+		}
+	}
+	if vec == nil {
+		return
+	}
+	for j := 0; j < nev; j++ {
+		i := idx[j]
+		var nrm float64
+		for r := 0; r < n; r++ {
+			var s complex128
+			for c := 0; c < m; c++ {
+				s += complex(ar.v[c][r], 0) * Y[c][i]
+			}
+			if sym {
+				s = complex(real(s), 0)
+			}
+			vec.Set(r, j, s)
+			nrm += real(s)*real(s) + imag(s)*imag(s)
+		}
+		if !sym || bop == nil { // normalise (B-orthonormality is preserved in the symmetric case)
+			nrm = math.Sqrt(nrm)
+			for r := 0; r < n; r++ {
+				vec.Set(r, j, vec.Get(r, j)/complex(nrm, 0))
+			}
+		}
+	}
+	return
+}
+
+// spEigCheckWhich checks the "which" argument
+func spEigCheckWhich(which string, sym bool) {
+	switch which {
+	case "LM", "SM":
+		return
+	case "LA", "SA":
+		if sym {
+			return
+		}
+	case "LR", "SR", "LI", "SI":
+		if !sym {
+			return
+		}
+	}
+	chk.Panic("which = %q is invalid (symmetric = %v)\n", which, sym)
+}
+
+// spEigSort returns the indices of θ sorted such that the wanted eigenvalues come first
+func spEigSort(θ []complex128, which string) (idx []int) {
+	idx = make([]int, len(θ))
+	for i := range idx {
+		idx[i] = i
+	}
+	key := func(z complex128) float64 {
+		switch which {
+		case "LM":
+			return -cmplx.Abs(z)
+		case "SM":
+			return cmplx.Abs(z)
+		case "LA", "LR":
+			return -real(z)
+		case "SA", "SR":
+			return real(z)
+		case "LI":
+			return -math.Abs(imag(z))
+		}
+		return math.Abs(imag(z)) // "SI"
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		ka, kb := key(θ[idx[a]]), key(θ[idx[b]])
+		if ka == kb { // keep complex conjugate pairs together; positive imaginary part first
+			return imag(θ[idx[a]]) > imag(θ[idx[b]])
+		}
+		return ka < kb
+	})
+	return
+}
+
+// spEigArnoldi holds the Arnoldi factorisation Op ⋅ V = V ⋅ H + f ⋅ eₘᵀ
+type spEigArnoldi struct {
+	n, m int         // dimension and number of basis vectors
+	op   EigOperator // operator
+	bop  EigOperator // B-product [may be nil]
+	v    []Vector    // basis (B-orthonormal)
+	bv   []Vector    // B ⋅ v [bv = v if bop == nil]
+	h    [][]float64 // m x m upper Hessenberg matrix
+	f    Vector      // residual vector
+	bf   Vector      // B ⋅ f
+	β    float64     // B-norm of f
+	hc   []float64   // coefficients of the orthogonalisation
+	nmv  int         // number of operator applications
+	rnd  *rand.Rand  // random numbers generator
+	tmp  Vector      // workspace
+}
+
+// newSpEigArnoldi allocates a new Arnoldi factorisation
+func newSpEigArnoldi(n, m int, op, bop EigOperator) (o *spEigArnoldi) {
+	o = &spEigArnoldi{n: n, m: m, op: op, bop: bop}
+	o.v = make([]Vector, m)
+	o.bv = make([]Vector, m)
+	for j := 0; j < m; j++ {
+		o.v[j] = NewVector(n)
+		o.bv[j] = o.v[j]
+		if bop != nil {
+			o.bv[j] = NewVector(n)
+		}
+	}
+	o.h = make([][]float64, m)
+	for i := 0; i < m; i++ {
+		o.h[i] = make([]float64, m)
+	}
+	o.f, o.tmp = NewVector(n), NewVector(n)
+	o.bf = o.f
+	if bop != nil {
+		o.bf = NewVector(n)
+	}
+	o.hc = make([]float64, m)
+	o.rnd = rand.New(rand.NewSource(1234))
+	return
+}
+
+// random fills x with random numbers in [-1,1]
+func (o *spEigArnoldi) random(x Vector) {
+	for i := range x {
+		x[i] = 2*o.rnd.Float64() - 1
+	}
+}
+
+// norm computes the B-norm of x and sets bf := B⋅x if x is f
+func (o *spEigArnoldi) norm(x Vector) float64 {
+	if o.bop == nil {
+		return x.Norm()
+	}
+	o.bop(o.tmp, x)
+	if &x[0] == &o.f[0] {
+		copy(o.bf, o.tmp)
+	}
+	return math.Sqrt(math.Max(0, VecDot(x, o.tmp)))
+}
+
+// orthogonalise orthogonalises f against v[0:j+1] (twice; DGKS) and adds the coefficients to h[:,j].
+// It returns the B-norm of f
+func (o *spEigArnoldi) orthogonalise(j int, h []float64) (β float64) {
+	for pass := 0; pass < 2; pass++ {
+		for i := 0; i <= j; i++ {
+			c := VecDot(o.bv[i], o.f)
+			for r := 0; r < o.n; r++ {
+				o.f[r] -= c * o.v[i][r]
+			}
+			if h != nil {
+				h[i] += c
+			}
+		}
+	}
+	return o.norm(o.f)
+}
+
+// extend extends the factorisation from k to m basis vectors
+func (o *spEigArnoldi) extend(k int) {
+	for j := k; j < o.m; j++ {
+
+		// new basis vector v[j] := f/β
+		if o.β <= machEps*o.hnorm() { // invariant subspace: restart with random orthogonal vector
+			o.random(o.f)
+			o.β = o.orthogonalise(j-1, nil)
+			if j > 0 {
+				o.h[j][j-1] = 0
+			}
+		} else if j > 0 {
+			o.h[j][j-1] = o.β
+		}
+		for r := 0; r < o.n; r++ {
+			o.v[j][r] = o.f[r] / o.β
+			if o.bop != nil {
+				o.bv[j][r] = o.bf[r] / o.β
+			}
+		}
+
+		// f := Op⋅v[j] - V⋅h with h := Vᵀ⋅B⋅Op⋅v[j]
+		o.op(o.f, o.v[j])
+		o.nmv++
+		for i := 0; i <= j; i++ {
+			o.hc[i] = 0
+		}
+		o.β = o.orthogonalise(j, o.hc)
+		for i := 0; i <= j; i++ {
+			o.h[i][j] = o.hc[i]
+		}
+	}
+}
+
+// hnorm returns an estimate of the norm of H (at least 1)
+func (o *spEigArnoldi) hnorm() (nrm float64) {
+	nrm = 1
+	for i := 0; i < o.m; i++ {
+		for j := 0; j < o.m; j++ {
+			nrm = math.Max(nrm, math.Abs(o.h[i][j]))
+		}
+	}
+	return
+}
+
+// restart applies the shifts θ[unwanted] to compress the factorisation to k basis vectors
+func (o *spEigArnoldi) restart(k int, θ []complex128, unwanted []int) {
+
+	// apply shifts to H
+	m := o.m
+	Q := spEigIdentity(m)
+	done := make([]bool, len(θ))
+	for a, i := range unwanted {
+		if done[i] {
+			continue
+		}
+		done[i] = true
+		μ := θ[i]
+		M := spEigCopy(o.h)
+		if imag(μ) != 0 {
+			partner := -1
+			for _, j := range unwanted[a+1:] {
+				if !done[j] && θ[j] == cmplx.Conj(μ) {
+					partner = j
+					break
+				}
+			}
+			if partner >= 0 { // double shift: M := H² - 2⋅Re(μ)⋅H + |μ|²⋅I
+				done[partner] = true
+				s, t := 2*real(μ), real(μ)*real(μ)+imag(μ)*imag(μ)
+				H2 := spEigMatMul(o.h, o.h, false)
+				for r := 0; r < m; r++ {
+					for c := 0; c < m; c++ {
+						M[r][c] = H2[r][c] - s*o.h[r][c]
+					}
+					M[r][r] += t
+				}
+			} else {
+				μ = complex(real(μ), 0)
+			}
+		}
+		if imag(μ) == 0 { // single shift: M := H - μ⋅I
+			for r := 0; r < m; r++ {
+				M[r][r] -= real(μ)
+			}
+		}
+		q := spEigQrQ(M)
+		o.h = spEigMatMul(spEigMatMul(q, o.h, true), q, false)
+		for r := 0; r < m; r++ { // clean below the subdiagonal
+			for c := 0; c < r-1; c++ {
+				o.h[r][c] = 0
+			}
+		}
+		Q = spEigMatMul(Q, q, false)
+	}
+
+	// V := V ⋅ Q[:,0:k+1]
+	o.v = spEigUpdateBasis(o.v, Q, k+1)
+	if o.bop != nil {
+		o.bv = spEigUpdateBasis(o.bv, Q, k+1)
+	} else {
+		o.bv = o.v
+	}
+
+	// f := v[k] ⋅ H[k][k-1] + f ⋅ Q[m-1][k-1]
+	hk, qk := o.h[k][k-1], Q[m-1][k-1]
+	for r := 0; r < o.n; r++ {
+		o.f[r] = o.v[k][r]*hk + o.f[r]*qk
+		if o.bop != nil {
+			o.bf[r] = o.bv[k][r]*hk + o.bf[r]*qk
+		}
+	}
+	if o.bop != nil {
+		o.β = math.Sqrt(math.Max(0, VecDot(o.f, o.bf)))
+	} else {
+		o.β = o.f.Norm()
+	}
+	for i := 0; i < m; i++ {
+		for j := 0; j < m; j++ {
+			if i >= k || j >= k {
+				o.h[i][j] = 0
+			}
+		}
+	}
+}
+
+// spEigUpdateBasis computes the first ncol columns of V ⋅ Q (the other columns are kept as workspace)
+func spEigUpdateBasis(v []Vector, Q [][]float64, ncol int) []Vector {
+	m, n := len(v), len(v[0])
+	res := make([]Vector, m)
+	for j := 0; j < m; j++ {
+		if j >= ncol {
+			res[j] = v[j]
+			continue
+		}
+		res[j] = NewVector(n)
+		for i := 0; i < m; i++ {
+			if Q[i][j] != 0 {
+				for r := 0; r < n; r++ {
+					res[j][r] += v[i][r] * Q[i][j]
+				}
+			}
+		}
+	}
+	return res
+}
+
+// spEigHessenberg computes the eigenvalues and eigenvectors of an upper Hessenberg matrix H using
+// the (complex) shifted QR algorithm. The eigenvectors are the columns of Y with unit norm
+//
+//   H = Z ⋅ T ⋅ Zᴴ   where T is upper triangular (Schur form)
+//
+//   If sym, H is symmetric; thus T is diagonal and Y = Z. Otherwise, the eigenvectors of T are
+//   computed by back-substitution and transformed by Z
+//
+func spEigHessenberg(h [][]float64, sym bool) (θ []complex128, Y [][]complex128) {
+
+	// Schur form
+	n := len(h)
+	T := make([][]complex128, n)
+	Z := make([][]complex128, n)
+	for i := 0; i < n; i++ {
+		T[i], Z[i] = make([]complex128, n), make([]complex128, n)
+		for j := 0; j < n; j++ {
+			if sym && j < i-1 {
+				continue
+			}
+			if sym && i < j-1 { // use the tridiagonal part only
+				continue
+			}
+			T[i][j] = complex(h[i][j], 0)
+		}
+		Z[i][i] = 1
+	}
+	if sym { // symmetrise
+		for i := 1; i < n; i++ {
+			e := (T[i][i-1] + T[i-1][i]) / 2
+			T[i][i-1], T[i-1][i] = e, e
+		}
+	}
+	spEigSchur(T, Z)
+
+	// eigenvalues
+	θ = make([]complex128, n)
+	for i := 0; i < n; i++ {
+		θ[i] = T[i][i]
+	}
+	if sym {
+		return θ, Z
+	}
+
+	// eigenvectors of T
+	var tnrm float64
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			tnrm = math.Max(tnrm, cmplx.Abs(T[i][j]))
+		}
+	}
+	small := math.Max(machEps*tnrm, math.SmallestNonzeroFloat64)
+	Y = make([][]complex128, n)
+	for i := 0; i < n; i++ {
+		Y[i] = make([]complex128, n)
+	}
+	y := make([]complex128, n)
+	for k := 0; k < n; k++ {
+		for i := range y {
+			y[i] = 0
+		}
+		y[k] = 1
+		for i := k - 1; i >= 0; i-- {
+			var s complex128
+			for j := i + 1; j <= k; j++ {
+				s += T[i][j] * y[j]
+			}
+			d := T[i][i] - θ[k]
+			if cmplx.Abs(d) < small {
+				d = complex(small, 0)
+			}
+			y[i] = -s / d
+		}
+
+		// Y[:,k] := Z ⋅ y / ‖Z ⋅ y‖
+		var nrm float64
+		for i := 0; i < n; i++ {
+			var s complex128
+			for j := 0; j <= k; j++ {
+				s += Z[i][j] * y[j]
+			}
+			Y[i][k] = s
+			nrm += real(s)*real(s) + imag(s)*imag(s)
+		}
+		nrm = math.Sqrt(nrm)
+		for i := 0; i < n; i++ {
+			Y[i][k] /= complex(nrm, 0)
+		}
+	}
+	spEigConjPairs(θ, Y, 100*small)
+	return
+}
+
+// spEigConjPairs enforces that the eigenvalues (and eigenvectors) of a real matrix computed in
+// complex arithmetic are either real or exact complex conjugate pairs
+func spEigConjPairs(θ []complex128, Y [][]complex128, tol float64) {
+	n := len(θ)
+	for i := 0; i < n; i++ {
+		if math.Abs(imag(θ[i])) <= tol {
+			θ[i] = complex(real(θ[i]), 0)
+		}
+	}
+	paired := make([]bool, n)
+	for i := 0; i < n; i++ {
+		if paired[i] || imag(θ[i]) <= 0 {
+			continue
+		}
+		k, dmin := -1, math.Inf(1)
+		for j := 0; j < n; j++ {
+			if !paired[j] && imag(θ[j]) < 0 {
+				if d := cmplx.Abs(θ[j] - cmplx.Conj(θ[i])); d < dmin {
+					k, dmin = j, d
+				}
+			}
+		}
+		if k < 0 {
+			continue
+		}
+		paired[i], paired[k] = true, true
+		θ[k] = cmplx.Conj(θ[i])
+		for r := 0; r < n; r++ {
+			Y[r][k] = cmplx.Conj(Y[r][i])
+		}
+	}
+}
+
+// spEigSchur computes the Schur form of the upper Hessenberg matrix T (in place) by the single-shift
+// QR algorithm with Wilkinson shifts. The unitary transformations are accumulated in Z
+func spEigSchur(T, Z [][]complex128) {
+	n := len(T)
+	hi := n - 1
+	for iter := 0; hi > 0; {
+
+		// find small subdiagonal entry
+		l := hi
+		for ; l > 0; l-- {
+			if cmplx.Abs(T[l][l-1]) <= machEps*(cmplx.Abs(T[l-1][l-1])+cmplx.Abs(T[l][l])) {
+				T[l][l-1] = 0
+				break
+			}
+		}
+		if l == hi { // deflation
+			hi--
+			iter = 0
+			continue
+		}
+		iter++
+		if iter > 30*n {
+			chk.Panic("QR algorithm for the eigenvalues of the Hessenberg matrix did not converge\n")
+		}
+
+		// Wilkinson shift from the trailing 2 x 2 block
+		a, b, c, d := T[hi-1][hi-1], T[hi-1][hi], T[hi][hi-1], T[hi][hi]
+		tr, det := a+d, a*d-b*c
+		disc := cmplx.Sqrt(tr*tr/4 - det)
+		μ := tr/2 + disc
+		if cmplx.Abs(tr/2-disc-d) < cmplx.Abs(μ-d) {
+			μ = tr/2 - disc
+		}
+		if iter%10 == 0 { // exceptional shift
+			μ = d + complex(cmplx.Abs(c), 0)
+		}
+
+		// bulge chasing with Givens rotations
+		x, y := T[l][l]-μ, T[l+1][l]
+		for k := l; k < hi; k++ {
+			if k > l {
+				x, y = T[k][k-1], T[k+1][k-1]
+			}
+			cs, sn := spEigGivensC(x, y)
+			for j := utl.Imax(l, k-1); j < n; j++ { // rows k and k+1
+				t1, t2 := T[k][j], T[k+1][j]
+				T[k][j] = complex(cs, 0)*t1 + sn*t2
+				T[k+1][j] = -cmplx.Conj(sn)*t1 + complex(cs, 0)*t2
+			}
+			for i := 0; i <= utl.Imin(k+2, hi); i++ { // columns k and k+1
+				t1, t2 := T[i][k], T[i][k+1]
+				T[i][k] = t1*complex(cs, 0) + t2*cmplx.Conj(sn)
+				T[i][k+1] = -t1*sn + t2*complex(cs, 0)
+			}
+			for i := 0; i < n; i++ {
+				t1, t2 := Z[i][k], Z[i][k+1]
+				Z[i][k] = t1*complex(cs, 0) + t2*cmplx.Conj(sn)
+				Z[i][k+1] = -t1*sn + t2*complex(cs, 0)
+			}
+		}
+	}
+}
+
+// spEigGivensC computes the complex Givens rotation G = [c s; -conj(s) c] such that G ⋅ [x; y] = [r; 0]
+func spEigGivensC(x, y complex128) (c float64, s complex128) {
+	ax, ay := cmplx.Abs(x), cmplx.Abs(y)
+	if ay == 0 {
+		return 1, 0
+	}
+	if ax == 0 {
+		return 0, 1
+	}
+	r := math.Hypot(ax, ay)
+	c = ax / r
+	s = x / complex(ax, 0) * cmplx.Conj(y) / complex(r, 0)
+	return
+}
+
+// spEigQrQ computes the orthogonal matrix Q of the QR decomposition M = Q ⋅ R (Householder)
+func spEigQrQ(M [][]float64) (Q [][]float64) {
+	m := len(M)
+	A := spEigCopy(M)
+	Q = spEigIdentity(m)
+	v := make([]float64, m)
+	for k := 0; k < m-1; k++ {
+
+		// Householder vector
+		var α float64
+		for i := k; i < m; i++ {
+			α += A[i][k] * A[i][k]
+		}
+		α = math.Sqrt(α)
+		if α == 0 {
+			continue
+		}
+		if A[k][k] > 0 {
+			α = -α
+		}
+		var vv float64
+		for i := k; i < m; i++ {
+			v[i] = A[i][k]
+			if i == k {
+				v[i] -= α
+			}
+			vv += v[i] * v[i]
+		}
+		if vv == 0 {
+			continue
+		}
+
+		// A := (I - 2⋅v⋅vᵀ/vᵀv) ⋅ A  and  Q := Q ⋅ (I - 2⋅v⋅vᵀ/vᵀv)
+		for j := k; j < m; j++ {
+			var s float64
+			for i := k; i < m; i++ {
+				s += v[i] * A[i][j]
+			}
+			s *= 2 / vv
+			for i := k; i < m; i++ {
+				A[i][j] -= s * v[i]
+			}
+		}
+		for i := 0; i < m; i++ {
+			var s float64
+			for j := k; j < m; j++ {
+				s += Q[i][j] * v[j]
+			}
+			s *= 2 / vv
+			for j := k; j < m; j++ {
+				Q[i][j] -= s * v[j]
+			}
+		}
+	}
+	return
+}
+
+// spEigIdentity returns the m x m identity matrix
+func spEigIdentity(m int) (I [][]float64) {
+	I = make([][]float64, m)
+	for i := 0; i < m; i++ {
+		I[i] = make([]float64, m)
+		I[i][i] = 1
+	}
+	return
+}
+
+// spEigCopy returns a copy of a dense matrix
+func spEigCopy(a [][]float64) (b [][]float64) {
+	b = make([][]float64, len(a))
+	for i := range a {
+		b[i] = append([]float64{}, a[i]...)
+	}
+	return
+}
+
+// spEigMatMul returns a ⋅ b (or aᵀ ⋅ b if transA) for square matrices
+func spEigMatMul(a, b [][]float64, transA bool) (c [][]float64) {
+	m := len(a)
+	c = make([][]float64, m)
+	for i := 0; i < m; i++ {
+		c[i] = make([]float64, m)
+		for k := 0; k < m; k++ {
+			aik := a[i][k]
+			if transA {
+				aik = a[k][i]
+			}
+			if aik == 0 {
+				continue
+			}
+			for j := 0; j < m; j++ {
+				c[i][j] += aik * b[k][j]
+			}
+		}
+	}
+	return
+}
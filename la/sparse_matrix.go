@@ -93,6 +93,72 @@ func (o *Triplet) PutCCMatAndMatT(a *CCMatrix) {
 	}
 }
 
+// PutCCMat adds the content of a compressed-column matrix "a" multiplied by α to triplet "o" with
+// its first entry at (i0,j0)
+// ex: i0=1, j0=2
+//        0   1   2   3
+//      [... ... ... ...] 0
+//      [... ... a00 a01] 1  =>  [. .  ]
+//      [... ... a10 a11] 2      [. α⋅a]
+func (o *Triplet) PutCCMat(i0, j0 int, α float64, a *CCMatrix) {
+	if i0+a.m > o.m || j0+a.n > o.n {
+		chk.Panic("cannot put larger matrix into sparse matrix.\nb := [[.. ..] [.. a]] with len(a)=(%d,%d), offset=(%d,%d) and len(b)=(%d,%d)", a.m, a.n, i0, j0, o.m, o.n)
+	}
+	for j := 0; j < a.n; j++ {
+		for k := a.p[j]; k < a.p[j+1]; k++ {
+			o.Put(i0+a.i[k], j0+j, α*a.x[k])
+		}
+	}
+}
+
+// PutCCMatT adds the transpose of a compressed-column matrix "a" multiplied by α to triplet "o"
+// with its first entry at (i0,j0)
+// ex: i0=1, j0=2
+//        0   1   2   3
+//      [... ... ... ...] 0
+//      [... ... a00 a10] 1  =>  [. .   ]
+//      [... ... a01 a11] 2      [. α⋅at]
+func (o *Triplet) PutCCMatT(i0, j0 int, α float64, a *CCMatrix) {
+	if i0+a.n > o.m || j0+a.m > o.n {
+		chk.Panic("cannot put larger matrix into sparse matrix.\nb := [[.. ..] [.. at]] with len(a)=(%d,%d), offset=(%d,%d) and len(b)=(%d,%d)", a.m, a.n, i0, j0, o.m, o.n)
+	}
+	for j := 0; j < a.n; j++ {
+		for k := a.p[j]; k < a.p[j+1]; k++ {
+			o.Put(i0+j, j0+a.i[k], α*a.x[k])
+		}
+	}
+}
+
+// PutTriplet adds the content of triplet "a" to triplet "o" with its first entry at (i0,j0)
+// ex: i0=1, j0=2
+//        0   1   2   3
+//      [... ... ... ...] 0
+//      [... ... a00 a01] 1  =>  [. .]
+//      [... ... a10 a11] 2      [. a]
+func (o *Triplet) PutTriplet(i0, j0 int, a *Triplet) {
+	if i0+a.m > o.m || j0+a.n > o.n {
+		chk.Panic("cannot put larger matrix into sparse matrix.\nb := [[.. ..] [.. a]] with len(a)=(%d,%d), offset=(%d,%d) and len(b)=(%d,%d)", a.m, a.n, i0, j0, o.m, o.n)
+	}
+	for k := 0; k < a.pos; k++ {
+		o.Put(i0+a.i[k], j0+a.j[k], a.x[k])
+	}
+}
+
+// PutTripletT adds the transpose of triplet "a" to triplet "o" with its first entry at (i0,j0)
+// ex: i0=1, j0=2
+//        0   1   2   3
+//      [... ... ... ...] 0
+//      [... ... a00 a10] 1  =>  [. .]
+//      [... ... a01 a11] 2      [. at]
+func (o *Triplet) PutTripletT(i0, j0 int, a *Triplet) {
+	if i0+a.n > o.m || j0+a.m > o.n {
+		chk.Panic("cannot put larger matrix into sparse matrix.\nb := [[.. ..] [.. at]] with len(a)=(%d,%d), offset=(%d,%d) and len(b)=(%d,%d)", a.m, a.n, i0, j0, o.m, o.n)
+	}
+	for k := 0; k < a.pos; k++ {
+		o.Put(i0+a.j[k], j0+a.i[k], a.x[k])
+	}
+}
+
 // Start (re)starts index for inserting items using the Put command
 func (o *Triplet) Start() {
 	o.pos = 0
@@ -192,6 +258,12 @@ func (o *CCMatrix) ToDense() (res *Matrix) {
 	return
 }
 
+// Nnz returns the number of non-zeros in the column-compressed matrix; i.e. after duplicated
+// entries of the triplet have been summed up
+func (o *CCMatrix) Nnz() int {
+	return o.p[o.n]
+}
+
 // Set sets column-compressed matrix directly
 func (o *CCMatrix) Set(m, n int, Ap, Ai []int, Ax []float64) {
 	if len(Ap)-1 != n {
@@ -0,0 +1,568 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"container/heap"
+	"sort"
+
+	"github.com/dicksontsai/gosl/chk"
+)
+
+// spAdjacency returns the adjacency lists of the graph of A + Aᵀ (diagonal entries are ignored)
+func spAdjacency(a *CCMatrix) (adj [][]int) {
+	if a.m != a.n {
+		chk.Panic("matrix must be square to compute the adjacency of its graph. %d != %d\n", a.m, a.n)
+	}
+	adj = make([][]int, a.n)
+	for j := 0; j < a.n; j++ {
+		for k := a.p[j]; k < a.p[j+1]; k++ {
+			i := a.i[k]
+			if i != j {
+				adj[i] = append(adj[i], j)
+				adj[j] = append(adj[j], i)
+			}
+		}
+	}
+	spAdjClean(adj)
+	return
+}
+
+// spAdjClean removes duplicates and sorts adjacency lists
+func spAdjClean(adj [][]int) {
+	mark := make([]int, len(adj))
+	for i := 0; i < len(adj); i++ {
+		mark[i] = -1
+	}
+	for i := 0; i < len(adj); i++ {
+		l := 0
+		for _, j := range adj[i] {
+			if mark[j] != i {
+				mark[j] = i
+				adj[i][l] = j
+				l++
+			}
+		}
+		adj[i] = adj[i][:l]
+		sort.Ints(adj[i])
+	}
+}
+
+// SpOrdering computes a fill-reducing (or bandwidth-reducing) ordering of the graph of A + Aᵀ
+//
+//   kind -- "amd" (approximate minimum degree), "rcm" (reverse Cuthill-McKee),
+//           "nd" (nested dissection) or "natural"
+//   perm -- new-to-old map: row/column perm[k] of A becomes row/column k of the reordered matrix
+//
+//   NOTE: use SpPermute to reorder the matrix and VecPermute to reorder vectors
+//
+func SpOrdering(kind string, a *CCMatrix) (perm []int) {
+	if kind == "natural" {
+		perm = make([]int, a.n)
+		for i := 0; i < a.n; i++ {
+			perm[i] = i
+		}
+		return
+	}
+	return spOrderAdj(kind, spAdjacency(a))
+}
+
+// SpOrderingAdj computes an ordering of a graph given by its adjacency in compressed format;
+// e.g. as returned by graph.Graph.GetAdjacency
+//
+//   xadj   -- the neighbours of vertex i are adjncy[xadj[i]:xadj[i+1]]
+//   adjncy -- adjacent vertices
+//   kind   -- "amd", "rcm", "nd" or "natural" (see SpOrdering)
+//   perm   -- new-to-old map of vertices
+//
+//   NOTE: the graph is symmetrised and self-loops are ignored
+//
+func SpOrderingAdj(kind string, xadj, adjncy []int32) (perm []int) {
+	n := len(xadj) - 1
+	if n < 0 {
+		chk.Panic("xadj must have at least one entry\n")
+	}
+	adj := make([][]int, n)
+	for i := 0; i < n; i++ {
+		for k := xadj[i]; k < xadj[i+1]; k++ {
+			if j := int(adjncy[k]); j != i {
+				adj[i] = append(adj[i], j)
+				adj[j] = append(adj[j], i)
+			}
+		}
+	}
+	spAdjClean(adj)
+	if kind == "natural" {
+		perm = make([]int, n)
+		for i := 0; i < n; i++ {
+			perm[i] = i
+		}
+		return
+	}
+	return spOrderAdj(kind, adj)
+}
+
+// PermInv returns the inverse of a permutation; i.e. old-to-new map from new-to-old map
+func PermInv(perm []int) (pinv []int) {
+	pinv = make([]int, len(perm))
+	for k, i := range perm {
+		pinv[i] = k
+	}
+	return
+}
+
+// VecPermute applies a permutation to a vector
+//
+//   res[k] := v[perm[k]]
+//
+//   NOTE: res and v must not be the same vector
+//
+func VecPermute(res, v Vector, perm []int) {
+	for k, i := range perm {
+		res[k] = v[i]
+	}
+}
+
+// VecPermuteInv applies the inverse of a permutation to a vector (undoes VecPermute)
+//
+//   res[perm[k]] := v[k]
+//
+//   NOTE: res and v must not be the same vector
+//
+func VecPermuteInv(res, v Vector, perm []int) {
+	for k, i := range perm {
+		res[i] = v[k]
+	}
+}
+
+// SpPermute returns the permuted matrix B = P ⋅ A ⋅ Qᵀ
+//
+//   B[k][l] = A[rowPerm[k]][colPerm[l]]
+//
+//   rowPerm -- new-to-old map of rows [may be nil ⇒ identity]
+//   colPerm -- new-to-old map of columns [may be nil ⇒ identity]
+//
+//   NOTE: (1) for symmetric reordering, use rowPerm = colPerm = SpOrdering(...)
+//         (2) use PermInv(rowPerm) and PermInv(colPerm) to undo a permutation
+//         (3) the row indices of each column of B are sorted
+//
+func SpPermute(a *CCMatrix, rowPerm, colPerm []int) (b *CCMatrix) {
+	var pinv []int
+	if rowPerm != nil {
+		if len(rowPerm) != a.m {
+			chk.Panic("len(rowPerm) must be equal to the number of rows. %d != %d\n", len(rowPerm), a.m)
+		}
+		pinv = PermInv(rowPerm)
+	}
+	if colPerm != nil && len(colPerm) != a.n {
+		chk.Panic("len(colPerm) must be equal to the number of columns. %d != %d\n", len(colPerm), a.n)
+	}
+	nnz := a.p[a.n]
+	b = &CCMatrix{m: a.m, n: a.n, nnz: nnz, p: make([]int, a.n+1), i: make([]int, nnz), x: make([]float64, nnz)}
+	for l := 0; l < a.n; l++ {
+		c := l
+		if colPerm != nil {
+			c = colPerm[l]
+		}
+		start := b.p[l]
+		q := start
+		for p := a.p[c]; p < a.p[c+1]; p++ {
+			b.i[q] = a.i[p]
+			if pinv != nil {
+				b.i[q] = pinv[a.i[p]]
+			}
+			b.x[q] = a.x[p]
+			q++
+		}
+		b.p[l+1] = q
+		sort.Sort(spColumn{b.i[start:q], b.x[start:q]})
+	}
+	return
+}
+
+// spOrderAdj computes an ordering of a graph given by (symmetric) adjacency lists
+func spOrderAdj(kind string, adj [][]int) (perm []int) {
+	switch kind {
+	case "amd":
+		return spOrderAmd(adj)
+	case "rcm":
+		return spOrderRcm(adj)
+	case "nd":
+		return spOrderNd(adj)
+	}
+	chk.Panic("ordering scheme %q is not available\n", kind)
+	return
+}
+
+// spColumn implements sort.Interface to sort the entries of a column by row index
+type spColumn struct {
+	i []int     // row indices
+	x []float64 // values
+}
+
+func (o spColumn) Len() int           { return len(o.i) }
+func (o spColumn) Less(a, b int) bool { return o.i[a] < o.i[b] }
+func (o spColumn) Swap(a, b int) {
+	o.i[a], o.i[b] = o.i[b], o.i[a]
+	o.x[a], o.x[b] = o.x[b], o.x[a]
+}
+
+// spOrderRcm computes the reverse Cuthill-McKee ordering of a graph given by adjacency lists.
+// Each connected component starts at a pseudo-peripheral node found by the George-Liu algorithm
+func spOrderRcm(adj [][]int) (perm []int) {
+	n := len(adj)
+	perm = make([]int, 0, n)
+	visited := make([]bool, n)
+	level := make([]int, n)
+	for {
+
+		// find unvisited node with minimum degree
+		start := -1
+		for i := 0; i < n; i++ {
+			if !visited[i] && (start < 0 || len(adj[i]) < len(adj[start])) {
+				start = i
+			}
+		}
+		if start < 0 {
+			break
+		}
+
+		// find pseudo-peripheral node
+		start = spPseudoPeripheral(adj, start, visited, level)
+
+		// breadth-first search with neighbours sorted by increasing degree
+		first := len(perm)
+		perm = append(perm, start)
+		visited[start] = true
+		for head := first; head < len(perm); head++ {
+			i := perm[head]
+			nbrs := make([]int, 0, len(adj[i]))
+			for _, j := range adj[i] {
+				if !visited[j] {
+					visited[j] = true
+					nbrs = append(nbrs, j)
+				}
+			}
+			sort.SliceStable(nbrs, func(a, b int) bool { return len(adj[nbrs[a]]) < len(adj[nbrs[b]]) })
+			perm = append(perm, nbrs...)
+		}
+	}
+
+	// reverse
+	for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+		perm[i], perm[j] = perm[j], perm[i]
+	}
+	return
+}
+
+// spPseudoPeripheral finds a pseudo-peripheral node in the component containing start.
+// Nodes marked as visited are ignored; level is used as workspace
+func spPseudoPeripheral(adj [][]int, start int, visited []bool, level []int) int {
+	ecc := -1
+	for {
+		// level structure rooted at start
+		for i := range level {
+			level[i] = -1
+		}
+		level[start] = 0
+		queue := []int{start}
+		for head := 0; head < len(queue); head++ {
+			i := queue[head]
+			for _, j := range adj[i] {
+				if !visited[j] && level[j] < 0 {
+					level[j] = level[i] + 1
+					queue = append(queue, j)
+				}
+			}
+		}
+		last := queue[len(queue)-1]
+		if level[last] <= ecc {
+			return start
+		}
+		ecc = level[last]
+
+		// node with minimum degree in the last level
+		next := last
+		for _, i := range queue {
+			if level[i] == ecc && len(adj[i]) < len(adj[next]) {
+				next = i
+			}
+		}
+		start = next
+	}
+}
+
+// spOrderAmd computes an approximate minimum degree ordering of a graph given by adjacency lists
+//
+//   The elimination is simulated on the quotient graph; i.e. eliminated nodes become "elements"
+//   representing cliques. The external degree of a variable u adjacent to the new element p is
+//   approximated by (Amestoy, Davis and Duff):
+//
+//     d(u) ≈ |A(u)| + |L(p) \ u| + Σ |L(e) \ L(p)|   for all elements e ≠ p adjacent to u
+//
+//   where A(u) are the variables adjacent to u and L(e) are the variables of element e
+//
+//   Reference:
+//     [1] Amestoy PR, Davis TA and Duff IS (1996) An approximate minimum degree ordering algorithm.
+//         SIAM Journal on Matrix Analysis and Applications, 17(4):886-905
+//
+func spOrderAmd(adj [][]int) (perm []int) {
+
+	// quotient graph
+	n := len(adj)
+	avar := make([][]int, n) // variables adjacent to each variable
+	aelm := make([][]int, n) // elements adjacent to each variable
+	lelm := make([][]int, n) // variables in each element (element e is created when e is eliminated)
+	for i := 0; i < n; i++ {
+		avar[i] = append([]int{}, adj[i]...)
+	}
+	eliminated := make([]bool, n)
+	absorbed := make([]bool, n)
+
+	// degrees and priority queue
+	deg := make([]int, n)
+	pq := new(amdQueue)
+	for i := 0; i < n; i++ {
+		deg[i] = len(avar[i])
+		heap.Push(pq, amdItem{deg[i], i})
+	}
+
+	// workspace
+	mark := make([]int, n) // stamps for variables
+	wflg := make([]int, n) // stamps for elements
+	w := make([]int, n)    // |L(e) \ L(p)|
+	for i := 0; i < n; i++ {
+		mark[i], wflg[i] = -1, -1
+	}
+
+	// eliminate
+	perm = make([]int, 0, n)
+	for stamp := 0; len(perm) < n; stamp++ {
+
+		// variable with minimum (approximate) degree
+		it := heap.Pop(pq).(amdItem)
+		v := it.node
+		if eliminated[v] || it.deg != deg[v] {
+			stamp--
+			continue
+		}
+		perm = append(perm, v)
+		eliminated[v] = true
+
+		// new element p = v with L(p) = (A(v) ∪ L(e) for e ∈ E(v)) \ v
+		mark[v] = stamp
+		lp := make([]int, 0, len(avar[v]))
+		for _, u := range avar[v] {
+			if !eliminated[u] && mark[u] != stamp {
+				mark[u] = stamp
+				lp = append(lp, u)
+			}
+		}
+		for _, e := range aelm[v] {
+			if absorbed[e] {
+				continue
+			}
+			for _, u := range lelm[e] {
+				if !eliminated[u] && mark[u] != stamp {
+					mark[u] = stamp
+					lp = append(lp, u)
+				}
+			}
+			absorbed[e] = true
+			lelm[e] = nil
+		}
+		lelm[v], avar[v], aelm[v] = lp, nil, nil
+
+		// compute |L(e) \ L(p)| for elements adjacent to variables in L(p)
+		for _, u := range lp {
+			for _, e := range aelm[u] {
+				if absorbed[e] {
+					continue
+				}
+				if wflg[e] != stamp {
+					wflg[e] = stamp
+					w[e] = 0
+					for _, x := range lelm[e] {
+						if !eliminated[x] {
+							w[e]++
+						}
+					}
+				}
+				w[e]--
+			}
+		}
+
+		// update variables in L(p)
+		for _, u := range lp {
+
+			// prune variables covered by the new element
+			l := 0
+			for _, x := range avar[u] {
+				if !eliminated[x] && mark[x] != stamp {
+					avar[u][l] = x
+					l++
+				}
+			}
+			avar[u] = avar[u][:l]
+
+			// remove absorbed elements and add the new element
+			l = 0
+			d := len(avar[u]) + len(lp) - 1
+			for _, e := range aelm[u] {
+				if !absorbed[e] {
+					aelm[u][l] = e
+					l++
+					d += w[e]
+				}
+			}
+			aelm[u] = append(aelm[u][:l], v)
+
+			// approximate degree
+			if rem := n - len(perm) - 1; d > rem {
+				d = rem
+			}
+			deg[u] = d
+			heap.Push(pq, amdItem{d, u})
+		}
+	}
+	return
+}
+
+// spNdLeafSize is the size of subgraphs ordered by minimum degree in nested dissection
+const spNdLeafSize = 16
+
+// spOrderNd computes the nested dissection ordering of a graph given by adjacency lists
+//
+//   The graph is split by a vertex separator taken from the middle of a level structure rooted at
+//   a pseudo-peripheral node (George's automatic nested dissection). Both parts are ordered
+//   recursively and the separator is numbered last. Small subgraphs are ordered by AMD
+//
+//   Reference:
+//     [1] George A and Liu JWH (1981) Computer Solution of Large Sparse Positive Definite Systems.
+//         Prentice-Hall. 324p
+//
+func spOrderNd(adj [][]int) (perm []int) {
+
+	// small graph
+	n := len(adj)
+	if n <= spNdLeafSize {
+		return spOrderAmd(adj)
+	}
+
+	// level structure rooted at pseudo-peripheral node
+	start := 0
+	for i := 1; i < n; i++ {
+		if len(adj[i]) < len(adj[start]) {
+			start = i
+		}
+	}
+	level := make([]int, n)
+	start = spPseudoPeripheral(adj, start, make([]bool, n), level)
+
+	// disconnected graph: order the component and the remaining nodes separately
+	var comp, rest []int
+	nlev := 0
+	for i := 0; i < n; i++ {
+		if level[i] < 0 {
+			rest = append(rest, i)
+			continue
+		}
+		comp = append(comp, i)
+		if level[i] >= nlev {
+			nlev = level[i] + 1
+		}
+	}
+	if len(rest) > 0 {
+		return append(spOrderNdSub(adj, comp), spOrderNdSub(adj, rest)...)
+	}
+	if nlev < 3 {
+		return spOrderAmd(adj)
+	}
+
+	// separator level: first level where the cumulative number of nodes reaches n/2
+	count := make([]int, nlev)
+	for i := 0; i < n; i++ {
+		count[level[i]]++
+	}
+	sl, cum := 1, count[0]
+	for sl < nlev-2 && cum+count[sl] < n/2 {
+		cum += count[sl]
+		sl++
+	}
+
+	// split: nodes in the separator level not connected to the next level are moved to part A
+	var partA, partB, sep []int
+	for i := 0; i < n; i++ {
+		switch {
+		case level[i] < sl:
+			partA = append(partA, i)
+		case level[i] > sl:
+			partB = append(partB, i)
+		default:
+			needed := false
+			for _, j := range adj[i] {
+				if level[j] == sl+1 {
+					needed = true
+					break
+				}
+			}
+			if needed {
+				sep = append(sep, i)
+			} else {
+				partA = append(partA, i)
+			}
+		}
+	}
+	perm = append(spOrderNdSub(adj, partA), spOrderNdSub(adj, partB)...)
+	return append(perm, sep...)
+}
+
+// spOrderNdSub computes the nested dissection ordering of the subgraph induced by nodes and
+// returns the ordering in terms of the original nodes
+func spOrderNdSub(adj [][]int, nodes []int) (perm []int) {
+	loc := make(map[int]int, len(nodes))
+	for k, i := range nodes {
+		loc[i] = k
+	}
+	sub := make([][]int, len(nodes))
+	for k, i := range nodes {
+		for _, j := range adj[i] {
+			if l, ok := loc[j]; ok {
+				sub[k] = append(sub[k], l)
+			}
+		}
+	}
+	perm = spOrderNd(sub)
+	for k, l := range perm {
+		perm[k] = nodes[l]
+	}
+	return
+}
+
+// amdItem holds a node and its degree in the priority queue
+type amdItem struct {
+	deg  int // degree
+	node int // node
+}
+
+// amdQueue implements a min-heap of amdItem (ties are broken by the node index)
+type amdQueue []amdItem
+
+func (o amdQueue) Len() int { return len(o) }
+func (o amdQueue) Less(i, j int) bool {
+	if o[i].deg == o[j].deg {
+		return o[i].node < o[j].node
+	}
+	return o[i].deg < o[j].deg
+}
+func (o amdQueue) Swap(i, j int)       { o[i], o[j] = o[j], o[i] }
+func (o *amdQueue) Push(x interface{}) { *o = append(*o, x.(amdItem)) }
+func (o *amdQueue) Pop() interface{} {
+	old := *o
+	n := len(old)
+	x := old[n-1]
+	*o = old[:n-1]
+	return x
+}
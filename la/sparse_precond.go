@@ -0,0 +1,386 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+
+	"github.com/dicksontsai/gosl/chk"
+)
+
+// Preconditioner defines an approximation M ≈ A whose inverse is cheap to apply. It is used to
+// accelerate the convergence of iterative (Krylov) solvers
+//
+//   Given:  r    compute z   such that   M ⋅ z = r
+//
+type Preconditioner interface {
+	Init(args *SpArgs) // initialises the preconditioner (e.g. reads parameters) [args may be nil]
+	Fact(a *CCMatrix)  // computes the preconditioner from the (square) matrix a
+	Apply(z, r Vector) // computes z := M⁻¹ ⋅ r
+}
+
+// precondMaker defines a function that makes Preconditioners
+type precondMaker func() Preconditioner
+
+// precondDB implements a database of Preconditioner makers
+var precondDB = make(map[string]precondMaker)
+
+// NewPreconditioner finds a Preconditioner in database or panic
+//   kind -- "jacobi", "ssor", "ilu0" or "ic0"
+func NewPreconditioner(kind string) Preconditioner {
+	if maker, ok := precondDB[kind]; ok {
+		return maker()
+	}
+	chk.Panic("cannot find Preconditioner named %q in database", kind)
+	return nil
+}
+
+// PcJacobi implements the Jacobi (diagonal) preconditioner
+//
+//   M = D = diag(A)
+//
+type PcJacobi struct {
+	dinv Vector // inverse of diagonal
+}
+
+// Init initialises the preconditioner
+func (o *PcJacobi) Init(args *SpArgs) {
+}
+
+// Fact computes the preconditioner
+func (o *PcJacobi) Fact(a *CCMatrix) {
+	d := spGetDiag(a)
+	o.dinv = NewVector(len(d))
+	for i := 0; i < len(d); i++ {
+		o.dinv[i] = 1.0 / d[i]
+	}
+}
+
+// Apply computes z := M⁻¹ ⋅ r
+func (o *PcJacobi) Apply(z, r Vector) {
+	for i := 0; i < len(r); i++ {
+		z[i] = o.dinv[i] * r[i]
+	}
+}
+
+// PcSsor implements the Symmetric Successive Over-Relaxation preconditioner
+//
+//           1
+//   M = ―――――――― (D + ω L) D⁻¹ (D + ω U)    with   A = L + D + U
+//       ω (2 - ω)
+//
+//   NOTE: ω = 1 corresponds to the symmetric Gauss-Seidel preconditioner
+//
+type PcSsor struct {
+	Omega float64   // relaxation parameter ω ∈ (0,2)
+	a     *CCMatrix // matrix
+	d     Vector    // diagonal
+	w     Vector    // workspace
+}
+
+// Init initialises the preconditioner
+func (o *PcSsor) Init(args *SpArgs) {
+	o.Omega = 1.0
+	if args != nil && args.PrecOmega != 0 {
+		o.Omega = args.PrecOmega
+	}
+	if o.Omega <= 0 || o.Omega >= 2 {
+		chk.Panic("relaxation parameter ω of SSOR preconditioner must be in (0,2). ω = %g is invalid\n", o.Omega)
+	}
+}
+
+// Fact computes the preconditioner
+func (o *PcSsor) Fact(a *CCMatrix) {
+	o.a = a
+	o.d = spGetDiag(a)
+	o.w = NewVector(a.n)
+}
+
+// Apply computes z := M⁻¹ ⋅ r
+func (o *PcSsor) Apply(z, r Vector) {
+
+	// forward substitution: (D + ω L) ⋅ w = r
+	a, ω, w := o.a, o.Omega, o.w
+	copy(w, r)
+	for j := 0; j < a.n; j++ {
+		w[j] /= o.d[j]
+		for k := a.p[j]; k < a.p[j+1]; k++ {
+			if a.i[k] > j {
+				w[a.i[k]] -= ω * a.x[k] * w[j]
+			}
+		}
+	}
+
+	// scaling: w := ω (2 - ω) D ⋅ w
+	for i := 0; i < a.n; i++ {
+		w[i] *= ω * (2.0 - ω) * o.d[i]
+	}
+
+	// backward substitution: (D + ω U) ⋅ z = w
+	copy(z, w)
+	for j := a.n - 1; j >= 0; j-- {
+		z[j] /= o.d[j]
+		for k := a.p[j]; k < a.p[j+1]; k++ {
+			if a.i[k] < j {
+				z[a.i[k]] -= ω * a.x[k] * z[j]
+			}
+		}
+	}
+}
+
+// PcIlu0 implements the incomplete LU factorisation with zero fill-in
+//
+//   M = L ⋅ U   where L (unit diagonal) and U have the same sparsity pattern as A
+//
+type PcIlu0 struct {
+	n    int       // dimension
+	p, j []int     // compressed-row pointers and column indices of L+U
+	x    []float64 // values of L (below diagonal) and U (diagonal and above)
+	diag []int     // positions of diagonal entries in x
+}
+
+// Init initialises the preconditioner
+func (o *PcIlu0) Init(args *SpArgs) {
+}
+
+// Fact computes the preconditioner
+func (o *PcIlu0) Fact(a *CCMatrix) {
+
+	// compressed-row copy of a (sorted column indices)
+	o.n = a.n
+	o.p, o.j, o.x = spToCompressedRow(a)
+
+	// diagonal positions
+	o.diag = make([]int, o.n)
+	for i := 0; i < o.n; i++ {
+		o.diag[i] = -1
+		for k := o.p[i]; k < o.p[i+1]; k++ {
+			if o.j[k] == i {
+				o.diag[i] = k
+				break
+			}
+		}
+		if o.diag[i] < 0 {
+			chk.Panic("ILU(0) preconditioner requires all diagonal entries to be present. A[%d][%d] is missing\n", i, i)
+		}
+	}
+
+	// IKJ variant of Gaussian elimination restricted to the pattern of A
+	pos := make([]int, o.n) // pos[col] = position of (i,col) in current row or -1
+	for l := range pos {
+		pos[l] = -1
+	}
+	for i := 0; i < o.n; i++ {
+		for k := o.p[i]; k < o.p[i+1]; k++ {
+			pos[o.j[k]] = k
+		}
+		for k := o.p[i]; k < o.diag[i]; k++ {
+			col := o.j[k]
+			piv := o.x[o.diag[col]]
+			if piv == 0 {
+				chk.Panic("ILU(0) preconditioner found a zero pivot at row %d\n", col)
+			}
+			o.x[k] /= piv
+			for m := o.diag[col] + 1; m < o.p[col+1]; m++ {
+				if q := pos[o.j[m]]; q >= 0 {
+					o.x[q] -= o.x[k] * o.x[m]
+				}
+			}
+		}
+		for k := o.p[i]; k < o.p[i+1]; k++ {
+			pos[o.j[k]] = -1
+		}
+		if o.x[o.diag[i]] == 0 {
+			chk.Panic("ILU(0) preconditioner found a zero pivot at row %d\n", i)
+		}
+	}
+}
+
+// Apply computes z := M⁻¹ ⋅ r
+func (o *PcIlu0) Apply(z, r Vector) {
+
+	// forward substitution: L ⋅ y = r (y is stored in z)
+	for i := 0; i < o.n; i++ {
+		sum := r[i]
+		for k := o.p[i]; k < o.diag[i]; k++ {
+			sum -= o.x[k] * z[o.j[k]]
+		}
+		z[i] = sum
+	}
+
+	// backward substitution: U ⋅ z = y
+	for i := o.n - 1; i >= 0; i-- {
+		sum := z[i]
+		for k := o.diag[i] + 1; k < o.p[i+1]; k++ {
+			sum -= o.x[k] * z[o.j[k]]
+		}
+		z[i] = sum / o.x[o.diag[i]]
+	}
+}
+
+// PcIc0 implements the incomplete Cholesky factorisation with zero fill-in
+//
+//   M = L ⋅ Lᵀ   where L has the same sparsity pattern as the lower triangle of A
+//
+//   NOTE: A must be symmetric positive-definite; only its lower triangle is used
+//
+type PcIc0 struct {
+	n    int       // dimension
+	p, i []int     // column-compressed pointers and row indices of L (diagonal first in each column)
+	x    []float64 // values of L
+}
+
+// Init initialises the preconditioner
+func (o *PcIc0) Init(args *SpArgs) {
+}
+
+// Fact computes the preconditioner
+func (o *PcIc0) Fact(a *CCMatrix) {
+
+	// lower triangle of a (with sorted row indices in each column)
+	o.n = a.n
+	o.p, o.i, o.x = spLowerTriangle(a)
+
+	// right-looking factorisation restricted to the pattern of the lower triangle
+	pos := make([]int, o.n) // pos[row] = position of (row,j) in current column or -1
+	for l := range pos {
+		pos[l] = -1
+	}
+	for k := 0; k < o.n; k++ {
+		if o.p[k] == o.p[k+1] || o.i[o.p[k]] != k {
+			chk.Panic("IC(0) preconditioner requires all diagonal entries to be present. A[%d][%d] is missing\n", k, k)
+		}
+		dkk := o.x[o.p[k]]
+		if dkk <= 0 {
+			chk.Panic("IC(0) preconditioner broke down with non-positive pivot %g at row %d. Is A positive-definite?\n", dkk, k)
+		}
+		dkk = math.Sqrt(dkk)
+		o.x[o.p[k]] = dkk
+		for m := o.p[k] + 1; m < o.p[k+1]; m++ {
+			o.x[m] /= dkk
+		}
+		for m := o.p[k] + 1; m < o.p[k+1]; m++ { // update columns j > k
+			j := o.i[m]
+			for q := o.p[j]; q < o.p[j+1]; q++ {
+				pos[o.i[q]] = q
+			}
+			for l := m; l < o.p[k+1]; l++ { // rows i ≥ j
+				if q := pos[o.i[l]]; q >= 0 {
+					o.x[q] -= o.x[l] * o.x[m]
+				}
+			}
+			for q := o.p[j]; q < o.p[j+1]; q++ {
+				pos[o.i[q]] = -1
+			}
+		}
+	}
+}
+
+// Apply computes z := M⁻¹ ⋅ r
+func (o *PcIc0) Apply(z, r Vector) {
+
+	// forward substitution: L ⋅ y = r (y is stored in z)
+	copy(z, r)
+	for j := 0; j < o.n; j++ {
+		z[j] /= o.x[o.p[j]]
+		for k := o.p[j] + 1; k < o.p[j+1]; k++ {
+			z[o.i[k]] -= o.x[k] * z[j]
+		}
+	}
+
+	// backward substitution: Lᵀ ⋅ z = y
+	for j := o.n - 1; j >= 0; j-- {
+		sum := z[j]
+		for k := o.p[j] + 1; k < o.p[j+1]; k++ {
+			sum -= o.x[k] * z[o.i[k]]
+		}
+		z[j] = sum / o.x[o.p[j]]
+	}
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// spGetDiag returns the diagonal of a square sparse matrix; it panics if a diagonal entry is zero
+func spGetDiag(a *CCMatrix) (d Vector) {
+	if a.m != a.n {
+		chk.Panic("matrix must be square. %d != %d\n", a.m, a.n)
+	}
+	d = NewVector(a.n)
+	for j := 0; j < a.n; j++ {
+		for k := a.p[j]; k < a.p[j+1]; k++ {
+			if a.i[k] == j {
+				d[j] += a.x[k]
+			}
+		}
+		if d[j] == 0 {
+			chk.Panic("diagonal entry A[%d][%d] must be non-zero\n", j, j)
+		}
+	}
+	return
+}
+
+// spToCompressedRow returns the compressed-row representation of a (with sorted column indices)
+func spToCompressedRow(a *CCMatrix) (p, j []int, x []float64) {
+	nnz := a.p[a.n]
+	p = make([]int, a.m+1)
+	j = make([]int, nnz)
+	x = make([]float64, nnz)
+	for k := 0; k < nnz; k++ {
+		p[a.i[k]+1]++
+	}
+	for r := 0; r < a.m; r++ {
+		p[r+1] += p[r]
+	}
+	next := make([]int, a.m)
+	copy(next, p)
+	for c := 0; c < a.n; c++ {
+		for k := a.p[c]; k < a.p[c+1]; k++ {
+			q := next[a.i[k]]
+			j[q], x[q] = c, a.x[k]
+			next[a.i[k]]++
+		}
+	}
+	return
+}
+
+// spLowerTriangle returns the lower triangle (including diagonal) of a in column-compressed form
+// with sorted row indices in each column
+func spLowerTriangle(a *CCMatrix) (p, i []int, x []float64) {
+	rp, rj, rx := spToCompressedRow(a) // transposing twice sorts the indices
+	p = make([]int, a.n+1)
+	for r := 0; r < a.m; r++ {
+		for k := rp[r]; k < rp[r+1]; k++ {
+			if r >= rj[k] {
+				p[rj[k]+1]++
+			}
+		}
+	}
+	for c := 0; c < a.n; c++ {
+		p[c+1] += p[c]
+	}
+	i = make([]int, p[a.n])
+	x = make([]float64, p[a.n])
+	next := make([]int, a.n)
+	copy(next, p)
+	for r := 0; r < a.m; r++ {
+		for k := rp[r]; k < rp[r+1]; k++ {
+			if r >= rj[k] {
+				q := next[rj[k]]
+				i[q], x[q] = r, rx[k]
+				next[rj[k]]++
+			}
+		}
+	}
+	return
+}
+
+// add preconditioners to database /////////////////////////////////////////////////////////////////
+
+func init() {
+	precondDB["jacobi"] = func() Preconditioner { return new(PcJacobi) }
+	precondDB["ssor"] = func() Preconditioner { return new(PcSsor) }
+	precondDB["ilu0"] = func() Preconditioner { return new(PcIlu0) }
+	precondDB["ic0"] = func() Preconditioner { return new(PcIc0) }
+}
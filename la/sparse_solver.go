@@ -6,6 +6,7 @@ package la
 
 import (
 	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
 	"github.com/dicksontsai/gosl/mpi"
 )
 
@@ -13,15 +14,21 @@ import (
 type SpArgs struct {
 	Symmetric    bool              // indicates symmetric system
 	Verbose      bool              // run on Verbose mode
-	Ordering     string            // set Ordering type (check MUMPS solver) [may be empty]
+	Ordering     string            // set Ordering type (check MUMPS solver, SpLu and SpChol) [may be empty]
 	Scaling      string            // set Scaling type (check MUMPS solver) [may be empty]
 	Guess        Vector            // initial guess for iterative solvers [may be nil]
+	GuessC       VectorC           // initial guess for iterative solvers with complex numbers [may be nil]
+	Tol          float64           // tolerance for iterative solvers: ‖b - A⋅x‖ ≤ Tol⋅‖b‖ [default = 1e-10]
+	MaxIt        int               // max number of iterations for iterative solvers [default = max(10⋅n, 100)]
+	Restart      int               // number of iterations before restarting GMRES [default = 30]
+	Precond      string            // preconditioner for iterative solvers: "jacobi", "ssor", "ilu0" or "ic0" [may be empty]
+	PrecOmega    float64           // relaxation parameter ω ∈ (0,2) for the "ssor" preconditioner [default = 1]
 	Communicator *mpi.Communicator // MPI communicator for parallel solvers [may be nil]
 }
 
 // real ////////////////////////////////////////////////////////////////////////////////////////////
 
-// SparseSolver solves sparse linear systems using UMFPACK or MUMPS
+// SparseSolver solves sparse linear systems using UMFPACK, MUMPS, native direct (LU, Cholesky) or iterative (Krylov) methods
 //
 //   Given:  A ⋅ x = b    find x   such that   x = A⁻¹ ⋅ b
 //
@@ -39,19 +46,22 @@ type spSolverMaker func() SparseSolver
 var spSolverDB = make(map[string]spSolverMaker)
 
 // NewSparseSolver finds a SparseSolver in database or panic
-//   kind -- "umfpack" or "mumps"
-//   NOTE: remember to call Free() to release allocated resources
+//   kind -- "umfpack", "mumps", the native direct solvers "splu" and "spchol" or
+//           the iterative solvers "cg", "bicgstab" and "gmres"
+//   NOTE: (1) remember to call Free() to release allocated resources
+//         (2) "umfpack" and "mumps" are only available if the package is built with the
+//             corresponding tags; e.g. go build -tags "umfpack mumps"
 func NewSparseSolver(kind string) SparseSolver {
 	if maker, ok := spSolverDB[kind]; ok {
 		return maker()
 	}
-	chk.Panic("cannot find SparseSolver named %q in database", kind)
+	chk.Panic("cannot find SparseSolver named %q in database%s", kind, spMissingTag(kind))
 	return nil
 }
 
 // complex /////////////////////////////////////////////////////////////////////////////////////////
 
-// SparseSolverC solves sparse linear systems using UMFPACK or MUMPS (complex version)
+// SparseSolverC solves sparse linear systems using UMFPACK, MUMPS, native LU or iterative (Krylov) methods (complex version)
 //
 //   Given:  A ⋅ x = b    find x   such that   x = A⁻¹ ⋅ b
 //
@@ -69,25 +79,46 @@ type spSolverMakerC func() SparseSolverC
 var spSolverDBc = make(map[string]spSolverMakerC)
 
 // NewSparseSolverC finds a SparseSolver in database or panic
-//   NOTE: remember to call Free() to release allocated resources
+//   kind -- "umfpack", "mumps", the native direct solver "splu" or
+//           the iterative solvers "cg", "bicgstab" and "gmres"
+//   NOTE: (1) remember to call Free() to release allocated resources
+//         (2) "umfpack" and "mumps" are only available if the package is built with the
+//             corresponding tags; e.g. go build -tags "umfpack mumps"
 func NewSparseSolverC(kind string) SparseSolverC {
 	if maker, ok := spSolverDBc[kind]; ok {
 		return maker()
 	}
-	chk.Panic("cannot find SparseSolverC named %q in database", kind)
+	chk.Panic("cannot find SparseSolverC named %q in database%s", kind, spMissingTag(kind))
 	return nil
 }
 
+// spMissingTag returns a hint on the build tag required by the wrappers to external solvers
+func spMissingTag(kind string) string {
+	if kind == "umfpack" || kind == "mumps" {
+		return io.Sf(". the package must be built with -tags %s", kind)
+	}
+	return ""
+}
+
+// SpDefaultKind returns the default kind of sparse solver: "umfpack" if the package was built
+// with this tag or the native "splu" otherwise
+func SpDefaultKind() string {
+	if _, ok := spSolverDB["umfpack"]; ok {
+		return "umfpack"
+	}
+	return "splu"
+}
+
 // high-level functions ////////////////////////////////////////////////////////////////////////////
 
-// SpSolve solves a sparse linear system (using UMFPACK)
+// SpSolve solves a sparse linear system (using UMFPACK if available or SpLu otherwise)
 //
 //   Given:  A ⋅ x = b    find x   such that   x = A⁻¹ ⋅ b
 //
 func SpSolve(A *Triplet, b Vector) (x Vector) {
 
 	// allocate solver
-	o := NewSparseSolver("umfpack")
+	o := NewSparseSolver(SpDefaultKind())
 	defer o.Free()
 
 	// initialise solver
@@ -102,14 +133,14 @@ func SpSolve(A *Triplet, b Vector) (x Vector) {
 	return
 }
 
-// SpSolveC solves a sparse linear system (using UMFPACK) (complex version)
+// SpSolveC solves a sparse linear system (using UMFPACK if available or SpLuC otherwise) (complex version)
 //
 //   Given:  A ⋅ x = b    find x   such that   x = A⁻¹ ⋅ b
 //
 func SpSolveC(A *TripletC, b VectorC) (x VectorC) {
 
 	// allocate solver
-	o := NewSparseSolverC("umfpack")
+	o := NewSparseSolverC(SpDefaultKind())
 	defer o.Free()
 
 	// initialise solver
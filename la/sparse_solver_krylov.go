@@ -0,0 +1,845 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+)
+
+// Krylov implements iterative solvers based on Krylov subspaces (pure Go)
+//
+//   Given:  A ⋅ x = b    find x   such that   ‖b - A⋅x‖ ≤ tol ⋅ ‖b‖
+//
+//   kinds:
+//     "cg"       -- Conjugate Gradient; A must be symmetric positive-definite (set SpArgs.Symmetric)
+//     "bicgstab" -- Biconjugate Gradient Stabilised (van der Vorst); A may be nonsymmetric
+//     "gmres"    -- Generalised Minimal Residual restarted after SpArgs.Restart iterations
+//
+//   A preconditioner M ≈ A may be selected with SpArgs.Precond (see NewPreconditioner). It is
+//   applied on the left (split symmetric form) for "cg" and on the right for "bicgstab" and "gmres";
+//   hence, the residual norms are always those of the original system.
+//
+//   The matrix may also be given as a (matrix-free) LinearOperator; see NewKrylov and InitOp.
+//
+//   References:
+//     [1] Saad Y (2003) Iterative Methods for Sparse Linear Systems. 2nd Edition. SIAM. 528p
+//     [2] Barrett R et al. (1994) Templates for the Solution of Linear Systems: Building Blocks
+//         for Iterative Methods. SIAM. 112p
+//
+type Krylov struct {
+
+	// statistics (from the last call to Solve)
+	NumIter int       // number of iterations
+	ResHist []float64 // history of residual norms ‖b - A⋅x‖; ResHist[0] corresponds to the initial guess
+
+	// data
+	kind    string         // "cg", "bicgstab" or "gmres"
+	t       *Triplet       // pointer to triplet [nil if initialised with an operator]
+	a       *CCMatrix      // column-compressed matrix [may be nil]
+	op      LinearOperator // operator: A itself or matrix-free
+	pc      Preconditioner // preconditioner [may be nil]
+	guess   Vector         // initial guess [may be nil]
+	tol     float64        // relative tolerance
+	maxIt   int            // max number of iterations
+	restart int            // GMRES restart parameter
+	verbose bool           // show messages
+
+	// workspace
+	r, p, q, s, w Vector      // auxiliary vectors
+	u, z, ph, sh  Vector      // auxiliary vectors (preconditioned)
+	v             []Vector    // GMRES: orthonormal basis
+	h             [][]float64 // GMRES: Hessenberg matrix
+	c, sn, g, y   []float64   // GMRES: Givens rotations, rhs and solution of least-squares problem
+
+	// derived
+	initialised bool
+	factorised  bool
+}
+
+// NewKrylov returns a new iterative solver
+//   kind -- "cg", "bicgstab" or "gmres"
+//   NOTE: use Init with a Triplet or InitOp with a (matrix-free) LinearOperator
+func NewKrylov(kind string) (o *Krylov) {
+	switch kind {
+	case "cg", "bicgstab", "gmres":
+		return &Krylov{kind: kind}
+	}
+	chk.Panic("cannot find Krylov solver named %q\n", kind)
+	return nil
+}
+
+// Init initialises the iterative solver
+func (o *Krylov) Init(t *Triplet, args *SpArgs) {
+
+	// check
+	if o.initialised {
+		chk.Panic("solver must be initialised just once\n")
+	}
+	if t.pos == 0 {
+		chk.Panic("triplet must have at least one item for initialisation\n")
+	}
+	if t.m != t.n {
+		chk.Panic("%s solver requires a square matrix. %d != %d\n", o.kind, t.m, t.n)
+	}
+
+	// initialise
+	o.t = t
+	o.init(t.m, args)
+}
+
+// InitOp initialises the iterative solver with a (matrix-free) linear operator
+//
+//   NOTE: preconditioners require an assembled matrix; i.e. op must be a *CCMatrix if
+//         SpArgs.Precond is given
+//
+func (o *Krylov) InitOp(op LinearOperator, args *SpArgs) {
+
+	// check
+	if o.initialised {
+		chk.Panic("solver must be initialised just once\n")
+	}
+	m, n := op.Dims()
+	if m != n {
+		chk.Panic("%s solver requires a square operator. %d != %d\n", o.kind, m, n)
+	}
+	if args != nil && args.Precond != "" {
+		if _, ok := op.(*CCMatrix); !ok {
+			chk.Panic("preconditioner %q requires the operator to be a *CCMatrix\n", args.Precond)
+		}
+	}
+
+	// initialise
+	o.op = op
+	o.init(n, args)
+}
+
+// init sets options and allocates workspace
+func (o *Krylov) init(n int, args *SpArgs) {
+
+	// default arguments
+	if args == nil {
+		args = new(SpArgs)
+	}
+	if o.kind == "cg" && !args.Symmetric {
+		chk.Panic("cg solver requires a symmetric positive-definite matrix (SpArgs.Symmetric must be true)\n")
+	}
+
+	// options
+	o.guess = args.Guess
+	o.tol, o.maxIt, o.restart = krylovDefaults(n, args)
+	o.verbose = args.Verbose
+	if args.Precond != "" {
+		o.pc = NewPreconditioner(args.Precond)
+		o.pc.Init(args)
+	}
+
+	// workspace
+	o.r, o.p, o.q, o.s, o.w = NewVector(n), NewVector(n), NewVector(n), NewVector(n), NewVector(n)
+	o.u, o.z, o.ph, o.sh = NewVector(n), NewVector(n), NewVector(n), NewVector(n)
+	if o.kind == "gmres" {
+		m := o.restart
+		o.v = make([]Vector, m+1)
+		for i := 0; i < m+1; i++ {
+			o.v[i] = NewVector(n)
+		}
+		o.h = make([][]float64, m+1)
+		for i := 0; i < m+1; i++ {
+			o.h[i] = make([]float64, m)
+		}
+		o.c, o.sn, o.g, o.y = make([]float64, m), make([]float64, m), make([]float64, m+1), make([]float64, m)
+	}
+
+	// success
+	o.initialised = true
+}
+
+// Free clears extra memory allocated by the solver (nothing to be done here)
+func (o *Krylov) Free() {
+}
+
+// Fact converts the triplet into the column-compressed form and computes the preconditioner, if any
+func (o *Krylov) Fact() {
+
+	// check
+	if !o.initialised {
+		chk.Panic("linear solver must be initialised first\n")
+	}
+	o.factorised = false
+
+	// convert triplet to column-compressed format
+	if o.t != nil {
+		o.a = o.t.ToMatrix(o.a)
+		o.op = o.a
+	} else if a, ok := o.op.(*CCMatrix); ok {
+		o.a = a
+	}
+
+	// preconditioner
+	if o.pc != nil {
+		o.pc.Fact(o.a)
+	}
+
+	// success
+	o.factorised = true
+}
+
+// Solve solves the linear system iteratively
+//
+//   Given:  A ⋅ x = b    find x   such that   x = A⁻¹ ⋅ b
+//
+//   NOTE: SpArgs.Guess is used as the starting point, if given; otherwise x₀ = 0
+//
+func (o *Krylov) Solve(x, b Vector, dummy bool) {
+
+	// check
+	if !o.factorised {
+		chk.Panic("factorisation must be performed first\n")
+	}
+
+	// initial guess
+	if o.guess != nil {
+		copy(x, o.guess)
+	} else {
+		x.Fill(0)
+	}
+
+	// solve
+	o.NumIter = 0
+	o.ResHist = o.ResHist[:0]
+	bnorm := b.Norm()
+	if bnorm == 0 {
+		x.Fill(0)
+		o.ResHist = append(o.ResHist, 0)
+		return
+	}
+	var ok bool
+	switch o.kind {
+	case "cg":
+		ok = o.cg(x, b, bnorm)
+	case "bicgstab":
+		ok = o.bicgstab(x, b, bnorm)
+	case "gmres":
+		ok = o.gmres(x, b, bnorm)
+	}
+	if !ok {
+		chk.Panic("%s solver did not converge after %d iterations. ‖r‖/‖b‖ = %g\n", o.kind, o.NumIter, o.ResHist[len(o.ResHist)-1]/bnorm)
+	}
+}
+
+// residual computes r := b - A⋅x and returns ‖r‖
+func (o *Krylov) residual(r, x, b Vector) float64 {
+	o.op.MatVec(r, x)
+	for i := range r {
+		r[i] = b[i] - r[i]
+	}
+	return r.Norm()
+}
+
+// record saves the residual norm and tells whether the tolerance has been reached
+func (o *Krylov) record(rnorm, bnorm float64) (converged bool) {
+	o.ResHist = append(o.ResHist, rnorm)
+	if o.verbose {
+		io.Pf("%s: it = %4d  ‖r‖ = %23.15e  ‖r‖/‖b‖ = %13.6e\n", o.kind, o.NumIter, rnorm, rnorm/bnorm)
+	}
+	return rnorm <= o.tol*bnorm
+}
+
+// precond computes z := M⁻¹ ⋅ r or z := r if there is no preconditioner
+func (o *Krylov) precond(z, r Vector) {
+	if o.pc == nil {
+		copy(z, r)
+		return
+	}
+	o.pc.Apply(z, r)
+}
+
+// cg implements the (preconditioned) Conjugate Gradient method
+func (o *Krylov) cg(x, b Vector, bnorm float64) bool {
+	r, z, p, q := o.r, o.z, o.p, o.q
+	if o.record(o.residual(r, x, b), bnorm) {
+		return true
+	}
+	o.precond(z, r)
+	copy(p, z)
+	rz := VecDot(r, z)
+	for o.NumIter < o.maxIt {
+		o.op.MatVec(q, p) // q := A⋅p
+		pq := VecDot(p, q)
+		if pq <= 0 {
+			chk.Panic("cg solver found a non-positive curvature pᵀ⋅A⋅p = %g. Is A positive-definite?\n", pq)
+		}
+		α := rz / pq
+		for i := range x {
+			x[i] += α * p[i]
+			r[i] -= α * q[i]
+		}
+		o.NumIter++
+		if o.record(r.Norm(), bnorm) {
+			return true
+		}
+		o.precond(z, r)
+		rzNew := VecDot(r, z)
+		β := rzNew / rz
+		for i := range p {
+			p[i] = z[i] + β*p[i]
+		}
+		rz = rzNew
+	}
+	return false
+}
+
+// bicgstab implements the (right-preconditioned) Biconjugate Gradient Stabilised method
+func (o *Krylov) bicgstab(x, b Vector, bnorm float64) bool {
+	r, rHat, p, v, s, t, ph, sh := o.r, o.w, o.p, o.q, o.s, o.u, o.ph, o.sh
+	if o.record(o.residual(r, x, b), bnorm) {
+		return true
+	}
+	copy(rHat, r)
+	p.Fill(0)
+	v.Fill(0)
+	ρ, α, ω := 1.0, 1.0, 1.0
+	for o.NumIter < o.maxIt {
+		ρNew := VecDot(rHat, r)
+		if ρNew == 0 {
+			chk.Panic("bicgstab solver broke down (ρ = 0) after %d iterations\n", o.NumIter)
+		}
+		β := (ρNew / ρ) * (α / ω)
+		for i := range p {
+			p[i] = r[i] + β*(p[i]-ω*v[i])
+		}
+		o.precond(ph, p)   // p̂ := M⁻¹⋅p
+		o.op.MatVec(v, ph) // v := A⋅p̂
+		α = ρNew / VecDot(rHat, v)
+		for i := range s {
+			s[i] = r[i] - α*v[i]
+		}
+		o.NumIter++
+		snorm := s.Norm()
+		if snorm <= o.tol*bnorm {
+			for i := range x {
+				x[i] += α * ph[i]
+			}
+			copy(r, s)
+			o.record(snorm, bnorm)
+			return true
+		}
+		o.precond(sh, s)   // ŝ := M⁻¹⋅s
+		o.op.MatVec(t, sh) // t := A⋅ŝ
+		tt := VecDot(t, t)
+		if tt == 0 {
+			chk.Panic("bicgstab solver broke down (‖t‖ = 0) after %d iterations\n", o.NumIter)
+		}
+		ω = VecDot(t, s) / tt
+		for i := range x {
+			x[i] += α*ph[i] + ω*sh[i]
+			r[i] = s[i] - ω*t[i]
+		}
+		if o.record(r.Norm(), bnorm) {
+			return true
+		}
+		if ω == 0 {
+			chk.Panic("bicgstab solver broke down (ω = 0) after %d iterations\n", o.NumIter)
+		}
+		ρ = ρNew
+	}
+	return false
+}
+
+// gmres implements the restarted (right-preconditioned) Generalised Minimal Residual method
+func (o *Krylov) gmres(x, b Vector, bnorm float64) bool {
+	r, w, z, u, v, h, c, sn, g, y := o.r, o.w, o.z, o.u, o.v, o.h, o.c, o.sn, o.g, o.y
+	β := o.residual(r, x, b)
+	if o.record(β, bnorm) {
+		return true
+	}
+	for o.NumIter < o.maxIt {
+
+		// first basis vector
+		for i := range r {
+			v[0][i] = r[i] / β
+		}
+		for i := range g {
+			g[i] = 0
+		}
+		g[0] = β
+
+		// Arnoldi process with modified Gram-Schmidt orthogonalisation
+		k, converged := 0, false
+		for j := 0; j < o.restart && o.NumIter < o.maxIt; j++ {
+			o.precond(z, v[j]) // z := M⁻¹⋅vj
+			o.op.MatVec(w, z)  // w := A⋅z
+			for i := 0; i <= j; i++ {
+				h[i][j] = VecDot(w, v[i])
+				for l := range w {
+					w[l] -= h[i][j] * v[i][l]
+				}
+			}
+			h[j+1][j] = w.Norm()
+			if h[j+1][j] != 0 {
+				for i := range w {
+					v[j+1][i] = w[i] / h[j+1][j]
+				}
+			}
+
+			// apply previous Givens rotations to the new column of H
+			for i := 0; i < j; i++ {
+				tmp := c[i]*h[i][j] + sn[i]*h[i+1][j]
+				h[i+1][j] = -sn[i]*h[i][j] + c[i]*h[i+1][j]
+				h[i][j] = tmp
+			}
+
+			// compute and apply new Givens rotation
+			ρ := math.Hypot(h[j][j], h[j+1][j])
+			if ρ == 0 {
+				chk.Panic("gmres solver broke down (singular Hessenberg matrix) after %d iterations\n", o.NumIter)
+			}
+			c[j], sn[j] = h[j][j]/ρ, h[j+1][j]/ρ
+			h[j][j], h[j+1][j] = ρ, 0
+			g[j+1] = -sn[j] * g[j]
+			g[j] = c[j] * g[j]
+
+			// check convergence
+			k = j + 1
+			o.NumIter++
+			if o.record(math.Abs(g[j+1]), bnorm) {
+				converged = true
+				break
+			}
+		}
+
+		// solve the upper triangular system H⋅y = g and update x := x + M⁻¹⋅V⋅y
+		for i := k - 1; i >= 0; i-- {
+			y[i] = g[i]
+			for l := i + 1; l < k; l++ {
+				y[i] -= h[i][l] * y[l]
+			}
+			y[i] /= h[i][i]
+		}
+		u.Fill(0)
+		for l := 0; l < k; l++ {
+			for i := range u {
+				u[i] += y[l] * v[l][i]
+			}
+		}
+		o.precond(z, u)
+		for i := range x {
+			x[i] += z[i]
+		}
+		if converged {
+			return true
+		}
+
+		// restart
+		β = o.residual(r, x, b)
+	}
+	return false
+}
+
+// KrylovC implements iterative solvers based on Krylov subspaces (pure Go; complex version)
+//
+//   kinds:
+//     "cg"       -- Conjugate Gradient; A must be Hermitian positive-definite (set SpArgs.Symmetric)
+//     "bicgstab" -- Biconjugate Gradient Stabilised; A may be non-Hermitian
+//     "gmres"    -- Generalised Minimal Residual restarted after SpArgs.Restart iterations
+//
+//   NOTE: inner products are computed with the conjugate of the first argument: ⟨u,v⟩ = Σ conj(uᵢ)⋅vᵢ
+//
+type KrylovC struct {
+
+	// statistics (from the last call to Solve)
+	NumIter int       // number of iterations
+	ResHist []float64 // history of residual norms ‖b - A⋅x‖; ResHist[0] corresponds to the initial guess
+
+	// data
+	kind    string     // "cg", "bicgstab" or "gmres"
+	t       *TripletC  // pointer to triplet
+	a       *CCMatrixC // column-compressed matrix
+	guess   Vector     // real initial guess; used if guessC is nil [may be nil]
+	guessC  VectorC    // initial guess [may be nil]
+	tol     float64    // relative tolerance
+	maxIt   int        // max number of iterations
+	restart int        // GMRES restart parameter
+	verbose bool       // show messages
+
+	// workspace
+	r, p, q, s, u, w VectorC        // auxiliary vectors
+	v                []VectorC      // GMRES: orthonormal basis
+	h                [][]complex128 // GMRES: Hessenberg matrix
+	sn, g, y         []complex128   // GMRES: Givens rotations (sine), rhs and solution of least-squares problem
+	c                []float64      // GMRES: Givens rotations (cosine)
+
+	// derived
+	initialised bool
+	factorised  bool
+}
+
+// Init initialises the iterative solver
+func (o *KrylovC) Init(t *TripletC, args *SpArgs) {
+
+	// check
+	if o.initialised {
+		chk.Panic("solver must be initialised just once\n")
+	}
+	if t.pos == 0 {
+		chk.Panic("triplet must have at least one item for initialisation\n")
+	}
+	if t.m != t.n {
+		chk.Panic("%s solver requires a square matrix. %d != %d\n", o.kind, t.m, t.n)
+	}
+
+	// default arguments
+	if args == nil {
+		args = new(SpArgs)
+	}
+	if o.kind == "cg" && !args.Symmetric {
+		chk.Panic("cg solver requires a Hermitian positive-definite matrix (SpArgs.Symmetric must be true)\n")
+	}
+	if args.Precond != "" {
+		chk.Panic("preconditioners are not available for complex systems yet (SpArgs.Precond = %q)\n", args.Precond)
+	}
+
+	// options
+	o.t = t
+	o.guess, o.guessC = args.Guess, args.GuessC
+	o.tol, o.maxIt, o.restart = krylovDefaults(t.m, args)
+	o.verbose = args.Verbose
+
+	// workspace
+	n := t.m
+	o.r, o.p, o.q, o.s, o.u, o.w = NewVectorC(n), NewVectorC(n), NewVectorC(n), NewVectorC(n), NewVectorC(n), NewVectorC(n)
+	if o.kind == "gmres" {
+		m := o.restart
+		o.v = make([]VectorC, m+1)
+		for i := 0; i < m+1; i++ {
+			o.v[i] = NewVectorC(n)
+		}
+		o.h = make([][]complex128, m+1)
+		for i := 0; i < m+1; i++ {
+			o.h[i] = make([]complex128, m)
+		}
+		o.c, o.sn, o.g, o.y = make([]float64, m), make([]complex128, m), make([]complex128, m+1), make([]complex128, m)
+	}
+
+	// success
+	o.initialised = true
+}
+
+// Free clears extra memory allocated by the solver (nothing to be done here)
+func (o *KrylovC) Free() {
+}
+
+// Fact converts the triplet into the column-compressed form (there is no factorisation)
+func (o *KrylovC) Fact() {
+
+	// check
+	if !o.initialised {
+		chk.Panic("linear solver must be initialised first\n")
+	}
+	o.factorised = false
+
+	// convert triplet to column-compressed format
+	o.a = o.t.ToMatrix(o.a)
+
+	// success
+	o.factorised = true
+}
+
+// Solve solves the linear system iteratively
+//
+//   Given:  A ⋅ x = b    find x   such that   x = A⁻¹ ⋅ b
+//
+//   NOTE: SpArgs.GuessC is used as the starting point, if given; otherwise, the real SpArgs.Guess
+//         is used (with zero imaginary part), if given; otherwise x₀ = 0
+//
+func (o *KrylovC) Solve(x, b VectorC, dummy bool) {
+
+	// check
+	if !o.factorised {
+		chk.Panic("factorisation must be performed first\n")
+	}
+
+	// initial guess
+	switch {
+	case o.guessC != nil:
+		copy(x, o.guessC)
+	case o.guess != nil:
+		for i := range x {
+			x[i] = complex(o.guess[i], 0)
+		}
+	default:
+		x.Fill(0)
+	}
+
+	// solve
+	o.NumIter = 0
+	o.ResHist = o.ResHist[:0]
+	bnorm := normC(b)
+	if bnorm == 0 {
+		x.Fill(0)
+		o.ResHist = append(o.ResHist, 0)
+		return
+	}
+	var ok bool
+	switch o.kind {
+	case "cg":
+		ok = o.cg(x, b, bnorm)
+	case "bicgstab":
+		ok = o.bicgstab(x, b, bnorm)
+	case "gmres":
+		ok = o.gmres(x, b, bnorm)
+	}
+	if !ok {
+		chk.Panic("%s solver did not converge after %d iterations. ‖r‖/‖b‖ = %g\n", o.kind, o.NumIter, o.ResHist[len(o.ResHist)-1]/bnorm)
+	}
+}
+
+// residual computes r := b - A⋅x and returns ‖r‖
+func (o *KrylovC) residual(r, x, b VectorC) float64 {
+	SpMatVecMulC(r, -1, o.a, x)
+	for i := range r {
+		r[i] += b[i]
+	}
+	return normC(r)
+}
+
+// record saves the residual norm and tells whether the tolerance has been reached
+func (o *KrylovC) record(rnorm, bnorm float64) (converged bool) {
+	o.ResHist = append(o.ResHist, rnorm)
+	if o.verbose {
+		io.Pf("%s: it = %4d  ‖r‖ = %23.15e  ‖r‖/‖b‖ = %13.6e\n", o.kind, o.NumIter, rnorm, rnorm/bnorm)
+	}
+	return rnorm <= o.tol*bnorm
+}
+
+// cg implements the Conjugate Gradient method
+func (o *KrylovC) cg(x, b VectorC, bnorm float64) bool {
+	r, p, q := o.r, o.p, o.q
+	if o.record(o.residual(r, x, b), bnorm) {
+		return true
+	}
+	copy(p, r)
+	rr := real(dotC(r, r))
+	for o.NumIter < o.maxIt {
+		SpMatVecMulC(q, 1, o.a, p) // q := A⋅p
+		pq := dotC(p, q)
+		if real(pq) <= 0 {
+			chk.Panic("cg solver found a non-positive curvature pᴴ⋅A⋅p = %v. Is A positive-definite?\n", pq)
+		}
+		α := complex(rr, 0) / pq
+		for i := range x {
+			x[i] += α * p[i]
+			r[i] -= α * q[i]
+		}
+		rrNew := real(dotC(r, r))
+		o.NumIter++
+		if o.record(math.Sqrt(rrNew), bnorm) {
+			return true
+		}
+		β := complex(rrNew/rr, 0)
+		for i := range p {
+			p[i] = r[i] + β*p[i]
+		}
+		rr = rrNew
+	}
+	return false
+}
+
+// bicgstab implements the Biconjugate Gradient Stabilised method
+func (o *KrylovC) bicgstab(x, b VectorC, bnorm float64) bool {
+	r, rHat, p, v, s, t := o.r, o.w, o.p, o.q, o.s, o.u
+	if o.record(o.residual(r, x, b), bnorm) {
+		return true
+	}
+	copy(rHat, r)
+	p.Fill(0)
+	v.Fill(0)
+	var ρ, α, ω complex128 = 1, 1, 1
+	for o.NumIter < o.maxIt {
+		ρNew := dotC(rHat, r)
+		if ρNew == 0 {
+			chk.Panic("bicgstab solver broke down (ρ = 0) after %d iterations\n", o.NumIter)
+		}
+		β := (ρNew / ρ) * (α / ω)
+		for i := range p {
+			p[i] = r[i] + β*(p[i]-ω*v[i])
+		}
+		SpMatVecMulC(v, 1, o.a, p) // v := A⋅p
+		α = ρNew / dotC(rHat, v)
+		for i := range s {
+			s[i] = r[i] - α*v[i]
+		}
+		o.NumIter++
+		snorm := normC(s)
+		if snorm <= o.tol*bnorm {
+			for i := range x {
+				x[i] += α * p[i]
+			}
+			copy(r, s)
+			o.record(snorm, bnorm)
+			return true
+		}
+		SpMatVecMulC(t, 1, o.a, s) // t := A⋅s
+		tt := dotC(t, t)
+		if tt == 0 {
+			chk.Panic("bicgstab solver broke down (‖t‖ = 0) after %d iterations\n", o.NumIter)
+		}
+		ω = dotC(t, s) / tt
+		for i := range x {
+			x[i] += α*p[i] + ω*s[i]
+			r[i] = s[i] - ω*t[i]
+		}
+		if o.record(normC(r), bnorm) {
+			return true
+		}
+		if ω == 0 {
+			chk.Panic("bicgstab solver broke down (ω = 0) after %d iterations\n", o.NumIter)
+		}
+		ρ = ρNew
+	}
+	return false
+}
+
+// gmres implements the restarted Generalised Minimal Residual method
+func (o *KrylovC) gmres(x, b VectorC, bnorm float64) bool {
+	r, w, v, h, c, sn, g, y := o.r, o.w, o.v, o.h, o.c, o.sn, o.g, o.y
+	β := o.residual(r, x, b)
+	if o.record(β, bnorm) {
+		return true
+	}
+	for o.NumIter < o.maxIt {
+
+		// first basis vector
+		for i := range r {
+			v[0][i] = r[i] / complex(β, 0)
+		}
+		for i := range g {
+			g[i] = 0
+		}
+		g[0] = complex(β, 0)
+
+		// Arnoldi process with modified Gram-Schmidt orthogonalisation
+		k, converged := 0, false
+		for j := 0; j < o.restart && o.NumIter < o.maxIt; j++ {
+			SpMatVecMulC(w, 1, o.a, v[j]) // w := A⋅vj
+			for i := 0; i <= j; i++ {
+				h[i][j] = dotC(v[i], w)
+				for l := range w {
+					w[l] -= h[i][j] * v[i][l]
+				}
+			}
+			wnorm := normC(w)
+			h[j+1][j] = complex(wnorm, 0)
+			if wnorm != 0 {
+				for i := range w {
+					v[j+1][i] = w[i] / h[j+1][j]
+				}
+			}
+
+			// apply previous Givens rotations to the new column of H
+			for i := 0; i < j; i++ {
+				tmp := complex(c[i], 0)*h[i][j] + sn[i]*h[i+1][j]
+				h[i+1][j] = -cmplx.Conj(sn[i])*h[i][j] + complex(c[i], 0)*h[i+1][j]
+				h[i][j] = tmp
+			}
+
+			// compute and apply new Givens rotation
+			habs := cmplx.Abs(h[j][j])
+			ρ := math.Hypot(habs, wnorm)
+			if ρ == 0 {
+				chk.Panic("gmres solver broke down (singular Hessenberg matrix) after %d iterations\n", o.NumIter)
+			}
+			if habs == 0 {
+				c[j], sn[j] = 0, 1
+				h[j][j] = h[j+1][j]
+			} else {
+				phase := h[j][j] / complex(habs, 0)
+				c[j], sn[j] = habs/ρ, phase*cmplx.Conj(h[j+1][j])/complex(ρ, 0)
+				h[j][j] = phase * complex(ρ, 0)
+			}
+			h[j+1][j] = 0
+			g[j+1] = -cmplx.Conj(sn[j]) * g[j]
+			g[j] = complex(c[j], 0) * g[j]
+
+			// check convergence
+			k = j + 1
+			o.NumIter++
+			if o.record(cmplx.Abs(g[j+1]), bnorm) {
+				converged = true
+				break
+			}
+		}
+
+		// solve the upper triangular system H⋅y = g and update x := x + V⋅y
+		for i := k - 1; i >= 0; i-- {
+			y[i] = g[i]
+			for l := i + 1; l < k; l++ {
+				y[i] -= h[i][l] * y[l]
+			}
+			y[i] /= h[i][i]
+		}
+		for l := 0; l < k; l++ {
+			for i := range x {
+				x[i] += y[l] * v[l][i]
+			}
+		}
+		if converged {
+			return true
+		}
+
+		// restart
+		β = o.residual(r, x, b)
+	}
+	return false
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// krylovDefaults returns the tolerance, max number of iterations and restart parameter
+func krylovDefaults(n int, args *SpArgs) (tol float64, maxIt, restart int) {
+	tol, maxIt, restart = args.Tol, args.MaxIt, args.Restart
+	if tol <= 0 {
+		tol = 1e-10
+	}
+	if maxIt <= 0 {
+		maxIt = 10 * n
+		if maxIt < 100 {
+			maxIt = 100
+		}
+	}
+	if restart <= 0 {
+		restart = 30
+	}
+	if restart > n {
+		restart = n
+	}
+	return
+}
+
+// dotC computes the inner product ⟨u,v⟩ = Σ conj(uᵢ)⋅vᵢ
+func dotC(u, v VectorC) (res complex128) {
+	for i := range u {
+		res += cmplx.Conj(u[i]) * v[i]
+	}
+	return
+}
+
+// normC computes the Euclidean norm of a complex vector
+func normC(u VectorC) float64 {
+	return math.Sqrt(real(dotC(u, u)))
+}
+
+// add solvers to database /////////////////////////////////////////////////////////////////////////
+
+func init() {
+	for _, kind := range []string{"cg", "bicgstab", "gmres"} {
+		k := kind
+		spSolverDB[k] = func() SparseSolver { return &Krylov{kind: k} }
+		spSolverDBc[k] = func() SparseSolverC { return &KrylovC{kind: k} }
+	}
+}
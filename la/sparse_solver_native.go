@@ -0,0 +1,858 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+)
+
+// spLuPivTol is the threshold for partial pivoting in the sparse LU factorisation: the diagonal
+// entry is kept as pivot if |a_kk| ≥ spLuPivTol ⋅ max_i |a_ik|, preserving the fill-reducing ordering
+const spLuPivTol = 0.1
+
+// real ////////////////////////////////////////////////////////////////////////////////////////////
+
+// SpLu implements a sparse direct solver based on the LU factorisation with partial pivoting (pure Go)
+//
+//   Computes:  P ⋅ A ⋅ Q = L ⋅ U
+//
+//   where Q is a fill-reducing column ordering (set by SpArgs.Ordering) and P is the row
+//   permutation from threshold partial pivoting. L is unit lower triangular and U is upper
+//   triangular. The factorisation is left-looking (Gilbert-Peierls); i.e. each column of L and U
+//   is computed by a sparse triangular solve whose nonzero pattern is found by a depth-first search.
+//
+//   SpArgs.Ordering:
+//     "amd"     -- approximate minimum degree on the pattern of A + Aᵀ [default]
+//     "rcm"     -- reverse Cuthill-McKee on the pattern of A + Aᵀ
+//     "nd"      -- nested dissection on the pattern of A + Aᵀ
+//     "natural" -- no ordering
+//
+//   References:
+//     [1] Gilbert JR and Peierls T (1988) Sparse partial pivoting in time proportional to arithmetic
+//         operations. SIAM Journal on Scientific and Statistical Computing, 9(5):862-874
+//     [2] Davis TA (2006) Direct Methods for Sparse Linear Systems. SIAM. 217p
+//
+type SpLu struct {
+
+	// statistics (from the last call to Fact)
+	NnzL int // number of nonzeros in L (including the unit diagonal)
+	NnzU int // number of nonzeros in U
+
+	// data
+	t        *Triplet  // pointer to triplet
+	a        *CCMatrix // column-compressed matrix
+	ordering string    // ordering scheme
+	verbose  bool      // show messages
+
+	// factors
+	q      []int     // column permutation (new-to-old)
+	pinv   []int     // inverse row permutation (old-to-new)
+	lp, li []int     // L: column pointers and row indices
+	lx     []float64 // L: values
+	up, ui []int     // U: column pointers and row indices
+	ux     []float64 // U: values
+
+	// workspace
+	x    []float64 // dense column
+	xi   []int     // nonzero pattern and stack
+	mark []bool    // marks for depth-first search
+
+	// derived
+	initialised bool
+	factorised  bool
+}
+
+// Init initialises the sparse LU solver
+func (o *SpLu) Init(t *Triplet, args *SpArgs) {
+
+	// check
+	if o.initialised {
+		chk.Panic("solver must be initialised just once\n")
+	}
+	if t.pos == 0 {
+		chk.Panic("triplet must have at least one item for initialisation\n")
+	}
+	if t.m != t.n {
+		chk.Panic("splu solver requires a square matrix. %d != %d\n", t.m, t.n)
+	}
+
+	// default arguments
+	if args == nil {
+		args = new(SpArgs)
+	}
+
+	// options
+	o.t = t
+	o.ordering = nativeOrdering(args.Ordering)
+	o.verbose = args.Verbose
+
+	// workspace
+	n := t.m
+	o.x = make([]float64, n)
+	o.xi = make([]int, 2*n)
+	o.mark = make([]bool, n)
+
+	// success
+	o.initialised = true
+}
+
+// Free clears extra memory allocated by the solver (nothing to be done here)
+func (o *SpLu) Free() {
+}
+
+// Fact performs the symbolic (ordering) and numeric factorisations
+func (o *SpLu) Fact() {
+
+	// check
+	if !o.initialised {
+		chk.Panic("linear solver must be initialised first\n")
+	}
+	o.factorised = false
+
+	// convert triplet to column-compressed format
+	o.a = o.t.ToMatrix(o.a)
+	a := o.a
+	n := a.n
+
+	// column ordering
+	o.q = SpOrdering(o.ordering, a)
+
+	// allocate factors
+	nnz := a.p[n]
+	o.lp, o.up = make([]int, n+1), make([]int, n+1)
+	o.li, o.ui = make([]int, 0, 4*nnz+n), make([]int, 0, 4*nnz+n)
+	o.lx, o.ux = make([]float64, 0, 4*nnz+n), make([]float64, 0, 4*nnz+n)
+	o.pinv = make([]int, n)
+	for i := 0; i < n; i++ {
+		o.pinv[i] = -1
+	}
+
+	// compute L and U column by column
+	x := o.x
+	for k := 0; k < n; k++ {
+		o.lp[k], o.up[k] = len(o.li), len(o.ui)
+
+		// x := L \ A(:,col)
+		col := o.q[k]
+		top := spReach(o.lp, o.li, a.i[a.p[col]:a.p[col+1]], o.pinv, o.xi, o.mark)
+		for p := top; p < n; p++ {
+			x[o.xi[p]] = 0
+		}
+		for p := a.p[col]; p < a.p[col+1]; p++ {
+			x[a.i[p]] = a.x[p]
+		}
+		for p := top; p < n; p++ {
+			j := o.xi[p]
+			J := o.pinv[j]
+			if J < 0 {
+				continue
+			}
+			for m := o.lp[J] + 1; m < o.lp[J+1]; m++ {
+				x[o.li[m]] -= o.lx[m] * x[j]
+			}
+		}
+
+		// find pivot and store U(:,k)
+		ipiv, amax := -1, -1.0
+		for p := top; p < n; p++ {
+			i := o.xi[p]
+			if o.pinv[i] < 0 {
+				if t := math.Abs(x[i]); t > amax {
+					amax, ipiv = t, i
+				}
+			} else {
+				o.ui = append(o.ui, o.pinv[i])
+				o.ux = append(o.ux, x[i])
+			}
+		}
+		if ipiv < 0 || amax <= 0 {
+			chk.Panic("splu: matrix is structurally or numerically singular (column %d)\n", col)
+		}
+		if o.pinv[col] < 0 && math.Abs(x[col]) >= spLuPivTol*amax {
+			ipiv = col
+		}
+		pivot := x[ipiv]
+		o.ui = append(o.ui, k)
+		o.ux = append(o.ux, pivot)
+		o.pinv[ipiv] = k
+
+		// store L(:,k)
+		o.li = append(o.li, ipiv)
+		o.lx = append(o.lx, 1)
+		for p := top; p < n; p++ {
+			i := o.xi[p]
+			if o.pinv[i] < 0 {
+				o.li = append(o.li, i)
+				o.lx = append(o.lx, x[i]/pivot)
+			}
+			x[i] = 0
+		}
+	}
+	o.lp[n], o.up[n] = len(o.li), len(o.ui)
+
+	// renumber the rows of L
+	for p := range o.li {
+		o.li[p] = o.pinv[o.li[p]]
+	}
+
+	// statistics
+	o.NnzL, o.NnzU = len(o.li), len(o.ui)
+	if o.verbose {
+		nativeStats("splu", o.ordering, n, nnz, o.NnzL+o.NnzU-n)
+	}
+
+	// success
+	o.factorised = true
+}
+
+// Solve solves the linear system using the LU factors
+//
+//   Given:  A ⋅ x = b    find x   such that   x = A⁻¹ ⋅ b
+//
+func (o *SpLu) Solve(x, b Vector, dummy bool) {
+
+	// check
+	if !o.factorised {
+		chk.Panic("factorisation must be performed first\n")
+	}
+
+	// y := P ⋅ b
+	n := len(o.q)
+	y := o.x
+	for i := 0; i < n; i++ {
+		y[o.pinv[i]] = b[i]
+	}
+
+	// L ⋅ z = y (unit diagonal stored first)
+	for j := 0; j < n; j++ {
+		for p := o.lp[j] + 1; p < o.lp[j+1]; p++ {
+			y[o.li[p]] -= o.lx[p] * y[j]
+		}
+	}
+
+	// U ⋅ w = z (diagonal stored last)
+	for j := n - 1; j >= 0; j-- {
+		y[j] /= o.ux[o.up[j+1]-1]
+		for p := o.up[j]; p < o.up[j+1]-1; p++ {
+			y[o.ui[p]] -= o.ux[p] * y[j]
+		}
+	}
+
+	// x := Q ⋅ w
+	for k := 0; k < n; k++ {
+		x[o.q[k]] = y[k]
+		y[k] = 0
+	}
+}
+
+// SpChol implements a sparse direct solver based on the Cholesky factorisation (pure Go)
+//
+//   Computes:  P ⋅ A ⋅ Pᵀ = L ⋅ Lᵀ
+//
+//   where A is symmetric positive-definite and P is a fill-reducing ordering (set by
+//   SpArgs.Ordering). The elimination tree gives the pattern of each row of L, which is then
+//   computed by an up-looking algorithm. Only the lower triangle of A is used.
+//
+//   SpArgs.Ordering:
+//     "amd"     -- approximate minimum degree [default]
+//     "rcm"     -- reverse Cuthill-McKee
+//     "nd"      -- nested dissection
+//     "natural" -- no ordering
+//
+//   Reference:
+//     [1] Davis TA (2006) Direct Methods for Sparse Linear Systems. SIAM. 217p
+//
+type SpChol struct {
+
+	// statistics (from the last call to Fact)
+	NnzL int // number of nonzeros in L
+
+	// data
+	t        *Triplet  // pointer to triplet
+	a        *CCMatrix // column-compressed matrix
+	ordering string    // ordering scheme
+	verbose  bool      // show messages
+
+	// factors
+	perm   []int     // symmetric permutation (new-to-old)
+	lp, li []int     // L: column pointers and row indices
+	lx     []float64 // L: values
+
+	// workspace
+	x []float64 // dense row
+	s []int     // nonzero pattern of row of L and stack
+	w []int     // marks for the elimination-tree reach and next entry of each column of L
+
+	// derived
+	initialised bool
+	factorised  bool
+}
+
+// Init initialises the sparse Cholesky solver
+func (o *SpChol) Init(t *Triplet, args *SpArgs) {
+
+	// check
+	if o.initialised {
+		chk.Panic("solver must be initialised just once\n")
+	}
+	if t.pos == 0 {
+		chk.Panic("triplet must have at least one item for initialisation\n")
+	}
+	if t.m != t.n {
+		chk.Panic("spchol solver requires a square matrix. %d != %d\n", t.m, t.n)
+	}
+
+	// default arguments
+	if args == nil {
+		args = new(SpArgs)
+	}
+	if !args.Symmetric {
+		chk.Panic("spchol solver requires a symmetric positive-definite matrix (SpArgs.Symmetric must be true)\n")
+	}
+
+	// options
+	o.t = t
+	o.ordering = nativeOrdering(args.Ordering)
+	o.verbose = args.Verbose
+
+	// workspace
+	n := t.m
+	o.x = make([]float64, n)
+	o.s = make([]int, n)
+	o.w = make([]int, n)
+
+	// success
+	o.initialised = true
+}
+
+// Free clears extra memory allocated by the solver (nothing to be done here)
+func (o *SpChol) Free() {
+}
+
+// Fact performs the symbolic (ordering and elimination tree) and numeric factorisations
+func (o *SpChol) Fact() {
+
+	// check
+	if !o.initialised {
+		chk.Panic("linear solver must be initialised first\n")
+	}
+	o.factorised = false
+
+	// convert triplet to column-compressed format
+	o.a = o.t.ToMatrix(o.a)
+	n := o.a.n
+
+	// C := upper triangle of P⋅A⋅Pᵀ
+	o.perm = SpOrdering(o.ordering, o.a)
+	cp, ci, cx := spSymPermUpper(o.a, PermInv(o.perm))
+
+	// elimination tree and column counts
+	parent := spEtree(n, cp, ci, o.w)
+	for i := 0; i < n; i++ {
+		o.w[i] = 0
+	}
+	counts := make([]int, n)
+	for k := 0; k < n; k++ {
+		top := spEreach(k, cp, ci, parent, o.s, o.w)
+		for p := top; p < n; p++ {
+			counts[o.s[p]]++
+		}
+		counts[k]++
+	}
+	o.lp = make([]int, n+1)
+	for k := 0; k < n; k++ {
+		o.lp[k+1] = o.lp[k] + counts[k]
+	}
+	o.li = make([]int, o.lp[n])
+	o.lx = make([]float64, o.lp[n])
+
+	// numeric factorisation: compute L(k,:) for each row k
+	c := counts // next free entry in each column of L
+	copy(c, o.lp[:n])
+	x := o.x
+	for k := 0; k < n; k++ {
+
+		// x := C(0:k,k) and pattern of L(k,:)
+		top := spEreach(k, cp, ci, parent, o.s, o.w)
+		x[k] = 0
+		for p := cp[k]; p < cp[k+1]; p++ {
+			x[ci[p]] += cx[p]
+		}
+		d := x[k]
+		x[k] = 0
+
+		// triangular solve
+		for ; top < n; top++ {
+			i := o.s[top]
+			lki := x[i] / o.lx[o.lp[i]]
+			x[i] = 0
+			for p := o.lp[i] + 1; p < c[i]; p++ {
+				x[o.li[p]] -= o.lx[p] * lki
+			}
+			d -= lki * lki
+			p := c[i]
+			c[i]++
+			o.li[p] = k
+			o.lx[p] = lki
+		}
+
+		// diagonal
+		if d <= 0 {
+			chk.Panic("spchol: matrix is not positive-definite (pivot %d = %g)\n", k, d)
+		}
+		p := c[k]
+		c[k]++
+		o.li[p] = k
+		o.lx[p] = math.Sqrt(d)
+	}
+
+	// statistics
+	o.NnzL = o.lp[n]
+	if o.verbose {
+		nativeStats("spchol", o.ordering, n, o.a.p[n], o.NnzL)
+	}
+
+	// success
+	o.factorised = true
+}
+
+// Solve solves the linear system using the Cholesky factor
+//
+//   Given:  A ⋅ x = b    find x   such that   x = A⁻¹ ⋅ b
+//
+func (o *SpChol) Solve(x, b Vector, dummy bool) {
+
+	// check
+	if !o.factorised {
+		chk.Panic("factorisation must be performed first\n")
+	}
+
+	// y := P ⋅ b
+	n := len(o.perm)
+	y := o.x
+	for k := 0; k < n; k++ {
+		y[k] = b[o.perm[k]]
+	}
+
+	// L ⋅ z = y (diagonal stored first)
+	for j := 0; j < n; j++ {
+		y[j] /= o.lx[o.lp[j]]
+		for p := o.lp[j] + 1; p < o.lp[j+1]; p++ {
+			y[o.li[p]] -= o.lx[p] * y[j]
+		}
+	}
+
+	// Lᵀ ⋅ w = z
+	for j := n - 1; j >= 0; j-- {
+		for p := o.lp[j] + 1; p < o.lp[j+1]; p++ {
+			y[j] -= o.lx[p] * y[o.li[p]]
+		}
+		y[j] /= o.lx[o.lp[j]]
+	}
+
+	// x := Pᵀ ⋅ w
+	for k := 0; k < n; k++ {
+		x[o.perm[k]] = y[k]
+		y[k] = 0
+	}
+}
+
+// complex /////////////////////////////////////////////////////////////////////////////////////////
+
+// SpLuC implements a sparse direct solver based on the LU factorisation with partial pivoting
+// (pure Go; complex version). See SpLu for details
+type SpLuC struct {
+
+	// statistics (from the last call to Fact)
+	NnzL int // number of nonzeros in L (including the unit diagonal)
+	NnzU int // number of nonzeros in U
+
+	// data
+	t        *TripletC  // pointer to triplet
+	a        *CCMatrixC // column-compressed matrix
+	ordering string     // ordering scheme
+	verbose  bool       // show messages
+
+	// factors
+	q      []int        // column permutation (new-to-old)
+	pinv   []int        // inverse row permutation (old-to-new)
+	lp, li []int        // L: column pointers and row indices
+	lx     []complex128 // L: values
+	up, ui []int        // U: column pointers and row indices
+	ux     []complex128 // U: values
+
+	// workspace
+	x    []complex128 // dense column
+	xi   []int        // nonzero pattern and stack
+	mark []bool       // marks for depth-first search
+
+	// derived
+	initialised bool
+	factorised  bool
+}
+
+// Init initialises the sparse LU solver
+func (o *SpLuC) Init(t *TripletC, args *SpArgs) {
+
+	// check
+	if o.initialised {
+		chk.Panic("solver must be initialised just once\n")
+	}
+	if t.pos == 0 {
+		chk.Panic("triplet must have at least one item for initialisation\n")
+	}
+	if t.m != t.n {
+		chk.Panic("splu solver requires a square matrix. %d != %d\n", t.m, t.n)
+	}
+
+	// default arguments
+	if args == nil {
+		args = new(SpArgs)
+	}
+
+	// options
+	o.t = t
+	o.ordering = nativeOrdering(args.Ordering)
+	o.verbose = args.Verbose
+
+	// workspace
+	n := t.m
+	o.x = make([]complex128, n)
+	o.xi = make([]int, 2*n)
+	o.mark = make([]bool, n)
+
+	// success
+	o.initialised = true
+}
+
+// Free clears extra memory allocated by the solver (nothing to be done here)
+func (o *SpLuC) Free() {
+}
+
+// Fact performs the symbolic (ordering) and numeric factorisations
+func (o *SpLuC) Fact() {
+
+	// check
+	if !o.initialised {
+		chk.Panic("linear solver must be initialised first\n")
+	}
+	o.factorised = false
+
+	// convert triplet to column-compressed format
+	o.a = o.t.ToMatrix(o.a)
+	a := o.a
+	n := a.n
+
+	// column ordering
+	o.q = SpOrdering(o.ordering, &CCMatrix{m: a.m, n: a.n, p: a.p, i: a.i})
+
+	// allocate factors
+	nnz := a.p[n]
+	o.lp, o.up = make([]int, n+1), make([]int, n+1)
+	o.li, o.ui = make([]int, 0, 4*nnz+n), make([]int, 0, 4*nnz+n)
+	o.lx, o.ux = make([]complex128, 0, 4*nnz+n), make([]complex128, 0, 4*nnz+n)
+	o.pinv = make([]int, n)
+	for i := 0; i < n; i++ {
+		o.pinv[i] = -1
+	}
+
+	// compute L and U column by column
+	x := o.x
+	for k := 0; k < n; k++ {
+		o.lp[k], o.up[k] = len(o.li), len(o.ui)
+
+		// x := L \ A(:,col)
+		col := o.q[k]
+		top := spReach(o.lp, o.li, a.i[a.p[col]:a.p[col+1]], o.pinv, o.xi, o.mark)
+		for p := top; p < n; p++ {
+			x[o.xi[p]] = 0
+		}
+		for p := a.p[col]; p < a.p[col+1]; p++ {
+			x[a.i[p]] = a.x[p]
+		}
+		for p := top; p < n; p++ {
+			j := o.xi[p]
+			J := o.pinv[j]
+			if J < 0 {
+				continue
+			}
+			for m := o.lp[J] + 1; m < o.lp[J+1]; m++ {
+				x[o.li[m]] -= o.lx[m] * x[j]
+			}
+		}
+
+		// find pivot and store U(:,k)
+		ipiv, amax := -1, -1.0
+		for p := top; p < n; p++ {
+			i := o.xi[p]
+			if o.pinv[i] < 0 {
+				if t := cmplx.Abs(x[i]); t > amax {
+					amax, ipiv = t, i
+				}
+			} else {
+				o.ui = append(o.ui, o.pinv[i])
+				o.ux = append(o.ux, x[i])
+			}
+		}
+		if ipiv < 0 || amax <= 0 {
+			chk.Panic("splu: matrix is structurally or numerically singular (column %d)\n", col)
+		}
+		if o.pinv[col] < 0 && cmplx.Abs(x[col]) >= spLuPivTol*amax {
+			ipiv = col
+		}
+		pivot := x[ipiv]
+		o.ui = append(o.ui, k)
+		o.ux = append(o.ux, pivot)
+		o.pinv[ipiv] = k
+
+		// store L(:,k)
+		o.li = append(o.li, ipiv)
+		o.lx = append(o.lx, 1)
+		for p := top; p < n; p++ {
+			i := o.xi[p]
+			if o.pinv[i] < 0 {
+				o.li = append(o.li, i)
+				o.lx = append(o.lx, x[i]/pivot)
+			}
+			x[i] = 0
+		}
+	}
+	o.lp[n], o.up[n] = len(o.li), len(o.ui)
+
+	// renumber the rows of L
+	for p := range o.li {
+		o.li[p] = o.pinv[o.li[p]]
+	}
+
+	// statistics
+	o.NnzL, o.NnzU = len(o.li), len(o.ui)
+	if o.verbose {
+		nativeStats("splu", o.ordering, n, nnz, o.NnzL+o.NnzU-n)
+	}
+
+	// success
+	o.factorised = true
+}
+
+// Solve solves the linear system using the LU factors
+//
+//   Given:  A ⋅ x = b    find x   such that   x = A⁻¹ ⋅ b
+//
+func (o *SpLuC) Solve(x, b VectorC, dummy bool) {
+
+	// check
+	if !o.factorised {
+		chk.Panic("factorisation must be performed first\n")
+	}
+
+	// y := P ⋅ b
+	n := len(o.q)
+	y := o.x
+	for i := 0; i < n; i++ {
+		y[o.pinv[i]] = b[i]
+	}
+
+	// L ⋅ z = y (unit diagonal stored first)
+	for j := 0; j < n; j++ {
+		for p := o.lp[j] + 1; p < o.lp[j+1]; p++ {
+			y[o.li[p]] -= o.lx[p] * y[j]
+		}
+	}
+
+	// U ⋅ w = z (diagonal stored last)
+	for j := n - 1; j >= 0; j-- {
+		y[j] /= o.ux[o.up[j+1]-1]
+		for p := o.up[j]; p < o.up[j+1]-1; p++ {
+			y[o.ui[p]] -= o.ux[p] * y[j]
+		}
+	}
+
+	// x := Q ⋅ w
+	for k := 0; k < n; k++ {
+		x[o.q[k]] = y[k]
+		y[k] = 0
+	}
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// nativeOrdering returns the ordering scheme for the native solvers
+func nativeOrdering(ordering string) string {
+	switch ordering {
+	case "", "amd":
+		return "amd"
+	case "rcm", "nd", "natural":
+		return ordering
+	}
+	chk.Panic("ordering %q is not available for the native sparse solvers. Use \"amd\", \"rcm\", \"nd\" or \"natural\"\n", ordering)
+	return ""
+}
+
+// nativeStats prints statistics of the factorisation
+func nativeStats(kind, ordering string, n, nnzA, nnzF int) {
+	io.Pf("%s: n = %d, ordering = %s, nnz(A) = %d, nnz(factors) = %d, fill = %.2f\n", kind, n, ordering, nnzA, nnzF, float64(nnzF)/float64(nnzA))
+}
+
+// spReach computes the nonzero pattern of x in L ⋅ x = b where b has the pattern bi. L is given by
+// the column pointers lp and row indices li (in original numbering) of the columns computed so far;
+// pinv maps rows of L to columns. The pattern is returned in xi[top:n] in topological order
+func spReach(lp, li, bi, pinv, xi []int, mark []bool) (top int) {
+	n := len(mark)
+	top = n
+	for _, i := range bi {
+		if !mark[i] {
+			top = spDfs(i, lp, li, pinv, top, xi, mark)
+		}
+	}
+	for p := top; p < n; p++ {
+		mark[xi[p]] = false
+	}
+	return
+}
+
+// spDfs performs a non-recursive depth-first search of the graph of L starting at node j.
+// xi[:n] is used as stack and xi[n:] holds the positions within the columns
+func spDfs(j int, lp, li, pinv []int, top int, xi []int, mark []bool) int {
+	n := len(mark)
+	stack, pstack := xi[:n], xi[n:]
+	head := 0
+	stack[0] = j
+	for head >= 0 {
+		j = stack[head]
+		jnew := pinv[j]
+		if !mark[j] {
+			mark[j] = true
+			if jnew < 0 {
+				pstack[head] = 0
+			} else {
+				pstack[head] = lp[jnew] + 1
+			}
+		}
+		done := true
+		p2 := 0
+		if jnew >= 0 {
+			p2 = lp[jnew+1]
+		}
+		for p := pstack[head]; p < p2; p++ {
+			i := li[p]
+			if mark[i] {
+				continue
+			}
+			pstack[head] = p
+			head++
+			stack[head] = i
+			done = false
+			break
+		}
+		if done {
+			head--
+			top--
+			xi[top] = j
+		}
+	}
+	return top
+}
+
+// spSymPermUpper returns the upper triangle of P⋅A⋅Pᵀ in column-compressed form, computed from the
+// lower triangle of A. pinv is the inverse permutation (old-to-new)
+func spSymPermUpper(a *CCMatrix, pinv []int) (cp, ci []int, cx []float64) {
+	n := a.n
+	count := make([]int, n)
+	for j := 0; j < n; j++ {
+		for p := a.p[j]; p < a.p[j+1]; p++ {
+			if a.i[p] >= j {
+				r, c := pinv[a.i[p]], pinv[j]
+				if r > c {
+					c = r
+				}
+				count[c]++
+			}
+		}
+	}
+	cp = make([]int, n+1)
+	for j := 0; j < n; j++ {
+		cp[j+1] = cp[j] + count[j]
+		count[j] = cp[j]
+	}
+	ci = make([]int, cp[n])
+	cx = make([]float64, cp[n])
+	for j := 0; j < n; j++ {
+		for p := a.p[j]; p < a.p[j+1]; p++ {
+			if i := a.i[p]; i >= j {
+				r, c := pinv[i], pinv[j]
+				if r > c {
+					r, c = c, r
+				}
+				q := count[c]
+				count[c]++
+				ci[q] = r
+				cx[q] = a.x[p]
+			}
+		}
+	}
+	return
+}
+
+// spEtree computes the elimination tree of a symmetric matrix given by its upper triangle.
+// ancestor is used as workspace
+func spEtree(n int, cp, ci, ancestor []int) (parent []int) {
+	parent = make([]int, n)
+	for k := 0; k < n; k++ {
+		parent[k] = -1
+		ancestor[k] = -1
+		for p := cp[k]; p < cp[k+1]; p++ {
+			for i := ci[p]; i != -1 && i < k; {
+				inext := ancestor[i]
+				ancestor[i] = k
+				if inext == -1 {
+					parent[i] = k
+				}
+				i = inext
+			}
+		}
+	}
+	return
+}
+
+// spEreach computes the nonzero pattern of row k of L (excluding the diagonal) by traversing the
+// elimination tree. The pattern is returned in s[top:n]; w holds the marks and must be zero on input
+func spEreach(k int, cp, ci, parent, s, w []int) (top int) {
+	n := len(parent)
+	top = n
+	w[k] = -1
+	for p := cp[k]; p < cp[k+1]; p++ {
+		i := ci[p]
+		if i > k {
+			continue
+		}
+		l := 0
+		for ; w[i] >= 0; i = parent[i] {
+			s[l] = i
+			l++
+			w[i] = -1
+		}
+		for l > 0 {
+			top--
+			l--
+			s[top] = s[l]
+		}
+	}
+	for p := top; p < n; p++ {
+		w[s[p]] = 0
+	}
+	w[k] = 0
+	return
+}
+
+// register solvers
+func init() {
+	spSolverDB["splu"] = func() SparseSolver { return new(SpLu) }
+	spSolverDB["spchol"] = func() SparseSolver { return new(SpChol) }
+	spSolverDBc["splu"] = func() SparseSolverC { return new(SpLuC) }
+}
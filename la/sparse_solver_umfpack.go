@@ -2,6 +2,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build umfpack
+
 package la
 
 /*
@@ -341,3 +343,40 @@ func init() {
 	spSolverDB["umfpack"] = func() SparseSolver { return new(Umfpack) }
 	spSolverDBc["umfpack"] = func() SparseSolverC { return new(UmfpackC) }
 }
+
+// umfErr returns UMFPACK error codes
+func umfErr(code C.LONG) string {
+	switch code {
+	case C.UMFPACK_ERROR_out_of_memory:
+		return "out_of_memory (-1)"
+	case C.UMFPACK_ERROR_invalid_Numeric_object:
+		return "invalid_Numeric_object (-3)"
+	case C.UMFPACK_ERROR_invalid_Symbolic_object:
+		return "invalid_Symbolic_object (-4)"
+	case C.UMFPACK_ERROR_argument_missing:
+		return "argument_missing (-5)"
+	case C.UMFPACK_ERROR_n_nonpositive:
+		return "n_nonpositive (-6)"
+	case C.UMFPACK_ERROR_invalid_matrix:
+		return "invalid_matrix (-8)"
+	case C.UMFPACK_ERROR_different_pattern:
+		return "different_pattern (-11)"
+	case C.UMFPACK_ERROR_invalid_system:
+		return "invalid_system (-13)"
+	case C.UMFPACK_ERROR_invalid_permutation:
+		return "invalid_permutation (-15)"
+	case C.UMFPACK_ERROR_internal_error:
+		return "internal_error (-911)"
+	case C.UMFPACK_ERROR_file_IO:
+		return "file_IO (-17)"
+	case -18:
+		return "ordering_failed (-18)"
+	case C.UMFPACK_WARNING_singular_matrix:
+		return "singular_matrix (1)"
+	case C.UMFPACK_WARNING_determinant_underflow:
+		return "determinant_underflow (2)"
+	case C.UMFPACK_WARNING_determinant_overflow:
+		return "determinant_overflow (3)"
+	}
+	return "unknown UMFPACK error"
+}
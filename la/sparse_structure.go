@@ -0,0 +1,248 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"bytes"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// SpInfo holds structural information about a sparse matrix; i.e. information that depends on
+// the pattern of non-zeros only
+type SpInfo struct {
+	M, N       int   // dimensions
+	Nnz        int   // number of stored entries (including repetitions and explicit zeros)
+	Kl, Ku     int   // lower and upper bandwidths
+	Profile    int   // profile (envelope size) of the pattern of A + Aᵀ [square only]
+	StructRank int   // structural rank; i.e. size of maximum matching of rows and columns
+	Ncomps     int   // number of connected components of the graph of A + Aᵀ [square only]
+	EmptyRows  []int // rows without entries
+	EmptyCols  []int // columns without entries
+	Unmatched  []int // rows not matched by the maximum matching (structurally dependent rows)
+}
+
+// SpGetInfo computes structural information about a sparse matrix
+func SpGetInfo(a *CCMatrix) (o *SpInfo) {
+	o = new(SpInfo)
+	o.M, o.N = a.m, a.n
+	o.Nnz = a.p[a.n]
+	o.Kl, o.Ku = SpBandwidth(a)
+	rowCount := make([]int, a.m)
+	for k := 0; k < o.Nnz; k++ {
+		rowCount[a.i[k]]++
+	}
+	for i := 0; i < a.m; i++ {
+		if rowCount[i] == 0 {
+			o.EmptyRows = append(o.EmptyRows, i)
+		}
+	}
+	for j := 0; j < a.n; j++ {
+		if a.p[j] == a.p[j+1] {
+			o.EmptyCols = append(o.EmptyCols, j)
+		}
+	}
+	var match []int
+	o.StructRank, match = SpStructRank(a)
+	for i := 0; i < a.m; i++ {
+		if match[i] < 0 {
+			o.Unmatched = append(o.Unmatched, i)
+		}
+	}
+	if a.m == a.n {
+		o.Profile = SpProfile(a)
+		o.Ncomps, _ = SpComponents(a)
+	}
+	return
+}
+
+// StructSingular returns whether the matrix is structurally rank deficient; i.e. singular (or
+// rank deficient) for any values of its non-zero entries
+func (o *SpInfo) StructSingular() bool {
+	return o.StructRank < utl.Imin(o.M, o.N)
+}
+
+// String returns a report with the structural information
+func (o *SpInfo) String() string {
+	var b bytes.Buffer
+	io.Ff(&b, "dimensions           = %d x %d\n", o.M, o.N)
+	io.Ff(&b, "number of entries    = %d\n", o.Nnz)
+	io.Ff(&b, "bandwidths (kl, ku)  = %d, %d\n", o.Kl, o.Ku)
+	if o.M == o.N {
+		io.Ff(&b, "profile              = %d\n", o.Profile)
+		io.Ff(&b, "connected components = %d\n", o.Ncomps)
+	}
+	io.Ff(&b, "structural rank      = %d\n", o.StructRank)
+	if len(o.EmptyRows) > 0 {
+		io.Ff(&b, "empty rows           = %v\n", o.EmptyRows)
+	}
+	if len(o.EmptyCols) > 0 {
+		io.Ff(&b, "empty columns        = %v\n", o.EmptyCols)
+	}
+	if o.StructSingular() {
+		io.Ff(&b, "structurally singular: unmatched rows = %v\n", o.Unmatched)
+	}
+	return b.String()
+}
+
+// SpBandwidth returns the lower and upper bandwidths of a sparse matrix
+//
+//   kl = max(i - j) and ku = max(j - i) for all stored entries (i,j)
+//
+func SpBandwidth(a *CCMatrix) (kl, ku int) {
+	for j := 0; j < a.n; j++ {
+		for p := a.p[j]; p < a.p[j+1]; p++ {
+			kl = utl.Imax(kl, a.i[p]-j)
+			ku = utl.Imax(ku, j-a.i[p])
+		}
+	}
+	return
+}
+
+// SpProfile returns the profile (envelope size) of the pattern of A + Aᵀ
+//
+//   profile = Σᵢ (i - fᵢ)   where   fᵢ = min { j ≤ i : aᵢⱼ ≠ 0 or aⱼᵢ ≠ 0 }
+//
+//   NOTE: the profile is the number of entries that are stored by skyline (envelope) solvers
+//         besides the diagonal
+//
+func SpProfile(a *CCMatrix) (profile int) {
+	if a.m != a.n {
+		chk.Panic("matrix must be square to compute the profile. %d != %d\n", a.m, a.n)
+	}
+	first := utl.IntRange(a.n)
+	for j := 0; j < a.n; j++ {
+		for p := a.p[j]; p < a.p[j+1]; p++ {
+			i := a.i[p]
+			if j < i {
+				first[i] = utl.Imin(first[i], j)
+			} else {
+				first[j] = utl.Imin(first[j], i)
+			}
+		}
+	}
+	for i := 0; i < a.n; i++ {
+		profile += i - first[i]
+	}
+	return
+}
+
+// SpStructRank computes the structural rank of a sparse matrix by finding a maximum matching of
+// rows and columns (augmenting paths found by depth-first search)
+//
+//   rank  -- structural rank; an upper bound of the numerical rank
+//   match -- match[i] is the column matched to row i or -1 if row i is not matched
+//
+//   Reference:
+//     [1] Duff IS (1981) On algorithms for obtaining a maximum transversal. ACM Transactions on
+//         Mathematical Software, 7(3):315-330
+//
+func SpStructRank(a *CCMatrix) (rank int, match []int) {
+
+	// cheap assignment
+	match = utl.IntVals(a.m, -1)
+	jmatch := utl.IntVals(a.n, -1)
+	for j := 0; j < a.n; j++ {
+		for p := a.p[j]; p < a.p[j+1]; p++ {
+			if i := a.i[p]; match[i] < 0 {
+				match[i], jmatch[j] = j, i
+				rank++
+				break
+			}
+		}
+	}
+
+	// augmenting paths
+	stamp := utl.IntVals(a.m, -1)
+	var cols, ptrs, rows []int
+	for j0 := 0; j0 < a.n; j0++ {
+		if jmatch[j0] >= 0 {
+			continue
+		}
+		cols, ptrs, rows = append(cols[:0], j0), append(ptrs[:0], a.p[j0]), rows[:0]
+		found := false
+		for len(cols) > 0 && !found {
+			top := len(cols) - 1
+			j := cols[top]
+			advanced := false
+			for ptrs[top] < a.p[j+1] {
+				i := a.i[ptrs[top]]
+				ptrs[top]++
+				if stamp[i] == j0 {
+					continue
+				}
+				stamp[i] = j0
+				rows = append(rows, i)
+				if match[i] < 0 {
+					found = true
+				} else {
+					cols = append(cols, match[i])
+					ptrs = append(ptrs, a.p[match[i]])
+					advanced = true
+				}
+				break
+			}
+			if !found && !advanced { // backtrack
+				cols, ptrs = cols[:top], ptrs[:top]
+				if top > 0 {
+					rows = rows[:top-1]
+				}
+			}
+		}
+		if found { // flip the matching along the path: rows[k] ↔ cols[k]
+			for k, j := range cols {
+				match[rows[k]], jmatch[j] = j, rows[k]
+			}
+			rank++
+		}
+	}
+	return
+}
+
+// SpComponents computes the connected components of the graph of A + Aᵀ
+//
+//   ncomp -- number of connected components
+//   comp  -- comp[i] is the component (0 ≤ comp[i] < ncomp) of row/column i; components are
+//            numbered in the order of their smallest node
+//
+func SpComponents(a *CCMatrix) (ncomp int, comp []int) {
+	if a.m != a.n {
+		chk.Panic("matrix must be square to compute the connected components of its graph. %d != %d\n", a.m, a.n)
+	}
+
+	// union-find with path halving
+	parent := utl.IntRange(a.n)
+	find := func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	for j := 0; j < a.n; j++ {
+		for p := a.p[j]; p < a.p[j+1]; p++ {
+			ri, rj := find(a.i[p]), find(j)
+			if ri < rj {
+				parent[rj] = ri
+			} else if rj < ri {
+				parent[ri] = rj
+			}
+		}
+	}
+
+	// label components
+	comp = make([]int, a.n)
+	for i := 0; i < a.n; i++ {
+		if r := find(i); r == i {
+			comp[i] = ncomp
+			ncomp++
+		} else {
+			comp[i] = comp[r]
+		}
+	}
+	return
+}
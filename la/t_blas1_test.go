@@ -70,5 +70,7 @@ func TestBlas1tst02(tst *testing.T) {
 		VecAdd(w[:n], 1, u[:n], 1, v[:n])
 		chk.Array(tst, io.Sf("n=%3d: w:=u-v", n), 1e-15, w[:n], wref[:n])
 		chk.Float64(tst, "u⋅v", 1e-15, VecDot(u, v), dot)
+		VecAdd(u[:n], 1, u[:n], 1, v[:n]) // in-place
+		chk.Array(tst, io.Sf("n=%3d: u:=u-v", n), 1e-15, u[:n], wref[:n])
 	}
 }
@@ -6,6 +6,7 @@ package la
 
 import (
 	"math"
+	"math/cmplx"
 	"testing"
 
 	"github.com/dicksontsai/gosl/chk"
@@ -195,3 +196,144 @@ func TestEigen05(tst *testing.T) {
 	EigenVecR(v3, w3, A, true)
 	chk.Deep2c(tst, "v3", 1e-15, v3.GetDeep2(), vRef)
 }
+
+func TestEigen06(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Eigen06. symmetric matrix")
+
+	A := NewMatrixDeep2([][]float64{
+		{+2, -1, +0},
+		{-1, +2, -1},
+		{+0, -1, +2},
+	})
+
+	w := NewVector(A.M)
+	EigenValSym(w, A, true)
+	io.Pforan("w = %v\n", w)
+	s2 := math.Sqrt2
+	chk.Array(tst, "w", 1e-14, w, []float64{2 - s2, 2, 2 + s2})
+
+	v := NewMatrix(A.M, A.M)
+	EigenVecSym(v, w, A)
+	chk.Array(tst, "w", 1e-14, w, []float64{2 - s2, 2, 2 + s2})
+	CheckEigenVecSym(tst, A, nil, w, v, 1e-14)
+
+	// orthonormal eigenvectors
+	VtV := NewMatrix(A.M, A.M)
+	MatTrMatMul(VtV, 1, v, v)
+	chk.Deep2(tst, "vᵀ⋅v", 1e-15, VtV.GetDeep2(), [][]float64{
+		{1, 0, 0},
+		{0, 1, 0},
+		{0, 0, 1},
+	})
+
+	// A is not modified
+	chk.Deep2(tst, "A", 1e-17, A.GetDeep2(), [][]float64{
+		{+2, -1, +0},
+		{-1, +2, -1},
+		{+0, -1, +2},
+	})
+}
+
+func TestEigen07(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Eigen07. symmetric-definite generalized problem")
+
+	// vibration problem: K ⋅ v = ω² ⋅ M ⋅ v
+	K := NewMatrixDeep2([][]float64{
+		{+6, -2},
+		{-2, +4},
+	})
+	M := NewMatrixDeep2([][]float64{
+		{2, 0},
+		{0, 1},
+	})
+
+	w := NewVector(2)
+	EigenValSymGen(w, K, M, true)
+	io.Pforan("ω² = %v\n", w)
+	chk.Array(tst, "ω²", 1e-14, w, []float64{2, 5})
+
+	v := NewMatrix(2, 2)
+	EigenVecSymGen(v, w, K, M)
+	chk.Array(tst, "ω²", 1e-14, w, []float64{2, 5})
+	CheckEigenVecSym(tst, K, M, w, v, 1e-14)
+
+	// M-orthonormal eigenvectors
+	Mv := NewMatrix(2, 2)
+	VtMv := NewMatrix(2, 2)
+	MatMatMul(Mv, 1, M, v)
+	MatTrMatMul(VtMv, 1, v, Mv)
+	chk.Deep2(tst, "vᵀ⋅M⋅v", 1e-15, VtMv.GetDeep2(), [][]float64{
+		{1, 0},
+		{0, 1},
+	})
+}
+
+func TestEigen08(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Eigen08. general generalized problem")
+
+	// singular B ⇒ one infinite eigenvalue
+	A := NewMatrixDeep2([][]float64{
+		{1, 2},
+		{3, 4},
+	})
+	B := NewMatrixDeep2([][]float64{
+		{1, 0},
+		{0, 0},
+	})
+	α := NewVectorC(2)
+	β := NewVector(2)
+	v := NewMatrixC(2, 2)
+	EigenVecGen(v, α, β, A, B, true)
+	io.Pforan("α = %v\n", α)
+	io.Pforan("β = %v\n", β)
+	CheckEigenVecGen(tst, A, B, α, β, v, 1e-14)
+	nInf := 0
+	for j := 0; j < 2; j++ {
+		if math.Abs(β[j]) < 1e-14 {
+			nInf++
+		} else {
+			chk.AnaNumC(tst, "λ", 1e-14, -0.5, α[j]/complex(β[j], 0), chk.Verbose)
+		}
+	}
+	chk.Int(tst, "number of infinite eigenvalues", nInf, 1)
+
+	// complex eigenvalues ±i and 1.5
+	A = NewMatrixDeep2([][]float64{
+		{+0, 1, 0},
+		{-1, 0, 0},
+		{+0, 0, 3},
+	})
+	B = NewMatrixDeep2([][]float64{
+		{1, 0, 0},
+		{0, 1, 0},
+		{0, 0, 2},
+	})
+	α = NewVectorC(3)
+	β = NewVector(3)
+	EigenValGen(α, β, A, B, true)
+	λ := NewVectorC(3)
+	for j := 0; j < 3; j++ {
+		λ[j] = α[j] / complex(β[j], 0)
+	}
+	io.Pforan("λ = %v\n", λ)
+	for _, λref := range []complex128{1i, -1i, 1.5} {
+		found := false
+		for j := 0; j < 3; j++ {
+			if cmplx.Abs(λ[j]-λref) < 1e-14 {
+				found = true
+			}
+		}
+		if !found {
+			tst.Errorf("eigenvalue %v was not found\n", λref)
+		}
+	}
+	v = NewMatrixC(3, 3)
+	EigenVecGen(v, α, β, A, B, true)
+	CheckEigenVecGen(tst, A, B, α, β, v, 1e-14)
+}
@@ -0,0 +1,266 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+)
+
+func TestBand01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Band01. general banded matrix")
+
+	// matrix with kl=1 and ku=2 requiring pivoting (zero on the diagonal)
+	a := NewBandMatrix(5, 1, 2)
+	Ad := NewMatrixDeep2([][]float64{
+		{0, 2, 3, 0, 0},
+		{4, 1, 5, 6, 0},
+		{0, 7, 0, 8, 9},
+		{0, 0, 1, 2, 3},
+		{0, 0, 0, 4, -1},
+	})
+	for i := 0; i < 5; i++ {
+		for j := 0; j < 5; j++ {
+			if a.InBand(i, j) {
+				a.Set(i, j, Ad.Get(i, j))
+			}
+		}
+	}
+	chk.Deep2(tst, "A", 1e-17, a.ToDense().GetDeep2(), Ad.GetDeep2())
+	chk.Array(tst, "Data", 1e-17, a.Data, []float64{
+		0, 0, 0, 4,
+		0, 2, 1, 7,
+		3, 5, 0, 1,
+		6, 8, 2, 4,
+		9, 3, -1, 0,
+	})
+	chk.Float64(tst, "A(4,0) (outside)", 1e-17, a.Get(4, 0), 0)
+
+	// matrix-vector multiplication
+	u := Vector{1, -2, 3, 0.5, 2}
+	v := NewVector(5)
+	vCorrect := NewVector(5)
+	BandMatVecMul(v, 2, a, u)
+	MatVecMul(vCorrect, 2, Ad, u)
+	chk.Array(tst, "v = 2⋅A⋅u", 1e-15, v, vCorrect)
+
+	// solve
+	xCorrect := Vector{1, 2, 3, 4, 5}
+	b := NewVector(5)
+	MatVecMul(b, 1, Ad, xCorrect)
+	x := NewVector(5)
+	a.Factorize()
+	a.Solve(x, b)
+	io.Pforan("ipiv = %v\n", a.ipiv)
+	chk.Array(tst, "x", 1e-14, x, xCorrect)
+	chk.Deep2(tst, "A (not modified)", 1e-17, a.ToDense().GetDeep2(), Ad.GetDeep2())
+
+	// solve again with the same factorisation (x and b are the same vector)
+	a.Solve(b, b)
+	chk.Array(tst, "x (again)", 1e-14, b, xCorrect)
+
+	// setting values discards the factorisation
+	a.Add(0, 0, 1)
+	defer chk.RecoverTstPanicIsOK(tst)
+	a.Solve(x, b)
+}
+
+func TestBand02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Band02. symmetric banded matrix and conversion from triplet")
+
+	// 1D Laplacian with (non-symmetric) Neumann row
+	n := 8
+	t := krylovTestLaplacian(n)
+	t.Put(0, 1, -1) // A[0][1] = -2
+	a := NewBandMatrixTriplet(t, false)
+	chk.Int(tst, "Kl", a.Kl, 1)
+	chk.Int(tst, "Ku", a.Ku, 1)
+	chk.Float64(tst, "A01", 1e-17, a.Get(0, 1), -2)
+	chk.Deep2(tst, "A", 1e-17, a.ToDense().GetDeep2(), t.ToDense().GetDeep2())
+
+	// symmetric positive-definite pentadiagonal matrix
+	t = new(Triplet)
+	t.Init(n, n, 5*n)
+	for i := 0; i < n; i++ {
+		t.Put(i, i, 6)
+		if i+1 < n {
+			t.Put(i, i+1, -4)
+			t.Put(i+1, i, -4)
+		}
+		if i+2 < n {
+			t.Put(i, i+2, 1)
+			t.Put(i+2, i, 1)
+		}
+	}
+	s := NewBandMatrixTriplet(t, true)
+	chk.Int(tst, "K", s.Ku, 2)
+	chk.Int(tst, "Ldab", s.Ldab, 3)
+	Sd := t.ToDense()
+	chk.Deep2(tst, "S", 1e-17, s.ToDense().GetDeep2(), Sd.GetDeep2())
+	chk.Float64(tst, "S(2,0)", 1e-17, s.Get(2, 0), 1)
+
+	// matrix-vector multiplication
+	u := NewVectorMapped(n, func(i int) float64 { return math.Cos(float64(i)) })
+	v := NewVector(n)
+	vCorrect := NewVector(n)
+	BandMatVecMul(v, 1, s, u)
+	MatVecMul(vCorrect, 1, Sd, u)
+	chk.Array(tst, "v = S⋅u", 1e-15, v, vCorrect)
+
+	// Cholesky solve
+	xCorrect := NewVectorMapped(n, func(i int) float64 { return float64(i*i) - 3 })
+	b := NewVector(n)
+	MatVecMul(b, 1, Sd, xCorrect)
+	x := NewVector(n)
+	s.Factorize()
+	s.Solve(x, b)
+	chk.Array(tst, "x", 1e-11, x, xCorrect)
+
+	// compare with LU of general band matrix
+	g := NewBandMatrixTriplet(t, false)
+	g.Factorize()
+	g.Solve(x, b)
+	chk.Array(tst, "x (LU)", 1e-11, x, xCorrect)
+
+	// not positive-definite
+	s.Set(3, 3, -1)
+	defer chk.RecoverTstPanicIsOK(tst)
+	s.Factorize()
+}
+
+func TestTridiag01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Tridiag01. Thomas algorithm")
+
+	// -u'' = 1 with u(0) = u(1) = 0 ⇒ u = x⋅(1-x)/2 (exact for finite differences)
+	n := 9
+	h := 1.0 / float64(n+1)
+	a := NewVector(n)
+	b := NewVector(n)
+	c := NewVector(n)
+	d := NewVector(n)
+	a.Fill(-1)
+	b.Fill(2)
+	c.Fill(-1)
+	d.Fill(h * h)
+	x := NewVector(n)
+	SolveTridiag(x, a, b, c, d)
+	xCorrect := NewVectorMapped(n, func(i int) float64 {
+		xi := float64(i+1) * h
+		return xi * (1 - xi) / 2
+	})
+	chk.Array(tst, "u", 1e-15, x, xCorrect)
+
+	// non-symmetric system; x and d are the same vector
+	a = Vector{0, 1, -2, 0.5}
+	b = Vector{4, 5, 6, 3}
+	c = Vector{1, 2, 1, 0}
+	xCorrect = Vector{1, -1, 2, 0.5}
+	d = Vector{
+		b[0]*xCorrect[0] + c[0]*xCorrect[1],
+		a[1]*xCorrect[0] + b[1]*xCorrect[1] + c[1]*xCorrect[2],
+		a[2]*xCorrect[1] + b[2]*xCorrect[2] + c[2]*xCorrect[3],
+		a[3]*xCorrect[2] + b[3]*xCorrect[3],
+	}
+	SolveTridiag(d, a, b, c, d)
+	chk.Array(tst, "x", 1e-15, d, xCorrect)
+
+	// empty system
+	SolveTridiag(x[:0], a[:0], b[:0], c[:0], d[:0])
+
+	// zero pivot
+	defer chk.RecoverTstPanicIsOK(tst)
+	SolveTridiag(x[:2], Vector{0, 1}, Vector{1, 1}, Vector{1, 0}, Vector{1, 1})
+}
+
+func TestTridiag02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Tridiag02. block Thomas algorithm")
+
+	// 2D Laplacian on (nx x ny) grid: blocks are (nx x nx)
+	nx, ny := 4, 5
+	N := nx * ny
+	t := new(Triplet)
+	t.Init(N, N, 5*N)
+	A := make([]*Matrix, ny)
+	B := make([]*Matrix, ny)
+	C := make([]*Matrix, ny)
+	for k := 0; k < ny; k++ {
+		B[k] = NewMatrix(nx, nx)
+		if k > 0 {
+			A[k] = NewMatrix(nx, nx)
+		}
+		if k < ny-1 {
+			C[k] = NewMatrix(nx, nx)
+		}
+		for i := 0; i < nx; i++ {
+			I := i + k*nx
+			B[k].Set(i, i, 4)
+			t.Put(I, I, 4)
+			if i > 0 {
+				B[k].Set(i, i-1, -1)
+				t.Put(I, I-1, -1)
+			}
+			if i < nx-1 {
+				B[k].Set(i, i+1, -2) // non-symmetric
+				t.Put(I, I+1, -2)
+			}
+			if k > 0 {
+				A[k].Set(i, i, -1)
+				t.Put(I, I-nx, -1)
+			}
+			if k < ny-1 {
+				C[k].Set(i, i, -0.5)
+				t.Put(I, I+nx, -0.5)
+			}
+		}
+	}
+
+	// reference solution
+	Ad := t.ToDense()
+	xCorrect := NewVectorMapped(N, func(i int) float64 { return math.Sin(float64(i)) })
+	b := NewVector(N)
+	MatVecMul(b, 1, Ad, xCorrect)
+
+	// solve with blocks
+	x := make([]Vector, ny)
+	d := make([]Vector, ny)
+	for k := 0; k < ny; k++ {
+		x[k] = NewVector(nx)
+		d[k] = b[k*nx : (k+1)*nx]
+	}
+	SolveBlockTridiag(x, A, B, C, d)
+	for k := 0; k < ny; k++ {
+		chk.Array(tst, io.Sf("x%d", k), 1e-14, x[k], xCorrect[k*nx:(k+1)*nx])
+	}
+
+	// solve with banded matrix
+	band := NewBandMatrixTriplet(t, false)
+	chk.Int(tst, "Kl", band.Kl, nx)
+	chk.Int(tst, "Ku", band.Ku, nx)
+	band.Factorize()
+	y := NewVector(N)
+	band.Solve(y, b)
+	chk.Array(tst, "x (band)", 1e-14, y, xCorrect)
+
+	// blocks of different sizes: (1 x 1), (2 x 2) and (1 x 1)
+	A = []*Matrix{nil, NewMatrixDeep2([][]float64{{1}, {2}}), NewMatrixDeep2([][]float64{{1, -1}})}
+	B = []*Matrix{NewMatrixDeep2([][]float64{{5}}), NewMatrixDeep2([][]float64{{4, 1}, {1, 3}}), NewMatrixDeep2([][]float64{{6}})}
+	C = []*Matrix{NewMatrixDeep2([][]float64{{1, 0}}), NewMatrixDeep2([][]float64{{0}, {1}}), nil}
+	d = []Vector{{5 + 1}, {1 + 4 - 1, 2 + 1 - 3 + 2}, {2 + 12}}
+	SolveBlockTridiag(d, A, B, C, d)
+	chk.Array(tst, "x0", 1e-15, d[0], []float64{1})
+	chk.Array(tst, "x1", 1e-15, d[1], []float64{1, -1})
+	chk.Array(tst, "x2", 1e-15, d[2], []float64{2})
+}
@@ -0,0 +1,248 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+)
+
+func TestMatFcn01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("MatFcn01. matrix exponential")
+
+	// rotation: exp(t⋅[0 1; -1 0]) = [cos(t) sin(t); -sin(t) cos(t)]
+	E := NewMatrix(2, 2)
+	for _, t := range []float64{1e-3, 0.1, 1, 2, 10, 100} {
+		A := NewMatrixDeep2([][]float64{
+			{+0, t},
+			{-t, 0},
+		})
+		MatExp(E, A)
+		c, s := math.Cos(t), math.Sin(t)
+		chk.Deep2(tst, io.Sf("exp(rot(%g))", t), 1e-13*math.Max(1, t), E.GetDeep2(), [][]float64{
+			{+c, s},
+			{-s, c},
+		})
+	}
+
+	// nilpotent matrix
+	A := NewMatrixDeep2([][]float64{
+		{0, 1, 2},
+		{0, 0, 3},
+		{0, 0, 0},
+	})
+	E = NewMatrix(3, 3)
+	MatExp(E, A)
+	chk.Deep2(tst, "exp(nilpotent)", 1e-15, E.GetDeep2(), [][]float64{
+		{1, 1, 2 + 1.5},
+		{0, 1, 3},
+		{0, 0, 1},
+	})
+
+	// stiff matrix: A = V ⋅ diag(-1,-1000) ⋅ V⁻¹ with V = [1 1; 0 1]
+	A = NewMatrixDeep2([][]float64{
+		{-1, -999},
+		{+0, -1000},
+	})
+	E = NewMatrix(2, 2)
+	MatExp(E, A)
+	e1, e2 := math.Exp(-1), math.Exp(-1000)
+	chk.Deep2(tst, "exp(stiff)", 1e-13, E.GetDeep2(), [][]float64{
+		{e1, e2 - e1},
+		{0, e2},
+	})
+}
+
+func TestMatFcn02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("MatFcn02. matrix square root and logarithm")
+
+	// square root of upper triangular matrix
+	A := NewMatrixDeep2([][]float64{
+		{1, 4},
+		{0, 9},
+	})
+	S := NewMatrix(2, 2)
+	MatSqrt(S, A)
+	chk.Deep2(tst, "sqrt(A)", 1e-14, S.GetDeep2(), [][]float64{
+		{1, 1},
+		{0, 3},
+	})
+
+	// square root of SPD matrix
+	A = NewMatrixDeep2([][]float64{
+		{+4, +1, 0},
+		{+1, 30, 2},
+		{+0, +2, 1.0},
+	})
+	S = NewMatrix(3, 3)
+	MatSqrt(S, A)
+	SS := NewMatrix(3, 3)
+	MatMatMul(SS, 1, S, S)
+	chk.Deep2(tst, "sqrt(A)²", 1e-13, SS.GetDeep2(), A.GetDeep2())
+
+	// logarithm: log(exp(B)) = B
+	B := NewMatrixDeep2([][]float64{
+		{+1.0, 2.0, 0.0},
+		{-0.5, 0.3, 1.0},
+		{+0.0, 0.2, 2.5},
+	})
+	E := NewMatrix(3, 3)
+	L := NewMatrix(3, 3)
+	MatExp(E, B)
+	MatLog(L, E)
+	io.Pforan("log(exp(B)) =\n%v\n", L.Print("%12.8f"))
+	chk.Deep2(tst, "log(exp(B))", 1e-12, L.GetDeep2(), B.GetDeep2())
+
+	// logarithm of identity and of diagonal matrix
+	A = NewMatrixDeep2([][]float64{
+		{1, 0},
+		{0, 1e3},
+	})
+	L = NewMatrix(2, 2)
+	MatLog(L, A)
+	chk.Deep2(tst, "log(diag)", 1e-13, L.GetDeep2(), [][]float64{
+		{0, 0},
+		{0, math.Log(1e3)},
+	})
+}
+
+func TestMatFcn03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("MatFcn03. φ-functions")
+
+	// diagonal matrix with z = 0, 1e-3, -2 and 5
+	zz := []float64{0, 1e-3, -2, 5}
+	n := len(zz)
+	A := NewMatrix(n, n)
+	for i, z := range zz {
+		A.Set(i, i, z)
+	}
+	phi := []*Matrix{NewMatrix(n, n), NewMatrix(n, n), NewMatrix(n, n), NewMatrix(n, n)}
+	MatPhi(phi, A)
+
+	// reference: φₖ(z) = Σᵢ zⁱ / (i+k)!
+	φ := func(k int, z float64) (res float64) {
+		term := 1.0
+		for i := 1; i <= k; i++ {
+			term /= float64(i)
+		}
+		for i := 0; i < 100; i++ {
+			res += term
+			term *= z / float64(i+k+1)
+		}
+		return
+	}
+	for k := 0; k < len(phi); k++ {
+		for i, z := range zz {
+			chk.AnaNum(tst, io.Sf("φ%d(%g)", k, z), 1e-13*math.Max(1, math.Exp(z)), φ(k, z), phi[k].Get(i, i), chk.Verbose)
+		}
+	}
+
+	// recurrence: A ⋅ φₖ₊₁(A) = φₖ(A) - I/k! for a full matrix
+	A = NewMatrixDeep2([][]float64{
+		{-1.0, 2.0, 0.5},
+		{+0.3, -4.0, 1.0},
+		{+0.0, 0.7, -0.2},
+	})
+	n = 3
+	phi = []*Matrix{NewMatrix(n, n), NewMatrix(n, n), NewMatrix(n, n)}
+	MatPhi(phi, A)
+	AP := NewMatrix(n, n)
+	fact := 1.0
+	for k := 0; k < 2; k++ {
+		if k > 0 {
+			fact *= float64(k)
+		}
+		MatMatMul(AP, 1, A, phi[k+1])
+		R := phi[k].GetCopy()
+		for i := 0; i < n; i++ {
+			R.Add(i, i, -1/fact)
+		}
+		chk.Deep2(tst, io.Sf("A⋅φ%d", k+1), 1e-14, AP.GetDeep2(), R.GetDeep2())
+	}
+	E := NewMatrix(n, n)
+	MatExp(E, A)
+	chk.Deep2(tst, "φ0", 1e-15, phi[0].GetDeep2(), E.GetDeep2())
+}
+
+func TestMatFcn04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("MatFcn04. action of the exponential of sparse matrix")
+
+	// 1D Laplacian (heat equation): y = exp(-t⋅A) ⋅ x
+	n := 50
+	A := krylovTestLaplacian(n).ToMatrix(nil)
+	Ad := A.ToDense()
+	x := NewVectorMapped(n, func(i int) float64 { return math.Sin(float64(i)) + 1 })
+	y := NewVector(n)
+	yCorrect := NewVector(n)
+	E := NewMatrix(n, n)
+	for _, t := range []float64{0, 1e-4, 0.1, 1, 10, 100} {
+		Ad.Apply(-t, A.ToDense())
+		MatExp(E, Ad)
+		MatVecMul(yCorrect, 1, E, x)
+		SpMatExpVec(y, -t, A, x)
+		chk.Array(tst, io.Sf("exp(-%g⋅A)⋅x", t), 1e-12, y, yCorrect)
+	}
+
+	// non-symmetric matrix with positive shift and aliasing of x and y
+	t := new(Triplet)
+	t.Init(3, 3, 6)
+	t.Put(0, 0, 3)
+	t.Put(0, 1, 2)
+	t.Put(1, 1, 2)
+	t.Put(2, 0, -1)
+	t.Put(2, 2, 1)
+	t.Put(1, 2, 0.5)
+	B := t.ToMatrix(nil)
+	x = Vector{1, -2, 0.5}
+	E = NewMatrix(3, 3)
+	MatExp(E, B.ToDense())
+	yCorrect = NewVector(3)
+	MatVecMul(yCorrect, 1, E, x)
+	SpMatExpVec(x, 1, B, x)
+	chk.Array(tst, "exp(B)⋅x", 1e-13, x, yCorrect)
+	chk.Float64(tst, "‖x‖∞", 1e-15, Vector{1, -3, 2}.NormInf(), 3)
+}
+
+func TestMatFcn05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("MatFcn05. square root of ill-conditioned (Hilbert) matrix")
+
+	// 8x8 Hilbert matrix: cond(A) ≈ 1.5e10
+	n := 8
+	A := NewMatrix(n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			A.Set(i, j, 1.0/float64(i+j+1))
+		}
+	}
+	S := NewMatrix(n, n)
+	MatSqrt(S, A)
+	SS := NewMatrix(n, n)
+	MatMatMul(SS, 1, S, S)
+	chk.Deep2(tst, "sqrt(A)²", 1e-10, SS.GetDeep2(), A.GetDeep2())
+}
+
+func TestMatFcn06(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("MatFcn06. φ-functions with wrong dimensions")
+
+	defer chk.RecoverTstPanicIsOK(tst)
+	A := NewMatrix(2, 2)
+	phi := []*Matrix{NewMatrix(2, 2), NewMatrix(3, 3)}
+	MatPhi(phi, A)
+}
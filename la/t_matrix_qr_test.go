@@ -0,0 +1,169 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+)
+
+// checkOrthogonal checks Qᵀ⋅Q = I
+func checkOrthogonal(tst *testing.T, msg string, tol float64, Q *Matrix) {
+	QtQ := NewMatrix(Q.N, Q.N)
+	MatTrMatMul(QtQ, 1, Q, Q)
+	I := NewMatrix(Q.N, Q.N)
+	I.SetDiag(1)
+	chk.Deep2(tst, msg, tol, QtQ.GetDeep2(), I.GetDeep2())
+}
+
+func TestQR01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("QR01. Householder QR and least-squares")
+
+	// data for fitting y = c₀ + c₁⋅x + c₂⋅x²
+	xx := []float64{0, 1, 2, 3, 4, 5}
+	A := NewMatrix(len(xx), 3)
+	b := NewVector(len(xx))
+	for i, x := range xx {
+		A.Set(i, 0, 1)
+		A.Set(i, 1, x)
+		A.Set(i, 2, x*x)
+		b[i] = 1 - 2*x + 0.5*x*x + 0.1*float64(i%2) // perturbed
+	}
+
+	// decomposition
+	qr := NewQR(A, false)
+	Q := qr.GetQ(true)
+	R := qr.GetR(true)
+	chk.Int(tst, "Q.M", Q.M, 6)
+	chk.Int(tst, "Q.N", Q.N, 3)
+	checkOrthogonal(tst, "QᵀQ (thin)", 1e-15, Q)
+	checkOrthogonal(tst, "QᵀQ (full)", 1e-15, qr.GetQ(false))
+	QR := NewMatrix(6, 3)
+	MatMatMul(QR, 1, Q, R)
+	chk.Deep2(tst, "Q⋅R", 1e-14, QR.GetDeep2(), A.GetDeep2())
+	for i := 1; i < 3; i++ {
+		for j := 0; j < i; j++ {
+			chk.Float64(tst, "R (lower)", 1e-17, R.Get(i, j), 0)
+		}
+	}
+
+	// apply Q and Qᵀ
+	y := NewVector(6)
+	z := NewVector(6)
+	qr.ApplyQt(y, b)
+	qr.ApplyQ(z, y)
+	chk.Array(tst, "Q⋅Qᵀ⋅b", 1e-14, z, b)
+
+	// least-squares solution versus normal equations
+	x := NewVector(3)
+	rank := qr.Solve(x, b)
+	chk.Int(tst, "rank", rank, 3)
+	AtA := NewMatrix(3, 3)
+	MatTrMatMul(AtA, 1, A, A)
+	Atb := NewVector(3)
+	MatTrVecMul(Atb, 1, A, b)
+	xne := NewVector(3)
+	DenSolve(xne, AtA, Atb, false)
+	io.Pforan("x = %v\n", x)
+	chk.Array(tst, "x", 1e-12, x, xne)
+}
+
+func TestQR02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("QR02. column-pivoted QR and rank deficient problems")
+
+	// third column = first + second ⇒ rank = 2
+	A := NewMatrixDeep2([][]float64{
+		{1, 0, 1},
+		{0, 1, 1},
+		{1, 1, 2},
+		{2, -1, 1},
+	})
+	qr := NewQR(A, true)
+	io.Pforan("perm = %v\n", qr.Perm)
+	chk.Int(tst, "rank", qr.Rank(0), 2)
+	chk.Int(tst, "MatRank", MatRank(A, 0), 2)
+
+	// A⋅P = Q⋅R
+	Q, R := qr.GetQ(false), qr.GetR(false)
+	QR := NewMatrix(4, 3)
+	MatMatMul(QR, 1, Q, R)
+	for j, p := range qr.Perm {
+		chk.Array(tst, io.Sf("A⋅P[:,%d]", j), 1e-14, QR.GetCol(j), A.GetCol(p))
+	}
+	for i := 1; i < 3; i++ {
+		if math.Abs(R.Get(i, i)) > math.Abs(R.Get(i-1, i-1)) {
+			tst.Errorf("diagonal of R must be non-increasing\n")
+		}
+	}
+
+	// the minimum-norm solution is orthogonal to the null space n = (1,1,-1)
+	xCorrect := []float64{1, 2, 3}
+	b := NewVector(4)
+	MatVecMul(b, 1, A, xCorrect)
+	x := NewVector(3)
+	rank := LeastSquares(x, A, b, 0)
+	chk.Int(tst, "rank", rank, 2)
+	chk.Array(tst, "x (min-norm)", 1e-14, x, xCorrect)
+
+	// basic solution has one zero component
+	qr.Solve(x, b)
+	io.Pforan("basic solution = %v\n", x)
+	chk.Float64(tst, "x[perm[2]]", 1e-17, x[qr.Perm[2]], 0)
+	Ax := NewVector(4)
+	MatVecMul(Ax, 1, A, x)
+	chk.Array(tst, "A⋅x (basic)", 1e-14, Ax, b)
+
+	// unpivoted QR cannot solve rank deficient problems
+	defer chk.RecoverTstPanicIsOK(tst)
+	NewQR(A, false).Solve(x, b)
+}
+
+func TestQR03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("QR03. LQ and minimum-norm solution")
+
+	// underdetermined system
+	A := NewMatrixDeep2([][]float64{
+		{1, 2, 0, -1},
+		{0, 1, 3, 2},
+	})
+	b := []float64{3, -1}
+
+	// decomposition
+	lq := NewLQ(A)
+	L, Q := lq.GetL(true), lq.GetQ(true)
+	chk.Int(tst, "L.M", L.M, 2)
+	chk.Int(tst, "L.N", L.N, 2)
+	chk.Float64(tst, "L01", 1e-17, L.Get(0, 1), 0)
+	LQ := NewMatrix(2, 4)
+	MatMatMul(LQ, 1, L, Q)
+	chk.Deep2(tst, "L⋅Q", 1e-14, LQ.GetDeep2(), A.GetDeep2())
+	checkOrthogonal(tst, "QQᵀ (full)", 1e-15, lq.GetQ(false).GetTranspose())
+
+	// minimum-norm solution: x = Aᵀ⋅(A⋅Aᵀ)⁻¹⋅b
+	AAt := NewMatrix(2, 2)
+	MatMatTrMul(AAt, 1, A, A)
+	AAti := NewMatrix(2, 2)
+	MatInvSmall(AAti, AAt, 1e-14)
+	w := NewVector(2)
+	MatVecMul(w, 1, AAti, b)
+	xCorrect := NewVector(4)
+	MatTrVecMul(xCorrect, 1, A, w)
+	x := NewVector(4)
+	lq.Solve(x, b)
+	io.Pforan("x = %v\n", x)
+	chk.Array(tst, "x (LQ)", 1e-14, x, xCorrect)
+	rank := LeastSquares(x, A, b, 0)
+	chk.Int(tst, "rank", rank, 2)
+	chk.Array(tst, "x (LeastSquares)", 1e-14, x, xCorrect)
+}
@@ -0,0 +1,185 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+)
+
+func TestOperator01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Operator01. matrices as operators")
+
+	// matrix
+	a := NewMatrixDeep2([][]float64{
+		{1, 0, 2},
+		{0, 3, 0},
+		{4, 0, 5},
+		{0, 6, 7},
+	})
+	t := new(Triplet)
+	t.Init(4, 3, 8)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 3; j++ {
+			if a.Get(i, j) != 0 {
+				t.Put(i, j, a.Get(i, j))
+			}
+		}
+	}
+	x := []float64{1, 2, 3}
+	z := []float64{1, -1, 2, -2}
+	yCorrect := []float64{7, 6, 19, 33}
+	wCorrect := []float64{9, -15, -2}
+
+	// check all implementations
+	y := NewVector(4)
+	w := NewVector(3)
+	for _, op := range []LinearOperator{a, t, t.ToMatrix(nil)} {
+		m, n := op.Dims()
+		chk.Int(tst, "m", m, 4)
+		chk.Int(tst, "n", n, 3)
+		op.MatVec(y, x)
+		chk.Array(tst, "A⋅x", 1e-15, y, yCorrect)
+		op.MatTrVec(w, z)
+		chk.Array(tst, "Aᵀ⋅z", 1e-15, w, wCorrect)
+	}
+
+	// closure
+	f := NewOperatorFunc(4, 3, func(y, x Vector) { a.MatVec(y, x) }, nil)
+	f.MatVec(y, x)
+	chk.Array(tst, "f(x)", 1e-15, y, yCorrect)
+	defer chk.RecoverTstPanicIsOK(tst)
+	f.MatTrVec(w, z)
+}
+
+func TestOperator02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Operator02. composition")
+
+	// operators
+	A := NewMatrixDeep2([][]float64{
+		{1, 2},
+		{3, 4},
+		{5, 6},
+	})
+	B := NewMatrixDeep2([][]float64{
+		{-1, 0},
+		{2, 1},
+		{0, 3},
+	})
+	C := NewMatrixDeep2([][]float64{
+		{1, 1, 0},
+		{0, 2, 1},
+	})
+
+	// dense reference results
+	check := func(msg string, op LinearOperator, ref *Matrix) {
+		m, n := op.Dims()
+		chk.Int(tst, msg+": m", m, ref.M)
+		chk.Int(tst, msg+": n", n, ref.N)
+		x := NewVectorMapped(n, func(i int) float64 { return float64(i) + 1 })
+		z := NewVectorMapped(m, func(i int) float64 { return 2 - float64(i) })
+		y, yCorrect := NewVector(m), NewVector(m)
+		w, wCorrect := NewVector(n), NewVector(n)
+		op.MatVec(y, x)
+		ref.MatVec(yCorrect, x)
+		chk.Array(tst, msg+": A⋅x", 1e-14, y, yCorrect)
+		op.MatTrVec(w, z)
+		ref.MatTrVec(wCorrect, z)
+		chk.Array(tst, msg+": Aᵀ⋅z", 1e-14, w, wCorrect)
+		io.Pforan("%s: y = %v  w = %v\n", msg, y, w)
+	}
+
+	// sum
+	S := NewMatrix(3, 2)
+	MatAdd(S, 2, A, -3, B)
+	check("2⋅A-3⋅B", OpSum(2, A, -3, B), S)
+
+	// product
+	P := NewMatrix(3, 3)
+	MatMatMul(P, 1, A, C)
+	check("A⋅C", OpProduct(A, C), P)
+
+	// scaled
+	S = NewMatrix(3, 2)
+	MatAdd(S, 0.5, A, 0, B)
+	check("0.5⋅A", OpScaled(0.5, A), S)
+
+	// transpose
+	check("Aᵀ", OpTranspose(A), A.GetTranspose())
+
+	// block: [A B; nil Cᵀ]
+	K := NewMatrixDeep2([][]float64{
+		{1, 2, -1, 0},
+		{3, 4, 2, 1},
+		{5, 6, 0, 3},
+		{0, 0, 1, 0},
+		{0, 0, 1, 2},
+		{0, 0, 0, 1},
+	})
+	check("block", OpBlock([][]LinearOperator{{A, B}, {nil, OpTranspose(C)}}), K)
+}
+
+func TestOperator03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Operator03. matrix-free iterative solvers")
+
+	// 1D Laplacian as closure
+	n := 50
+	lap := NewOperatorFunc(n, n, func(y, x Vector) {
+		for i := 0; i < n; i++ {
+			y[i] = 2 * x[i]
+			if i > 0 {
+				y[i] -= x[i-1]
+			}
+			if i < n-1 {
+				y[i] -= x[i+1]
+			}
+		}
+	}, nil)
+	xCorrect := NewVectorMapped(n, func(i int) float64 { return float64(i%4) - 1 })
+	b := NewVector(n)
+	lap.MatVec(b, xCorrect)
+
+	// cg and gmres
+	x := NewVector(n)
+	for _, kind := range []string{"cg", "gmres"} {
+		o := NewKrylov(kind)
+		o.InitOp(lap, &SpArgs{Symmetric: true, Tol: 1e-12, Restart: n})
+		o.Fact()
+		o.Solve(x, b, false)
+		io.Pforan("%s: NumIter = %d\n", kind, o.NumIter)
+		chk.Array(tst, kind+": x", 1e-9, x, xCorrect)
+	}
+
+	// composition: A = I + Laplacian as the sum of a closure and an assembled matrix
+	T := new(Triplet)
+	T.Init(n, n, n)
+	for i := 0; i < n; i++ {
+		T.Put(i, i, 1)
+	}
+	A := OpSum(1, lap, 1, T.ToMatrix(nil))
+	A.MatVec(b, xCorrect)
+	o := NewKrylov("bicgstab")
+	o.InitOp(A, &SpArgs{Tol: 1e-12})
+	o.Fact()
+	o.Solve(x, b, false)
+	chk.Array(tst, "bicgstab: x", 1e-9, x, xCorrect)
+
+	// preconditioners require an assembled matrix
+	K := krylovTestLaplacian(n).ToMatrix(nil)
+	K.MatVec(b, xCorrect)
+	p := NewKrylov("bicgstab")
+	p.InitOp(K, &SpArgs{Tol: 1e-12, Precond: "ilu0"})
+	p.Fact()
+	p.Solve(x, b, false)
+	chk.Array(tst, "bicgstab+ilu0: x", 1e-9, x, xCorrect)
+}
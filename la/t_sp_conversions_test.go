@@ -123,3 +123,55 @@ func TestSpConversion04(tst *testing.T) {
 		{10 + 4i, 11 + 4i, 12 + 3i},
 	})
 }
+
+func TestSpConversion05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpConversion05. compressed structure: sorted rows and summed duplicates")
+
+	// unsorted entries with duplicates
+	var t Triplet
+	t.Init(3, 3, 8)
+	t.Put(2, 0, 3.0)
+	t.Put(0, 2, 1.0)
+	t.Put(0, 0, 1.0)
+	t.Put(2, 0, 3.0) // repeated
+	t.Put(1, 1, 2.0)
+	t.Put(0, 0, 1.0) // repeated
+	t.Put(2, 2, 4.0)
+	t.Put(0, 2, 1.0) // repeated
+	a := t.ToMatrix(nil)
+	chk.Int(tst, "nnz", a.nnz, 5)
+	chk.Ints(tst, "p", a.p, []int{0, 2, 3, 5})
+	chk.Ints(tst, "i", a.i, []int{0, 2, 1, 0, 2})
+	chk.Array(tst, "x", 1e-17, a.x, []float64{2, 6, 2, 2, 4})
+
+	// reuse matrix with another structure
+	t.Start()
+	t.Put(1, 0, 5.0)
+	t.Put(0, 1, 6.0)
+	t.Put(1, 0, 5.0) // repeated
+	b := t.ToMatrix(a)
+	if b != a {
+		tst.Errorf("ToMatrix should reuse the given matrix\n")
+		return
+	}
+	chk.Int(tst, "nnz", a.nnz, 2)
+	chk.Ints(tst, "p", a.p, []int{0, 1, 2, 2})
+	chk.Ints(tst, "i", a.i, []int{1, 0})
+	chk.Array(tst, "x", 1e-17, a.x, []float64{10, 6})
+
+	// complex version
+	var tc TripletC
+	tc.Init(2, 2, 5)
+	tc.Put(1, 1, 1+1i)
+	tc.Put(1, 0, 2-1i)
+	tc.Put(0, 0, 3)
+	tc.Put(1, 1, 1+1i) // repeated
+	tc.Put(0, 0, 1i)   // repeated
+	ac := tc.ToMatrix(nil)
+	chk.Int(tst, "nnz", ac.nnz, 3)
+	chk.Ints(tst, "p", ac.p, []int{0, 2, 3})
+	chk.Ints(tst, "i", ac.i, []int{0, 1, 1})
+	chk.ArrayC(tst, "x", 1e-17, ac.x, []complex128{3 + 1i, 2 - 1i, 2 + 2i})
+}
@@ -0,0 +1,185 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+)
+
+// checkSpEigen checks A ⋅ v[j] = λ[j] ⋅ B ⋅ v[j] (B = I if nil)
+func checkSpEigen(tst *testing.T, A, B *CCMatrix, λ VectorC, v *MatrixC, tol float64) {
+	n := A.n
+	Ac := A.ToDense().GetComplex()
+	var Bc *MatrixC
+	if B != nil {
+		Bc = B.ToDense().GetComplex()
+	}
+	Av := NewVectorC(n)
+	Bv := NewVectorC(n)
+	for j := 0; j < len(λ); j++ {
+		vj := v.GetCol(j)
+		MatVecMulC(Av, 1, Ac, vj)
+		if B != nil {
+			MatVecMulC(Bv, 1, Bc, vj)
+		} else {
+			copy(Bv, vj)
+		}
+		Bv.Apply(λ[j], Bv)
+		chk.ArrayC(tst, io.Sf("A⋅v[%d]", j), tol, Av, Bv)
+	}
+}
+
+func TestSpEigen01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpEigen01. Lanczos: 1D Laplacian")
+
+	// matrix: eigenvalues are λ[k] = 2 - 2⋅cos(k⋅π/(n+1)), k = 1…n
+	n := 100
+	A := krylovTestLaplacian(n).ToMatrix(nil)
+	exact := func(k int) float64 { return 2 - 2*math.Cos(float64(k)*math.Pi/float64(n+1)) }
+
+	// largest algebraic
+	nev := 4
+	w := NewVectorC(nev)
+	v := NewMatrixC(n, nev)
+	args := &SpEigArgs{Which: "LA"}
+	stat := SpEigenSym(v, w, A, nil, args)
+	io.Pforan("LA: λ = %v\n", w)
+	io.Pforan("NumIt = %d, NumMv = %d\n", stat.NumIt, stat.NumMv)
+	chk.Int(tst, "NumCnv", stat.NumCnv, nev)
+	for j := 0; j < nev; j++ {
+		chk.AnaNum(tst, io.Sf("λ[%d]", j), 1e-10, exact(n-j), real(w[j]), chk.Verbose)
+	}
+	checkSpEigen(tst, A, nil, w, v, 1e-8)
+
+	// smallest via shift-invert
+	args = &SpEigArgs{ShiftInvert: true, Sigma: 0}
+	SpEigenSym(v, w, A, nil, args)
+	io.Pforan("shift-invert: λ = %v\n", w)
+	for j := 0; j < nev; j++ {
+		chk.AnaNum(tst, io.Sf("λ[%d]", j), 1e-12, exact(j+1), real(w[j]), chk.Verbose)
+	}
+	checkSpEigen(tst, A, nil, w, v, 1e-10)
+}
+
+func TestSpEigen02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpEigen02. Arnoldi: complex eigenvalues")
+
+	// block upper-triangular matrix with 2x2 diagonal blocks [a b; -b a] ⇒ λ = a ± b⋅i
+	nb := 30
+	n := 2 * nb
+	t := new(Triplet)
+	t.Init(n, n, 6*n)
+	var exact []complex128
+	for k := 0; k < nb; k++ {
+		a, b := float64(k+1), 0.5*float64(k%3)
+		i := 2 * k
+		t.Put(i, i, a)
+		t.Put(i+1, i+1, a)
+		if b != 0 {
+			t.Put(i, i+1, b)
+			t.Put(i+1, i, -b)
+		}
+		if k < nb-1 { // coupling with the next block
+			t.Put(i, i+2, 0.3)
+			t.Put(i+1, i+3, -0.2)
+		}
+		exact = append(exact, complex(a, b), complex(a, -b))
+	}
+	A := t.ToMatrix(nil)
+
+	// largest magnitude
+	nev := 5
+	w := NewVectorC(nev)
+	v := NewMatrixC(n, nev)
+	args := &SpEigArgs{Which: "LM", Ncv: 24}
+	SpEigen(v, w, A, args)
+	io.Pforan("LM: λ = %v\n", w)
+	sort.Slice(exact, func(i, j int) bool { return cmplx.Abs(exact[i]) > cmplx.Abs(exact[j]) })
+	for j := 0; j < nev; j++ {
+		found := false
+		for _, e := range exact[:nev+1] {
+			if cmplx.Abs(w[j]-e) < 1e-8 {
+				found = true
+			}
+		}
+		if !found {
+			tst.Errorf("λ[%d] = %v is not among the largest eigenvalues\n", j, w[j])
+		}
+	}
+	checkSpEigen(tst, A, nil, w, v, 1e-7)
+
+	// closest to 10.7 via shift-invert
+	args = &SpEigArgs{ShiftInvert: true, Sigma: 10.7}
+	SpEigen(nil, w[:2], A, args)
+	io.Pforan("shift-invert: λ = %v\n", w[:2])
+	chk.AnaNumC(tst, "λ[0]", 1e-10, 11+0.5i, w[0], chk.Verbose)
+	chk.AnaNumC(tst, "λ[1]", 1e-10, 11-0.5i, w[1], chk.Verbose)
+}
+
+func TestSpEigen03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpEigen03. Lanczos: generalised problem and operator")
+
+	// K ⋅ v = λ ⋅ M ⋅ v with 1D Laplacian K and diagonal M
+	n := 60
+	K := krylovTestLaplacian(n).ToMatrix(nil)
+	tm := new(Triplet)
+	tm.Init(n, n, n)
+	for i := 0; i < n; i++ {
+		tm.Put(i, i, 1+float64(i%4))
+	}
+	M := tm.ToMatrix(nil)
+	nev := 3
+	w := NewVectorC(nev)
+	v := NewMatrixC(n, nev)
+	SpEigenSym(v, w, K, M, &SpEigArgs{ShiftInvert: true})
+	io.Pforan("generalised: λ = %v\n", w)
+	checkSpEigen(tst, K, M, w, v, 1e-10)
+
+	// eigenvectors are M-orthonormal
+	Md := M.ToDense()
+	for i := 0; i < nev; i++ {
+		for j := 0; j < nev; j++ {
+			var s float64
+			for r := 0; r < n; r++ {
+				s += real(v.Get(r, i)) * Md.Get(r, r) * real(v.Get(r, j))
+			}
+			if i == j {
+				chk.Float64(tst, "vᵀ⋅M⋅v", 1e-12, s, 1)
+			} else {
+				chk.Float64(tst, "vᵀ⋅M⋅v", 1e-12, s, 0)
+			}
+		}
+	}
+
+	// operator (matrix-free) version of the standard problem
+	op := func(y, x Vector) {
+		for i := 0; i < n; i++ {
+			y[i] = 2 * x[i]
+			if i > 0 {
+				y[i] -= x[i-1]
+			}
+			if i < n-1 {
+				y[i] -= x[i+1]
+			}
+		}
+	}
+	EigenLanczos(nil, w, n, op, &SpEigArgs{Which: "LA"})
+	io.Pforan("operator: λ = %v\n", w)
+	for j := 0; j < nev; j++ {
+		chk.AnaNum(tst, io.Sf("λ[%d]", j), 1e-10, 2-2*math.Cos(float64(n-j)*math.Pi/float64(n+1)), real(w[j]), chk.Verbose)
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+)
+
+func TestSpIo01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpIo01. Matrix Market: real write and read")
+
+	// general
+	a := new(Triplet)
+	a.Init(3, 4, 7)
+	a.Put(0, 0, 1.5)
+	a.Put(0, 3, -2)
+	a.Put(1, 1, 3e-10)
+	a.Put(2, 0, 4)
+	a.Put(2, 2, 5)
+	a.Put(2, 2, 1) // repeated
+	a.Put(1, 3, -7.25)
+	a.WriteMtx("/tmp/gosl/la", "mtx01", "")
+	b := new(Triplet)
+	b.ReadMtx("/tmp/gosl/la/mtx01.mtx")
+	chk.Int(tst, "m", b.m, 3)
+	chk.Int(tst, "n", b.n, 4)
+	chk.Int(tst, "nnz", b.Len(), 6)
+	chk.Deep2(tst, "a", 1e-15, b.ToDense().GetDeep2(), a.ToDense().GetDeep2())
+
+	// symmetric: only the lower triangle is written
+	s := new(Triplet)
+	s.Init(3, 3, 7)
+	s.Put(0, 0, 2)
+	s.Put(1, 0, -1)
+	s.Put(0, 1, -1)
+	s.Put(1, 1, 2)
+	s.Put(2, 1, -1)
+	s.Put(1, 2, -1)
+	s.Put(2, 2, 2)
+	s.WriteMtx("/tmp/gosl/la", "mtx01sym", "symmetric")
+	b.ReadMtx("/tmp/gosl/la/mtx01sym.mtx")
+	chk.Int(tst, "nnz", b.Len(), 7)
+	chk.Deep2(tst, "s", 1e-15, b.ToDense().GetDeep2(), s.ToDense().GetDeep2())
+
+	// dense matrix
+	d := NewMatrixDeep2([][]float64{
+		{1, 0, 3},
+		{-4, 5.5, 0},
+	})
+	d.WriteMtx("/tmp/gosl/la", "mtx01dense")
+	e := new(Matrix)
+	e.ReadMtx("/tmp/gosl/la/mtx01dense.mtx")
+	chk.Deep2(tst, "dense", 1e-15, e.GetDeep2(), d.GetDeep2())
+	b.ReadMtx("/tmp/gosl/la/mtx01dense.mtx")
+	chk.Int(tst, "nnz(dense)", b.Len(), 4)
+	chk.Deep2(tst, "dense → triplet", 1e-15, b.ToDense().GetDeep2(), d.GetDeep2())
+}
+
+func TestSpIo02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpIo02. Matrix Market: reading files from other tools")
+
+	// pattern with comments
+	io.WriteStringToFileD("/tmp/gosl/la", "mtx02pattern.mtx", `%%MatrixMarket matrix coordinate pattern symmetric
+% comment
+%
+3 3 3
+
+1 1
+3 1
+3 2
+`)
+	a := new(Triplet)
+	a.ReadMtx("/tmp/gosl/la/mtx02pattern.mtx")
+	chk.Deep2(tst, "pattern", 1e-15, a.ToDense().GetDeep2(), [][]float64{
+		{1, 0, 1},
+		{0, 0, 1},
+		{1, 1, 0},
+	})
+
+	// skew-symmetric array (integer)
+	io.WriteStringToFileD("/tmp/gosl/la", "mtx02skew.mtx", `%%MATRIXMARKET Matrix Array Integer Skew-Symmetric
+3 3
+1
+2
+3
+`)
+	var d Matrix
+	d.ReadMtx("/tmp/gosl/la/mtx02skew.mtx")
+	chk.Deep2(tst, "skew", 1e-15, d.GetDeep2(), [][]float64{
+		{0, -1, -2},
+		{1, 0, -3},
+		{2, 3, 0},
+	})
+
+	// complex hermitian
+	io.WriteStringToFileD("/tmp/gosl/la", "mtx02herm.mtx", `%%MatrixMarket matrix coordinate complex hermitian
+2 2 3
+1 1 2.0 0.0
+2 1 1.0 -1.5
+2 2 3.0 0.0
+`)
+	c := new(TripletC)
+	c.ReadMtx("/tmp/gosl/la/mtx02herm.mtx")
+	chk.Deep2c(tst, "hermitian", 1e-15, c.ToDense().GetDeep2(), [][]complex128{
+		{2, 1 + 1.5i},
+		{1 - 1.5i, 3},
+	})
+
+	// complex round trip
+	c.WriteMtx("/tmp/gosl/la", "mtx02cplx", "hermitian")
+	e := new(TripletC)
+	e.ReadMtx("/tmp/gosl/la/mtx02cplx.mtx")
+	chk.Deep2c(tst, "complex", 1e-15, e.ToDense().GetDeep2(), c.ToDense().GetDeep2())
+
+	// complex file cannot be read into real triplet
+	defer chk.RecoverTstPanicIsOK(tst)
+	a.ReadMtx("/tmp/gosl/la/mtx02herm.mtx")
+}
+
+func TestSpIo03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpIo03. Harwell-Boeing: write and read")
+
+	// real unsymmetric (with more than 8 columns to test line breaks)
+	n := 12
+	a := new(Triplet)
+	a.Init(n, n, 3*n)
+	for i := 0; i < n; i++ {
+		a.Put(i, i, float64(i+1)*1e5)
+		if i > 0 {
+			a.Put(i, i-1, -1.0/float64(i))
+		}
+		if i < n-2 {
+			a.Put(i, i+2, float64(i)-3)
+		}
+	}
+	a.WriteHB("/tmp/gosl/la", "hb03", "")
+	b := new(Triplet)
+	b.ReadHB("/tmp/gosl/la/hb03.hb")
+	chk.Deep2(tst, "a", 1e-15, b.ToDense().GetDeep2(), a.ToDense().GetDeep2())
+
+	// rectangular and skew-symmetric
+	r := new(Triplet)
+	r.Init(2, 3, 3)
+	r.Put(0, 2, 1)
+	r.Put(1, 0, 2)
+	r.Put(1, 1, 3)
+	r.WriteHB("/tmp/gosl/la", "hb03rect", "")
+	b.ReadHB("/tmp/gosl/la/hb03rect.hb")
+	chk.Deep2(tst, "rectangular", 1e-15, b.ToDense().GetDeep2(), r.ToDense().GetDeep2())
+	k := new(Triplet)
+	k.Init(3, 3, 4)
+	k.Put(1, 0, 2)
+	k.Put(0, 1, -2)
+	k.Put(2, 1, 5)
+	k.Put(1, 2, -5)
+	k.WriteHB("/tmp/gosl/la", "hb03skew", "skew-symmetric")
+	b.ReadHB("/tmp/gosl/la/hb03skew.hb")
+	chk.Deep2(tst, "skew", 1e-15, b.ToDense().GetDeep2(), k.ToDense().GetDeep2())
+
+	// complex symmetric
+	c := new(TripletC)
+	c.Init(3, 3, 5)
+	c.Put(0, 0, 1+2i)
+	c.Put(1, 0, -3i)
+	c.Put(0, 1, -3i)
+	c.Put(2, 2, 4.5)
+	c.Put(1, 1, -1-1i)
+	c.WriteHB("/tmp/gosl/la", "hb03cplx", "symmetric")
+	e := new(TripletC)
+	e.ReadHB("/tmp/gosl/la/hb03cplx.hb")
+	chk.Deep2c(tst, "complex", 1e-15, e.ToDense().GetDeep2(), c.ToDense().GetDeep2())
+}
+
+func TestSpIo04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpIo04. Harwell-Boeing: reading files from other tools")
+
+	// real symmetric with packed integers, D exponents and a right-hand side
+	io.WriteStringToFileD("/tmp/gosl/la", "hb04.rsa", `Test matrix                                                             TEST
+             6             1             1             2             2
+RSA                        3             3             4             0
+(4I3)           (4I3)           (1P,3D20.12)        (3E20.12)
+F                          1             0
+  1  3  4  5
+  1  2  2  3
+  4.000000000000D+00 -1.000000000000D+00  2.500000000000D+00
+  1.000000000000+001
+  1.0  2.0  3.0
+`)
+	a := new(Triplet)
+	a.ReadHB("/tmp/gosl/la/hb04.rsa")
+	chk.Deep2(tst, "a", 1e-15, a.ToDense().GetDeep2(), [][]float64{
+		{4, -1, 0},
+		{-1, 2.5, 0},
+		{0, 0, 10},
+	})
+
+	// pattern in Rutherford-Boeing layout (no RHSCRD and no value format)
+	io.WriteStringToFileD("/tmp/gosl/la", "hb04.rb", `Pattern                                                                 PAT
+             2             1             1             0
+pua                        2             2             3             0
+(3I8)           (3I8)
+       1       3       4
+       1       2       1
+`)
+	a.ReadHB("/tmp/gosl/la/hb04.rb")
+	chk.Deep2(tst, "pattern", 1e-15, a.ToDense().GetDeep2(), [][]float64{
+		{1, 1},
+		{1, 0},
+	})
+}
@@ -154,3 +154,50 @@ func TestSpMatrix02(tst *testing.T) {
 	chk.Deep2(tst, "Kaug", 1.0e-17, Kaug.GetDeep2(), Cor)
 	chk.Deep2(tst, "Laug", 1.0e-17, Laug.GetDeep2(), Cor)
 }
+
+func TestTriplet03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpTriplet03. put triplet with offsets")
+
+	//   1 2 0
+	//   0 3 4
+	a := NewTriplet(2, 3, 4)
+	a.Put(0, 0, 1)
+	a.Put(0, 1, 2)
+	a.Put(1, 1, 3)
+	a.Put(1, 2, 4)
+
+	b := NewTriplet(5, 5, 8)
+	b.PutTriplet(0, 2, a)
+	b.PutTripletT(2, 0, a)
+	l := b.ToDense().Print("%2g")
+	io.Pf("%v\n", l)
+	chk.String(tst, l, " 0 0 1 2 0\n 0 0 0 3 4\n 1 0 0 0 0\n 2 3 0 0 0\n 0 4 0 0 0")
+	chk.Int(tst, "len(b)", b.Len(), 8)
+}
+
+func TestTriplet04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpTriplet04. put compressed-column matrix with offsets")
+
+	//   1 2 0
+	//   0 3 4
+	a := NewTriplet(2, 3, 5)
+	a.Put(0, 0, 0.5)
+	a.Put(0, 1, 2)
+	a.Put(0, 0, 0.5) // duplicate
+	a.Put(1, 1, 3)
+	a.Put(1, 2, 4)
+	am := a.ToMatrix(nil)
+	chk.Int(tst, "nnz(a)", am.Nnz(), 4)
+
+	b := NewTriplet(5, 5, 8)
+	b.PutCCMat(0, 2, 1, am)
+	b.PutCCMatT(2, 0, -2, am)
+	l := b.ToDense().Print("%2g")
+	io.Pf("%v\n", l)
+	chk.String(tst, l, " 0 0 1 2 0\n 0 0 0 3 4\n-2 0 0 0 0\n-4-6 0 0 0\n 0-8 0 0 0")
+	chk.Int(tst, "len(b)", b.Len(), 8)
+}
@@ -0,0 +1,260 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// checkPerm checks whether perm is a permutation of 0…n-1
+func checkPerm(tst *testing.T, msg string, perm []int, n int) {
+	p := append([]int{}, perm...)
+	sort.Ints(p)
+	chk.Ints(tst, msg, p, utl.IntRange(n))
+}
+
+func TestSpOrdering01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpOrdering01. rcm, amd and nd orderings of 2D Laplacian")
+
+	// 2D Laplacian with shuffled labels
+	nx := 15
+	n := nx * nx
+	shuffle := make([]int, n)
+	for k := 0; k < n; k++ {
+		shuffle[k] = (k * 37) % n
+	}
+	a := SpPermute(precondTestLaplacian2d(nx).ToMatrix(nil), shuffle, shuffle)
+	kl, ku := SpBandwidth(a)
+	profile := SpProfile(a)
+	io.Pforan("%8s: bandwidth = %3d  profile = %5d\n", "shuffled", kl, profile)
+	chk.Int(tst, "kl = ku", kl, ku)
+
+	// orderings
+	for _, kind := range []string{"rcm", "amd", "nd", "natural"} {
+		perm := SpOrdering(kind, a)
+		checkPerm(tst, io.Sf("perm (%s)", kind), perm, n)
+		b := SpPermute(a, perm, perm)
+		kb, _ := SpBandwidth(b)
+		pb := SpProfile(b)
+		io.Pforan("%8s: bandwidth = %3d  profile = %5d\n", kind, kb, pb)
+		if kind == "rcm" {
+			if kb > nx+1 {
+				tst.Errorf("rcm bandwidth is too large: %d > %d\n", kb, nx+1)
+			}
+			if pb >= profile {
+				tst.Errorf("rcm should reduce the profile: %d ≥ %d\n", pb, profile)
+			}
+		}
+	}
+
+	// nested dissection: removing the separator (last nodes) disconnects the grid
+	lap := precondTestLaplacian2d(nx)
+	perm := SpOrdering("nd", lap.ToMatrix(nil))
+	isSep := make([]bool, n)
+	for _, i := range perm[n-nx:] {
+		isSep[i] = true
+	}
+	t := new(Triplet)
+	t.Init(n, n, lap.Len())
+	for k := 0; k < lap.Len(); k++ {
+		if !isSep[lap.i[k]] && !isSep[lap.j[k]] {
+			t.Put(lap.i[k], lap.j[k], lap.x[k])
+		}
+	}
+	ncomp, _ := SpComponents(t.ToMatrix(nil))
+	io.Pforan("nd: separator = %v\n", perm[n-nx:])
+	chk.Int(tst, "ncomp (without separator)", ncomp, 2+nx) // 2 parts + nx isolated separator nodes
+
+	// graph with two components and isolated node
+	xadj := []int32{0, 1, 3, 4, 4, 6, 7}
+	adjncy := []int32{1, 0, 2, 1, 5, 5, 4} // 0-1-2, 3, 4-5 (with self-loop 5-5)
+	for _, kind := range []string{"rcm", "amd", "nd", "natural"} {
+		perm = SpOrderingAdj(kind, xadj, adjncy)
+		io.Pforan("%8s: perm = %v\n", kind, perm)
+		checkPerm(tst, io.Sf("perm (%s)", kind), perm, 6)
+	}
+}
+
+func TestSpOrdering02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpOrdering02. permutations")
+
+	// matrix
+	A := [][]float64{
+		{1, 0, 2, 0},
+		{0, 3, 0, 0},
+		{4, 0, 5, 6},
+		{0, 7, 0, 8},
+	}
+	t := new(Triplet)
+	t.Init(4, 4, 16)
+	for j := 3; j >= 0; j-- { // unsorted row indices
+		for i := 3; i >= 0; i-- {
+			if A[i][j] != 0 {
+				t.Put(i, j, A[i][j])
+			}
+		}
+	}
+	a := t.ToMatrix(nil)
+
+	// permutations
+	p := []int{2, 0, 3, 1}
+	q := []int{1, 3, 0, 2}
+	chk.Ints(tst, "pinv", PermInv(p), []int{1, 3, 0, 2})
+	b := SpPermute(a, p, q)
+	B := b.ToDense()
+	for k := 0; k < 4; k++ {
+		for l := 0; l < 4; l++ {
+			chk.Float64(tst, io.Sf("B[%d][%d]", k, l), 1e-17, B.Get(k, l), A[p[k]][q[l]])
+		}
+	}
+	for l := 0; l < 4; l++ {
+		if !sort.IntsAreSorted(b.i[b.p[l]:b.p[l+1]]) {
+			tst.Errorf("row indices of column %d are not sorted\n", l)
+		}
+	}
+
+	// undo permutation
+	c := SpPermute(b, PermInv(p), PermInv(q))
+	chk.Deep2(tst, "A", 1e-17, c.ToDense().GetDeep2(), A)
+	chk.Deep2(tst, "A (rows only)", 1e-17, SpPermute(SpPermute(a, p, nil), PermInv(p), nil).ToDense().GetDeep2(), A)
+
+	// solve permuted system: (P⋅A⋅Qᵀ)⋅(Q⋅x) = P⋅b
+	x := Vector{1, 2, 3, 4}
+	r := NewVector(4)
+	SpMatVecMul(r, 1, a, x)
+	rp := NewVector(4)
+	xp := NewVector(4)
+	VecPermute(rp, r, p)
+	VecPermute(xp, x, q)
+	y := NewVector(4)
+	SpMatVecMul(y, 1, b, xp)
+	chk.Array(tst, "P⋅A⋅Qᵀ⋅Q⋅x", 1e-15, y, rp)
+	VecPermuteInv(y, xp, q)
+	chk.Array(tst, "x", 1e-17, y, x)
+}
+
+func TestSpStruct01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpStruct01. bandwidth, profile, structural rank and components")
+
+	// block diagonal matrix with blocks {0,2} and {1,3,4}
+	A := [][]float64{
+		{1, 0, 2, 0, 0},
+		{0, 3, 0, 0, 1},
+		{4, 0, 5, 0, 0},
+		{0, 0, 0, 6, 0},
+		{0, 7, 0, 8, 9},
+	}
+	t := new(Triplet)
+	t.Init(5, 5, 25)
+	for i := 0; i < 5; i++ {
+		for j := 0; j < 5; j++ {
+			if A[i][j] != 0 {
+				t.Put(i, j, A[i][j])
+			}
+		}
+	}
+	a := t.ToMatrix(nil)
+	kl, ku := SpBandwidth(a)
+	chk.Int(tst, "kl", kl, 3)
+	chk.Int(tst, "ku", ku, 3)
+	chk.Int(tst, "profile", SpProfile(a), 0+0+2+0+3)
+	rank, match := SpStructRank(a)
+	chk.Int(tst, "rank", rank, 5)
+	for i, j := range match {
+		if A[i][j] == 0 {
+			tst.Errorf("matched entry (%d,%d) is zero\n", i, j)
+		}
+	}
+	ncomp, comp := SpComponents(a)
+	chk.Int(tst, "ncomp", ncomp, 2)
+	chk.Ints(tst, "comp", comp, []int{0, 1, 0, 1, 1})
+	info := SpGetInfo(a)
+	io.Pforan("%v", info)
+	if info.StructSingular() {
+		tst.Errorf("matrix should not be structurally singular\n")
+	}
+
+	// structurally singular: rows 0 and 3 only have entries in column 0 and column 3 is empty;
+	// the maximum matching requires augmenting paths
+	A = [][]float64{
+		{1, 0, 0, 0},
+		{1, 1, 1, 0},
+		{0, 1, 0, 0},
+		{1, 0, 0, 0},
+	}
+	t.Init(4, 4, 16)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			if A[i][j] != 0 {
+				t.Put(i, j, A[i][j])
+			}
+		}
+	}
+	info = SpGetInfo(t.ToMatrix(nil))
+	io.Pforan("%v", info)
+	chk.Int(tst, "rank", info.StructRank, 3)
+	chk.Ints(tst, "empty cols", info.EmptyCols, []int{3})
+	chk.Int(tst, "len(empty rows)", len(info.EmptyRows), 0)
+	chk.Int(tst, "len(unmatched)", len(info.Unmatched), 1)
+	if !info.StructSingular() {
+		tst.Errorf("matrix should be structurally singular\n")
+	}
+
+	// rectangular matrix
+	t.Init(2, 3, 6)
+	t.Put(0, 2, 1)
+	t.Put(1, 2, 1)
+	info = SpGetInfo(t.ToMatrix(nil))
+	chk.Int(tst, "rank", info.StructRank, 1)
+	chk.Int(tst, "kl", info.Kl, 0)
+	chk.Int(tst, "ku", info.Ku, 2)
+	chk.Ints(tst, "empty cols", info.EmptyCols, []int{0, 1})
+}
+
+func TestSpStruct02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpStruct02. diagnosis of assembled equations")
+
+	// springs in series: 0-1-2 and 3-4 (the second chain has no support)
+	n := 5
+	eqs := NewEquations(n, []int{0})
+	eqs.Alloc([]int{4 * n, 4 * n, 4 * n, 4 * n}, false, false)
+	eqs.Start()
+	for _, e := range [][]int{{0, 1}, {1, 2}, {3, 4}} {
+		i, j := e[0], e[1]
+		eqs.Put(i, i, 1)
+		eqs.Put(j, j, 1)
+		eqs.Put(i, j, -1)
+		eqs.Put(j, i, -1)
+	}
+	info := eqs.StructInfo()
+	io.Pforan("%v", info)
+	chk.Int(tst, "Ncomps", info.Ncomps, 2)
+	chk.Int(tst, "StructRank", info.StructRank, 4)
+
+	// structural singularity: equation 4 without stiffness
+	eqs.Start()
+	eqs.Put(1, 1, 1)
+	eqs.Put(2, 2, 1)
+	eqs.Put(3, 3, 1)
+	eqs.Put(3, 4, 1)
+	info = eqs.StructInfo()
+	io.Pforan("%v", info)
+	chk.Int(tst, "StructRank", info.StructRank, 3)
+	chk.Ints(tst, "empty rows", info.EmptyRows, []int{4})
+	chk.Ints(tst, "unmatched", info.Unmatched, []int{4})
+}
@@ -0,0 +1,169 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+)
+
+// precondTestLaplacian2d returns the 5-point Laplacian matrix on a (nx x nx) grid
+func precondTestLaplacian2d(nx int) (t *Triplet) {
+	n := nx * nx
+	t = new(Triplet)
+	t.Init(n, n, 5*n)
+	for r := 0; r < nx; r++ {
+		for c := 0; c < nx; c++ {
+			i := r*nx + c
+			t.Put(i, i, 4)
+			if c > 0 {
+				t.Put(i, i-1, -1)
+			}
+			if c < nx-1 {
+				t.Put(i, i+1, -1)
+			}
+			if r > 0 {
+				t.Put(i, i-nx, -1)
+			}
+			if r < nx-1 {
+				t.Put(i, i+nx, -1)
+			}
+		}
+	}
+	return
+}
+
+func TestPrecond01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Precond01. Jacobi and SSOR")
+
+	// matrix
+	a := NewMatrixDeep2([][]float64{
+		{4, -1, 0, 1},
+		{-1, 5, 2, 0},
+		{0, 2, 6, -1},
+		{1, 0, -1, 3},
+	})
+	t := new(Triplet)
+	t.Init(4, 4, 16)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			if a.Get(i, j) != 0 {
+				t.Put(i, j, a.Get(i, j))
+			}
+		}
+	}
+	A := t.ToMatrix(nil)
+	r := []float64{1, 2, 3, 4}
+	z := NewVector(4)
+
+	// Jacobi
+	jac := NewPreconditioner("jacobi")
+	jac.Init(nil)
+	jac.Fact(A)
+	jac.Apply(z, r)
+	chk.Array(tst, "jacobi: z", 1e-15, z, []float64{1.0 / 4.0, 2.0 / 5.0, 3.0 / 6.0, 4.0 / 3.0})
+
+	// SSOR: check that M⋅z = r with M = (D+ωL)⋅D⁻¹⋅(D+ωU)/(ω⋅(2-ω))
+	ω := 1.2
+	ssor := NewPreconditioner("ssor")
+	ssor.Init(&SpArgs{PrecOmega: ω})
+	ssor.Fact(A)
+	ssor.Apply(z, r)
+	DL := NewMatrix(4, 4)
+	DU := NewMatrix(4, 4)
+	Di := NewMatrix(4, 4)
+	for i := 0; i < 4; i++ {
+		Di.Set(i, i, 1.0/a.Get(i, i))
+		for j := 0; j < 4; j++ {
+			switch {
+			case i == j:
+				DL.Set(i, j, a.Get(i, j))
+				DU.Set(i, j, a.Get(i, j))
+			case i > j:
+				DL.Set(i, j, ω*a.Get(i, j))
+			default:
+				DU.Set(i, j, ω*a.Get(i, j))
+			}
+		}
+	}
+	M := NewMatrix(4, 4)
+	tmp := NewMatrix(4, 4)
+	MatMatMul(tmp, 1, DL, Di)
+	MatMatMul(M, 1/(ω*(2-ω)), tmp, DU)
+	Mz := NewVector(4)
+	MatVecMul(Mz, 1, M, z)
+	chk.Array(tst, "ssor: M⋅z", 1e-14, Mz, r)
+}
+
+func TestPrecond02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Precond02. ILU(0) and IC(0) are exact for tridiagonal matrices")
+
+	// tridiagonal matrices have no fill-in
+	n := 8
+	t := krylovTestLaplacian(n)
+	t.Put(0, 1, -0.5) // nonsymmetric version for ILU(0)
+	A := t.ToMatrix(nil)
+	xCorrect := NewVectorMapped(n, func(i int) float64 { return float64(i) - 2.5 })
+	b := NewVector(n)
+	SpMatVecMul(b, 1, A, xCorrect)
+	x := NewVector(n)
+	ilu := NewPreconditioner("ilu0")
+	ilu.Init(nil)
+	ilu.Fact(A)
+	ilu.Apply(x, b)
+	chk.Array(tst, "ilu0: x", 1e-13, x, xCorrect)
+
+	// symmetric version for IC(0)
+	S := krylovTestLaplacian(n).ToMatrix(nil)
+	SpMatVecMul(b, 1, S, xCorrect)
+	ic := NewPreconditioner("ic0")
+	ic.Init(nil)
+	ic.Fact(S)
+	ic.Apply(x, b)
+	chk.Array(tst, "ic0: x", 1e-13, x, xCorrect)
+}
+
+func TestPrecond03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Precond03. preconditioned iterative solvers")
+
+	// system: 2D Laplacian
+	nx := 20
+	t := precondTestLaplacian2d(nx)
+	n := nx * nx
+	xCorrect := NewVectorMapped(n, func(i int) float64 { return 1.0 + float64(i%7)/7.0 })
+	b := NewVector(n)
+	SpMatVecMul(b, 1, t.ToMatrix(nil), xCorrect)
+
+	// solve with each preconditioner
+	x := NewVector(n)
+	solve := func(kind, precond string) (nit int) {
+		o := NewSparseSolver(kind)
+		defer o.Free()
+		o.Init(t, &SpArgs{Symmetric: true, Tol: 1e-10, Precond: precond})
+		o.Fact()
+		o.Solve(x, b, false)
+		chk.Array(tst, io.Sf("%s/%s: x", kind, precond), 1e-7, x, xCorrect)
+		nit = o.(*Krylov).NumIter
+		io.Pforan("%8s %6s: NumIter = %d\n", kind, precond, nit)
+		return
+	}
+	for _, kind := range []string{"cg", "bicgstab", "gmres"} {
+		nit0 := solve(kind, "")
+		for _, precond := range []string{"jacobi", "ssor", "ilu0", "ic0"} {
+			nit := solve(kind, precond)
+			if precond != "jacobi" && nit >= nit0 { // Jacobi = scaling for constant diagonal
+				tst.Errorf("%s with %s preconditioner should take fewer iterations: %d ≥ %d\n", kind, precond, nit, nit0)
+			}
+		}
+	}
+}
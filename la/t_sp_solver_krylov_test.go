@@ -0,0 +1,221 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+)
+
+// krylovTestTri returns the nonsymmetric 5x5 matrix of TestSpSolver01
+func krylovTestTri() (t *Triplet) {
+	t = new(Triplet)
+	t.Init(5, 5, 13)
+	t.Put(0, 0, +1.0) // << duplicated
+	t.Put(0, 0, +1.0) // << duplicated
+	t.Put(1, 0, +3.0)
+	t.Put(0, 1, +3.0)
+	t.Put(2, 1, -1.0)
+	t.Put(4, 1, +4.0)
+	t.Put(1, 2, +4.0)
+	t.Put(2, 2, -3.0)
+	t.Put(3, 2, +1.0)
+	t.Put(4, 2, +2.0)
+	t.Put(2, 3, +2.0)
+	t.Put(1, 4, +6.0)
+	t.Put(4, 4, +1.0)
+	return
+}
+
+// krylovTestLaplacian returns the (symmetric positive-definite) 1D Laplacian matrix tridiag(-1,2,-1)
+func krylovTestLaplacian(n int) (t *Triplet) {
+	t = new(Triplet)
+	t.Init(n, n, 3*n)
+	for i := 0; i < n; i++ {
+		t.Put(i, i, 2)
+		if i > 0 {
+			t.Put(i, i-1, -1)
+		}
+		if i < n-1 {
+			t.Put(i, i+1, -1)
+		}
+	}
+	return
+}
+
+func TestSpKrylov01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpKrylov01. real: bicgstab and gmres")
+
+	t := krylovTestTri()
+	b := []float64{8.0, 45.0, -3.0, 3.0, 19.0}
+	xCorrect := []float64{1, 2, 3, 4, 5}
+	TestSpSolver(tst, "bicgstab", false, t, b, xCorrect, 1e-9, 1e-8, chk.Verbose, false, nil)
+	TestSpSolver(tst, "gmres", false, t, b, xCorrect, 1e-9, 1e-8, chk.Verbose, false, nil)
+}
+
+func TestSpKrylov02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpKrylov02. real: cg with 1D Laplacian")
+
+	// system
+	n := 20
+	t := krylovTestLaplacian(n)
+	xCorrect := NewVectorMapped(n, func(i int) float64 { return float64(i*i) / 10.0 })
+	b := NewVector(n)
+	SpMatVecMul(b, 1, t.ToMatrix(nil), xCorrect)
+
+	// solver
+	o := NewSparseSolver("cg")
+	defer o.Free()
+	o.Init(t, &SpArgs{Symmetric: true, Tol: 1e-12})
+	o.Fact()
+	x := NewVector(n)
+	o.Solve(x, b, false)
+
+	// check
+	chk.Array(tst, "x", 1e-9, x, xCorrect)
+	TestSolverResidual(tst, t.ToDense(), x, b, 1e-9)
+
+	// in exact arithmetic, CG converges in at most n iterations
+	s := o.(*Krylov)
+	io.Pforan("NumIter = %v\n", s.NumIter)
+	if s.NumIter > n+2 {
+		tst.Errorf("cg should converge in about n=%d iterations. NumIter = %d\n", n, s.NumIter)
+		return
+	}
+	chk.Int(tst, "len(ResHist)", len(s.ResHist), s.NumIter+1)
+}
+
+func TestSpKrylov03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpKrylov03. real: restarted gmres and initial guess")
+
+	// system
+	n := 30
+	t := krylovTestLaplacian(n)
+	t.Put(0, n-1, 0.5) // make it nonsymmetric
+	xCorrect := NewVectorMapped(n, func(i int) float64 { return 1.0 + float64(i%3) })
+	b := NewVector(n)
+	SpMatVecMul(b, 1, t.ToMatrix(nil), xCorrect)
+
+	// restarted gmres
+	o := new(Krylov)
+	o.kind = "gmres"
+	o.Init(t, &SpArgs{Restart: 5, MaxIt: 2000, Tol: 1e-13})
+	o.Fact()
+	x := NewVector(n)
+	o.Solve(x, b, false)
+	chk.Array(tst, "x", 1e-8, x, xCorrect)
+	io.Pforan("gmres(5): NumIter = %v\n", o.NumIter)
+	if o.NumIter <= 5 {
+		tst.Errorf("restarted gmres should require more than one cycle\n")
+		return
+	}
+
+	// exact initial guess gives zero iterations
+	guess := xCorrect.GetCopy()
+	p := new(Krylov)
+	p.kind = "bicgstab"
+	p.Init(t, &SpArgs{Guess: guess})
+	p.Fact()
+	p.Solve(x, b, false)
+	chk.Array(tst, "x", 1e-15, x, xCorrect)
+	chk.Int(tst, "NumIter", p.NumIter, 0)
+}
+
+func TestSpKrylov04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpKrylov04. complex: bicgstab and gmres")
+
+	// matrix of TestSpSolver02
+	A := new(TripletC)
+	A.Init(5, 5, 16)
+	A.Put(0, 0, 19.73+0.00i)
+	A.Put(1, 0, +0.00-0.51i)
+	A.Put(0, 1, 12.11-1.00i)
+	A.Put(1, 1, 32.30+7.00i)
+	A.Put(2, 1, +0.00-0.51i)
+	A.Put(0, 2, +0.00+5.0i)
+	A.Put(1, 2, 23.07+0.0i)
+	A.Put(2, 2, 70.00+7.3i)
+	A.Put(3, 2, +1.00+1.1i)
+	A.Put(1, 3, +0.00+1.000i)
+	A.Put(2, 3, +3.95+0.000i)
+	A.Put(3, 3, 50.17+0.000i)
+	A.Put(4, 3, +0.00-9.351i)
+	A.Put(2, 4, 19.00+31.83i)
+	A.Put(3, 4, 45.51+0.00i)
+	A.Put(4, 4, 55.00+0.00i)
+
+	// solution
+	xCorrect := []complex128{
+		+3.3 - 1.00i,
+		+1.0 + 0.17i,
+		+5.5 + 0.00i,
+		+9.0 + 0.00i,
+		10.0 - 17.75i,
+	}
+	b := NewVectorC(5)
+	SpMatVecMulC(b, 1, A.ToMatrix(nil), xCorrect)
+
+	// run tests
+	TestSpSolverC(tst, "bicgstab", false, A, b, xCorrect, 1e-8, 1e-7, chk.Verbose, false, nil)
+	TestSpSolverC(tst, "gmres", false, A, b, xCorrect, 1e-8, 1e-7, chk.Verbose, false, nil)
+
+	// exact complex initial guess gives zero iterations; the solver may be called many times
+	guess := NewVectorC(5)
+	copy(guess, xCorrect)
+	o := new(KrylovC)
+	o.kind = "bicgstab"
+	o.Init(A, &SpArgs{GuessC: guess})
+	o.Fact()
+	x := NewVectorC(5)
+	for k := 0; k < 2; k++ {
+		o.Solve(x, b, false)
+		chk.ArrayC(tst, "x", 1e-15, x, xCorrect)
+		chk.Int(tst, "NumIter", o.NumIter, 0)
+	}
+
+	// guess with real part only
+	guessR := NewVector(5)
+	for i := range guessR {
+		guessR[i] = real(xCorrect[i])
+	}
+	p := new(KrylovC)
+	p.kind = "bicgstab"
+	p.Init(A, &SpArgs{Guess: guessR})
+	p.Fact()
+	p.Solve(x, b, false)
+	chk.ArrayC(tst, "x", 1e-8, x, xCorrect)
+}
+
+func TestSpKrylov05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpKrylov05. complex: cg with Hermitian matrix")
+
+	// Hermitian positive-definite matrix
+	A := new(TripletC)
+	A.Init(3, 3, 7)
+	A.Put(0, 0, 4)
+	A.Put(1, 1, 5)
+	A.Put(2, 2, 6)
+	A.Put(0, 1, 1+1i)
+	A.Put(1, 0, 1-1i)
+	A.Put(1, 2, 2i)
+	A.Put(2, 1, -2i)
+
+	xCorrect := []complex128{1 + 2i, -1, 3 - 1i}
+	b := NewVectorC(3)
+	SpMatVecMulC(b, 1, A.ToMatrix(nil), xCorrect)
+	TestSpSolverC(tst, "cg", true, A, b, xCorrect, 1e-10, 1e-9, chk.Verbose, false, nil)
+}
@@ -0,0 +1,243 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+)
+
+func TestSpNative01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpNative01. real: splu")
+
+	t := krylovTestTri()
+	b := []float64{8.0, 45.0, -3.0, 3.0, 19.0}
+	xCorrect := []float64{1, 2, 3, 4, 5}
+	TestSpSolver(tst, "splu", false, t, b, xCorrect, 1e-14, 1e-13, chk.Verbose, false, nil)
+	for _, ordering := range []string{"", "rcm", "natural"} {
+		o := NewSparseSolver("splu")
+		o.Init(t, &SpArgs{Ordering: ordering})
+		o.Fact()
+		x := NewVector(5)
+		o.Solve(x, b, false)
+		chk.Array(tst, io.Sf("x (%q)", ordering), 1e-13, x, xCorrect)
+	}
+}
+
+func TestSpNative02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpNative02. real: splu requires pivoting")
+
+	// zero diagonal: natural ordering without pivoting would fail
+	a := [][]float64{
+		{0, 2, 0, 1},
+		{3, 0, 1, 0},
+		{0, 1, 0, 4},
+		{1, 0, 5, 1},
+	}
+	t := new(Triplet)
+	t.Init(4, 4, 16)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			if a[i][j] != 0 {
+				t.Put(i, j, a[i][j])
+			}
+		}
+	}
+	xCorrect := []float64{-1, 2, 0.5, 3}
+	b := NewVector(4)
+	SpMatVecMul(b, 1, t.ToMatrix(nil), xCorrect)
+	for _, ordering := range []string{"amd", "rcm", "natural"} {
+		o := NewSparseSolver("splu")
+		o.Init(t, &SpArgs{Ordering: ordering})
+		o.Fact()
+		x := NewVector(4)
+		o.Solve(x, b, false)
+		chk.Array(tst, io.Sf("x (%s)", ordering), 1e-14, x, xCorrect)
+	}
+}
+
+func TestSpNative03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpNative03. real: spchol and fill-reducing orderings")
+
+	// system: 2D Laplacian
+	nx := 15
+	n := nx * nx
+	t := precondTestLaplacian2d(nx)
+	xCorrect := NewVectorMapped(n, func(i int) float64 { return float64(i%5) - 1.5 })
+	b := NewVector(n)
+	SpMatVecMul(b, 1, t.ToMatrix(nil), xCorrect)
+
+	// solve with each ordering and compare fill-in
+	x := NewVector(n)
+	nnzL := make(map[string]int)
+	for _, ordering := range []string{"amd", "rcm", "nd", "natural"} {
+		o := NewSparseSolver("spchol")
+		o.Init(t, &SpArgs{Symmetric: true, Ordering: ordering})
+		o.Fact()
+		o.Solve(x, b, false)
+		chk.Array(tst, io.Sf("chol: x (%s)", ordering), 1e-12, x, xCorrect)
+		nnzL[ordering] = o.(*SpChol).NnzL
+
+		p := NewSparseSolver("splu")
+		p.Init(t, &SpArgs{Ordering: ordering})
+		p.Fact()
+		p.Solve(x, b, false)
+		chk.Array(tst, io.Sf("lu: x (%s)", ordering), 1e-12, x, xCorrect)
+		lu := p.(*SpLu)
+		io.Pforan("%8s: nnz(L) chol = %5d   nnz(L) lu = %5d   nnz(U) lu = %5d\n", ordering, nnzL[ordering], lu.NnzL, lu.NnzU)
+
+		// LU of SPD matrix without pivoting has the same L pattern as Cholesky
+		chk.Int(tst, "nnz(L) lu", lu.NnzL, nnzL[ordering])
+	}
+	for _, ordering := range []string{"amd", "nd"} {
+		if nnzL[ordering] >= nnzL["natural"] {
+			tst.Errorf("%s ordering should reduce fill-in: %d ≥ %d\n", ordering, nnzL[ordering], nnzL["natural"])
+		}
+	}
+}
+
+func TestSpNative04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpNative04. complex: splu")
+
+	// matrix of TestSpSolver02
+	A := new(TripletC)
+	A.Init(5, 5, 16)
+	A.Put(0, 0, 19.73+0.00i)
+	A.Put(1, 0, +0.00-0.51i)
+	A.Put(0, 1, 12.11-1.00i)
+	A.Put(1, 1, 32.30+7.00i)
+	A.Put(2, 1, +0.00-0.51i)
+	A.Put(0, 2, +0.00+5.0i)
+	A.Put(1, 2, 23.07+0.0i)
+	A.Put(2, 2, 70.00+7.3i)
+	A.Put(3, 2, +1.00+1.1i)
+	A.Put(1, 3, +0.00+1.000i)
+	A.Put(2, 3, +3.95+0.000i)
+	A.Put(3, 3, 50.17+0.000i)
+	A.Put(4, 3, +0.00-9.351i)
+	A.Put(2, 4, 19.00+31.83i)
+	A.Put(3, 4, 45.51+0.00i)
+	A.Put(4, 4, 55.00+0.00i)
+
+	// solution
+	xCorrect := []complex128{
+		+3.3 - 1.00i,
+		+1.0 + 0.17i,
+		+5.5 + 0.00i,
+		+9.0 + 0.00i,
+		10.0 - 17.75i,
+	}
+	b := NewVectorC(5)
+	SpMatVecMulC(b, 1, A.ToMatrix(nil), xCorrect)
+	TestSpSolverC(tst, "splu", false, A, b, xCorrect, 1e-13, 1e-12, chk.Verbose, false, nil)
+}
+
+func TestSpNative05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpNative05. orderings")
+
+	// arrow matrix: node 0 is connected to all others
+	n := 6
+	t := new(Triplet)
+	t.Init(n, n, 3*n)
+	for i := 0; i < n; i++ {
+		t.Put(i, i, 10)
+		if i > 0 {
+			t.Put(0, i, 1)
+			t.Put(i, 0, 1)
+		}
+	}
+	a := t.ToMatrix(nil)
+
+	// amd eliminates the hub after the leaves (when only one leaf remains, both have degree 1)
+	perm := SpOrdering("amd", a)
+	io.Pforan("amd: perm = %v\n", perm)
+	if PermInv(perm)[0] < n-2 {
+		tst.Errorf("amd should eliminate the hub last. perm = %v\n", perm)
+	}
+
+	// rcm of a path graph with shuffled labels recovers the path
+	adj := [][]int{{3}, {2, 4}, {1}, {0, 4}, {1, 3}}
+	perm = spOrderRcm(adj)
+	io.Pforan("rcm: perm = %v\n", perm)
+	pinv := PermInv(perm)
+	for i, nbrs := range adj {
+		for _, j := range nbrs {
+			if d := pinv[i] - pinv[j]; d > 1 || d < -1 {
+				tst.Errorf("rcm ordering of path should have unit bandwidth\n")
+				return
+			}
+		}
+	}
+}
+
+func TestSpNative06(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpNative06. assembly with duplicates and refactorisation")
+
+	// 1D finite elements: the element matrices k⋅[[1,-1],[-1,1]] overlap at the nodes; thus, the
+	// triplet has duplicated entries which are summed up by the (pure Go) conversion to CCMatrix
+	nel := 8
+	n := nel + 1
+	t := new(Triplet)
+	t.Init(n, n, 4*nel+1)
+	assemble := func(k float64) {
+		t.Start()
+		for e := nel - 1; e >= 0; e-- {
+			t.Put(e+1, e+1, k)
+			t.Put(e, e+1, -k)
+			t.Put(e+1, e, -k)
+			t.Put(e, e, k)
+		}
+		t.Put(0, 0, k) // support at node 0
+	}
+	b := NewVector(n)
+	b[n-1] = 1 // unit force at the free end ⇒ all springs carry the unit force
+
+	// factorise once and refactorise with new values after reassembling the triplet
+	assemble(1)
+	lu := NewSparseSolver("splu")
+	chol := NewSparseSolver("spchol")
+	lu.Init(t, nil)
+	chol.Init(t, &SpArgs{Symmetric: true})
+	x := NewVector(n)
+	for _, k := range []float64{1, 4} {
+		assemble(k)
+		xCorrect := NewVectorMapped(n, func(i int) float64 { return float64(i+1) / k })
+		lu.Fact()
+		lu.Solve(x, b, false)
+		chk.Array(tst, io.Sf("lu: x (k=%g)", k), 1e-13, x, xCorrect)
+		chol.Fact()
+		chol.Solve(x, b, false)
+		chk.Array(tst, io.Sf("chol: x (k=%g)", k), 1e-13, x, xCorrect)
+	}
+
+	// complex version
+	tc := new(TripletC)
+	tc.Init(2, 2, 5)
+	tc.Put(1, 1, 1+1i)
+	tc.Put(0, 0, 2)
+	tc.Put(1, 0, 1)
+	tc.Put(1, 1, 1-1i) // repeated
+	tc.Put(0, 0, 2i)   // repeated
+	xc := NewVectorC(2)
+	luc := NewSparseSolverC("splu")
+	luc.Init(tc, nil)
+	luc.Fact()
+	luc.Solve(xc, []complex128{2 + 2i, 5}, false)
+	chk.ArrayC(tst, "lu: x (complex)", 1e-15, xc, []complex128{1, 2})
+}
@@ -0,0 +1,116 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/la/oblas"
+)
+
+// SolveTridiag solves a tridiagonal linear system using the Thomas algorithm
+//
+//        | b0  c0                   |   | x0   |   | d0   |
+//        | a1  b1  c1               |   | x1   |   | d1   |
+//        |     a2  b2  c2           | ⋅ | x2   | = | d2   |
+//        |         ⋱   ⋱    ⋱       |   | ⋮    |   | ⋮    |
+//        |             an-1  bn-1   |   | xn-1 |   | dn-1 |
+//
+//   Input:
+//     a -- sub-diagonal; a[0] is ignored
+//     b -- diagonal
+//     c -- super-diagonal; c[n-1] is ignored
+//     d -- right-hand side
+//   Output:
+//     x -- solution. x and d may be the same vector
+//
+//   NOTE: no pivoting is performed; thus the algorithm is stable for diagonally dominant or
+//         symmetric positive-definite matrices
+//
+func SolveTridiag(x, a, b, c, d Vector) {
+	n := len(b)
+	if len(a) != n || len(c) != n || len(d) != n || len(x) != n {
+		chk.Panic("all vectors must have the same length = %d\n", n)
+	}
+	if n == 0 {
+		return
+	}
+	cp := NewVector(n) // modified super-diagonal
+	copy(x, d)
+	den := b[0]
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			den = b[i] - a[i]*cp[i-1]
+			x[i] -= a[i] * x[i-1]
+		}
+		if den == 0 {
+			chk.Panic("Thomas algorithm failed: zero pivot at row %d\n", i)
+		}
+		if i < n-1 {
+			cp[i] = c[i] / den
+		}
+		x[i] /= den
+	}
+	for i := n - 2; i >= 0; i-- {
+		x[i] -= cp[i] * x[i+1]
+	}
+}
+
+// SolveBlockTridiag solves a block-tridiagonal linear system using the block Thomas algorithm
+//
+//        | B0  C0                   |   | x0   |   | d0   |
+//        | A1  B1  C1               |   | x1   |   | d1   |
+//        |     A2  B2  C2           | ⋅ | x2   | = | d2   |
+//        |         ⋱   ⋱    ⋱       |   | ⋮    |   | ⋮    |
+//        |             An-1  Bn-1   |   | xn-1 |   | dn-1 |
+//
+//   Input:
+//     A -- sub-diagonal blocks; A[0] is ignored and may be nil
+//     B -- diagonal (square) blocks
+//     C -- super-diagonal blocks; C[n-1] is ignored and may be nil
+//     d -- right-hand side
+//   Output:
+//     x -- solution. x and d may be the same set of vectors
+//
+//   NOTE: (1) the blocks may have different sizes; e.g. Ai is (mi x mi-1) and Ci is (mi x mi+1)
+//         (2) the diagonal blocks are factorised with LAPACK (partial pivoting within blocks only)
+//
+func SolveBlockTridiag(x []Vector, A, B, C []*Matrix, d []Vector) {
+	n := len(B)
+	if len(A) != n || len(C) != n || len(d) != n || len(x) != n {
+		chk.Panic("all slices must have the same length = %d\n", n)
+	}
+	Cp := make([]*Matrix, n) // modified super-diagonal blocks
+	for i := 0; i < n; i++ {
+
+		// B' = Bi - Ai ⋅ C'(i-1) and d' = di - Ai ⋅ x(i-1)
+		m := B[i].M
+		Bp := B[i].GetCopy()
+		copy(x[i], d[i])
+		if i > 0 {
+			MatMatMulAdd(Bp, -1, A[i], Cp[i-1])
+			MatVecMulAdd(x[i], -1, A[i], x[i-1])
+		}
+
+		// solve B' ⋅ [C'i | xi] = [Ci | d']
+		nc := 0
+		if i < n-1 {
+			nc = C[i].N
+		}
+		rhs := NewMatrix(m, nc+1)
+		if nc > 0 {
+			copy(rhs.Data, C[i].Data)
+		}
+		copy(rhs.Data[nc*m:], x[i])
+		ipiv := make([]int32, m)
+		oblas.Dgesv(m, nc+1, Bp.Data, m, ipiv, rhs.Data, m)
+		if nc > 0 {
+			Cp[i] = NewMatrixRaw(m, nc, rhs.Data[:nc*m])
+		}
+		copy(x[i], rhs.Data[nc*m:])
+	}
+	for i := n - 2; i >= 0; i-- {
+		MatVecMulAdd(x[i], -1, Cp[i], x[i+1])
+	}
+}
@@ -93,6 +93,15 @@ func (o Vector) Norm() (nrm float64) {
 	return math.Sqrt(VecDot(o, o))
 }
 
+// NormInf returns the infinite norm of a vector:
+//  nrm := max_i |v[i]|
+func (o Vector) NormInf() (nrm float64) {
+	for i := 0; i < len(o); i++ {
+		nrm = math.Max(nrm, math.Abs(o[i]))
+	}
+	return
+}
+
 // Rms returns the root-mean-square of this vector
 //                ________________________
 //               /     ————            2
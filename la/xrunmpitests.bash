@@ -11,5 +11,5 @@ t_sp_mpi_main
 "
 
 for t in $tests; do
-    go build -o /tmp/gosl/$t "$t".go && mpirun -np 2 /tmp/gosl/$t
+    go build -tags "umfpack mumps" -o /tmp/gosl/$t "$t".go && mpirun -np 2 /tmp/gosl/$t
 done
@@ -5,6 +5,8 @@
 package ml
 
 import (
+	"math"
+
 	"github.com/dicksontsai/gosl/chk"
 	"github.com/dicksontsai/gosl/fun/dbf"
 	"github.com/dicksontsai/gosl/la"
@@ -95,6 +97,14 @@ func (o *LinReg) Gradients(dCdθ la.Vector) (dCdb float64) {
 //     data -- X,y data
 //   Output:
 //     params -- θ and b
+//
+//   NOTE: the least-squares problem with centred features is solved using QR decomposition
+//
+//     min ‖ [    Xc ] ⋅ θ - [ y ] ‖     with   Xc = X - (1/m) o⋅sᵀ
+//         ‖ [ √λ⋅I  ]       [ 0 ] ‖
+//
+//   which avoids the normal equations (XcᵀXc + λI)⋅θ = Xcᵀy and thus squaring the condition number
+//
 func (o *LinReg) Train() {
 
 	// auxiliary
@@ -102,28 +112,30 @@ func (o *LinReg) Train() {
 	X, y := o.data.X, o.data.Y
 	s, t := o.data.Stat.SumVars()
 
-	// r vector
+	// augmented matrix and right-hand side
 	m := float64(o.data.Nsamples)
 	n := o.data.Nfeatures
-	r := la.NewVector(n)
-	la.MatTrVecMul(r, 1, X, y)  // r := a = Xᵀy
-	la.VecAdd(r, 1, r, -t/m, s) // r := a - (t/m)s
-
-	// K matrix
-	B := la.NewMatrix(n, n)
-	K := la.NewMatrix(n, n)
-	la.VecVecTrMul(B, 1.0/m, s, s) // B := (1/m) ssᵀ
-	la.MatTrMatMul(K, 1, X, X)     // K := A = XᵀX
-	la.MatAdd(K, 1, K, -1, B)      // K := A - B
+	nr := o.data.Nsamples
+	if λ > 0 {
+		nr += n
+	}
+	A := la.NewMatrix(nr, n)
+	r := la.NewVector(nr)
+	for i := 0; i < o.data.Nsamples; i++ {
+		for j := 0; j < n; j++ {
+			A.Set(i, j, X.Get(i, j)-s[j]/m) // Xc := X - (1/m) o⋅sᵀ
+		}
+		r[i] = y[i]
+	}
 	if λ > 0 {
-		for i := 0; i < n; i++ {
-			K.Set(i, i, K.Get(i, i)+λ) // K := A - B + λI
+		for j := 0; j < n; j++ {
+			A.Set(o.data.Nsamples+j, j, math.Sqrt(λ))
 		}
 	}
 
-	// solve system
+	// solve least-squares problem
 	θ := o.AccessThetas()
-	la.DenSolve(θ, K, r, false)
+	la.LeastSquares(θ, A, r, 0)
 	b := (t - la.VecDot(s, θ)) / m
 	o.SetBias(b)
 }
@@ -131,7 +143,7 @@ func (o *LinReg) Train() {
 // TrainNumerical trains model using numerical optimizer
 //   θini -- initial (trial) θ values
 //   bini -- initial (trial) bias
-//   method -- method/kind of numerical solver. e.g. conjgrad, powel, graddesc
+//   method -- method/kind of numerical solver. e.g. conjgrad, powel, graddesc, bfgs, lbfgs
 //   saveHist -- save history
 //   control -- parameters to numerical solver. See package 'opt'
 func (o *LinReg) TrainNumerical(θini la.Vector, bini float64, method string, saveHist bool, control dbf.Params) (minCost float64, hist *opt.History) {
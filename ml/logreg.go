@@ -8,7 +8,6 @@ import (
 	"math"
 	"testing"
 
-	"github.com/dicksontsai/gosl/chk"
 	"github.com/dicksontsai/gosl/fun"
 	"github.com/dicksontsai/gosl/fun/dbf"
 	"github.com/dicksontsai/gosl/la"
@@ -262,13 +261,15 @@ func (o *LogReg) Train() {
 // TrainNumerical trains model using numerical optimizer
 //   θini -- initial (trial) θ values
 //   bini -- initial (trial) bias
-//   method -- method/kind of numerical solver. e.g. conjgrad, powel, graddesc
+//   method -- method/kind of numerical solver. e.g. conjgrad, powel, graddesc, bfgs, lbfgs, newton, dogleg, steihaug
 //   saveHist -- save history
 //   control -- parameters to numerical solver. See package 'opt'
 func (o *LogReg) TrainNumerical(θini la.Vector, bini float64, method string, saveHist bool, control dbf.Params) (minCost float64, hist *opt.History) {
 
 	// auxiliary
 	n := o.data.Nfeatures
+	var d, u la.Vector  // Hessian workspace (allocated if needed)
+	var D, H *la.Matrix // Hessian workspace (allocated if needed)
 
 	// set optimization problem
 	// v = {θ, b}  ⇒  θ = v[:n], b = v[n]
@@ -284,8 +285,21 @@ func (o *LogReg) TrainNumerical(θini la.Vector, bini float64, method string, sa
 			o.SetBias(v[n])
 			g[n] = o.Gradients(g[:n])
 		},
-		Hfcn: func(f *la.Matrix, v la.Vector) {
-			chk.Panic("cannot use Hessian yet\n")
+		Hfcn: func(h *la.Matrix, v la.Vector) {
+			if d == nil {
+				d, u, D, H = o.AllocateHessian()
+			}
+			o.SetThetas(v[:n])
+			o.SetBias(v[n])
+			w := o.Hessian(d, u, D, H)
+			for j := 0; j < n; j++ {
+				for i := 0; i < n; i++ {
+					h.Set(i, j, H.Get(i, j))
+				}
+				h.Set(n, j, u[j])
+				h.Set(j, n, u[j])
+			}
+			h.Set(n, n, w)
 		},
 	}
 
@@ -298,6 +298,31 @@ func TestLogReg04(tst *testing.T) {
 	chk.Array(tst, "θ", 1e-14, model.AccessThetas()[:4], []float64{6.252526148274546e-01, 1.180976145721166, -2.019842398401904, -9.173659359499787e-01})
 	chk.Float64(tst, "b", 1e-14, model.GetBias(), 1.272656700281225)
 
+	// train using quasi-Newton methods (should reach the same or a lower cost)
+	control := dbf.NewParams(
+		&dbf.P{N: "ftol", V: 1e-14},
+		&dbf.P{N: "gtol", V: 1e-9},
+	)
+	costTrain := model.Cost()
+	costBfgs, _ := model.TrainNumerical(θini, bini, "bfgs", false, control)
+	costLbfgs, _ := model.TrainNumerical(θini, bini, "lbfgs", false, control)
+	io.Pf("costs: Train = %v  bfgs = %v  lbfgs = %v\n", costTrain, costBfgs, costLbfgs)
+	chk.Float64(tst, "bfgs versus lbfgs", 1e-12, costBfgs, costLbfgs)
+	if costBfgs > costTrain+1e-12 {
+		tst.Errorf("bfgs cost is greater than Train cost\n")
+	}
+
+	// train using the exact Hessian (quadratic convergence ⇒ looser gtol is enough)
+	control = dbf.NewParams(
+		&dbf.P{N: "ftol", V: 1e-14},
+		&dbf.P{N: "gtol", V: 1e-8},
+	)
+	costNewton, _ := model.TrainNumerical(θini, bini, "newton", false, control)
+	costSteihaug, _ := model.TrainNumerical(θini, bini, "steihaug", false, control)
+	io.Pf("costs: newton = %v  steihaug = %v\n", costNewton, costSteihaug)
+	chk.Float64(tst, "newton versus bfgs", 1e-12, costNewton, costBfgs)
+	chk.Float64(tst, "steihaug versus bfgs", 1e-12, costSteihaug, costBfgs)
+
 	// plot
 	if chk.Verbose {
 		plt.Reset(true, &plt.A{WidthPt: 400, Dpi: 150, Prop: 0.8})
@@ -36,7 +36,7 @@ type NlSolver struct {
 	scal  la.Vector // scaling vector
 	fx    la.Vector // f(x)
 	mdx   la.Vector // - delta x
-	useDn bool      // use dense solver (matrix inversion) instead of Umfpack (sparse)
+	useDn bool      // use dense solver (matrix inversion) instead of sparse solver
 	numJ  bool      // use numerical Jacobian (with sparse solver)
 
 	// callbacks
@@ -47,11 +47,11 @@ type NlSolver struct {
 	// output callback
 	Out func(x []float64) // output callback function
 
-	// data for Umfpack (sparse)
-	Jtri    la.Triplet // triplet
-	w       la.Vector  // workspace
-	lis     la.Umfpack // linear solver
-	lsReady bool       // linear solver is lsReady
+	// data for sparse solver
+	Jtri    la.Triplet      // triplet
+	w       la.Vector       // workspace
+	lis     la.SparseSolver // linear solver: "umfpack" if available or "splu" otherwise
+	lsReady bool            // linear solver is lsReady
 
 	// data for dense solver (matrix inversion)
 	J  *la.Matrix // dense Jacobian matrix
@@ -141,6 +141,7 @@ func (o *NlSolver) Init(neq int, Ffcn fun.Vv, JfcnSp fun.Tv, JfcnDn fun.Mv, useD
 		// use sparse linear solver
 	} else {
 		o.Jtri.Init(o.neq, o.neq, o.neq*o.neq)
+		o.lis = la.NewSparseSolver(la.SpDefaultKind())
 		if JfcnSp == nil {
 			o.numJ = true
 		}
@@ -156,7 +157,7 @@ func (o *NlSolver) Init(neq int, Ffcn fun.Vv, JfcnSp fun.Tv, JfcnDn fun.Mv, useD
 
 // Free frees memory
 func (o *NlSolver) Free() {
-	if !o.useDn {
+	if o.lis != nil {
 		o.lis.Free()
 	}
 }
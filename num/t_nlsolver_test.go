@@ -58,7 +58,7 @@ func TestNls01(tst *testing.T) {
 	ffcn(fx, x)
 	io.Pf("x    = %v  expected = %v\n", x, []float64{1.0, 0.0})
 	io.Pf("f(x) = %v\n", fx)
-	chk.Array(tst, "f(x) = 0? ", 1e-16, fx, []float64{})
+	chk.Array(tst, "f(x) = 0? ", 1e-15, fx, []float64{})
 
 	// check Jacobian
 	io.Pforan("\nchecking Jacobian @ %v\n", x)
@@ -79,7 +79,7 @@ func TestNls01(tst *testing.T) {
 	ffcn(fx, xx)
 	io.Pf("xx    = %v  expected = %v\n", xx, []float64{1.0, 0.0})
 	io.Pf("f(xx) = %v\n", fx)
-	chk.Array(tst, "f(x) = 0? ", 1e-16, fx, []float64{})
+	chk.Array(tst, "f(x) = 0? ", 1e-15, fx, []float64{})
 	chk.Array(tst, "x == xx", 1e-15, x, xx)
 
 	// check Jacobian
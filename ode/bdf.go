@@ -0,0 +1,460 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/num"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// bdfMaxOrder is the maximum order of the BDF/NDF methods
+const bdfMaxOrder = 5
+
+// BDF implements the variable-order (1 to 5), variable-step backward differentiation formulae
+// (BDF) and the numerical differentiation formulae (NDF) in quasi-constant stepsize form
+//
+//   The methods available are:
+//     bdf -- backward differentiation formulae
+//     ndf -- numerical differentiation formulae (Klopfenstein-Shampine); more accurate than
+//            BDF with almost the same stability
+//
+//   The solution is represented by the array of backward differences D (modified divided
+//   differences) which is rescaled whenever the stepsize changes; thus, the stepsize may be
+//   changed freely by the solver (e.g. at the last step). The Jacobian is kept during many steps
+//   and only re-evaluated when Newton's iterations fail to converge. The iteration matrix
+//   M - h/α ⋅ J is factorised again when the stepsize or order changes.
+//
+//   References:
+//     [1] Shampine LF, Reichelt MW (1997) The MATLAB ODE suite. SIAM Journal on Scientific
+//         Computing, 18(1):1-22
+//     [2] Hairer E, Wanner G (1996). Solving Ordinary Differential Equations II: Stiff and
+//         Differential-Algebraic Problems. Springer Series in Computational Mathematics,
+//         Vol. 14, Berlin, Germany, 614 p.
+//
+type BDF struct {
+
+	// main
+	ndim int          // problem dimension
+	conf *Config      // configurations
+	work *rkwork      // workspace
+	stat *Stat        // statistics
+	fcn  Func         // dy/dx := f(x,y)
+	jac  JacF         // Jacobian function: df/dy(x,y)
+	dfdy *la.Triplet  // df/dy matrix
+	mtri *la.Triplet  // M matrix in triplet format
+	mmat *la.CCMatrix // M matrix in compressed-column format
+	hasM bool         // has M matrix
+	ndf  bool         // use NDF instead of BDF
+
+	// coefficients
+	gamma []float64 // γk = Σ 1/j, j = 1…k
+	alpha []float64 // αk = (1 - κk) ⋅ γk
+	errc  []float64 // error constants: κk ⋅ γk + 1/(k+1)
+
+	// state
+	D      []la.Vector // [bdfMaxOrder+3][ndim] backward differences
+	order  int         // current order
+	kStep  int         // order used in the last accepted step (for dense output)
+	hD     float64     // stepsize corresponding to D
+	nEqual int         // number of steps with the same stepsize and order
+	safety float64     // safety factor depending on the number of iterations
+	jacOK  bool        // a Jacobian is available
+	jacCur bool        // the Jacobian was computed at the beginning of the current step
+	cLU    float64     // coefficient c = h/α used in the current factorisation
+
+	// linear systems solver
+	kmat  *la.Triplet     // iteration matrix: kmat = M - c ⋅ dfdy
+	ls    la.SparseSolver // linear solver
+	ready bool            // matrices and solver are ready
+
+	// workspace
+	ynew la.Vector   // y at the end of the step
+	d    la.Vector   // ynew - ypredicted
+	psi  la.Vector   // Σ γj ⋅ Dj / αk
+	scal la.Vector   // atol + rtol ⋅ abs(y)
+	rhs  la.Vector   // right-hand side of linear system
+	dy   la.Vector   // correction of y
+	w    la.Vector   // workspace
+	R    [][]float64 // [bdfMaxOrder+1][bdfMaxOrder+1] matrix to change the stepsize
+	U    [][]float64 // [bdfMaxOrder+1][bdfMaxOrder+1] matrix to change the stepsize
+}
+
+// add methods to database
+func init() {
+	rkmDB["bdf"] = func() rkmethod { return new(BDF) }
+	rkmDB["ndf"] = func() rkmethod { return &BDF{ndf: true} }
+}
+
+// Free releases memory
+func (o *BDF) Free() {
+	if o.ls != nil {
+		o.ls.Free()
+	}
+}
+
+// Info returns information about this method
+func (o *BDF) Info() (fixedOnly, implicit bool, nstages int) {
+	return false, true, 1
+}
+
+// Init initialises structure
+func (o *BDF) Init(ndim int, conf *Config, work *rkwork, stat *Stat, fcn Func, jac JacF, M *la.Triplet) {
+
+	// check
+	if conf.distr {
+		chk.Panic("BDF solver cannot run in distributed mode yet\n")
+	}
+
+	// main
+	o.ndim = ndim
+	o.conf = conf
+	o.work = work
+	o.stat = stat
+	o.fcn = fcn
+	o.jac = jac
+	o.dfdy = new(la.Triplet)
+	o.mtri = M
+	if M == nil {
+		o.mtri = new(la.Triplet)
+		la.SpTriSetDiag(o.mtri, ndim, 1)
+	} else {
+		o.hasM = true
+	}
+	o.mmat = o.mtri.ToMatrix(nil)
+
+	// coefficients
+	kappa := make([]float64, bdfMaxOrder+1)
+	if o.ndf {
+		kappa = []float64{0, -0.1850, -1.0 / 9.0, -0.0823, -0.0415, 0}
+	}
+	o.gamma = make([]float64, bdfMaxOrder+1)
+	o.alpha = make([]float64, bdfMaxOrder+1)
+	o.errc = make([]float64, bdfMaxOrder+1)
+	for k := 0; k <= bdfMaxOrder; k++ {
+		if k > 0 {
+			o.gamma[k] = o.gamma[k-1] + 1.0/float64(k)
+		}
+		o.alpha[k] = (1.0 - kappa[k]) * o.gamma[k]
+		o.errc[k] = kappa[k]*o.gamma[k] + 1.0/float64(k+1)
+	}
+
+	// state
+	o.D = make([]la.Vector, bdfMaxOrder+3)
+	for i := 0; i < len(o.D); i++ {
+		o.D[i] = la.NewVector(ndim)
+	}
+
+	// linear systems solver
+	o.kmat = new(la.Triplet)
+	o.ls = la.NewSparseSolver(o.conf.lsKind)
+
+	// workspace
+	o.ynew = la.NewVector(ndim)
+	o.d = la.NewVector(ndim)
+	o.psi = la.NewVector(ndim)
+	o.scal = la.NewVector(ndim)
+	o.rhs = la.NewVector(ndim)
+	o.dy = la.NewVector(ndim)
+	o.w = la.NewVector(ndim)
+	o.R = utl.Alloc(bdfMaxOrder+1, bdfMaxOrder+1)
+	o.U = utl.Alloc(bdfMaxOrder+1, bdfMaxOrder+1)
+}
+
+// Accept accepts update and computes next stepsize and order
+func (o *BDF) Accept(y0 la.Vector, x0 float64) (dxnew float64) {
+
+	// update y
+	y0.Apply(1, o.ynew)
+
+	// update differences: D[k+1] := d = ∇ᵏ⁺¹ynew and ∇ʲ⁺¹ynew = ∇ʲynew - ∇ʲyold
+	k := o.order
+	la.VecAdd(o.D[k+2], 1, o.d, -1, o.D[k+1])
+	o.D[k+1].Apply(1, o.d)
+	for i := k; i >= 0; i-- {
+		la.VecAdd(o.D[i], 1, o.D[i+1], 1, o.D[i])
+	}
+	o.kStep = k
+	o.nEqual++
+	o.jacCur = false
+
+	// keep stepsize and order until k+1 steps with the same stepsize are performed
+	dxnew = o.work.h
+	if o.nEqual < k+1 {
+		return
+	}
+
+	// errors of lower and higher orders
+	errM, errP := math.Inf(1), math.Inf(1)
+	if k > 1 {
+		errM = o.errc[k-1] * o.errNorm(o.D[k])
+	}
+	if k < bdfMaxOrder {
+		errP = o.errc[k+1] * o.errNorm(o.D[k+2])
+	}
+
+	// select order with largest stepsize
+	norms := []float64{errM, o.work.rerr, errP}
+	fmax := 0.0
+	for i, e := range norms {
+		f := math.Pow(e, -1.0/float64(k+i))
+		if f > fmax {
+			fmax = f
+			o.order = k + i - 1
+		}
+	}
+
+	// new stepsize
+	o.nEqual = 0
+	dxnew = o.work.h * utl.Min(o.conf.Mmax, o.safety*fmax)
+	return
+}
+
+// Reject processes step rejection and computes next stepsize
+func (o *BDF) Reject() (dxnew float64) {
+	fac := o.safety * math.Pow(o.work.rerr, -1.0/float64(o.order+1))
+	dxnew = o.work.h * utl.Max(o.conf.Mmin, fac)
+	return
+}
+
+// DenseOut produces dense output (after Accept)
+func (o *BDF) DenseOut(yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	yout.Apply(1, o.D[0])
+	p := 1.0
+	for j := 1; j <= o.kStep; j++ {
+		p *= (xout - x + float64(j-1)*h) / (float64(j) * h)
+		la.VecAdd(yout, p, o.D[j], 1, yout)
+	}
+}
+
+// Step steps update
+func (o *BDF) Step(x0 float64, y0 la.Vector) {
+
+	// auxiliary
+	h := o.work.h
+	f := o.work.f[0]
+
+	// initial differences
+	if o.work.first {
+		if o.conf.fixed && o.jac != nil { // f0 is not computed by the solver in this case
+			o.stat.Nfeval++
+			o.fcn(o.work.f0, h, x0, y0)
+		}
+		o.D[0].Apply(1, y0)
+		o.D[1].Apply(h, o.work.f0)
+		o.order = 1
+		o.hD = h
+		o.nEqual = 0
+		o.jacOK = false
+	}
+
+	// change stepsize
+	if h != o.hD {
+		o.changeD(h / o.hD)
+		o.hD = h
+		o.nEqual = 0
+	}
+
+	// predictor and scaling
+	k := o.order
+	o.ynew.Fill(0)
+	for i := 0; i <= k; i++ {
+		la.VecAdd(o.ynew, 1, o.D[i], 1, o.ynew)
+	}
+	o.w.Apply(1, o.ynew) // w := ypredicted
+	la.VecScaleAbs(o.scal, o.conf.atol, o.conf.rtol, o.w)
+	o.psi.Fill(0)
+	for j := 1; j <= k; j++ {
+		la.VecAdd(o.psi, o.gamma[j]/o.alpha[k], o.D[j], 1, o.psi)
+	}
+
+	// iterations
+	c := h / o.alpha[k]
+	o.work.diverg = false
+	var converged bool
+	for {
+		if !o.jacOK {
+			o.jacobian(x0, y0)
+		}
+		if c != o.cLU {
+			o.factorise(c)
+		}
+		converged = o.newton(x0+h, c, f)
+		if converged || o.jacCur {
+			break
+		}
+		o.ynew.Apply(1, o.w) // ynew := ypredicted
+		o.jacOK = false
+	}
+
+	// diverging => reduce stepsize
+	if !converged {
+		if o.conf.fixed {
+			chk.Panic("BDF did not converge with nit=%d\n", o.work.nit)
+		}
+		o.work.dvfac = 0.5
+		o.work.diverg = true
+		o.work.rerr = 2.0 // must leave state intact, any rerr is OK
+		return
+	}
+
+	// error estimate
+	o.safety = o.conf.Mfac * float64(2*o.conf.NmaxIt+1) / float64(2*o.conf.NmaxIt+o.work.nit)
+	la.VecScaleAbs(o.scal, o.conf.atol, o.conf.rtol, o.ynew)
+	o.work.rerr = utl.Max(o.errc[k]*o.errNorm(o.d), 1.0e-10)
+}
+
+// newton solves the nonlinear system using the simplified Newton's method
+//   c ⋅ f(x,ynew) - M ⋅ (ψ + d) = 0   with   ynew = ypredicted + d
+func (o *BDF) newton(x, c float64, f la.Vector) (converged bool) {
+	o.d.Fill(0)
+	o.work.nit = 0
+	o.work.theta = 0
+	var dyNorm, dyNormOld, rate float64
+	for it := 0; it < o.conf.NmaxIt; it++ {
+
+		// max iterations ?
+		o.work.nit = it + 1
+		if o.work.nit > o.stat.Nitmax {
+			o.stat.Nitmax = o.work.nit
+		}
+
+		// residual
+		o.stat.Nfeval++
+		o.fcn(f, o.work.h, x, o.ynew)
+		la.VecAdd(o.dy, 1, o.psi, 1, o.d) // dy := ψ + d (workspace)
+		if o.hasM {
+			la.SpMatVecMul(o.rhs, 1, o.mmat, o.dy)
+			la.VecAdd(o.rhs, c, f, -1, o.rhs)
+		} else {
+			la.VecAdd(o.rhs, c, f, -1, o.dy)
+		}
+
+		// solve linear system
+		o.stat.Nlinsol++
+		o.ls.Solve(o.dy, o.rhs, false)
+		dyNorm = o.rmsNorm(o.dy)
+		if math.IsNaN(dyNorm) || math.IsInf(dyNorm, 0) {
+			return false
+		}
+
+		// check convergence rate
+		if it > 0 {
+			rate = dyNorm / dyNormOld
+			o.work.theta = rate
+			if rate >= 1 || math.Pow(rate, float64(o.conf.NmaxIt-it))/(1-rate)*dyNorm > o.conf.fnewt {
+				return false
+			}
+		}
+
+		// update
+		la.VecAdd(o.ynew, 1, o.dy, 1, o.ynew)
+		la.VecAdd(o.d, 1, o.dy, 1, o.d)
+
+		// converged
+		if dyNorm == 0 || (it > 0 && rate/(1-rate)*dyNorm < o.conf.fnewt) {
+			return true
+		}
+		dyNormOld = dyNorm
+	}
+	return false
+}
+
+// jacobian computes the Jacobian at the beginning of the step
+func (o *BDF) jacobian(x0 float64, y0 la.Vector) {
+	o.stat.Njeval++
+	if o.jac == nil {
+		num.Jacobian(o.dfdy, func(fy, yy la.Vector) {
+			o.fcn(fy, o.work.h, x0, yy)
+		}, y0, o.work.f0, o.rhs) // rhs works here as workspace variable
+	} else {
+		o.jac(o.dfdy, o.work.h, x0, y0)
+	}
+	o.jacOK = true
+	o.jacCur = true
+	o.cLU = 0 // force factorisation
+}
+
+// factorise computes and factorises the iteration matrix: kmat = M - c ⋅ dfdy
+func (o *BDF) factorise(c float64) {
+	if !o.ready {
+		o.kmat.Init(o.ndim, o.ndim, o.mtri.Len()+o.dfdy.Len())
+	}
+	la.SpTriAdd(o.kmat, 1, o.mtri, -c, o.dfdy)
+	if !o.ready {
+		o.ls.Init(o.kmat, o.conf.GetSpArgs())
+		o.ready = true
+	}
+	o.stat.Ndecomp++
+	o.ls.Fact()
+	o.cLU = c
+}
+
+// changeD rescales the differences array D to a new stepsize h = factor ⋅ hD
+//   D[0…k] := (R ⋅ U)ᵀ ⋅ D[0…k]
+func (o *BDF) changeD(factor float64) {
+	k := o.order
+	bdfCalcR(o.R, k, factor)
+	bdfCalcR(o.U, k, 1)
+	var ru [bdfMaxOrder + 1][bdfMaxOrder + 1]float64
+	for i := 0; i <= k; i++ {
+		for j := 0; j <= k; j++ {
+			for l := 0; l <= k; l++ {
+				ru[i][j] += o.R[i][l] * o.U[l][j]
+			}
+		}
+	}
+	var tmp [bdfMaxOrder + 1]float64
+	for m := 0; m < o.ndim; m++ {
+		for j := 0; j <= k; j++ {
+			tmp[j] = 0
+			for i := 0; i <= k; i++ {
+				tmp[j] += ru[i][j] * o.D[i][m]
+			}
+		}
+		for j := 0; j <= k; j++ {
+			o.D[j][m] = tmp[j]
+		}
+	}
+}
+
+// bdfCalcR computes the matrix used to change the stepsize of the differences array
+//   R[i][j] = Π (l - 1 - factor ⋅ j) / l,  l = 1…i   with   R[0][j] = 1
+func bdfCalcR(R [][]float64, order int, factor float64) {
+	for j := 0; j <= order; j++ {
+		R[0][j] = 1
+	}
+	for i := 1; i <= order; i++ {
+		R[i][0] = 0
+		for j := 1; j <= order; j++ {
+			R[i][j] = R[i-1][j] * (float64(i-1) - factor*float64(j)) / float64(i)
+		}
+	}
+}
+
+// rmsNorm computes the RMS norm
+func (o *BDF) rmsNorm(v la.Vector) (rms float64) {
+	var ratio float64
+	for m := 0; m < o.ndim; m++ {
+		ratio = v[m] / o.scal[m]
+		rms += ratio * ratio
+	}
+	return math.Sqrt(rms / float64(o.ndim))
+}
+
+// errNorm computes the RMS norm of the variables included in the local error estimate
+func (o *BDF) errNorm(v la.Vector) (rms float64) {
+	var ratio float64
+	for m := 0; m < o.ndim; m++ {
+		if o.conf.inErr(m) {
+			ratio = v[m] / o.scal[m]
+			rms += ratio * ratio
+		}
+	}
+	return math.Sqrt(rms / float64(o.conf.nErr(o.ndim)))
+}
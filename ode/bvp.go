@@ -0,0 +1,80 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/num"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// bvpData holds the problem definition shared by the boundary value problem solvers
+type bvpData struct {
+	ndim int         // size of y
+	fcn  Func        // dy/dx := f(x,y)
+	jac  JacF        // Jacobian: df/dy [may be nil ⇒ numerical Jacobian]
+	bc   BcF         // boundary conditions g(ya,yb) = 0
+	fy   *la.Triplet // df/dy
+	f    la.Vector   // f(x,y) for the numerical Jacobian
+	w    la.Vector   // workspace for the numerical Jacobian
+	g    la.Vector   // g(ya,yb)
+	gp   la.Vector   // g(ya,yb) with perturbed ya or yb
+	yp   la.Vector   // perturbed ya or yb
+}
+
+// newBvpData returns a new structure
+func newBvpData(ndim int, fcn Func, jac JacF, bc BcF) (o *bvpData) {
+	o = new(bvpData)
+	o.ndim = ndim
+	o.fcn, o.jac, o.bc = fcn, jac, bc
+	o.fy = new(la.Triplet)
+	o.f = la.NewVector(ndim)
+	o.w = la.NewVector(ndim)
+	o.g = la.NewVector(ndim)
+	o.gp = la.NewVector(ndim)
+	o.yp = la.NewVector(ndim)
+	return
+}
+
+// calcJac computes the (dense) Jacobian df/dy at (x,y)
+func (o *bvpData) calcJac(x float64, y la.Vector) (J *la.Matrix) {
+	if o.jac == nil {
+		o.fcn(o.f, 0, x, y)
+		num.Jacobian(o.fy, func(fy, yy la.Vector) {
+			o.fcn(fy, 0, x, yy)
+		}, y, o.f, o.w)
+	} else {
+		o.jac(o.fy, 0, x, y)
+	}
+	return o.fy.ToDense()
+}
+
+// calcBcJac computes the derivatives of the boundary conditions dg/dya and dg/dyb by means of
+// finite differences. It also computes g(ya,yb) and stores the results in o.g
+func (o *bvpData) calcBcJac(Ba, Bb *la.Matrix, ya, yb la.Vector) {
+	o.bc(o.g, ya, yb)
+	for k, B := range []*la.Matrix{Ba, Bb} {
+		y := ya
+		if k == 1 {
+			y = yb
+		}
+		copy(o.yp, y)
+		for j := 0; j < o.ndim; j++ {
+			δ := math.Sqrt(num.MACHEPS * utl.Max(1e-5, math.Abs(y[j])))
+			o.yp[j] = y[j] + δ
+			if k == 0 {
+				o.bc(o.gp, o.yp, yb)
+			} else {
+				o.bc(o.gp, ya, o.yp)
+			}
+			for i := 0; i < o.ndim; i++ {
+				B.Set(i, j, (o.gp[i]-o.g[i])/δ)
+			}
+			o.yp[j] = y[j]
+		}
+	}
+}
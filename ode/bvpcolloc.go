@@ -0,0 +1,410 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"sort"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// BvpColloc implements a collocation method to solve two-point boundary value problems
+//
+//   d{y}/dx = {f}(x, {y})     with     {g}({y}(a), {y}(b)) = {0}
+//
+//   The solution is approximated by a C¹-continuous piecewise cubic polynomial {S}(x) that
+//   satisfies the boundary conditions and the differential equations at the ends and midpoint of
+//   each interval of the mesh a = x0 < x1 < ... < xN = b (three-stage Lobatto IIIA formula of
+//   order 4). For each interval [xi,xi+1] with h = xi+1 - xi, the collocation equations are
+//
+//     {y}i+1 - {y}i - h/6 ({f}i + 4 {f}i+½ + {f}i+1) = {0}
+//
+//   where {f}i+½ = {f}(xi + h/2, {y}i+½) and {y}i+½ = ({y}i + {y}i+1)/2 - h/8 ({f}i+1 - {f}i). The
+//   nonlinear equations are solved by a damped Newton's method with a sparse linear solver.
+//
+//   The mesh is adapted by controlling the residual {r}(x) = d{S}/dx - {f}(x, {S}(x)) of the
+//   continuous solution. The residual of each interval is computed by a Lobatto quadrature of
+//   the scaled residual r_j / (Atol/Rtol + |f_j|); intervals with residuals greater than Rtol are
+//   subdivided into two (or three if the residual is greater than 100⋅Rtol) intervals, whereas
+//   pairs of consecutive intervals with residuals smaller than Rtol/100 are merged. The equations
+//   are then solved again using the previous solution as initial guess.
+//
+//   NOTE: the function f is called with h = 0
+//
+//   References:
+//     [1] Kierzenka J, Shampine LF (2001) A BVP solver based on residual control and the MATLAB
+//         PSE. ACM Transactions on Mathematical Software, 27(3):299-316
+//     [2] Shampine LF, Gladwell I, Thompson S (2003) Solving ODEs with MATLAB. Cambridge
+//         University Press. 263p
+//
+type BvpColloc struct {
+
+	// input
+	Atol     float64 // absolute tolerance [default = 1e-6]
+	Rtol     float64 // relative tolerance of the residual [default = 1e-3]
+	NmaxMesh int     // maximum number of mesh points [default = 5000]
+	NmaxIt   int     // maximum number of Newton iterations for each mesh [default = 40]
+	LsKind   string  // kind of linear solver: "umfpack", native "splu" or iterative "gmres", ... [default = la.SpDefaultKind()]
+	Verbose  bool    // show messages
+
+	// output
+	X     []float64   // mesh [npts]
+	Y     []la.Vector // {y} at the mesh points [npts][ndim]
+	F     []la.Vector // {f} at the mesh points [npts][ndim]
+	Rmax  float64     // maximum scaled residual of the continuous solution
+	Nit   int         // total number of Newton iterations of the last call to Solve
+	Nmesh int         // number of meshes used in the last call to Solve
+
+	// problem definition
+	data *bvpData // problem definition
+
+	// workspace
+	ym la.Vector  // {y} at midpoint
+	fm la.Vector  // {f} at midpoint
+	r  la.Vector  // residual of the continuous solution
+	fr la.Vector  // {f} at residual evaluation points
+	ba *la.Matrix // dg/dya
+	bb *la.Matrix // dg/dyb
+}
+
+// NewBvpColloc returns a new collocation solver
+//
+//  INPUT:
+//    ndim -- problem dimension
+//    fcn  -- f(x,y) = dy/dx function
+//    jac  -- Jacobian: df/dy function [may be nil ⇒ use numerical Jacobian]
+//    bc   -- boundary conditions g(ya,yb) = 0
+//
+func NewBvpColloc(ndim int, fcn Func, jac JacF, bc BcF) (o *BvpColloc) {
+	o = new(BvpColloc)
+	o.Atol = 1e-6
+	o.Rtol = 1e-3
+	o.NmaxMesh = 5000
+	o.NmaxIt = 40
+	o.LsKind = la.SpDefaultKind()
+	o.data = newBvpData(ndim, fcn, jac, bc)
+	o.ym = la.NewVector(ndim)
+	o.fm = la.NewVector(ndim)
+	o.r = la.NewVector(ndim)
+	o.fr = la.NewVector(ndim)
+	o.ba = la.NewMatrix(ndim, ndim)
+	o.bb = la.NewMatrix(ndim, ndim)
+	return
+}
+
+// Init sets the initial mesh and the initial guess
+//
+//  INPUT:
+//    x     -- initial mesh a = x0 < x1 < ... < xN = b [N ≥ 1]
+//    guess -- function computing the initial guess {y}(x) [may be nil ⇒ zero]
+//
+func (o *BvpColloc) Init(x []float64, guess func(y la.Vector, x float64)) {
+	if len(x) < 2 {
+		chk.Panic("at least two mesh points are required. len(x) = %d is invalid\n", len(x))
+	}
+	for i := 1; i < len(x); i++ {
+		if x[i] <= x[i-1] {
+			chk.Panic("mesh points must be strictly increasing. x[%d]=%g ≤ x[%d]=%g\n", i, x[i], i-1, x[i-1])
+		}
+	}
+	o.X = make([]float64, len(x))
+	copy(o.X, x)
+	o.Y = make([]la.Vector, len(x))
+	o.F = make([]la.Vector, len(x))
+	for i := range x {
+		o.Y[i] = la.NewVector(o.data.ndim)
+		o.F[i] = la.NewVector(o.data.ndim)
+		if guess != nil {
+			guess(o.Y[i], x[i])
+		}
+	}
+}
+
+// Solve solves the boundary value problem using the current values of Y as initial guess. The
+// mesh is refined until the residuals are smaller than Rtol
+func (o *BvpColloc) Solve() {
+
+	// check
+	if len(o.X) < 2 {
+		chk.Panic("Init must be called first\n")
+	}
+
+	// solve and refine mesh
+	o.Nit, o.Nmesh = 0, 0
+	for {
+		o.Nmesh++
+		o.newton()
+		res := o.residuals()
+		o.Rmax = la.Vector(res).Max()
+		if o.Verbose {
+			io.Pf("mesh %3d: npts = %5d  Rmax = %g\n", o.Nmesh, len(o.X), o.Rmax)
+		}
+		if o.Rmax <= o.Rtol {
+			return
+		}
+		o.refine(res)
+	}
+}
+
+// Eval computes {y}(x) using the continuous (cubic Hermite) solution
+//  NOTE: this function must be called after Solve
+func (o *BvpColloc) Eval(y la.Vector, x float64) {
+	n := len(o.X)
+	if x < o.X[0] || x > o.X[n-1] {
+		chk.Panic("x=%g is outside the interval [%g,%g]\n", x, o.X[0], o.X[n-1])
+	}
+	i := sort.SearchFloat64s(o.X, x) // X[i] ≥ x
+	if i > 0 {
+		i--
+	}
+	if i == n-1 {
+		i--
+	}
+	o.hermite(y, nil, i, x)
+}
+
+// Continuation solves a sequence of problems depending on a parameter p; each solution (and
+// mesh) is used as the initial guess of the next problem
+//
+//  INPUT:
+//    ps   -- values of the parameter
+//    setp -- function to set the parameter p of the problem (e.g. used by fcn and bc)
+//    out  -- function to process the solution of each problem [may be nil]
+//
+func (o *BvpColloc) Continuation(ps []float64, setp func(p float64), out func(p float64)) {
+	for _, p := range ps {
+		setp(p)
+		o.Solve()
+		if out != nil {
+			out(p)
+		}
+	}
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// hermite computes the cubic Hermite interpolant {S}(x) and its derivative d{S}/dx [may be nil]
+// within interval [xi,xi+1]
+func (o *BvpColloc) hermite(S, dSdx la.Vector, i int, x float64) {
+	h := o.X[i+1] - o.X[i]
+	t := (x - o.X[i]) / h
+	t2, t3 := t*t, t*t*t
+	yi, yj, fi, fj := o.Y[i], o.Y[i+1], o.F[i], o.F[i+1]
+	for k := 0; k < o.data.ndim; k++ {
+		S[k] = (2*t3-3*t2+1)*yi[k] + (t3-2*t2+t)*h*fi[k] + (3*t2-2*t3)*yj[k] + (t3-t2)*h*fj[k]
+		if dSdx != nil {
+			dSdx[k] = 6*(t2-t)*(yi[k]-yj[k])/h + (3*t2-4*t+1)*fi[k] + (3*t2-2*t)*fj[k]
+		}
+	}
+}
+
+// calcF computes {f} at the mesh points
+func (o *BvpColloc) calcF() {
+	for i, x := range o.X {
+		o.data.fcn(o.F[i], 0, x, o.Y[i])
+	}
+}
+
+// midpoint computes {y} and {f} at the midpoint of interval [xi,xi+1]
+func (o *BvpColloc) midpoint(i int) (xm, h float64) {
+	h = o.X[i+1] - o.X[i]
+	xm = o.X[i] + h/2
+	for k := 0; k < o.data.ndim; k++ {
+		o.ym[k] = (o.Y[i][k]+o.Y[i+1][k])/2 - h*(o.F[i+1][k]-o.F[i][k])/8
+	}
+	o.data.fcn(o.fm, 0, xm, o.ym)
+	return
+}
+
+// calcPhi computes the residuals of the collocation equations and boundary conditions
+func (o *BvpColloc) calcPhi(phi la.Vector) {
+	n := o.data.ndim
+	N := len(o.X) - 1
+	o.calcF()
+	for i := 0; i < N; i++ {
+		_, h := o.midpoint(i)
+		for k := 0; k < n; k++ {
+			phi[i*n+k] = o.Y[i+1][k] - o.Y[i][k] - h*(o.F[i][k]+4*o.fm[k]+o.F[i+1][k])/6
+		}
+	}
+	o.data.bc(phi[N*n:], o.Y[0], o.Y[N])
+}
+
+// calcJac computes the Jacobian of the collocation equations and boundary conditions. It must be
+// called after calcPhi
+//
+//   d{Φ}i/d{y}i   = - [I] - h/6 [J]i - 2h/3 [J]i+½ ⋅ ([I]/2 + h/8 [J]i)
+//   d{Φ}i/d{y}i+1 =   [I] - h/6 [J]i+1 - 2h/3 [J]i+½ ⋅ ([I]/2 - h/8 [J]i+1)
+//
+func (o *BvpColloc) calcJac(K *la.Triplet) {
+	n := o.data.ndim
+	N := len(o.X) - 1
+	K.Start()
+	Ji := o.data.calcJac(o.X[0], o.Y[0])
+	for i := 0; i < N; i++ {
+		xm, h := o.midpoint(i)
+		Jm := o.data.calcJac(xm, o.ym)
+		Jj := o.data.calcJac(o.X[i+1], o.Y[i+1])
+		for r := 0; r < n; r++ {
+			for c := 0; c < n; c++ {
+				var δ, JmJi, JmJj float64
+				if r == c {
+					δ = 1
+				}
+				for l := 0; l < n; l++ {
+					JmJi += Jm.Get(r, l) * Ji.Get(l, c)
+					JmJj += Jm.Get(r, l) * Jj.Get(l, c)
+				}
+				a := -δ - h*Ji.Get(r, c)/6 - h*Jm.Get(r, c)/3 - h*h*JmJi/12
+				b := δ - h*Jj.Get(r, c)/6 - h*Jm.Get(r, c)/3 + h*h*JmJj/12
+				K.Put(i*n+r, i*n+c, a)
+				K.Put(i*n+r, (i+1)*n+c, b)
+			}
+		}
+		Ji = Jj
+	}
+	o.data.calcBcJac(o.ba, o.bb, o.Y[0], o.Y[N])
+	for r := 0; r < n; r++ {
+		for c := 0; c < n; c++ {
+			K.Put(N*n+r, c, o.ba.Get(r, c))
+			K.Put(N*n+r, N*n+c, o.bb.Get(r, c))
+		}
+	}
+}
+
+// newton solves the collocation equations on the current mesh by the damped Newton's method
+func (o *BvpColloc) newton() {
+
+	// workspace
+	n := o.data.ndim
+	neq := n * len(o.X)
+	phi := la.NewVector(neq)
+	δ := la.NewVector(neq)
+	y0 := la.NewVector(neq)
+	K := new(la.Triplet)
+	K.Init(neq, neq, 2*n*n*len(o.X))
+	ls := la.NewSparseSolver(o.LsKind)
+	defer ls.Free()
+
+	// iterations
+	o.calcPhi(phi)
+	nrm := phi.Norm()
+	for it := 0; it < o.NmaxIt; it++ {
+		o.Nit++
+
+		// solve K⋅δ = -Φ
+		o.calcJac(K)
+		if it == 0 {
+			ls.Init(K, &la.SpArgs{Symmetric: false, Verbose: false, Ordering: "", Scaling: "", Guess: nil, Communicator: nil})
+		}
+		ls.Fact()
+		ls.Solve(δ, phi, false)
+		var err float64
+		for i := range o.Y {
+			for k := 0; k < n; k++ {
+				y0[i*n+k] = o.Y[i][k]
+				e := δ[i*n+k] / (o.Atol + o.Rtol*math.Abs(o.Y[i][k]))
+				err = utl.Max(err, math.Abs(e))
+			}
+		}
+
+		// damped update
+		λ := 1.0
+		for {
+			for i := range o.Y {
+				for k := 0; k < n; k++ {
+					o.Y[i][k] = y0[i*n+k] - λ*δ[i*n+k]
+				}
+			}
+			o.calcPhi(phi)
+			nrmNew := phi.Norm()
+			if nrmNew <= (1-λ/4)*nrm || err*λ < 1 {
+				nrm = nrmNew
+				break
+			}
+			λ /= 2
+			if λ < 1e-4 {
+				chk.Panic("damped Newton's method failed: the step is too small (λ = %g)\n", λ)
+			}
+		}
+		if o.Verbose {
+			io.Pf("  it = %3d  λ = %6g  |Φ| = %13.6e  |δ|/tol = %13.6e\n", it, λ, nrm, err)
+		}
+
+		// check convergence
+		if λ == 1 && err < 1e-3 {
+			return
+		}
+	}
+	chk.Panic("Newton's method did not converge after %d iterations\n", o.NmaxIt)
+}
+
+// residuals computes the scaled residuals of the continuous solution in each interval
+func (o *BvpColloc) residuals() (res []float64) {
+	n := o.data.ndim
+	N := len(o.X) - 1
+	res = make([]float64, N)
+	thres := o.Atol / o.Rtol
+	for i := 0; i < N; i++ {
+		xm := (o.X[i] + o.X[i+1]) / 2
+		h := o.X[i+1] - o.X[i]
+		for _, s := range []float64{-1, 1} {
+			x := xm + s*math.Sqrt(3.0/7.0)*h/2
+			o.hermite(o.ym, o.r, i, x)
+			o.data.fcn(o.fr, 0, x, o.ym)
+			var rmax float64
+			for k := 0; k < n; k++ {
+				rmax = utl.Max(rmax, math.Abs(o.r[k]-o.fr[k])/(thres+math.Abs(o.fr[k])))
+			}
+			res[i] += 49.0 / 180.0 * rmax * rmax // Lobatto weights: 49/90 over [-1,1] ⇒ half for mean
+		}
+		res[i] = math.Sqrt(res[i])
+	}
+	return
+}
+
+// refine subdivides the intervals with large residuals, merges pairs of consecutive intervals
+// with very small residuals and interpolates the solution
+func (o *BvpColloc) refine(res []float64) {
+	var X []float64
+	var Y []la.Vector
+	for i := 0; i < len(res); i++ {
+		X = append(X, o.X[i])
+		Y = append(Y, o.Y[i].GetCopy())
+		if i+1 < len(res) && res[i] < o.Rtol/100 && res[i+1] < o.Rtol/100 {
+			i++ // skip x[i+1]
+			continue
+		}
+		nsub := 1
+		if res[i] > 100*o.Rtol {
+			nsub = 3
+		} else if res[i] > o.Rtol {
+			nsub = 2
+		}
+		h := o.X[i+1] - o.X[i]
+		for j := 1; j < nsub; j++ {
+			x := o.X[i] + float64(j)*h/float64(nsub)
+			y := la.NewVector(o.data.ndim)
+			o.hermite(y, nil, i, x)
+			X = append(X, x)
+			Y = append(Y, y)
+		}
+	}
+	N := len(o.X) - 1
+	X = append(X, o.X[N])
+	Y = append(Y, o.Y[N].GetCopy())
+	if len(X) > o.NmaxMesh {
+		chk.Panic("the number of mesh points (%d) exceeds the maximum (%d)\n", len(X), o.NmaxMesh)
+	}
+	o.X, o.Y = X, Y
+	o.F = make([]la.Vector, len(X))
+	for i := range X {
+		o.F[i] = la.NewVector(o.data.ndim)
+	}
+}
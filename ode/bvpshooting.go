@@ -0,0 +1,229 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"sort"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/num"
+)
+
+// BvpShooting implements the multiple shooting method to solve two-point boundary value problems
+//
+//   d{y}/dx = {f}(x, {y})     with     {g}({y}(a), {y}(b)) = {0}
+//
+//   The interval [a,b] is divided by the shooting nodes a = x0 < x1 < ... < xm = b. The unknowns
+//   are the initial values {s}k = {y}(xk) of the segments [xk,xk+1] and the equations are
+//
+//     {y}(xk+1; xk, {s}k) - {s}k+1 = {0}     for k = 0 ... m-2
+//     {g}({s}0, {y}(xm; xm-1, {s}m-1)) = {0}
+//
+//   where {y}(x; xk, {s}k) is the solution of the initial value problem starting at xk with {s}k.
+//   The equations are solved by num.NlSolver. The Jacobian is computed with the sensitivity
+//   matrices d{y}(xk+1)/d{s}k obtained by SensFwd; the derivatives of {g} are computed by finite
+//   differences. With m = 1, the single shooting method is obtained.
+//
+//   NOTE: the initial value problems may be unstable (e.g. with fast growing modes); in this
+//         case, more shooting nodes are required or BvpColloc should be used
+//
+//   References:
+//     [1] Stoer J, Bulirsch R (2002) Introduction to Numerical Analysis. 3rd Edition. Springer.
+//         744p
+//     [2] Ascher UM, Mattheij RMM, Russell RD (1995) Numerical Solution of Boundary Value
+//         Problems for Ordinary Differential Equations. SIAM. 595p
+//
+type BvpShooting struct {
+
+	// input
+	NlsPrms map[string]float64 // parameters of num.NlSolver (see num.NlSolver.Init) [may be nil]
+	Verbose bool               // show messages of num.NlSolver
+
+	// output
+	X   []float64   // shooting nodes [m+1]
+	Y   []la.Vector // {y} at the shooting nodes [m+1][ndim]
+	Nit int         // number of iterations of the last call to Solve
+
+	// problem definition
+	data *bvpData   // problem definition
+	sol  *Solver    // solver of the initial value problems
+	sens *SensFwd   // solver of the sensitivity equations
+	smat *la.Matrix // sensitivity matrix d{y}(xk+1)/d{s}k
+	ba   *la.Matrix // dg/dya
+	bb   *la.Matrix // dg/dyb
+	z    la.Vector  // {y}(xk+1; xk, {s}k)
+}
+
+// NewBvpShooting returns a new multiple shooting solver
+//
+//  INPUT:
+//    ndim -- problem dimension
+//    conf -- configuration parameters of the ODE solver (outputs and events are disregarded)
+//    fcn  -- f(x,y) = dy/dx function
+//    jac  -- Jacobian: df/dy function [may be nil ⇒ use numerical Jacobian]
+//    bc   -- boundary conditions g(ya,yb) = 0
+//
+//  NOTE: remember to call Free() to release allocated resources (e.g. from the linear solvers)
+//
+func NewBvpShooting(ndim int, conf *Config, fcn Func, jac JacF, bc BcF) (o *BvpShooting) {
+	c := new(Config)
+	*c = *conf
+	c.stepOut, c.denseOut, c.stepF, c.denseF, c.events = false, false, nil, nil, nil
+	o = new(BvpShooting)
+	o.data = newBvpData(ndim, fcn, jac, bc)
+	o.sol = NewSolver(ndim, c, fcn, jac, nil)
+	o.sens = NewSensFwd(ndim, ndim, c, fcn, jac, nil)
+	o.smat = la.NewMatrix(ndim, ndim)
+	o.ba = la.NewMatrix(ndim, ndim)
+	o.bb = la.NewMatrix(ndim, ndim)
+	o.z = la.NewVector(ndim)
+	return
+}
+
+// Free releases allocated memory
+func (o *BvpShooting) Free() {
+	o.sol.Free()
+	o.sens.Free()
+}
+
+// Init sets the shooting nodes and the initial guess
+//
+//  INPUT:
+//    x     -- shooting nodes a = x0 < x1 < ... < xm = b [m ≥ 1]
+//    guess -- function computing the initial guess {y}(x) [may be nil ⇒ zero]
+//
+func (o *BvpShooting) Init(x []float64, guess func(y la.Vector, x float64)) {
+	if len(x) < 2 {
+		chk.Panic("at least two shooting nodes are required. len(x) = %d is invalid\n", len(x))
+	}
+	for k := 1; k < len(x); k++ {
+		if x[k] <= x[k-1] {
+			chk.Panic("shooting nodes must be strictly increasing. x[%d]=%g ≤ x[%d]=%g\n", k, x[k], k-1, x[k-1])
+		}
+	}
+	o.X = make([]float64, len(x))
+	copy(o.X, x)
+	o.Y = make([]la.Vector, len(x))
+	for k := range x {
+		o.Y[k] = la.NewVector(o.data.ndim)
+		if guess != nil {
+			guess(o.Y[k], x[k])
+		}
+	}
+}
+
+// Solve solves the boundary value problem using the current values of Y as initial guess
+func (o *BvpShooting) Solve() {
+
+	// check
+	if len(o.X) < 2 {
+		chk.Panic("Init must be called first\n")
+	}
+
+	// unknowns
+	n := o.data.ndim
+	m := len(o.X) - 1
+	neq := n * m
+	s := la.NewVector(neq)
+	for k := 0; k < m; k++ {
+		copy(s[k*n:(k+1)*n], o.Y[k])
+	}
+
+	// residuals
+	ffcn := func(fs, s la.Vector) {
+		for k := 0; k < m; k++ {
+			copy(o.z, s[k*n:(k+1)*n])
+			o.sol.Solve(o.z, o.X[k], o.X[k+1])
+			if k < m-1 {
+				for i := 0; i < n; i++ {
+					fs[k*n+i] = o.z[i] - s[(k+1)*n+i]
+				}
+			}
+		}
+		o.data.bc(fs[(m-1)*n:], s[:n], o.z)
+	}
+
+	// Jacobian
+	jfcn := func(J *la.Triplet, s la.Vector) {
+		J.Start()
+		for k := 0; k < m; k++ {
+			copy(o.z, s[k*n:(k+1)*n])
+			o.smat.SetDiag(1)
+			o.sens.Solve(o.z, o.smat, o.X[k], o.X[k+1])
+			if k < m-1 {
+				for i := 0; i < n; i++ {
+					for j := 0; j < n; j++ {
+						J.Put(k*n+i, k*n+j, o.smat.Get(i, j))
+					}
+					J.Put(k*n+i, (k+1)*n+i, -1)
+				}
+			}
+		}
+		o.data.calcBcJac(o.ba, o.bb, s[:n], o.z)
+		r := (m - 1) * n
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				var bs float64 // (dg/dyb ⋅ S)ij
+				for l := 0; l < n; l++ {
+					bs += o.bb.Get(i, l) * o.smat.Get(l, j)
+				}
+				if m == 1 {
+					J.Put(r+i, j, o.ba.Get(i, j)+bs)
+				} else {
+					J.Put(r+i, j, o.ba.Get(i, j))
+					J.Put(r+i, r+j, bs)
+				}
+			}
+		}
+	}
+
+	// solve
+	var nls num.NlSolver
+	defer nls.Free()
+	nls.Init(neq, ffcn, jfcn, nil, false, false, o.NlsPrms)
+	nls.Solve(s, !o.Verbose)
+	o.Nit = nls.It
+
+	// results
+	for k := 0; k < m; k++ {
+		copy(o.Y[k], s[k*n:(k+1)*n])
+	}
+	copy(o.Y[m], o.Y[m-1])
+	o.sol.Solve(o.Y[m], o.X[m-1], o.X[m])
+}
+
+// Eval computes {y}(x) by integrating from the closest shooting node at the left of x
+//  NOTE: this function must be called after Solve
+func (o *BvpShooting) Eval(y la.Vector, x float64) {
+	if x < o.X[0] || x > o.X[len(o.X)-1] {
+		chk.Panic("x=%g is outside the interval [%g,%g]\n", x, o.X[0], o.X[len(o.X)-1])
+	}
+	k := sort.SearchFloat64s(o.X, x) // X[k] ≥ x
+	if k < len(o.X) && o.X[k] == x {
+		copy(y, o.Y[k])
+		return
+	}
+	copy(y, o.Y[k-1])
+	o.sol.Solve(y, o.X[k-1], x)
+}
+
+// Continuation solves a sequence of problems depending on a parameter p; each solution is used
+// as the initial guess of the next problem
+//
+//  INPUT:
+//    ps   -- values of the parameter
+//    setp -- function to set the parameter p of the problem (e.g. used by fcn and bc)
+//    out  -- function to process the solution of each problem [may be nil]
+//
+func (o *BvpShooting) Continuation(ps []float64, setp func(p float64), out func(p float64)) {
+	for _, p := range ps {
+		setp(p)
+		o.Solve()
+		if out != nil {
+			out(p)
+		}
+	}
+}
@@ -44,6 +44,12 @@ type Config struct {
 	StiffNyes  int     // number of "yes" stiff steps allowed [default = 15]
 	StiffNnot  int     // number of "not" stiff steps to disregard stiffness [default = 6]
 
+	// events
+	EventTol float64 // tolerance to localise events [default = 1e-10]
+
+	// differential-algebraic equations
+	SuppressAlg bool // exclude the algebraic variables from the local error estimate [default = false]
+
 	// output
 	stepF     StepOutF  // function to process step output (of accepted steps) [may be nil]
 	denseF    DenseOutF // function to process dense output [may be nil]
@@ -51,6 +57,15 @@ type Config struct {
 	stepOut   bool      // perform output of (variable) steps
 	denseOut  bool      // perform dense output is active
 	denseNstp int       // number of dense steps
+	events    []*Event  // events to be detected [may be nil]
+
+	// differential-algebraic equations
+	algebraic []bool // flags the algebraic variables [may be nil ⇒ all variables are differential]
+
+	// split right-hand side: f = fS + fN
+	splitS    Func // stiff part fS(x,y) [may be nil ⇒ no split]
+	splitJacS JacF // Jacobian of the stiff part dfS/dy [may be nil ⇒ numerical Jacobian]
+	splitN    Func // non-stiff part fN(x,y)
 
 	// linear solver
 	Symmetric bool   // assume symmetric matrix
@@ -82,10 +97,11 @@ type Config struct {
 }
 
 // NewConfig returns a new [default] set of configuration parameters
-//   method -- the ODE method: e.g. fweuler, bweuler, radau5, moeuler, dopri5
+//   method -- the ODE method: e.g. fweuler, bweuler, radau5, bdf, ndf, moeuler, dopri5, ark4, etdrk4
 //   comm   -- communicator for the linear solver [may be nil]
-//   lsKind -- kind of linear solver: "umfpack" or "mumps" [may be empty]
-//   NOTE: (1) if comm == nil, the linear solver will be "umfpack" by default
+//   lsKind -- kind of linear solver: "umfpack", "mumps", native "splu" or iterative: "cg", "bicgstab", "gmres" [may be empty]
+//   NOTE: (1) if lsKind is empty or comm == nil and lsKind == "mumps", the linear solver will be
+//             la.SpDefaultKind(), i.e. "umfpack" if la was built with this tag or "splu" otherwise
 //         (2) if comm != nil and comm.Size() == 1, you can use either "umfpack" or "mumps"
 //         (3) if comm != nil and comm.Size() > 1, the linear solver will be set to "mumps" automatically
 func NewConfig(method string, lsKind string, comm *mpi.Communicator) (o *Config) {
@@ -118,12 +134,15 @@ func NewConfig(method string, lsKind string, comm *mpi.Communicator) (o *Config)
 	o.StiffNyes = 15
 	o.StiffNnot = 6
 
+	// events
+	o.EventTol = 1e-10
+
 	// internal data
 	o.method = method
 
 	// linear solver control
-	if comm == nil || lsKind == "" {
-		lsKind = "umfpack"
+	if lsKind == "" || (comm == nil && lsKind == "mumps") {
+		lsKind = la.SpDefaultKind()
 	}
 	if comm != nil {
 		if comm.Size() > 1 {
@@ -142,6 +161,8 @@ func NewConfig(method string, lsKind string, comm *mpi.Communicator) (o *Config)
 	switch method {
 	case "radau5":
 		o.rerrPrevMin = 1e-2
+	case "bdf", "ndf":
+		o.NmaxIt = 4
 	case "dopri5":
 		o.StabBeta = 0.04
 		o.stabBetaM = 0.75
@@ -212,6 +233,65 @@ func (o *Config) SetDenseOut(save bool, dxOut, xf float64, out DenseOutF) {
 	}
 }
 
+// SetEvents sets events to be detected during the integration
+//  events -- events; i.e. zero crossings of g(x,y) functions
+//  NOTE: (1) the ODE method must have dense output and variable steps must be used
+//        (2) the events are recorded in the Output structure
+func (o *Config) SetEvents(events ...*Event) {
+	o.events = events
+}
+
+// SetAlgebraic sets the algebraic variables of differential-algebraic equations (DAE) given by
+//
+//   [M] ⋅ d{y}/dx = {f}(x, {y})     with singular [M]
+//
+//  algebraic -- flags the algebraic variables; i.e. y[i] is algebraic if algebraic[i] == true [ndim]
+//  NOTE: (1) the flags are used by ConsistentInit and by the local error estimate of radau5, bdf
+//            and ndf if SuppressAlg is true
+//        (2) the algebraic variables of index-1 problems can be safely excluded from the error
+//            estimate because they are determined by the differential ones; this is useful when
+//            the algebraic variables are badly scaled or converge slowly
+func (o *Config) SetAlgebraic(algebraic []bool) {
+	o.algebraic = algebraic
+}
+
+// SetSplit sets the split right-hand side of systems with stiff and non-stiff parts
+//
+//   d{y}/dx = {f}(x, {y}) = {fS}(x, {y}) + {fN}(x, {y})
+//
+//  fS   -- stiff part; e.g. diffusion [treated implicitly by ark3 and ark4]
+//  jacS -- Jacobian of the stiff part dfS/dy [may be nil ⇒ numerical Jacobian]
+//  fN   -- non-stiff part; e.g. advection and reaction [treated explicitly]
+//  NOTE: (1) the split is required by the IMEX methods ark3 and ark4 and by the exponential
+//            method etdrk4; with etdrk4, fS must be linear; i.e. fS = [L]⋅{y} with constant [L]
+//        (2) the function fcn given to NewSolver may be nil; in this case, f = fS + fN is used
+func (o *Config) SetSplit(fS Func, jacS JacF, fN Func) {
+	if fS == nil || fN == nil {
+		chk.Panic("the stiff and non-stiff parts of the split right-hand side must not be nil\n")
+	}
+	o.splitS, o.splitJacS, o.splitN = fS, jacS, fN
+}
+
+// inErr returns whether the i-th variable is included in the local error estimate
+func (o *Config) inErr(i int) bool {
+	return !(o.SuppressAlg && o.algebraic != nil && o.algebraic[i])
+}
+
+// nErr returns the number of variables included in the local error estimate
+func (o *Config) nErr(ndim int) (n int) {
+	for i := 0; i < ndim; i++ {
+		if o.inErr(i) {
+			n++
+		}
+	}
+	return
+}
+
+// needDense returns whether the dense output of the ODE method is required
+func (o *Config) needDense() bool {
+	return o.denseOut || o.denseF != nil || len(o.events) > 0
+}
+
 // GetSpArgs returns arguments for sparse solvers
 func (o *Config) GetSpArgs() *la.SpArgs {
 	return &la.SpArgs{Symmetric: o.Symmetric, Verbose: o.LsVerbose, Ordering: o.Ordering, Scaling: o.Scaling, Guess: nil, Communicator: o.comm}
@@ -0,0 +1,128 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/num"
+)
+
+// ConsistentInit computes consistent initial values of differential-algebraic equations (DAE) of
+// index 1 in semi-explicit form
+//
+//   [M] ⋅ d{y}/dx = {f}(x, {y})
+//
+//   The algebraic equations correspond to the rows of [M] with zero entries and the algebraic
+//   variables are given by Config.SetAlgebraic (or correspond to the columns of [M] with zero
+//   entries if SetAlgebraic has not been called). The differential variables {yd} are kept fixed
+//   and the algebraic variables {ya} are computed by solving (with num.NlSolver)
+//
+//     {fa}(x, {yd}, {ya}) = {0}
+//
+//   where {fa} are the algebraic equations. The index-1 condition requires d{fa}/d{ya} to be
+//   non-singular.
+//
+//  INPUT:
+//    y    -- initial values; the algebraic components are used as initial guess
+//    x    -- initial x
+//    conf -- configuration parameters (algebraic flags)
+//    fcn  -- f(x,y) function
+//    jac  -- Jacobian: df/dy function [may be nil ⇒ use numerical Jacobian]
+//    M    -- "mass" matrix
+//
+//  OUTPUT:
+//    y -- consistent initial values (only the algebraic components are modified)
+//
+func ConsistentInit(y la.Vector, x float64, conf *Config, fcn Func, jac JacF, M *la.Triplet) {
+
+	// check
+	if M == nil {
+		chk.Panic("the mass matrix is required to find the algebraic equations\n")
+	}
+	ndim := len(y)
+	Md := M.ToDense()
+	if Md.M != ndim || Md.N != ndim {
+		chk.Panic("the mass matrix (%d×%d) must be ndim×ndim = %d×%d\n", Md.M, Md.N, ndim, ndim)
+	}
+
+	// algebraic equations (zero rows) and variables (flags or zero columns)
+	var eqs, vars []int
+	for i := 0; i < ndim; i++ {
+		zeroRow, zeroCol := true, true
+		for j := 0; j < ndim; j++ {
+			if Md.Get(i, j) != 0 {
+				zeroRow = false
+			}
+			if Md.Get(j, i) != 0 {
+				zeroCol = false
+			}
+		}
+		if zeroRow {
+			eqs = append(eqs, i)
+		}
+		if conf.algebraic != nil {
+			if conf.algebraic[i] {
+				vars = append(vars, i)
+			}
+		} else if zeroCol {
+			vars = append(vars, i)
+		}
+	}
+	if len(eqs) != len(vars) {
+		chk.Panic("the number of algebraic equations (%d) must be equal to the number of algebraic variables (%d)\n", len(eqs), len(vars))
+	}
+	na := len(eqs)
+	if na == 0 {
+		return
+	}
+
+	// residual of algebraic equations
+	yy := y.GetCopy()
+	f := la.NewVector(ndim)
+	ffcn := func(fa, ya la.Vector) {
+		for k, i := range vars {
+			yy[i] = ya[k]
+		}
+		fcn(f, 0, x, yy)
+		for k, i := range eqs {
+			fa[k] = f[i]
+		}
+	}
+
+	// Jacobian of algebraic equations
+	var jfcn func(dfady *la.Triplet, ya la.Vector)
+	if jac != nil {
+		dfdy := new(la.Triplet)
+		jfcn = func(dfady *la.Triplet, ya la.Vector) {
+			for k, i := range vars {
+				yy[i] = ya[k]
+			}
+			jac(dfdy, 0, x, yy)
+			D := dfdy.ToDense()
+			dfady.Start()
+			for k, i := range eqs {
+				for l, j := range vars {
+					if v := D.Get(i, j); v != 0 {
+						dfady.Put(k, l, v)
+					}
+				}
+			}
+		}
+	}
+
+	// solve
+	ya := la.NewVector(na)
+	for k, i := range vars {
+		ya[k] = y[i]
+	}
+	var nls num.NlSolver
+	defer nls.Free()
+	nls.Init(na, ffcn, jfcn, nil, false, jac == nil, map[string]float64{"atol": 1e-10, "rtol": 1e-10, "ftol": 1e-12})
+	nls.Solve(ya, true)
+	for k, i := range vars {
+		y[i] = ya[k]
+	}
+}
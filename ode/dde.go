@@ -0,0 +1,341 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"sort"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// denseDegree holds the degree of the dense output polynomials of the ODE methods
+var denseDegree = map[string]int{
+	"dopri5":  4,
+	"dopri8":  7,
+	"radau5":  3,
+	"bdf":     5,
+	"ndf":     5,
+	"exprb32": 3,
+}
+
+// Delay defines a constant or state-dependent delay
+type Delay struct {
+	Tau float64 // constant delay τ > 0 (used if F is nil)
+	F   DelayF  // state-dependent delay τ(x,y) ≥ 0 [may be nil ⇒ constant]
+}
+
+// calc computes the delay τ(x,y)
+//  NOTE: negative values (e.g. due to inaccurate trial stages) are replaced by zero
+func (o *Delay) calc(x float64, y la.Vector) (τ float64) {
+	if o.F == nil {
+		return o.Tau
+	}
+	return utl.Max(o.F(x, y), 0)
+}
+
+// DDE implements a solver for delay differential equations
+//
+//   d{y}/dx = {f}(x, {y}(x), {y}(x-τ0), {y}(x-τ1), ...)     with     {y}(x) = {φ}(x) for x ≤ x0
+//
+//   where the delays τi may be constant or depend on x and {y}(x). The equations are solved by
+//   the ODE methods with dense output (dopri5, dopri8, radau5, bdf and ndf). The lagged values
+//   are computed from the history function φ for x-τi ≤ x0; otherwise, they are computed from
+//   the dense output (continuous extension) of the accepted steps, which is recorded after each
+//   step. When x-τi falls within the current step (e.g. vanishing delays), the dense output of
+//   the last accepted step is extrapolated.
+//
+//   Discontinuities of the solution (or its derivatives) at x0 propagate to the points where
+//   x-τi(x,y) crosses previous discontinuities. These points are tracked by means of (terminal)
+//   events; thus the integration is restarted at each discontinuity up to Nlevels levels of
+//   propagation. The lagged arguments x-τi are assumed to be non-decreasing.
+//
+//   NOTE: (1) the located discontinuities are also recorded in the Output as events with index
+//             k = len(conf events) + i, where i is the index of the delay
+//         (2) the Jacobian (for implicit methods) is computed numerically and disregards the
+//             dependence of f on the lagged values
+//
+//   References:
+//     [1] Bellen A, Zennaro M (2003) Numerical Methods for Delay Differential Equations. Oxford
+//         University Press, 395p
+//     [2] Shampine LF, Thompson S (2001) Solving DDEs in MATLAB. Applied Numerical Mathematics,
+//         37(4):441-458
+//
+type DDE struct {
+
+	// input
+	Nlevels int // maximum level of propagated discontinuities [default = 5]
+
+	// output
+	Sol       *Solver   // ODE solver
+	DiscX     []float64 // x at discontinuities (x0 and points where x-τi crosses discontinuities)
+	DiscLevel []int     // level of discontinuities (x0 has level 0)
+
+	// problem definition
+	ndim   int         // size of y
+	fcn    DdeF        // dy/dx = f(x, y, ylag)
+	hist   HistoryF    // history function
+	delays []*Delay    // delays
+	ylag   []la.Vector // lagged values [ndelays][ndim]
+	x0     float64     // initial x
+	y0     la.Vector   // initial y
+	nuser  int         // number of events given in conf
+	target []int       // index of next discontinuity to be crossed by x-τi [-1 ⇒ none]
+	tol    float64     // tolerance to compare x values of discontinuities
+
+	// history of accepted steps (samples of the dense output at Chebyshev-Lobatto nodes)
+	deg int         // degree of the dense output polynomial
+	θs  []float64   // nodes in [0,1] [deg+1]
+	wb  []float64   // barycentric weights [deg+1]
+	hx  []float64   // x at the beginning of steps
+	hh  []float64   // stepsizes
+	hy  []la.Vector // samples [nsteps][(deg+1)⋅ndim]
+	nh  int         // number of recorded steps
+}
+
+// NewDDE returns a new DDE solver
+//
+//  INPUT:
+//    ndim    -- problem dimension
+//    conf    -- configuration parameters (variable steps only)
+//    fcn     -- f(x, y, ylag) = dy/dx function
+//    history -- history function φ(x) for x ≤ x0
+//    delays  -- delays
+//
+//  NOTE: remember to call Free() to release allocated resources (e.g. from the linear solvers)
+//
+func NewDDE(ndim int, conf *Config, fcn DdeF, history HistoryF, delays ...*Delay) (o *DDE) {
+
+	// check
+	if len(delays) < 1 {
+		chk.Panic("at least one delay is required\n")
+	}
+	if conf.fixed {
+		chk.Panic("delay differential equations cannot be solved with fixed steps\n")
+	}
+	deg, ok := denseDegree[conf.method]
+	if !ok {
+		chk.Panic("method %q cannot be used to solve delay differential equations because it has no dense output\n", conf.method)
+	}
+
+	// data
+	o = new(DDE)
+	o.Nlevels = 5
+	o.ndim = ndim
+	o.fcn = fcn
+	o.hist = history
+	o.delays = delays
+	o.ylag = make([]la.Vector, len(delays))
+	for i := range delays {
+		o.ylag[i] = la.NewVector(ndim)
+	}
+	o.y0 = la.NewVector(ndim)
+	o.target = make([]int, len(delays))
+	o.tol = 1e3 * conf.EventTol
+
+	// nodes and barycentric weights
+	o.deg = deg
+	o.θs = make([]float64, deg+1)
+	o.wb = make([]float64, deg+1)
+	for j := 0; j <= deg; j++ {
+		o.θs[j] = (1 - math.Cos(float64(j)*math.Pi/float64(deg))) / 2
+		o.wb[j] = 1 - 2*float64(j%2)
+		if j == 0 || j == deg {
+			o.wb[j] /= 2
+		}
+	}
+
+	// events: user events and discontinuities
+	c := new(Config)
+	*c = *conf
+	c.events = nil
+	o.nuser = len(conf.events)
+	for _, ev := range conf.events {
+		e := *ev
+		if ev.Terminal && ev.Reset != nil {
+			reset := ev.Reset
+			e.Reset = func(k int, x float64, y la.Vector) (stop bool) {
+				stop = reset(k, x, y)
+				if !stop { // y has been modified
+					o.addDisc(x, 0)
+					o.setTargets(x, y)
+				}
+				return
+			}
+		}
+		c.events = append(c.events, &e)
+	}
+	for i, d := range delays {
+		i, d := i, d
+		c.events = append(c.events, &Event{
+			G: func(x float64, y la.Vector) float64 {
+				if o.target[i] < 0 {
+					return -1
+				}
+				return x - d.calc(x, y) - o.DiscX[o.target[i]]
+			},
+			Direction: 1,
+			Terminal:  true,
+			Reset: func(k int, x float64, y la.Vector) (stop bool) {
+				o.addDisc(x, o.levelAt(x, y)+1)
+				o.setTargets(x, y)
+				return
+			},
+		})
+	}
+
+	// solver
+	o.Sol = NewSolver(ndim, c, func(f la.Vector, h, x float64, y la.Vector) {
+		for i, d := range o.delays {
+			o.lagged(o.ylag[i], x-d.calc(x, y))
+		}
+		o.fcn(f, h, x, y, o.ylag)
+	}, nil, nil)
+	o.Sol.stepDone = o.record
+	return
+}
+
+// Free releases allocated memory
+func (o *DDE) Free() {
+	o.Sol.Free()
+}
+
+// Solve solves the delay differential equations from x to xf with initial y = y(x) given in y
+//  NOTE: y(x) may differ from the history φ(x); i.e. the solution may be discontinuous at x
+func (o *DDE) Solve(y la.Vector, x, xf float64) {
+	o.x0 = x
+	o.y0.Apply(1, y)
+	o.nh = 0
+	o.DiscX = []float64{x}
+	o.DiscLevel = []int{0}
+	o.setTargets(x, y)
+	o.Sol.Solve(y, x, xf)
+}
+
+// GetLagged computes y(x) using the history function or the recorded dense output
+//  NOTE: this function must be called after Solve and x must be ≤ the final x
+func (o *DDE) GetLagged(y la.Vector, x float64) {
+	o.lagged(y, x)
+}
+
+// lagged computes y(x) with x ≤ current x (or slightly beyond; i.e. within the current step)
+func (o *DDE) lagged(y la.Vector, x float64) {
+
+	// history
+	if x < o.x0 {
+		o.hist(y, x)
+		return
+	}
+	if o.nh == 0 {
+		y.Apply(1, o.y0)
+		return
+	}
+
+	// find step
+	j := sort.SearchFloat64s(o.hx[:o.nh], x) // hx[j] ≥ x
+	if j == o.nh || o.hx[j] > x {
+		j--
+	}
+	if j < 0 {
+		j = 0
+	}
+
+	// barycentric interpolation
+	θ := (x - o.hx[j]) / o.hh[j]
+	yj := o.hy[j]
+	n := o.ndim
+	var den float64
+	for k := 0; k <= o.deg; k++ {
+		if θ == o.θs[k] {
+			copy(y, yj[k*n:(k+1)*n])
+			return
+		}
+	}
+	y.Fill(0)
+	for k := 0; k <= o.deg; k++ {
+		c := o.wb[k] / (θ - o.θs[k])
+		den += c
+		for m := 0; m < n; m++ {
+			y[m] += c * yj[k*n+m]
+		}
+	}
+	for m := 0; m < n; m++ {
+		y[m] /= den
+	}
+}
+
+// record records the dense output of the accepted step (x-h,x]
+func (o *DDE) record(h, x float64, y la.Vector) {
+
+	// remove steps after the beginning of this step (e.g. after restart at a terminal event)
+	xa := x - h
+	for o.nh > 0 && o.hx[o.nh-1] >= xa-o.tol {
+		o.nh--
+	}
+
+	// allocate
+	n := o.ndim
+	if o.nh == len(o.hx) {
+		o.hx = append(o.hx, 0)
+		o.hh = append(o.hh, 0)
+		o.hy = append(o.hy, la.NewVector((o.deg+1)*n))
+	}
+
+	// sample dense output
+	o.hx[o.nh] = xa
+	o.hh[o.nh] = h
+	yj := o.hy[o.nh]
+	for k := 0; k < o.deg; k++ {
+		o.Sol.rkm.DenseOut(yj[k*n:(k+1)*n], h, x, y, xa+o.θs[k]*h)
+	}
+	copy(yj[o.deg*n:], y)
+	o.nh++
+}
+
+// addDisc adds a discontinuity at x
+func (o *DDE) addDisc(x float64, level int) {
+	for i, xd := range o.DiscX {
+		if math.Abs(xd-x) <= o.tol {
+			if level < o.DiscLevel[i] {
+				o.DiscLevel[i] = level
+			}
+			return
+		}
+	}
+	o.DiscX = append(o.DiscX, x)
+	o.DiscLevel = append(o.DiscLevel, level)
+}
+
+// levelAt returns the minimum level of the discontinuities crossed by x-τi(x,y) at x
+func (o *DDE) levelAt(x float64, y la.Vector) (level int) {
+	level = o.Nlevels
+	for _, d := range o.delays {
+		α := x - d.calc(x, y)
+		for i, xd := range o.DiscX {
+			if math.Abs(xd-α) <= o.tol && o.DiscLevel[i] < level {
+				level = o.DiscLevel[i]
+			}
+		}
+	}
+	return
+}
+
+// setTargets sets the next discontinuities to be crossed by x-τi(x,y)
+func (o *DDE) setTargets(x float64, y la.Vector) {
+	for i, d := range o.delays {
+		α := x - d.calc(x, y)
+		o.target[i] = -1
+		for j, xd := range o.DiscX {
+			if o.DiscLevel[j] >= o.Nlevels || xd <= α+o.tol {
+				continue
+			}
+			if o.target[i] < 0 || xd < o.DiscX[o.target[i]] {
+				o.target[i] = j
+			}
+		}
+	}
+}
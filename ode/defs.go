@@ -69,3 +69,101 @@ type DenseOutF func(istep int, h, x float64, y la.Vector, xout float64, yout la.
 
 // YanaF defines a function to be used when computing analytical solutions
 type YanaF func(res []float64, x float64)
+
+// EventF defines an event function g(x,y); an event occurs when g crosses zero
+//
+//   INPUT:
+//     x -- scalar variable
+//     y -- vector variable
+//
+//   OUTPUT:
+//     g -- value of the event function
+//
+type EventF func(x float64, y la.Vector) float64
+
+// EventResetF defines a function to be called at terminal events; it may modify y in order to
+// restart the integration from the event; e.g. to reverse the velocity after an impact
+//
+//   INPUT:
+//     k -- index of event
+//     x -- x at event
+//     y -- y at event [may be modified]
+//
+//   OUTPUT:
+//     stop -- stop simulation (nicely)
+//
+type EventResetF func(k int, x float64, y la.Vector) (stop bool)
+
+// DfdpF defines the derivatives of Func with respect to a set of parameters {p}
+//
+//   INPUT:
+//     h -- current stepsize = dx
+//     x -- current x
+//     y -- current {y}
+//
+//   OUTPUT:
+//     dfdp -- matrix d{f}/d{p} [ndim][np] (pre-allocated and filled with zeros)
+//
+type DfdpF func(dfdp *la.Matrix, h, x float64, y la.Vector)
+
+// PhiF defines the terms φk of a scalar functional of the solution given by
+//
+//   Ψ = Σ_k φk(xk, {y}(xk), {p})
+//
+//   INPUT:
+//     k -- index of term; i.e. index of xk in the list of observation points
+//     x -- xk
+//     y -- {y}(xk)
+//
+//   OUTPUT:
+//     dphidy -- d{φk}/d{y} [ndim] (pre-allocated and filled with zeros)
+//     dphidp -- ∂{φk}/∂{p} [np] (pre-allocated and filled with zeros)
+//     phi    -- φk
+//
+type PhiF func(dphidy, dphidp la.Vector, k int, x float64, y la.Vector) (phi float64)
+
+// AccF defines the right-hand side of second-order systems d²{q}/dx² = {a}(x, {q}); e.g. the
+// acceleration of mechanical systems with forces depending on positions only
+//
+//   INPUT:
+//     x -- current x
+//     q -- current {q}
+//
+//   OUTPUT:
+//     a -- {a}(x, {q})
+//
+type AccF func(a la.Vector, x float64, q la.Vector)
+
+// DdeF defines the main function of delay differential equations
+//
+//   d{y}/dx = {f}(x, {y}(x), {y}(x-τ0), {y}(x-τ1), ...)
+//
+//   INPUT:
+//     h    -- current stepsize = dx
+//     x    -- current x
+//     y    -- current {y}
+//     ylag -- lagged values {y}(x-τi) [ndelays][ndim]
+//
+//   OUTPUT:
+//     f -- {f}(x, {y}, {ylag})
+//
+type DdeF func(f la.Vector, h, x float64, y la.Vector, ylag []la.Vector)
+
+// DelayF defines a state-dependent delay τ(x,y) ≥ 0
+type DelayF func(x float64, y la.Vector) float64
+
+// HistoryF defines the history function; i.e. the values of {y}(x) for x ≤ x0
+type HistoryF func(y la.Vector, x float64)
+
+// BcF defines the two-point boundary conditions of boundary value problems
+//
+//   {g}({ya}, {yb}) = {0}
+//
+//   INPUT:
+//     ya -- {y} at the left boundary x = a
+//     yb -- {y} at the right boundary x = b
+//
+//   OUTPUT:
+//     g -- residuals of the boundary conditions [ndim]
+//
+type BcF func(g, ya, yb la.Vector)
@@ -0,0 +1,141 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"sort"
+
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/num"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// Event defines an event; i.e. a zero crossing of g(x,y) during the integration
+//
+//   The events are detected between accepted steps by checking the sign of g at the beginning
+//   and at the end of the step; then, the event is localised with Brent's method using the
+//   dense output of the ODE method. Therefore, the ODE method must have dense output.
+//
+//   NOTE: if g changes sign more than once within a step, the events may be missed
+//
+type Event struct {
+	G         EventF      // event function g(x,y)
+	Direction int         // direction of crossing: 0 ⇒ any; +1 ⇒ g increasing only; -1 ⇒ g decreasing only
+	Terminal  bool        // terminal event: stop the integration or restart it after calling Reset
+	Reset     EventResetF // function to modify y at terminal events [may be nil ⇒ stop]
+}
+
+// eventDetector detects and localises events between accepted steps
+type eventDetector struct {
+	list  []*Event   // events
+	g0    []float64  // g values at the beginning of the step
+	g1    []float64  // g values at the end of the step
+	ye    la.Vector  // y at event (or trial x)
+	brent *num.Brent // root finder
+	dout  func(yout la.Vector, h, x float64, y la.Vector, xout float64)
+	ge    EventF    // current event function
+	sc    float64   // scaling factor for g
+	h     float64   // current stepsize (for dense output)
+	x1    float64   // x at the end of the step (for dense output)
+	y1    la.Vector // y at the end of the step (for dense output)
+}
+
+// eventFound holds data of an event found within a step
+type eventFound struct {
+	k int     // index of event
+	x float64 // x at event
+}
+
+// newEventDetector returns a new event detector
+func newEventDetector(ndim int, conf *Config) (o *eventDetector) {
+	o = new(eventDetector)
+	o.list = conf.events
+	o.g0 = make([]float64, len(o.list))
+	o.g1 = make([]float64, len(o.list))
+	o.ye = la.NewVector(ndim)
+	o.brent = num.NewBrent(func(x float64) float64 {
+		o.dout(o.ye, o.h, o.x1, o.y1, x)
+		return o.ge(x, o.ye) / o.sc
+	}, nil)
+	o.brent.Tol = conf.EventTol
+	return
+}
+
+// start computes the values of the event functions at the beginning of the integration
+func (o *eventDetector) start(x float64, y la.Vector) {
+	for k, ev := range o.list {
+		o.g0[k] = ev.G(x, y)
+	}
+}
+
+// restart re-computes the values of the event functions after a terminal event at x
+func (o *eventDetector) restart(found []eventFound, x float64, y la.Vector) {
+	o.start(x, y)
+	for _, f := range found {
+		if math.Abs(f.x-x) <= o.brent.Tol {
+			o.g0[f.k] = 0 // the integration starts exactly at the root of g
+		}
+	}
+}
+
+// find finds the events within the step (x0,x1] with x0 = x1 - h. The events are sorted by x and
+// the list ends at the first terminal event, if any (including events at the same x)
+func (o *eventDetector) find(h, x1 float64, y1 la.Vector) (found []eventFound) {
+	o.h, o.x1, o.y1 = h, x1, y1
+	x0 := x1 - h
+	for k, ev := range o.list {
+		g0 := o.g0[k]
+		g1 := ev.G(x1, y1)
+		o.g1[k] = g1
+		up := g0 < 0 && g1 >= 0
+		down := g0 > 0 && g1 <= 0
+		if !(up && ev.Direction >= 0) && !(down && ev.Direction <= 0) {
+			continue
+		}
+		found = append(found, eventFound{k, o.locate(ev.G, x0, x1, g1)})
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].x < found[j].x })
+	for i, f := range found {
+		if o.list[f.k].Terminal {
+			n := i + 1
+			for n < len(found) && found[n].x-f.x <= o.brent.Tol {
+				n++
+			}
+			found = found[:n]
+			break
+		}
+	}
+	o.g0, o.g1 = o.g1, o.g0
+	return
+}
+
+// locate finds the root of g within [x0,x1] using the dense output
+func (o *eventDetector) locate(g EventF, x0, x1, g1 float64) float64 {
+	if g1 == 0 {
+		return x1
+	}
+	o.dout(o.ye, o.h, o.x1, o.y1, x0)
+	ga := g(x0, o.ye)
+	o.sc = utl.Max(math.Abs(ga), math.Abs(g1))
+	if ga*g1/(o.sc*o.sc) >= -num.MACHEPS { // root at one end (within round-off)
+		if math.Abs(ga) < math.Abs(g1) {
+			return x0
+		}
+		return x1
+	}
+	o.ge = g
+	return o.brent.Root(x0, x1)
+}
+
+// calcY computes y at x within the current step using the dense output
+func (o *eventDetector) calcY(x float64) la.Vector {
+	if x == o.x1 {
+		o.ye.Apply(1, o.y1)
+	} else {
+		o.dout(o.ye, o.h, o.x1, o.y1, x)
+	}
+	return o.ye
+}
@@ -0,0 +1,437 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/num"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// ETDRK4 implements the fourth-order exponential time differencing Runge-Kutta method of Cox and
+// Matthews [1] for split systems with linear stiff part
+//
+//   d{y}/dx = [L]⋅{y} + {N}(x, {y})
+//
+//   where [L] = dfS/dy is computed once (at the beginning of the integration) and {N} = {fN}
+//   (see Config.SetSplit). With φₖ = φₖ(h L), the update is given by
+//
+//     {a} = e^{hL/2}⋅{y} + h/2 φ₁(hL/2)⋅{N}(x, {y})
+//     {b} = e^{hL/2}⋅{y} + h/2 φ₁(hL/2)⋅{N}(x + h/2, {a})
+//     {c} = e^{hL/2}⋅{a} + h/2 φ₁(hL/2)⋅(2 {N}(x + h/2, {b}) - {N}(x, {y}))
+//     {y} ← e^{hL}⋅{y} + h (φ₁ - 3φ₂ + 4φ₃)⋅{N}y + 2h (φ₂ - 2φ₃)⋅({N}a + {N}b) + h (4φ₃ - φ₂)⋅{N}c
+//
+//   The φ-functions are computed by la.MatPhi (dense matrices); thus the linear stiff part is
+//   treated exactly. This method can only be used with fixed steps.
+//
+//   References:
+//     [1] Cox SM and Matthews PC (2002) Exponential time differencing for stiff systems. Journal
+//         of Computational Physics, 176:430-455
+//     [2] Kassam AK and Trefethen LN (2005) Fourth-order time-stepping for stiff PDEs. SIAM J.
+//         Sci. Comput. 26(4):1214-1233
+//
+type ETDRK4 struct {
+
+	// data
+	ndim int     // problem dimension
+	conf *Config // configuration
+	work *rkwork // workspace
+	stat *Stat   // statistics
+	fS   Func    // stiff (linear) part
+	jacS JacF    // Jacobian of the stiff part [may be nil]
+	fN   Func    // non-stiff part
+
+	// matrices
+	hL  float64     // stepsize corresponding to the matrices below
+	E   *la.Matrix  // e^{hL}
+	E2  *la.Matrix  // e^{hL/2}
+	P2  *la.Matrix  // h/2 φ₁(hL/2)
+	F1  *la.Matrix  // h (φ₁ - 3φ₂ + 4φ₃)
+	F2  *la.Matrix  // 2h (φ₂ - 2φ₃)
+	F3  *la.Matrix  // h (4φ₃ - φ₂)
+	dfS *la.Triplet // dfS/dy
+
+	// stages
+	ny, na, nb, nc la.Vector // N at stages
+	a, b, c        la.Vector // stage values
+	v, w           la.Vector // workspace and updated y
+}
+
+// ExpRB32 implements the exponential Rosenbrock method exprb32 of Hochbruck, Ostermann and
+// Schweitzer [1] with variable steps
+//
+//   The Jacobian [J] = df/dy and {v} = ∂f/∂x are computed at the beginning of each step and
+//   the method is applied to d{y}/dx = [J]⋅{y} + {v}⋅x + {g}(x, {y}). With φₖ = φₖ(h J):
+//
+//     {u} = {y} + h φ₁⋅{f}(x, {y}) + h² φ₂⋅{v}                    (exponential Rosenbrock-Euler)
+//     {d} = {f}(x + h, {u}) - {f}(x, {y}) - [J]⋅({u} - {y}) - h {v}
+//     {y} ← {u} + 2h φ₃⋅{d}
+//
+//   The method is of order 3 and the local error is estimated with the embedded exponential
+//   Rosenbrock-Euler method of order 2; i.e. lerr = 2h φ₃⋅{d}. The products of φ-functions and
+//   vectors are computed using the exponential of an augmented matrix [2] of size ndim + 3.
+//   The dense output is computed by cubic Hermite interpolation.
+//
+//   NOTE: since [J] is dense, this method is appropriate to small and moderate systems
+//
+//   References:
+//     [1] Hochbruck M, Ostermann A and Schweitzer J (2009) Exponential Rosenbrock-type methods.
+//         SIAM J. Numer. Anal. 47(1):786-803
+//     [2] Al-Mohy AH and Higham NJ (2011) Computing the action of the matrix exponential, with an
+//         application to exponential integrators. SIAM J. Sci. Comput. 33(2):488-511
+//
+type ExpRB32 struct {
+
+	// data
+	ndim int     // problem dimension
+	conf *Config // configuration
+	work *rkwork // workspace
+	stat *Stat   // statistics
+	fcn  Func    // dy/dx := f(x,y)
+	jac  JacF    // Jacobian: df/dy [may be nil]
+
+	// step data
+	xa   float64     // x at the beginning of the step
+	dfdy *la.Triplet // df/dy
+	J    *la.Matrix  // dense df/dy
+	v    la.Vector   // ∂f/∂x
+	u    la.Vector   // exponential Rosenbrock-Euler solution
+	d    la.Vector   // defect
+	fu   la.Vector   // f(x+h, u)
+	w    la.Vector   // updated y
+	lerr la.Vector   // local error
+	ws   []la.Vector // vectors multiplied by the φ-functions [3][ndim]
+
+	// step control
+	n    float64 // exponent n = 1/(q+1)
+	dmin float64 // dmin = 1/Mmin
+	dmax float64 // dmax = 1/Mmax
+
+	// dense output
+	yd0 la.Vector // y at the beginning of the last accepted step
+	fd0 la.Vector // f at the beginning of the last accepted step
+}
+
+// add methods to database
+func init() {
+	rkmDB["etdrk4"] = func() rkmethod { return new(ETDRK4) }
+	rkmDB["exprb32"] = func() rkmethod { return new(ExpRB32) }
+}
+
+// ETDRK4 //////////////////////////////////////////////////////////////////////////////////////////
+
+// Free releases memory
+func (o *ETDRK4) Free() {}
+
+// Info returns information about this method
+func (o *ETDRK4) Info() (fixedOnly, implicit bool, nstages int) {
+	return true, false, 4
+}
+
+// Init initialises structure
+func (o *ETDRK4) Init(ndim int, conf *Config, work *rkwork, stat *Stat, fcn Func, jac JacF, M *la.Triplet) {
+	if M != nil {
+		chk.Panic("exponential methods cannot handle M matrix\n")
+	}
+	if conf.splitS == nil {
+		chk.Panic("method %q requires a split right-hand side. call conf.SetSplit first\n", conf.method)
+	}
+	if conf.needDense() {
+		chk.Panic("dense output is not available for %q\n", conf.method)
+	}
+	o.ndim = ndim
+	o.conf = conf
+	o.work = work
+	o.stat = stat
+	o.fS, o.jacS, o.fN = conf.splitS, conf.splitJacS, conf.splitN
+	o.E = la.NewMatrix(ndim, ndim)
+	o.E2 = la.NewMatrix(ndim, ndim)
+	o.P2 = la.NewMatrix(ndim, ndim)
+	o.F1 = la.NewMatrix(ndim, ndim)
+	o.F2 = la.NewMatrix(ndim, ndim)
+	o.F3 = la.NewMatrix(ndim, ndim)
+	o.dfS = new(la.Triplet)
+	o.ny, o.na, o.nb, o.nc = la.NewVector(ndim), la.NewVector(ndim), la.NewVector(ndim), la.NewVector(ndim)
+	o.a, o.b, o.c = la.NewVector(ndim), la.NewVector(ndim), la.NewVector(ndim)
+	o.v, o.w = la.NewVector(ndim), la.NewVector(ndim)
+}
+
+// Accept accepts update
+func (o *ETDRK4) Accept(y0 la.Vector, x0 float64) (dxnew float64) {
+	y0.Apply(1, o.w)
+	return
+}
+
+// Reject processes step rejection
+func (o *ETDRK4) Reject() (dxnew float64) {
+	return
+}
+
+// DenseOut produces dense output (after Accept)
+func (o *ETDRK4) DenseOut(yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	chk.Panic("dense output is not available for %q\n", o.conf.method)
+}
+
+// Step steps update
+func (o *ETDRK4) Step(x0 float64, y0 la.Vector) {
+
+	// matrices
+	h := o.work.h
+	if o.work.first || h != o.hL {
+		o.calcMatrices(h, x0, y0)
+	}
+
+	// stages
+	o.stat.Nfeval++
+	o.fN(o.ny, h, x0, y0)
+	la.MatVecMul(o.a, 1, o.E2, y0)
+	la.MatVecMulAdd(o.a, 1, o.P2, o.ny) // a := E2⋅y + P2⋅Ny
+
+	o.stat.Nfeval++
+	o.fN(o.na, h, x0+h/2, o.a)
+	la.MatVecMul(o.b, 1, o.E2, y0)
+	la.MatVecMulAdd(o.b, 1, o.P2, o.na) // b := E2⋅y + P2⋅Na
+
+	o.stat.Nfeval++
+	o.fN(o.nb, h, x0+h/2, o.b)
+	for i := 0; i < o.ndim; i++ {
+		o.v[i] = 2*o.nb[i] - o.ny[i]
+	}
+	la.MatVecMul(o.c, 1, o.E2, o.a)
+	la.MatVecMulAdd(o.c, 1, o.P2, o.v) // c := E2⋅a + P2⋅(2Nb - Ny)
+
+	o.stat.Nfeval++
+	o.fN(o.nc, h, x0+h, o.c)
+
+	// update
+	la.VecAdd(o.v, 1, o.na, 1, o.nb)
+	la.MatVecMul(o.w, 1, o.E, y0)
+	la.MatVecMulAdd(o.w, 1, o.F1, o.ny)
+	la.MatVecMulAdd(o.w, 1, o.F2, o.v)
+	la.MatVecMulAdd(o.w, 1, o.F3, o.nc)
+}
+
+// calcMatrices computes [L] and the matrices of φ-functions
+func (o *ETDRK4) calcMatrices(h, x0 float64, y0 la.Vector) {
+
+	// linear operator
+	o.stat.Njeval++
+	if o.jacS == nil {
+		o.stat.Nfeval++
+		o.fS(o.v, h, x0, y0)
+		num.Jacobian(o.dfS, func(fy, yy la.Vector) {
+			o.fS(fy, h, x0, yy)
+		}, y0, o.v, o.w)
+		o.stat.Nfeval += o.ndim
+	} else {
+		o.jacS(o.dfS, h, x0, y0)
+	}
+	L := o.dfS.ToDense()
+
+	// φ-functions of hL/2
+	n := o.ndim
+	phi := []*la.Matrix{o.E2, o.P2}
+	hL := la.NewMatrix(n, n)
+	L.CopyInto(hL, h/2)
+	la.MatPhi(phi, hL)
+	o.P2.CopyInto(o.P2, h/2)
+
+	// φ-functions of hL
+	phi = []*la.Matrix{o.E, la.NewMatrix(n, n), la.NewMatrix(n, n), la.NewMatrix(n, n)}
+	L.CopyInto(hL, h)
+	la.MatPhi(phi, hL)
+	la.MatAdd(o.F1, h, phi[1], -3*h, phi[2])
+	la.MatAdd(o.F1, 1, o.F1, 4*h, phi[3])
+	la.MatAdd(o.F2, 2*h, phi[2], -4*h, phi[3])
+	la.MatAdd(o.F3, 4*h, phi[3], -h, phi[2])
+	o.hL = h
+}
+
+// ExpRB32 /////////////////////////////////////////////////////////////////////////////////////////
+
+// Free releases memory
+func (o *ExpRB32) Free() {}
+
+// Info returns information about this method
+func (o *ExpRB32) Info() (fixedOnly, implicit bool, nstages int) {
+	return false, false, 2
+}
+
+// Init initialises structure
+func (o *ExpRB32) Init(ndim int, conf *Config, work *rkwork, stat *Stat, fcn Func, jac JacF, M *la.Triplet) {
+	if M != nil {
+		chk.Panic("exponential methods cannot handle M matrix\n")
+	}
+	o.ndim = ndim
+	o.conf = conf
+	o.work = work
+	o.stat = stat
+	o.fcn = fcn
+	o.jac = jac
+	o.dfdy = new(la.Triplet)
+	o.v = la.NewVector(ndim)
+	o.u = la.NewVector(ndim)
+	o.d = la.NewVector(ndim)
+	o.fu = la.NewVector(ndim)
+	o.w = la.NewVector(ndim)
+	o.lerr = la.NewVector(ndim)
+	o.ws = []la.Vector{la.NewVector(ndim), la.NewVector(ndim), la.NewVector(ndim)}
+	o.n = 1.0 / 3.0
+	o.dmin = 1.0 / conf.Mmin
+	o.dmax = 1.0 / conf.Mmax
+	if conf.needDense() {
+		o.yd0 = la.NewVector(ndim)
+		o.fd0 = la.NewVector(ndim)
+	}
+}
+
+// Accept accepts update and computes next stepsize
+func (o *ExpRB32) Accept(y0 la.Vector, x0 float64) (dxnew float64) {
+
+	// store data for future dense output
+	if o.conf.needDense() {
+		o.yd0.Apply(1, y0)
+		o.fd0.Apply(1, o.work.f0)
+	}
+
+	// update y and f0
+	y0.Apply(1, o.w)
+	o.stat.Nfeval++
+	o.fcn(o.work.f0, o.work.h, o.xa+o.work.h, y0)
+
+	// estimate new stepsize
+	if o.conf.fixed {
+		return
+	}
+	d := math.Pow(o.work.rerr, o.n)
+	d = utl.Max(o.dmax, utl.Min(o.dmin, d/o.conf.Mfac)) // we require  fac1 <= hnew/h <= fac2
+	dxnew = o.work.h / d
+	return
+}
+
+// Reject processes step rejection and computes next stepsize
+func (o *ExpRB32) Reject() (dxnew float64) {
+	d := math.Pow(o.work.rerr, o.n) / o.conf.Mfac
+	dxnew = o.work.h / utl.Min(o.dmin, d)
+	return
+}
+
+// DenseOut produces dense output (after Accept)
+func (o *ExpRB32) DenseOut(yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	hermiteOut(yout, h, x, y, xout, o.yd0, o.fd0, o.work.f0)
+}
+
+// Step steps update
+func (o *ExpRB32) Step(x0 float64, y0 la.Vector) {
+
+	// f(x,y) at the beginning of the integration with fixed steps (otherwise, computed by Solver
+	// or in Accept)
+	h := o.work.h
+	f0 := o.work.f0
+	o.xa = x0
+	if o.work.first && o.conf.fixed {
+		o.stat.Nfeval++
+		o.fcn(f0, h, x0, y0)
+	}
+
+	// Jacobian and ∂f/∂x (by finite differences)
+	if !o.work.reject {
+		o.stat.Njeval++
+		if o.jac == nil {
+			num.Jacobian(o.dfdy, func(fy, yy la.Vector) {
+				o.fcn(fy, h, x0, yy)
+			}, y0, f0, o.w)
+			o.stat.Nfeval += o.ndim
+		} else {
+			o.jac(o.dfdy, h, x0, y0)
+		}
+		o.J = o.dfdy.ToDense()
+		δ := math.Sqrt(num.MACHEPS * utl.Max(1e-5, math.Abs(x0)))
+		o.stat.Nfeval++
+		o.fcn(o.v, h, x0+δ, y0)
+		for i := 0; i < o.ndim; i++ {
+			o.v[i] = (o.v[i] - f0[i]) / δ
+		}
+	}
+
+	// exponential Rosenbrock-Euler: u = y + h φ₁⋅f + h² φ₂⋅v
+	for i := 0; i < o.ndim; i++ {
+		o.ws[0][i] = h * f0[i]
+		o.ws[1][i] = h * h * o.v[i]
+		o.ws[2][i] = 0
+	}
+	phiVecs(o.u, h, o.J, o.ws[:2])
+	la.VecAdd(o.u, 1, o.u, 1, y0)
+
+	// defect: d = f(x+h, u) - f(x, y) - J⋅(u - y) - h v
+	o.stat.Nfeval++
+	o.fcn(o.fu, h, x0+h, o.u)
+	la.VecAdd(o.d, 1, o.u, -1, y0)
+	la.MatVecMul(o.w, 1, o.J, o.d)
+	for i := 0; i < o.ndim; i++ {
+		o.d[i] = o.fu[i] - f0[i] - o.w[i] - h*o.v[i]
+	}
+
+	// update and local error: lerr = 2h φ₃⋅d
+	for i := 0; i < o.ndim; i++ {
+		o.ws[0][i] = 0
+		o.ws[1][i] = 0
+		o.ws[2][i] = 2 * h * o.d[i]
+	}
+	phiVecs(o.lerr, h, o.J, o.ws)
+	la.VecAdd(o.w, 1, o.u, 1, o.lerr)
+	if o.conf.fixed {
+		return
+	}
+	var sum, sk float64
+	for i := 0; i < o.ndim; i++ {
+		sk = o.conf.atol + o.conf.rtol*utl.Max(math.Abs(y0[i]), math.Abs(o.w[i]))
+		sum += (o.lerr[i] / sk) * (o.lerr[i] / sk)
+	}
+	o.work.rerr = utl.Max(math.Sqrt(sum/float64(o.ndim)), 1.0e-10)
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// phiVecs computes the linear combination of products of φ-functions and vectors
+//
+//   {u} = Σ_{k=1}^{p} φₖ(h J)⋅{w}k     with p = len(w)
+//
+//   using the exponential of the augmented matrix [2, Eq. (2.11)]
+//
+//           ┌           ┐
+//           │ h J   W   │                                ┌ 0  1  0 ┐
+//     Ã  =  │           │    with W = [{w}p … {w}1]  and  K = │ 0  0  1 │ (p×p)
+//           │  0    K   │                                └ 0  0  0 ┘
+//           └           ┘
+//
+//   where {u} corresponds to the first ndim components of the last column of exp(Ã)
+//
+func phiVecs(u la.Vector, h float64, J *la.Matrix, w []la.Vector) {
+	n, p := J.M, len(w)
+	N := n + p
+	A := la.NewMatrix(N, N)
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			A.Set(i, j, h*J.Get(i, j))
+		}
+	}
+	for k := 0; k < p; k++ {
+		for i := 0; i < n; i++ {
+			A.Set(i, n+k, w[p-1-k][i])
+		}
+	}
+	for k := 0; k < p-1; k++ {
+		A.Set(n+k, n+k+1, 1)
+	}
+	E := la.NewMatrix(N, N)
+	la.MatExp(E, A)
+	for i := 0; i < n; i++ {
+		u[i] = E.Get(i, N-1)
+	}
+}
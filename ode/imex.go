@@ -0,0 +1,387 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/num"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// ImexRK implements implicit-explicit (IMEX) additive Runge-Kutta methods for split systems
+//
+//   d{y}/dx = {fS}(x, {y}) + {fN}(x, {y})
+//
+//   where the stiff part fS is treated by an ESDIRK method and the non-stiff part fN is treated
+//   by an explicit Runge-Kutta method (see Config.SetSplit). The stages are computed with
+//
+//     {z}i = {y} + h Σ_{j<i} (aNij {fN}j + aSij {fS}j) + h γ {fS}(xi, {z}i)
+//
+//   where the (linear) implicit equations are solved by Newton's method with the matrix
+//   [I] - h γ [dfS/dy] computed at the beginning of the step (and possibly reused).
+//
+//   The methods available are:
+//     ark3 -- ARK3(2)4L[2]SA: 4 stages, order 3 with embedded order 2 [1]
+//     ark4 -- ARK4(3)6L[2]SA: 6 stages, order 4 with embedded order 3 [1]
+//
+//   The implicit methods are stiffly accurate (ESDIRK) with an explicit first stage; thus only
+//   one linear system with [I] - h γ [dfS/dy] is factorised per step. The dense output is
+//   computed by cubic Hermite interpolation.
+//
+//   Reference:
+//     [1] Kennedy CA and Carpenter MH (2003) Additive Runge-Kutta schemes for convection-
+//         diffusion-reaction equations. Applied Numerical Mathematics, 44:139-181
+//
+type ImexRK struct {
+
+	// constants
+	AN   [][]float64 // A coefficients of the explicit method
+	AS   [][]float64 // A coefficients of the implicit method
+	B    []float64   // B coefficients (same for both methods)
+	Be   []float64   // B coefficients of the embedded method
+	C    []float64   // C coefficients
+	Nstg int         // number of stages
+	P    int         // order of y1
+	Q    int         // order of the error estimator
+
+	// data
+	ndim int     // problem dimension
+	conf *Config // configuration
+	work *rkwork // workspace
+	stat *Stat   // statistics
+	fS   Func    // stiff part
+	jacS JacF    // Jacobian of the stiff part [may be nil]
+	fN   Func    // non-stiff part
+
+	// stages
+	xa float64     // x at the beginning of the step
+	kS []la.Vector // fS at stages [nstg][ndim]
+	kN []la.Vector // fN at stages [nstg][ndim]
+	ψ  la.Vector   // explicit part of stage values
+	z  la.Vector   // stage value
+	w  la.Vector   // updated y
+	r  la.Vector   // residual of the implicit equations
+	δz la.Vector   // Newton's increment
+
+	// linear system
+	dfdy  *la.Triplet     // dfS/dy
+	imat  *la.Triplet     // identity matrix
+	kmat  *la.Triplet     // [I] - h γ [dfS/dy]
+	ls    la.SparseSolver // linear solver
+	ready bool            // linear solver is initialised
+
+	// step control
+	n    float64 // exponent n = 1/(q+1)
+	dmin float64 // dmin = 1/Mmin
+	dmax float64 // dmax = 1/Mmax
+
+	// dense output
+	yd0 la.Vector // y at the beginning of the last accepted step
+	fd0 la.Vector // f at the beginning of the last accepted step
+	fd1 la.Vector // f at the end of the last accepted step
+}
+
+// add methods to database
+func init() {
+	rkmDB["ark3"] = func() rkmethod { return newImexRK("ark3") }
+	rkmDB["ark4"] = func() rkmethod { return newImexRK("ark4") }
+}
+
+// Free releases memory
+func (o *ImexRK) Free() {
+	if o.ls != nil {
+		o.ls.Free()
+	}
+}
+
+// Info returns information about this method
+func (o *ImexRK) Info() (fixedOnly, implicit bool, nstages int) {
+	return false, true, o.Nstg
+}
+
+// Init initialises structure
+func (o *ImexRK) Init(ndim int, conf *Config, work *rkwork, stat *Stat, fcn Func, jac JacF, M *la.Triplet) {
+
+	// check
+	if M != nil {
+		chk.Panic("IMEX Runge-Kutta methods cannot handle M matrix yet\n")
+	}
+	if conf.splitS == nil {
+		chk.Panic("method %q requires a split right-hand side. call conf.SetSplit first\n", conf.method)
+	}
+
+	// data
+	o.ndim = ndim
+	o.conf = conf
+	o.work = work
+	o.stat = stat
+	o.fS, o.jacS, o.fN = conf.splitS, conf.splitJacS, conf.splitN
+
+	// stages
+	o.kS = make([]la.Vector, o.Nstg)
+	o.kN = make([]la.Vector, o.Nstg)
+	for i := 0; i < o.Nstg; i++ {
+		o.kS[i] = la.NewVector(ndim)
+		o.kN[i] = la.NewVector(ndim)
+	}
+	o.ψ = la.NewVector(ndim)
+	o.z = la.NewVector(ndim)
+	o.w = la.NewVector(ndim)
+	o.r = la.NewVector(ndim)
+	o.δz = la.NewVector(ndim)
+
+	// linear system
+	o.dfdy = new(la.Triplet)
+	o.imat = new(la.Triplet)
+	la.SpTriSetDiag(o.imat, ndim, 1)
+	o.kmat = new(la.Triplet)
+	o.ls = la.NewSparseSolver(conf.lsKind)
+
+	// step control
+	o.n = 1.0 / float64(o.Q+1)
+	o.dmin = 1.0 / conf.Mmin
+	o.dmax = 1.0 / conf.Mmax
+
+	// dense output
+	if conf.needDense() {
+		o.yd0 = la.NewVector(ndim)
+		o.fd0 = la.NewVector(ndim)
+		o.fd1 = la.NewVector(ndim)
+	}
+}
+
+// Accept accepts update and computes next stepsize
+func (o *ImexRK) Accept(y0 la.Vector, x0 float64) (dxnew float64) {
+
+	// store data for future dense output
+	if o.conf.needDense() {
+		o.yd0.Apply(1, y0)
+		la.VecAdd(o.fd0, 1, o.kS[0], 1, o.kN[0])
+	}
+
+	// update y and first stage of the next step (the explicit part is not stiffly accurate)
+	y0.Apply(1, o.w)
+	o.stat.Nfeval += 2
+	o.fS(o.kS[0], o.work.h, o.xa+o.work.h, y0)
+	o.fN(o.kN[0], o.work.h, o.xa+o.work.h, y0)
+	if o.conf.needDense() {
+		la.VecAdd(o.fd1, 1, o.kS[0], 1, o.kN[0])
+	}
+
+	// estimate new stepsize
+	d := math.Pow(o.work.rerr, o.n)
+	d = utl.Max(o.dmax, utl.Min(o.dmin, d/o.conf.Mfac)) // we require  fac1 <= hnew/h <= fac2
+	dxnew = o.work.h / d
+	return
+}
+
+// Reject processes step rejection and computes next stepsize
+func (o *ImexRK) Reject() (dxnew float64) {
+	o.work.reuseJdec = false // h will change
+	d := math.Pow(o.work.rerr, o.n) / o.conf.Mfac
+	dxnew = o.work.h / utl.Min(o.dmin, d)
+	return
+}
+
+// DenseOut produces dense output (after Accept)
+func (o *ImexRK) DenseOut(yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	hermiteOut(yout, h, x, y, xout, o.yd0, o.fd0, o.fd1)
+}
+
+// Step steps update
+func (o *ImexRK) Step(xa float64, ya la.Vector) {
+
+	// auxiliary
+	h := o.work.h
+	γ := o.AS[1][1]
+
+	// first stage (otherwise, computed in Accept)
+	o.xa = xa
+	if o.work.first && !o.work.reject {
+		o.stat.Nfeval += 2
+		o.fS(o.kS[0], h, xa, ya)
+		o.fN(o.kN[0], h, xa, ya)
+	}
+
+	// Jacobian and factorisation
+	if !o.work.reuseJdec || o.work.first || !o.ready {
+		if !o.work.reuseJ || o.work.first || !o.ready {
+			o.stat.Njeval++
+			if o.jacS == nil {
+				num.Jacobian(o.dfdy, func(fy, yy la.Vector) {
+					o.fS(fy, h, xa, yy)
+				}, ya, o.kS[0], o.w) // w works here as workspace
+				o.stat.Nfeval += o.ndim
+			} else {
+				o.jacS(o.dfdy, h, xa, ya)
+			}
+		}
+		if !o.ready {
+			o.kmat.Init(o.ndim, o.ndim, o.imat.Len()+o.dfdy.Max())
+		}
+		la.SpTriAdd(o.kmat, 1, o.imat, -h*γ, o.dfdy) // K = I - h⋅γ⋅dfS/dy
+		if !o.ready {
+			o.ls.Init(o.kmat, o.conf.GetSpArgs())
+			o.ready = true
+		}
+		o.stat.Ndecomp++
+		o.ls.Fact()
+	}
+
+	// stages
+	o.work.theta = 0
+	for i := 1; i < o.Nstg; i++ {
+		xi := xa + h*o.C[i]
+
+		// explicit part
+		o.ψ.Apply(1, ya)
+		for j := 0; j < i; j++ {
+			for m := 0; m < o.ndim; m++ {
+				o.ψ[m] += h * (o.AN[i][j]*o.kN[j][m] + o.AS[i][j]*o.kS[j][m])
+			}
+		}
+
+		// Newton's iterations: z - ψ - h⋅γ⋅fS(xi, z) = 0
+		o.z.Apply(1, o.ψ)
+		var ndz, ndzPrev float64
+		converged := false
+		for it := 0; it < o.conf.NmaxIt; it++ {
+			if it+1 > o.stat.Nitmax {
+				o.stat.Nitmax = it + 1
+			}
+			o.stat.Nfeval++
+			o.fS(o.kS[i], h, xi, o.z)
+			for m := 0; m < o.ndim; m++ {
+				o.r[m] = o.z[m] - o.ψ[m] - h*γ*o.kS[i][m]
+			}
+			o.stat.Nlinsol++
+			o.ls.Solve(o.δz, o.r, false) // δz := inv(K) ⋅ r
+			ndz = 0
+			for m := 0; m < o.ndim; m++ {
+				o.z[m] -= o.δz[m]
+				ndz += math.Pow(o.δz[m]/o.work.scal[m], 2)
+			}
+			ndz = math.Sqrt(ndz / float64(o.ndim))
+			if o.conf.Verbose {
+				io.Pfgrey("    stage %d: it = %d  |δz| = %10.5e  (tol = %10.5e)\n", i, it, ndz, o.conf.fnewt)
+			}
+			if math.IsNaN(ndz) || math.IsInf(ndz, 0) {
+				break
+			}
+			if it > 0 {
+				θ := ndz / ndzPrev
+				o.work.theta = utl.Max(o.work.theta, θ)
+				if θ >= 1 {
+					break
+				}
+			}
+			if ndz < o.conf.fnewt {
+				converged = true
+				break
+			}
+			ndzPrev = ndz
+		}
+
+		// diverging iterations: reduce h and recompute the Jacobian
+		if !converged {
+			o.work.diverg = true
+			o.work.dvfac = 0.5
+			o.work.reuseJdec = false
+			o.work.reuseJ = false
+			return
+		}
+
+		// stage values: fS from the implicit equation (avoids amplifying the Newton's error)
+		for m := 0; m < o.ndim; m++ {
+			o.kS[i][m] = (o.z[m] - o.ψ[m]) / (h * γ)
+		}
+		o.stat.Nfeval++
+		o.fN(o.kN[i], h, xi, o.z)
+	}
+
+	// update and error estimate
+	var sum, lerr, k, sk float64
+	for m := 0; m < o.ndim; m++ {
+		o.w[m] = ya[m]
+		lerr = 0
+		for i := 0; i < o.Nstg; i++ {
+			k = h * (o.kS[i][m] + o.kN[i][m])
+			o.w[m] += o.B[i] * k
+			lerr += (o.B[i] - o.Be[i]) * k
+		}
+		sk = o.conf.atol + o.conf.rtol*utl.Max(math.Abs(ya[m]), math.Abs(o.w[m]))
+		sum += (lerr / sk) * (lerr / sk)
+	}
+	o.work.rerr = utl.Max(math.Sqrt(sum/float64(o.ndim)), 1.0e-10)
+}
+
+// hermiteOut computes the dense output by cubic Hermite interpolation within the last accepted
+// step [x-h,x] with y0 = y(x-h), f0 = f(x-h), y = y(x) and f1 = f(x)
+func hermiteOut(yout la.Vector, h, x float64, y la.Vector, xout float64, y0, f0, f1 la.Vector) {
+	θ := (xout - (x - h)) / h
+	θ2, θ3 := θ*θ, θ*θ*θ
+	for m := 0; m < len(y); m++ {
+		yout[m] = (2*θ3-3*θ2+1)*y0[m] + (θ3-2*θ2+θ)*h*f0[m] + (3*θ2-2*θ3)*y[m] + (θ3-θ2)*h*f1[m]
+	}
+}
+
+// newImexRK returns the coefficients of the IMEX Runge-Kutta method
+func newImexRK(kind string) rkmethod {
+	o := new(ImexRK)
+	switch kind {
+	case "ark3": // ARK3(2)4L[2]SA
+		γ := 1767732205903.0 / 4055673282236.0
+		o.C = []float64{0, 1767732205903.0 / 2027836641118.0, 3.0 / 5.0, 1}
+		o.AN = [][]float64{
+			{0, 0, 0, 0},
+			{1767732205903.0 / 2027836641118.0, 0, 0, 0},
+			{5535828885825.0 / 10492691773637.0, 788022342437.0 / 10882634858940.0, 0, 0},
+			{6485989280629.0 / 16251701735622.0, -4246266847089.0 / 9704473918619.0, 10755448449292.0 / 10357097424841.0, 0},
+		}
+		o.AS = [][]float64{
+			{0, 0, 0, 0},
+			{γ, γ, 0, 0},
+			{2746238789719.0 / 10658868560708.0, -640167445237.0 / 6845629431997.0, γ, 0},
+			{1471266399579.0 / 7840856788654.0, -4482444167858.0 / 7529755066697.0, 11266239266428.0 / 11593286722821.0, γ},
+		}
+		o.B = []float64{1471266399579.0 / 7840856788654.0, -4482444167858.0 / 7529755066697.0, 11266239266428.0 / 11593286722821.0, γ}
+		o.Be = []float64{2756255671327.0 / 12835298489170.0, -10771552573575.0 / 22201958757719.0, 9247589265047.0 / 10645013368117.0, 2193209047091.0 / 5459859503100.0}
+		o.P = 3
+		o.Q = 2
+
+	case "ark4": // ARK4(3)6L[2]SA
+		γ := 1.0 / 4.0
+		o.C = []float64{0, 1.0 / 2.0, 83.0 / 250.0, 31.0 / 50.0, 17.0 / 20.0, 1}
+		o.AN = [][]float64{
+			{0, 0, 0, 0, 0, 0},
+			{1.0 / 2.0, 0, 0, 0, 0, 0},
+			{13861.0 / 62500.0, 6889.0 / 62500.0, 0, 0, 0, 0},
+			{-116923316275.0 / 2393684061468.0, -2731218467317.0 / 15368042101831.0, 9408046702089.0 / 11113171139209.0, 0, 0, 0},
+			{-451086348788.0 / 2902428689909.0, -2682348792572.0 / 7519795681897.0, 12662868775082.0 / 11960479115383.0, 3355817975965.0 / 11060851509271.0, 0, 0},
+			{647845179188.0 / 3216320057751.0, 73281519250.0 / 8382639484533.0, 552539513391.0 / 3454668386233.0, 3354512671639.0 / 8306763924573.0, 4040.0 / 17871.0, 0},
+		}
+		o.AS = [][]float64{
+			{0, 0, 0, 0, 0, 0},
+			{γ, γ, 0, 0, 0, 0},
+			{8611.0 / 62500.0, -1743.0 / 31250.0, γ, 0, 0, 0},
+			{5012029.0 / 34652500.0, -654441.0 / 2922500.0, 174375.0 / 388108.0, γ, 0, 0},
+			{15267082809.0 / 155376265600.0, -71443401.0 / 120774400.0, 730878875.0 / 902184768.0, 2285395.0 / 8070912.0, γ, 0},
+			{82889.0 / 524892.0, 0, 15625.0 / 83664.0, 69875.0 / 102672.0, -2260.0 / 8211.0, γ},
+		}
+		o.B = []float64{82889.0 / 524892.0, 0, 15625.0 / 83664.0, 69875.0 / 102672.0, -2260.0 / 8211.0, γ}
+		o.Be = []float64{4586570599.0 / 29645900160.0, 0, 178811875.0 / 945068544.0, 814220225.0 / 1159782912.0, -3700637.0 / 11593932.0, 61727.0 / 225920.0}
+		o.P = 4
+		o.Q = 3
+
+	default:
+		chk.Panic("IMEX Runge-Kutta method %q is not available\n", kind)
+	}
+	o.Nstg = len(o.B)
+	return o
+}
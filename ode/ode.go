@@ -4,7 +4,9 @@
 
 // Package ode implements solvers for ordinary differential equations, including explicit and
 // implicit Runge-Kutta methods; e.g. the fantastic Radau5 method by
-// Hairer, Norsett & Wanner [1, 2].
+// Hairer, Norsett & Wanner [1, 2]. Variable-order backward differentiation formulae (BDF/NDF)
+// are also available. Second-order systems can be solved with symplectic and Runge-Kutta-Nyström
+// integrators and delay differential equations can be solved using the dense output.
 //   References:
 //     [1] Hairer E, Nørsett SP, Wanner G (1993). Solving Ordinary Differential Equations I:
 //         Nonstiff Problems. Springer Series in Computational Mathematics, Vol. 8, Berlin,
@@ -41,6 +43,12 @@ type Solver struct {
 	FixedOnly bool     // method can only be used with fixed steps
 	Implicit  bool     // method is implicit
 	work      *rkwork  // Runge-Kutta workspace
+
+	// events
+	events *eventDetector // detects events [may be nil]
+
+	// hooks
+	stepDone func(h, x float64, y la.Vector) // called after accepted steps (x-h,x]; e.g. to record the dense output [may be nil]
 }
 
 // NewSolver returns a new ODE structure with default values and allocated slices
@@ -49,7 +57,7 @@ type Solver struct {
 //    ndim -- problem dimension
 //    conf -- configuration parameters
 //    out  -- output handler [may be nil]
-//    fcn  -- f(x,y) = dy/dx function
+//    fcn  -- f(x,y) = dy/dx function [may be nil if conf.SetSplit has been called ⇒ f = fS + fN]
 //    jac  -- Jacobian: df/dy function [may be nil ⇒ use numerical Jacobian, if necessary]
 //    M    -- "mass" matrix, such that M ⋅ dy/dx = f(x,y) [may be nil]
 //
@@ -57,6 +65,28 @@ type Solver struct {
 //
 func NewSolver(ndim int, conf *Config, fcn Func, jac JacF, M *la.Triplet) (o *Solver) {
 
+	// check
+	if conf.algebraic != nil {
+		if len(conf.algebraic) != ndim {
+			chk.Panic("the number of algebraic/differential flags (%d) must be equal to ndim (%d)\n", len(conf.algebraic), ndim)
+		}
+		if conf.nErr(ndim) == 0 {
+			chk.Panic("at least one variable must be included in the local error estimate\n")
+		}
+	}
+
+	// split right-hand side
+	if fcn == nil && conf.splitS != nil {
+		fn := la.NewVector(ndim)
+		fcn = func(f la.Vector, h, x float64, y la.Vector) {
+			conf.splitS(f, h, x, y)
+			conf.splitN(fn, h, x, y)
+			for i := 0; i < ndim; i++ {
+				f[i] += fn[i]
+			}
+		}
+	}
+
 	// main
 	o = new(Solver)
 	o.conf = conf
@@ -87,6 +117,12 @@ func NewSolver(ndim int, conf *Config, fcn Func, jac JacF, M *la.Triplet) (o *So
 	if o.Out != nil {
 		o.Out.dout = o.rkm.DenseOut
 	}
+
+	// events
+	if len(o.conf.events) > 0 {
+		o.events = newEventDetector(ndim, o.conf)
+		o.events.dout = o.rkm.DenseOut
+	}
 	return
 }
 
@@ -107,6 +143,9 @@ func (o *Solver) Solve(y la.Vector, x, xf float64) {
 	if o.FixedOnly && !o.conf.fixed {
 		chk.Panic("method %q can only be used with fixed steps. make sure to call conf.SetFixedH > 0\n", o.conf.method)
 	}
+	if o.events != nil && o.conf.fixed {
+		chk.Panic("events cannot be detected with fixed steps\n")
+	}
 
 	// initial step size
 	o.work.h = xf - x
@@ -129,12 +168,18 @@ func (o *Solver) Solve(y la.Vector, x, xf float64) {
 	// set control flags
 	o.work.first = true
 
+	// initial values of event functions
+	if o.events != nil {
+		o.events.start(x, y)
+	}
+
 	// first scaling variable
 	la.VecScaleAbs(o.work.scal, o.conf.atol, o.conf.rtol, y) // scal = atol + rtol * abs(y)
 
-	// make sure that final x is equal to xf in the end
+	// make sure that final x is equal to xf in the end (unless the simulation was stopped)
+	var stopped bool
 	defer func() {
-		if math.Abs(x-xf) > 1e-15 {
+		if !stopped && math.Abs(x-xf) > 1e-15 {
 			chk.Panic("internal error: x must be equal to xf in the end. x-xf=%v\n", x-xf)
 		}
 	}()
@@ -159,6 +204,7 @@ func (o *Solver) Solve(y la.Vector, x, xf float64) {
 			if o.Out != nil {
 				stop := o.Out.execute(istep, false, o.work.rs, o.work.h, x, y)
 				if stop {
+					stopped = true
 					return
 				}
 			}
@@ -197,7 +243,7 @@ func (o *Solver) Solve(y la.Vector, x, xf float64) {
 	var dxmax, xstep, dxnew, dxratio float64
 	var last, failed bool
 	for x < xf {
-		dxmax, xstep = Δx, x+Δx
+		dxmax, xstep = Δx, xf
 		failed = false
 		for iss := 0; iss < o.conf.NmaxSS+1; iss++ {
 
@@ -256,11 +302,42 @@ func (o *Solver) Solve(y la.Vector, x, xf float64) {
 				// update x and y
 				dxnew = o.rkm.Accept(y, x)
 				x += o.work.h
+				if last {
+					x = xstep // avoid round-off errors
+				}
+				if o.stepDone != nil {
+					o.stepDone(o.work.h, x, y)
+				}
+
+				// events
+				if o.events != nil {
+					terminal, stop := o.processEvents(&x, y)
+					if stop {
+						stopped = true
+						return
+					}
+
+					// restart from event
+					if terminal {
+						last = false
+						o.work.first = true
+						o.work.reject = false
+						o.work.reuseJdec = false
+						o.work.reuseJ = false
+						o.work.hPrev = o.work.h
+						o.work.h = utl.Min(o.conf.IniH, xstep-x)
+						la.VecScaleAbs(o.work.scal, o.conf.atol, o.conf.rtol, y)
+						o.Stat.Nfeval++
+						o.fcn(o.work.f0, o.work.h, x, y) // o.f0 := f(x,y)
+						continue
+					}
+				}
 
 				// output
 				if o.Out != nil {
 					stop := o.Out.execute(o.Stat.Naccepted, last, o.work.rs, o.work.h, x, y)
 					if stop {
+						stopped = true
 						return
 					}
 				}
@@ -349,3 +426,48 @@ func (o *Solver) Solve(y la.Vector, x, xf float64) {
 		}
 	}
 }
+
+// processEvents detects events within the last accepted step (x-h,x] and records them. At a
+// terminal event, x and y are moved back to the event, the output is executed and the Reset
+// function is called (if any)
+//   terminal -- a terminal event was found; i.e. the integration must be restarted from x
+//   stop     -- the integration must stop
+func (o *Solver) processEvents(x *float64, y la.Vector) (terminal, stop bool) {
+
+	// find and record events
+	found := o.events.find(o.work.h, *x, y)
+	var ev *Event
+	var k int
+	var xe float64
+	for _, f := range found {
+		ye := o.events.calcY(f.x)
+		if o.Out != nil {
+			o.Out.addEvent(f.k, f.x, ye)
+		}
+		if o.events.list[f.k].Terminal && ev == nil {
+			ev, k, xe = o.events.list[f.k], f.k, f.x
+		}
+	}
+	if ev == nil {
+		return
+	}
+
+	// terminal event
+	terminal = true
+	stop = ev.Reset == nil
+	ye := o.events.calcY(xe)
+	if o.Out != nil {
+		if o.Out.executeAt(o.Stat.Naccepted, stop, o.work.rs, o.work.h, *x, y, xe, ye) {
+			stop = true
+		}
+	}
+	*x = xe
+	y.Apply(1, ye)
+	if !stop {
+		stop = ev.Reset(k, xe, y)
+	}
+	if !stop {
+		o.events.restart(found, xe, y)
+	}
+	return
+}
@@ -33,6 +33,11 @@ type Output struct {
 	xout      float64     // current x of dense output
 	yout      la.Vector   // current y of dense output (used if denseF != nil only)
 
+	// events
+	EventK []int       // index of event (in the list given to Config.SetEvents)
+	EventX []float64   // x values of events
+	EventY []la.Vector // y values of events (before any reset)
+
 	// from RK method
 	dout func(yout la.Vector, h, x float64, y la.Vector, xout float64) // function to calculate dense values of y
 }
@@ -69,10 +74,17 @@ func newOutput(ndim int, conf *Config) (o *Output) {
 
 // execute executes output; e.g. call Fcn and saves x and y values
 func (o *Output) execute(istep int, last bool, ρs, h, x float64, y []float64) (stop bool) {
+	return o.executeAt(istep, last, ρs, h, x, y, x, y)
+}
+
+// executeAt executes output at xc within the last step; e.g. at an event
+//  x, y   -- values at the end of the step (for dense output)
+//  xc, yc -- current values; xc ≤ x
+func (o *Output) executeAt(istep int, last bool, ρs, h, x float64, y []float64, xc float64, yc []float64) (stop bool) {
 
 	// step output using function
 	if o.conf.stepF != nil {
-		stop = o.conf.stepF(istep, h, x, y)
+		stop = o.conf.stepF(istep, h, xc, yc)
 		if stop {
 			return
 		}
@@ -82,9 +94,9 @@ func (o *Output) execute(istep int, last bool, ρs, h, x float64, y []float64) (
 	if o.StepIdx < o.stepNmax {
 		o.StepRS[o.StepIdx] = ρs
 		o.StepH[o.StepIdx] = h
-		o.StepX[o.StepIdx] = x
+		o.StepX[o.StepIdx] = xc
 		o.StepY[o.StepIdx] = la.NewVector(o.ndim)
-		o.StepY[o.StepIdx].Apply(1, y)
+		o.StepY[o.StepIdx].Apply(1, yc)
 		o.StepIdx++
 	}
 
@@ -92,18 +104,18 @@ func (o *Output) execute(istep int, last bool, ρs, h, x float64, y []float64) (
 	var xo float64
 	if o.conf.denseF != nil {
 		if istep == 0 || last {
-			xo = x
-			o.yout.Apply(1, y)
-			stop = o.conf.denseF(istep, h, x, y, xo, o.yout)
+			xo = xc
+			o.yout.Apply(1, yc)
+			stop = o.conf.denseF(istep, h, xc, yc, xo, o.yout)
 			if stop {
 				return
 			}
 			xo += o.conf.denseDx
 		} else {
 			xo = o.xout
-			for x >= xo {
+			for xc >= xo {
 				o.dout(o.yout, h, x, y, xo)
-				stop = o.conf.denseF(istep, h, x, y, xo, o.yout)
+				stop = o.conf.denseF(istep, h, xc, yc, xo, o.yout)
 				if stop {
 					return
 				}
@@ -115,16 +127,16 @@ func (o *Output) execute(istep int, last bool, ρs, h, x float64, y []float64) (
 	// save dense output
 	if o.DenseIdx < o.denseNmax {
 		if istep == 0 || last {
-			xo = x
+			xo = xc
 			o.DenseS[o.DenseIdx] = istep
 			o.DenseX[o.DenseIdx] = xo
 			o.DenseY[o.DenseIdx] = la.NewVector(o.ndim)
-			o.DenseY[o.DenseIdx].Apply(1, y)
+			o.DenseY[o.DenseIdx].Apply(1, yc)
 			o.DenseIdx++
 			xo = o.conf.denseDx
 		} else {
 			xo = o.xout
-			for x >= xo {
+			for xc >= xo {
 				o.DenseS[o.DenseIdx] = istep
 				o.DenseX[o.DenseIdx] = xo
 				o.DenseY[o.DenseIdx] = la.NewVector(o.ndim)
@@ -140,6 +152,13 @@ func (o *Output) execute(istep int, last bool, ρs, h, x float64, y []float64) (
 	return
 }
 
+// addEvent records an event
+func (o *Output) addEvent(k int, x float64, y la.Vector) {
+	o.EventK = append(o.EventK, k)
+	o.EventX = append(o.EventX, x)
+	o.EventY = append(o.EventY, y.GetCopy())
+}
+
 // step output ////////////////////////////////////////////////////////////////////////////////////
 
 // GetStepRs returns all ρs (stiffness ratio) values
@@ -207,6 +226,30 @@ func (o *Output) GetStepYtableT() (Y [][]float64) {
 	return
 }
 
+// events output //////////////////////////////////////////////////////////////////////////////////
+
+// GetEventX returns the x values
+// of the event with index k (or of all events if k < 0)
+func (o *Output) GetEventX(k int) (X []float64) {
+	for i, x := range o.EventX {
+		if k < 0 || o.EventK[i] == k {
+			X = append(X, x)
+		}
+	}
+	return
+}
+
+// GetEventY extracts the y[i] values
+// of the event with index k (or of all events if k < 0)
+func (o *Output) GetEventY(k, i int) (Y []float64) {
+	for j, y := range o.EventY {
+		if k < 0 || o.EventK[j] == k {
+			Y = append(Y, y[i])
+		}
+	}
+	return
+}
+
 // dense output ///////////////////////////////////////////////////////////////////////////////////
 
 // GetDenseS returns all s (step-index) values
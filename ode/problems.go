@@ -27,6 +27,7 @@ type Problem struct {
 	Y    la.Vector   // initial (current) y vector
 	Ndim int         // dimension == len(Y)
 	M    *la.Triplet // "mass" matrix
+	Alg  []bool      // flags algebraic variables of DAEs [may be nil]
 	Ytmp la.Vector   // to use with Yana
 }
 
@@ -44,13 +45,14 @@ func (o *Problem) Solve(method string, fixedStp, numJac bool) (y la.Vector, stat
 		conf.SetFixedH(o.Dx, o.Xf)
 	}
 	conf.SetStepOut(true, nil)
+	conf.SetAlgebraic(o.Alg)
 
 	// allocate solver
 	jac := o.Jac
 	if numJac {
 		jac = nil
 	}
-	sol := NewSolver(o.Ndim, conf, o.Fcn, jac, nil)
+	sol := NewSolver(o.Ndim, conf, o.Fcn, jac, o.M)
 	defer sol.Free()
 
 	// solve ODE
@@ -336,6 +338,146 @@ func ProbHwAmplifier() (o *Problem) {
 	return
 }
 
+// ProbDaeSimple returns a simple semi-explicit DAE of index 1 with a nonlinear algebraic equation
+//
+//   y0' = -y0 + y1³ + y1
+//     0 = y1³ + y1 - y0 - cos(x)
+//
+//   with y0(0) = 1 and y1(0) = 1. The solution is y0 = 1 + sin(x) and y1 is the real root of
+//   y1³ + y1 = 1 + sin(x) + cos(x)
+func ProbDaeSimple() (o *Problem) {
+
+	o = new(Problem)
+	o.Xf = 2.0
+	o.Y = la.NewVectorSlice([]float64{1.0, 1.0})
+	o.Ndim = len(o.Y)
+	o.Alg = []bool{false, true}
+
+	o.Yana = func(res []float64, x float64) {
+		c := 1 + math.Sin(x) + math.Cos(x)
+		d := math.Sqrt(c*c/4 + 1.0/27.0)
+		res[0] = 1 + math.Sin(x)
+		res[1] = math.Cbrt(c/2+d) + math.Cbrt(c/2-d) // Cardano's formula
+	}
+
+	o.Fcn = func(f la.Vector, dx, x float64, y la.Vector) {
+		f[0] = -y[0] + y[1]*y[1]*y[1] + y[1]
+		f[1] = y[1]*y[1]*y[1] + y[1] - y[0] - math.Cos(x)
+	}
+
+	o.Jac = func(dfdy *la.Triplet, dx, x float64, y la.Vector) {
+		if dfdy.Max() == 0 {
+			dfdy.Init(2, 2, 4)
+		}
+		dfdy.Start()
+		dfdy.Put(0, 0, -1.0)
+		dfdy.Put(0, 1, 3.0*y[1]*y[1]+1.0)
+		dfdy.Put(1, 0, -1.0)
+		dfdy.Put(1, 1, 3.0*y[1]*y[1]+1.0)
+	}
+
+	o.M = new(la.Triplet)
+	o.M.Init(2, 2, 1)
+	o.M.Put(0, 0, 1.0)
+	return
+}
+
+// ProbRobertsonDae returns the Robertson's Equation written as a DAE of index 1 by replacing the
+// last equation with the conservation of mass; see Hairer-Wanner VII-p3 Eq.(1.4)
+//
+//   y0' = -0.04 y0 + 1e4 y1 y2
+//   y1' =  0.04 y0 - 1e4 y1 y2 - 3e7 y1²
+//     0 = y0 + y1 + y2 - 1
+func ProbRobertsonDae() (o *Problem) {
+
+	o = new(Problem)
+	o.Xf = 0.3
+	o.Y = la.NewVectorSlice([]float64{1.0, 0.0, 0.0})
+	o.Ndim = len(o.Y)
+	o.Alg = []bool{false, false, true}
+
+	o.Fcn = func(f la.Vector, dx, x float64, y la.Vector) {
+		f[0] = -0.04*y[0] + 1.0e4*y[1]*y[2]
+		f[1] = 0.04*y[0] - 1.0e4*y[1]*y[2] - 3.0e7*y[1]*y[1]
+		f[2] = y[0] + y[1] + y[2] - 1.0
+	}
+
+	o.Jac = func(dfdy *la.Triplet, dx, x float64, y la.Vector) {
+		if dfdy.Max() == 0 {
+			dfdy.Init(3, 3, 9)
+		}
+		dfdy.Start()
+		dfdy.Put(0, 0, -0.04)
+		dfdy.Put(0, 1, 1.0e4*y[2])
+		dfdy.Put(0, 2, 1.0e4*y[1])
+		dfdy.Put(1, 0, 0.04)
+		dfdy.Put(1, 1, -1.0e4*y[2]-6.0e7*y[1])
+		dfdy.Put(1, 2, -1.0e4*y[1])
+		dfdy.Put(2, 0, 1.0)
+		dfdy.Put(2, 1, 1.0)
+		dfdy.Put(2, 2, 1.0)
+	}
+
+	o.M = new(la.Triplet)
+	o.M.Init(3, 3, 2)
+	o.M.Put(0, 0, 1.0)
+	o.M.Put(1, 1, 1.0)
+	return
+}
+
+// ProbPendulumDae returns the (unit) pendulum written as a DAE of index 1; i.e. the constraint
+// x² + y² = 1 is differentiated twice and the Lagrange multiplier λ is the algebraic variable
+//
+//   x' = u        u' = -λ x
+//   y' = v        v' = -λ y - g
+//    0 = u² + v² - λ (x² + y²) - g y
+//
+//   with y = {x, y, u, v, λ} and initial angle θ0 = π/4 (at rest). Consistent initial values for
+//   λ must be computed; e.g. with ConsistentInit
+func ProbPendulumDae() (o *Problem) {
+
+	o = new(Problem)
+	g := 9.81
+	θ0 := math.Pi / 4
+	o.Xf = 3.0
+	o.Y = la.NewVectorSlice([]float64{math.Sin(θ0), -math.Cos(θ0), 0, 0, 0})
+	o.Ndim = len(o.Y)
+	o.Alg = []bool{false, false, false, false, true}
+
+	o.Fcn = func(f la.Vector, dx, x float64, y la.Vector) {
+		f[0] = y[2]
+		f[1] = y[3]
+		f[2] = -y[4] * y[0]
+		f[3] = -y[4]*y[1] - g
+		f[4] = y[2]*y[2] + y[3]*y[3] - y[4]*(y[0]*y[0]+y[1]*y[1]) - g*y[1]
+	}
+
+	o.Jac = func(dfdy *la.Triplet, dx, x float64, y la.Vector) {
+		if dfdy.Max() == 0 {
+			dfdy.Init(5, 5, 12)
+		}
+		dfdy.Start()
+		dfdy.Put(0, 2, 1.0)
+		dfdy.Put(1, 3, 1.0)
+		dfdy.Put(2, 0, -y[4])
+		dfdy.Put(2, 4, -y[0])
+		dfdy.Put(3, 1, -y[4])
+		dfdy.Put(3, 4, -y[1])
+		dfdy.Put(4, 0, -2.0*y[4]*y[0])
+		dfdy.Put(4, 1, -2.0*y[4]*y[1]-g)
+		dfdy.Put(4, 2, 2.0*y[2])
+		dfdy.Put(4, 3, 2.0*y[3])
+		dfdy.Put(4, 4, -(y[0]*y[0] + y[1]*y[1]))
+	}
+
+	o.M = new(la.Triplet)
+	o.M.Init(5, 5, 4)
+	for i := 0; i < 4; i++ {
+		o.M.Put(i, i, 1.0)
+	}
+	return
+}
+
 // ProbArenstorf returns the Arenstorf orbit problem
 func ProbArenstorf() (o *Problem) {
 	o = new(Problem)
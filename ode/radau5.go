@@ -119,7 +119,7 @@ func (o *Radau5) Init(ndim int, conf *Config, work *rkwork, stat *Stat, fcn Func
 
 	// coefficients
 	o.mni = o.conf.Mfac * float64(1+2*o.conf.NmaxIt)
-	o.ndf = float64(ndim)
+	o.ndf = float64(o.conf.nErr(ndim))
 	o.denLdw = float64(3 * ndim)
 	o.nmaxit = float64(o.conf.NmaxIt)
 
@@ -460,8 +460,10 @@ func (o *Radau5) errorEstimate(x0 float64, y0 la.Vector) {
 		for m := 0; m < o.ndim; m++ {
 			o.ez[m] = o.E0*o.z[0][m] + o.E1*o.z[1][m] + o.E2*o.z[2][m]
 			o.lerr[m] = o.Gam0*h*o.work.f0[m] + o.ez[m]
-			ratio := o.lerr[m] / o.work.scal[m]
-			sum += ratio * ratio
+			if o.conf.inErr(m) {
+				ratio := o.lerr[m] / o.work.scal[m]
+				sum += ratio * ratio
+			}
 		}
 		o.work.rerr = utl.Max(math.Sqrt(sum/o.ndf), 1.0e-10)
 		return
@@ -551,10 +553,13 @@ func (o *Radau5) distrAddToRHS(γ float64) {
 	la.VecAdd(o.rhs, 1, o.rhs, 1, o.dw[1])     // rhs += dw[1]
 }
 
-// rmsNorm computes the RMS norm
+// rmsNorm computes the RMS norm (of variables included in the error estimate)
 func (o *Radau5) rmsNorm(diff la.Vector) (rms float64) {
 	var ratio float64
 	for m := 0; m < o.ndim; m++ {
+		if !o.conf.inErr(m) {
+			continue
+		}
 		ratio = diff[m] / o.work.scal[m]
 		rms += ratio * ratio
 	}
@@ -0,0 +1,426 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"sort"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/num"
+)
+
+// sensData holds the problem definition and the derivatives of f shared by the sensitivity solvers
+type sensData struct {
+	ndim int         // size of y
+	np   int         // number of parameters
+	fcn  Func        // dy/dx := f(x,y)
+	jac  JacF        // Jacobian: df/dy [may be nil ⇒ numerical Jacobian]
+	dfdp DfdpF       // df/dp [may be nil ⇒ f does not depend on p]
+	fy   *la.Triplet // df/dy
+	fp   *la.Matrix  // df/dp [ndim][np]
+	f    la.Vector   // f(x,y) for the numerical Jacobian
+	w    la.Vector   // workspace for the numerical Jacobian
+}
+
+// newSensData returns a new structure
+func newSensData(ndim, np int, fcn Func, jac JacF, dfdp DfdpF) (o *sensData) {
+	if np < 1 {
+		chk.Panic("the number of parameters must be at least 1. np=%d is invalid\n", np)
+	}
+	o = new(sensData)
+	o.ndim, o.np = ndim, np
+	o.fcn, o.jac, o.dfdp = fcn, jac, dfdp
+	o.fy = new(la.Triplet)
+	o.fp = la.NewMatrix(ndim, np)
+	if jac == nil {
+		o.f = la.NewVector(ndim)
+		o.w = la.NewVector(ndim)
+	}
+	return
+}
+
+// calcDerivs computes df/dy and df/dp at (x,y)
+func (o *sensData) calcDerivs(h, x float64, y la.Vector) {
+	if o.jac == nil {
+		o.fcn(o.f, h, x, y)
+		num.Jacobian(o.fy, func(fy, yy la.Vector) {
+			o.fcn(fy, h, x, yy)
+		}, y, o.f, o.w)
+	} else {
+		o.jac(o.fy, h, x, y)
+	}
+	if o.dfdp != nil {
+		o.fp.Fill(0)
+		o.dfdp(o.fp, h, x, y)
+	}
+}
+
+// SensFwd computes forward sensitivities of the solution with respect to a set of parameters {p}
+//
+//   The sensitivities s_j = d{y}/dp_j are computed by solving the sensitivity equations
+//
+//     d{s_j}/dx = [df/dy] ⋅ {s_j} + {df/dp_j}     with     {s_j}(x0) = d{y0}/dp_j
+//
+//   together with d{y}/dx = {f}(x,{y}). The augmented system {z} = {y, s_0, s_1, ... s_np-1} is
+//   solved by the chosen ODE method; thus the error of the sensitivities is also controlled. The
+//   Jacobian of the augmented system (for implicit methods) is block-diagonal with [df/dy] blocks.
+//
+//   NOTE: the y vector given to the output functions and stored in Output has the augmented
+//         size ndim⋅(1+np)
+//
+type SensFwd struct {
+	Sol  *Solver   // solver of the augmented system
+	data *sensData // problem definition and derivatives
+	z    la.Vector // augmented vector
+}
+
+// NewSensFwd returns a new forward sensitivity solver
+//
+//  INPUT:
+//    ndim -- problem dimension
+//    np   -- number of parameters
+//    conf -- configuration parameters
+//    fcn  -- f(x,y) = dy/dx function
+//    jac  -- Jacobian: df/dy function [may be nil ⇒ use numerical Jacobian]
+//    dfdp -- df/dp function [may be nil ⇒ f does not depend on p; e.g. p are initial values]
+//
+//  NOTE: remember to call Free() to release allocated resources (e.g. from the linear solvers)
+//
+func NewSensFwd(ndim, np int, conf *Config, fcn Func, jac JacF, dfdp DfdpF) (o *SensFwd) {
+	o = new(SensFwd)
+	o.data = newSensData(ndim, np, fcn, jac, dfdp)
+	nz := ndim * (1 + np)
+	o.z = la.NewVector(nz)
+	d := o.data
+	fcnz := func(fz la.Vector, h, x float64, z la.Vector) {
+		y := z[:ndim]
+		fcn(fz[:ndim], h, x, y)
+		d.calcDerivs(h, x, y)
+		for j := 0; j < np; j++ {
+			sj := z[(1+j)*ndim : (2+j)*ndim]
+			fj := fz[(1+j)*ndim : (2+j)*ndim]
+			la.SpTriMatVecMul(fj, d.fy, sj) // fj := df/dy ⋅ sj
+			if dfdp != nil {
+				for i := 0; i < ndim; i++ {
+					fj[i] += d.fp.Get(i, j)
+				}
+			}
+		}
+	}
+	jacz := func(dfdz *la.Triplet, h, x float64, z la.Vector) {
+		d.calcDerivs(h, x, z[:ndim])
+		if dfdz.Max() == 0 {
+			dfdz.Init(nz, nz, (1+np)*d.fy.Max())
+		}
+		dfdz.Start()
+		for j := 0; j <= np; j++ {
+			dfdz.PutTriplet(j*ndim, j*ndim, d.fy)
+		}
+	}
+	o.Sol = NewSolver(nz, conf, fcnz, jacz, nil)
+	return
+}
+
+// Free releases allocated memory
+func (o *SensFwd) Free() {
+	o.Sol.Free()
+}
+
+// Solve solves dy/dx = f(x,y) and the sensitivity equations from x to xf
+//
+//  INPUT:
+//    y -- initial values {y}(x)
+//    S -- initial sensitivities d{y}/d{p} at x [ndim][np]; e.g. zero or identity (if p = y0)
+//
+//  OUTPUT:
+//    y -- final values {y}(xf)
+//    S -- final sensitivities d{y}/d{p} at xf
+//
+func (o *SensFwd) Solve(y la.Vector, S *la.Matrix, x, xf float64) {
+	ndim := o.data.ndim
+	copy(o.z[:ndim], y)
+	copy(o.z[ndim:], S.Data) // column-major ⇒ s_j are contiguous
+	o.Sol.Solve(o.z, x, xf)
+	copy(y, o.z[:ndim])
+	copy(S.Data, o.z[ndim:])
+}
+
+// SensAdj computes the gradient of a scalar functional of the solution with respect to a set of
+// parameters {p} by means of the adjoint method
+//
+//   The functional is Ψ = Σ_k φk(xk, {y}(xk), {p}) where xk are observation points. The adjoint
+//   variables {λ} and the quadrature variables {μ} are computed backwards from the last
+//   observation point xN to x0 according to
+//
+//     d{λ}/dx = - [df/dy]ᵀ ⋅ {λ}     and     d{μ}/dx = - [df/dp]ᵀ ⋅ {λ}
+//
+//   with {λ}(xN) = {μ}(xN) = 0 and jumps {λ}(xk⁻) = {λ}(xk⁺) + d{φk}/d{y} at each xk. The
+//   gradient is then:
+//
+//     dΨ/d{p} = Σ_k ∂{φk}/∂{p} + {μ}(x0) + [dy0/dp]ᵀ ⋅ {λ}(x0)
+//
+//   The forward solution is stored at checkpoints only (every Ncheck accepted steps). In the
+//   backward pass, the forward problem is re-solved within each interval between checkpoints
+//   and {y} is interpolated (cubic Hermite) from the recomputed steps. The backward problem is
+//   solved by the same ODE method with τ = -x as independent variable.
+//
+//   Reference:
+//     [1] Cao Y, Li S, Petzold L, Serban R (2003) Adjoint sensitivity analysis for
+//         differential-algebraic equations: the adjoint DAE system and its numerical solution.
+//         SIAM Journal on Scientific Computing, 24(3):1076-1089
+//
+type SensAdj struct {
+
+	// input
+	Ncheck int // number of accepted steps between checkpoints [default = 20]
+
+	// solvers
+	Fwd *Solver // solver of the forward problem
+	Adj *Solver // solver of the adjoint problem in τ = -x with {z} = {λ, μ}
+
+	// problem definition and derivatives
+	data *sensData
+
+	// checkpoints
+	chkX []float64   // x at checkpoints
+	chkY []la.Vector // y at checkpoints
+
+	// recomputed steps within the current interval between checkpoints
+	recording bool        // forward solver is recomputing steps
+	nstp      int         // number of recomputed steps
+	stpX      []float64   // x at recomputed steps
+	stpY      []la.Vector // y at recomputed steps
+	stpF      []la.Vector // f(x,y) at recomputed steps
+	ystp      la.Vector   // y during recomputation
+	yint      la.Vector   // interpolated y
+}
+
+// NewSensAdj returns a new adjoint sensitivity solver
+//
+//  INPUT:
+//    ndim -- problem dimension
+//    np   -- number of parameters
+//    conf -- configuration parameters (output functions and events are ignored)
+//    fcn  -- f(x,y) = dy/dx function
+//    jac  -- Jacobian: df/dy function [may be nil ⇒ use numerical Jacobian]
+//    dfdp -- df/dp function [may be nil ⇒ f does not depend on p; e.g. p are initial values]
+//
+//  NOTE: remember to call Free() to release allocated resources (e.g. from the linear solvers)
+//
+func NewSensAdj(ndim, np int, conf *Config, fcn Func, jac JacF, dfdp DfdpF) (o *SensAdj) {
+
+	// check
+	if conf.fixed {
+		chk.Panic("adjoint sensitivities cannot be computed with fixed steps\n")
+	}
+
+	// data
+	o = new(SensAdj)
+	o.Ncheck = 20
+	o.data = newSensData(ndim, np, fcn, jac, dfdp)
+	o.ystp = la.NewVector(ndim)
+	o.yint = la.NewVector(ndim)
+	d := o.data
+
+	// forward solver
+	cfwd := newSensConfig(conf)
+	cfwd.stepF = func(istep int, h, x float64, y la.Vector) (stop bool) {
+		if o.recording {
+			o.addStep(x, y)
+		} else if istep%o.Ncheck == 0 {
+			o.addCheckpoint(x, y)
+		}
+		return
+	}
+	o.Fwd = NewSolver(ndim, cfwd, fcn, jac, nil)
+
+	// adjoint solver
+	nz := ndim + np
+	fcnz := func(fz la.Vector, h, τ float64, z la.Vector) {
+		y := o.interp(-τ)
+		d.calcDerivs(h, -τ, y)
+		λ := z[:ndim]
+		la.SpTriMatTrVecMul(fz[:ndim], d.fy, λ) // dλ/dτ = [df/dy]ᵀ ⋅ λ
+		if dfdp != nil {
+			la.MatTrVecMul(fz[ndim:], 1, d.fp, λ) // dμ/dτ = [df/dp]ᵀ ⋅ λ
+		} else {
+			fz[ndim:].Fill(0)
+		}
+	}
+	jacz := func(dfdz *la.Triplet, h, τ float64, z la.Vector) {
+		y := o.interp(-τ)
+		d.calcDerivs(h, -τ, y)
+		if dfdz.Max() == 0 {
+			dfdz.Init(nz, nz, d.fy.Max()+ndim*np)
+		}
+		dfdz.Start()
+		dfdz.PutTripletT(0, 0, d.fy)
+		if dfdp != nil {
+			for j := 0; j < np; j++ {
+				for i := 0; i < ndim; i++ {
+					if v := d.fp.Get(i, j); v != 0 {
+						dfdz.Put(ndim+j, i, v)
+					}
+				}
+			}
+		}
+	}
+	o.Adj = NewSolver(nz, newSensConfig(conf), fcnz, jacz, nil)
+	return
+}
+
+// Free releases allocated memory
+func (o *SensAdj) Free() {
+	o.Fwd.Free()
+	o.Adj.Free()
+}
+
+// Gradient computes Ψ = Σ_k φk(xk, {y}(xk), {p}) and its gradient with respect to {p}
+//
+//  INPUT:
+//    y     -- initial values {y}(x0)
+//    dy0dp -- derivatives of the initial values d{y0}/d{p} [ndim][np] [may be nil ⇒ zero]
+//    x0    -- initial x
+//    xobs  -- observation points (sorted) with xobs[k] ≥ x0
+//    phi   -- terms of the functional
+//
+//  OUTPUT:
+//    grad -- gradient dΨ/d{p} [np] (pre-allocated)
+//    y    -- final values {y}(xN) with xN = xobs[len(xobs)-1]
+//    Ψ    -- the functional
+//
+func (o *SensAdj) Gradient(grad, y la.Vector, dy0dp *la.Matrix, x0 float64, xobs []float64, phi PhiF) (Ψ float64) {
+
+	// check
+	ndim, np := o.data.ndim, o.data.np
+	nobs := len(xobs)
+	if nobs < 1 {
+		chk.Panic("at least one observation point is required\n")
+	}
+	if xobs[0] < x0 || !sort.Float64sAreSorted(xobs) {
+		chk.Panic("observation points must be sorted and greater than or equal to x0=%v. xobs=%v\n", x0, xobs)
+	}
+	if o.Ncheck < 1 {
+		chk.Panic("the number of steps between checkpoints must be at least 1. Ncheck=%d is invalid\n", o.Ncheck)
+	}
+
+	// forward pass: compute Ψ and save checkpoints
+	o.recording = false
+	o.chkX, o.chkY = o.chkX[:0], o.chkY[:0]
+	o.addCheckpoint(x0, y)
+	dphidy := make([]la.Vector, nobs)
+	dphidp := la.NewVector(np)
+	grad.Fill(0)
+	x := x0
+	for k, xk := range xobs {
+		if xk > x {
+			o.Fwd.Solve(y, x, xk)
+			o.addCheckpoint(xk, y)
+			x = xk
+		}
+		dphidy[k] = la.NewVector(ndim)
+		dphidp.Fill(0)
+		Ψ += phi(dphidy[k], dphidp, k, xk, y)
+		la.VecAdd(grad, 1, dphidp, 1, grad)
+	}
+
+	// backward pass
+	z := la.NewVector(ndim + np)
+	λ := z[:ndim]
+	k := nobs - 1
+	for c := len(o.chkX) - 1; c >= 0; c-- {
+
+		// jumps of λ at observation points
+		xb := o.chkX[c]
+		for ; k >= 0 && xobs[k] == xb; k-- {
+			la.VecAdd(λ, 1, dphidy[k], 1, λ)
+		}
+		if c == 0 {
+			break
+		}
+
+		// recompute forward solution within interval
+		xa := o.chkX[c-1]
+		o.recording = true
+		o.nstp = 0
+		o.ystp.Apply(1, o.chkY[c-1])
+		o.Fwd.Solve(o.ystp, xa, xb)
+		for i := 0; i < o.nstp; i++ {
+			o.data.fcn(o.stpF[i], 0, o.stpX[i], o.stpY[i])
+		}
+		o.recording = false
+
+		// solve adjoint problem from xb to xa
+		o.Adj.Solve(z, -xb, -xa)
+	}
+
+	// gradient
+	la.VecAdd(grad, 1, z[ndim:], 1, grad)
+	if dy0dp != nil {
+		for j := 0; j < np; j++ {
+			grad[j] += la.VecDot(dy0dp.Col(j), λ)
+		}
+	}
+	return
+}
+
+// addCheckpoint adds checkpoint at x (if not added yet)
+func (o *SensAdj) addCheckpoint(x float64, y la.Vector) {
+	if n := len(o.chkX); n > 0 && o.chkX[n-1] == x {
+		return
+	}
+	o.chkX = append(o.chkX, x)
+	o.chkY = append(o.chkY, y.GetCopy())
+}
+
+// addStep records a recomputed step
+func (o *SensAdj) addStep(x float64, y la.Vector) {
+	if o.nstp == len(o.stpX) {
+		o.stpX = append(o.stpX, 0)
+		o.stpY = append(o.stpY, la.NewVector(o.data.ndim))
+		o.stpF = append(o.stpF, la.NewVector(o.data.ndim))
+	}
+	o.stpX[o.nstp] = x
+	o.stpY[o.nstp].Apply(1, y)
+	o.nstp++
+}
+
+// interp computes y(x) by cubic Hermite interpolation of the recomputed steps
+func (o *SensAdj) interp(x float64) la.Vector {
+	n := o.nstp
+	if n < 2 {
+		o.yint.Apply(1, o.stpY[0])
+		return o.yint
+	}
+	i := sort.SearchFloat64s(o.stpX[:n], x) - 1 // stpX[i] < x ≤ stpX[i+1]
+	if i < 0 {
+		i = 0
+	}
+	if i > n-2 {
+		i = n - 2
+	}
+	h := o.stpX[i+1] - o.stpX[i]
+	t := (x - o.stpX[i]) / h
+	h00 := (1 + 2*t) * (1 - t) * (1 - t)
+	h10 := t * (1 - t) * (1 - t) * h
+	h01 := t * t * (3 - 2*t)
+	h11 := t * t * (t - 1) * h
+	y0, f0, y1, f1 := o.stpY[i], o.stpF[i], o.stpY[i+1], o.stpF[i+1]
+	for m := 0; m < o.data.ndim; m++ {
+		o.yint[m] = h00*y0[m] + h10*f0[m] + h01*y1[m] + h11*f1[m]
+	}
+	return o.yint
+}
+
+// newSensConfig returns a copy of the configuration without output functions and events
+func newSensConfig(conf *Config) (o *Config) {
+	o = new(Config)
+	*o = *conf
+	o.stepF, o.denseF = nil, nil
+	o.stepOut, o.denseOut = false, false
+	o.events = nil
+	return
+}
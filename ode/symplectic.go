@@ -0,0 +1,361 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// Symplectic implements symplectic (geometric) and Runge-Kutta-Nyström integrators for
+// second-order systems
+//
+//   d²{q}/dx² = {a}(x, {q})   ⇔   d{q}/dx = {v}   and   d{v}/dx = {a}(x, {q})
+//
+//   These systems correspond to separable Hamiltonians H = ½ {v}ᵀ{v} + V(x, {q}) with
+//   {a} = -dV/d{q}. The symplectic methods do not exhibit drift of energy (or other invariants)
+//   over long-time integrations, unlike standard Runge-Kutta methods (e.g. dopri5).
+//
+//   The methods available are:
+//     verlet   -- 2 Störmer-Verlet (velocity version; kick-drift-kick) [1, page 190]
+//     ruth3    -- 3 Ruth's symplectic partitioned Runge-Kutta method [2]
+//     yoshida4 -- 4 Yoshida's (Forest-Ruth) composition of three Verlet steps [3]
+//     yoshida6 -- 6 Yoshida's composition of seven Verlet steps (solution A) [3]
+//     rkn4     -- 4 Nyström's Runge-Kutta-Nyström method with 3 stages [4, page 285]
+//     rkn43    -- 4(3) rkn4 with embedded 3rd order error estimator using the FSAL stage
+//
+//   The symplectic methods (verlet, ruth3, yoshida4 and yoshida6) require fixed steps since
+//   varying the stepsize destroys their good long-time behaviour. The rkn43 method can be used
+//   with variable steps.
+//
+//   The output y vector has size 2⋅ndim and corresponds to {y} = {q, v}.
+//
+//   References:
+//     [1] Hairer E, Lubich C, Wanner G (2006) Geometric Numerical Integration. Structure-
+//         Preserving Algorithms for Ordinary Differential Equations. Second Edition. Springer
+//         Series in Computational Mathematics, Vol. 31, 644p
+//     [2] Ruth RD (1983) A canonical integration technique. IEEE Transactions on Nuclear
+//         Science, 30(4):2669-2671
+//     [3] Yoshida H (1990) Construction of higher order symplectic integrators. Physics
+//         Letters A, 150(5-7):262-268
+//     [4] Hairer E, Nørsett SP, Wanner G (1993). Solving Ordinary Differential Equations I:
+//         Nonstiff Problems. Springer Series in Computational Mathematics, Vol. 8, 523p
+//
+type Symplectic struct {
+
+	// structures
+	conf *Config // configuration parameters
+	Out  *Output // output handler
+	Stat *Stat   // statistics
+
+	// problem definition
+	ndim int  // size of q
+	acc  AccF // d²q/dx² = a(x,q)
+
+	// method
+	method string    // method name
+	P      int       // order of method
+	rkn    bool      // Runge-Kutta-Nyström method
+	embed  bool      // RKN method has embedded error estimator
+	kick   []float64 // splitting methods: coefficients of kicks (update of v) [nstg+1]
+	drift  []float64 // splitting methods: coefficients of drifts (update of q) [nstg]
+
+	// state
+	y  la.Vector // {q, v} at x
+	a  la.Vector // a(x, q) at x (FSAL)
+	y0 la.Vector // {q, v} at the beginning of the last step (for dense output)
+	a0 la.Vector // a at the beginning of the last step (for dense output)
+
+	// workspace
+	k    []la.Vector // RKN stages k2 and k3 (k1 = a0)
+	w    la.Vector   // auxiliary q
+	yerr la.Vector   // RKN error estimate
+}
+
+// NewSymplectic returns a new integrator for second-order systems
+//
+//  INPUT:
+//    method -- the method; e.g. verlet, ruth3, yoshida4, yoshida6, rkn4, rkn43
+//    ndim   -- dimension of q
+//    conf   -- configuration parameters; e.g. with conf.SetFixedH(dx, xf)
+//    acc    -- d²q/dx² = a(x,q) function
+//
+//  NOTE: conf.method is ignored
+//
+func NewSymplectic(method string, ndim int, conf *Config, acc AccF) (o *Symplectic) {
+
+	// main
+	o = new(Symplectic)
+	o.conf = conf
+	o.Out = newOutput(2*ndim, conf)
+	o.Stat = NewStat("", false)
+	o.ndim = ndim
+	o.acc = acc
+	o.method = method
+
+	// coefficients
+	switch method {
+	case "verlet":
+		o.P = 2
+		o.setComposition([]float64{1})
+	case "ruth3":
+		o.P = 3
+		o.kick = []float64{7.0 / 24.0, 3.0 / 4.0, -1.0 / 24.0, 0}
+		o.drift = []float64{2.0 / 3.0, -2.0 / 3.0, 1}
+	case "yoshida4":
+		o.P = 4
+		w1 := 1.0 / (2.0 - math.Cbrt(2))
+		w0 := 1.0 - 2.0*w1
+		o.setComposition([]float64{w1, w0, w1})
+	case "yoshida6":
+		o.P = 6
+		w1 := -1.17767998417887
+		w2 := 0.235573213359357
+		w3 := 0.784513610477560
+		w0 := 1.0 - 2.0*(w1+w2+w3)
+		o.setComposition([]float64{w3, w2, w1, w0, w1, w2, w3})
+	case "rkn4", "rkn43":
+		o.P = 4
+		o.rkn = true
+		o.embed = method == "rkn43"
+	default:
+		chk.Panic("cannot find symplectic/RKN method named %q\n", method)
+	}
+
+	// workspace
+	o.y = la.NewVector(2 * ndim)
+	o.a = la.NewVector(ndim)
+	o.y0 = la.NewVector(2 * ndim)
+	o.a0 = la.NewVector(ndim)
+	o.w = la.NewVector(ndim)
+	if o.rkn {
+		o.k = make([]la.Vector, 2)
+		for i := 0; i < 2; i++ {
+			o.k[i] = la.NewVector(ndim)
+		}
+		o.yerr = la.NewVector(2 * ndim)
+	}
+
+	// connect dense output function
+	o.Out.dout = o.denseOut
+	return
+}
+
+// setComposition sets the coefficients of kicks and drifts corresponding to the composition of
+// velocity Verlet steps with stepsizes wi⋅h
+func (o *Symplectic) setComposition(ws []float64) {
+	n := len(ws)
+	o.kick = make([]float64, n+1)
+	o.drift = make([]float64, n)
+	for i, w := range ws {
+		o.kick[i] += w / 2
+		o.drift[i] = w
+		o.kick[i+1] += w / 2
+	}
+}
+
+// Solve solves d²q/dx² = a(x,q) from x to xf
+//
+//  INPUT:
+//    q -- initial positions q(x)
+//    v -- initial velocities dq/dx(x)
+//
+//  OUTPUT:
+//    q -- final positions q(xf)
+//    v -- final velocities dq/dx(xf)
+//
+func (o *Symplectic) Solve(q, v la.Vector, x, xf float64) {
+
+	// check
+	if xf < x {
+		chk.Panic("xf=%v must be greater than x=%v\n", xf, x)
+	}
+	if !o.conf.fixed && !o.embed {
+		chk.Panic("method %q can only be used with fixed steps. make sure to call conf.SetFixedH > 0\n", o.method)
+	}
+
+	// initial state
+	n := o.ndim
+	copy(o.y[:n], q)
+	copy(o.y[n:], v)
+	o.Stat.Reset()
+	o.Stat.Nfeval++
+	o.acc(o.a, x, q)
+
+	// output
+	defer func() {
+		copy(q, o.y[:n])
+		copy(v, o.y[n:])
+	}()
+	if o.Out.execute(0, false, 0, 0, x, o.y) {
+		return
+	}
+
+	// fixed steps
+	if o.conf.fixed {
+		x0 := x
+		nsteps := int(math.Ceil((xf-x0)/o.conf.fixedH - 1e-8)) // fixedH is computed with x0=0
+		h := (xf - x0) / float64(nsteps)
+		for i := 0; i < nsteps; i++ {
+			o.step(x, h)
+			o.Stat.Nsteps++
+			o.Stat.Naccepted++
+			x = x0 + float64(i+1)*h
+			if o.conf.Verbose {
+				io.Pfgreen("x = %v\n", x)
+				io.Pf("y = %v\n", o.y)
+			}
+			if o.Out.execute(o.Stat.Naccepted, i == nsteps-1, 0, h, x, o.y) {
+				return
+			}
+		}
+		o.Stat.Hopt = h
+		return
+	}
+
+	// variable steps
+	dmin, dmax := 1.0/o.conf.Mmin, 1.0/o.conf.Mmax
+	h := utl.Min(o.conf.IniH, xf-x)
+	var last, reject bool
+	for x < xf {
+
+		// check
+		if o.Stat.Nsteps == o.conf.NmaxSS {
+			chk.Panic("substepping did not converge after %d steps\n", o.conf.NmaxSS)
+		}
+		if h < o.conf.Hmin {
+			chk.Panic("stepsize is too small: h=%g < Hmin=%g\n", h, o.conf.Hmin)
+		}
+
+		// step
+		o.Stat.Nsteps++
+		rerr := o.step(x, h)
+		d := math.Pow(rerr, 0.25) / o.conf.Mfac // rerr^(1/(q+1)) with q = 3
+
+		// accepted
+		if rerr < 1 {
+			o.Stat.Naccepted++
+			if last {
+				x = xf
+			} else {
+				x += h
+			}
+			if o.Out.execute(o.Stat.Naccepted, last, 0, h, x, o.y) {
+				return
+			}
+			if last {
+				o.Stat.Hopt = h
+				break
+			}
+			hnew := h / utl.Max(dmax, utl.Min(dmin, d))
+			if reject {
+				hnew = utl.Min(h, hnew)
+			}
+			reject = false
+			if x+hnew >= xf {
+				last = true
+				hnew = xf - x
+			}
+			h = hnew
+			continue
+		}
+
+		// rejected: restore state
+		if o.Stat.Naccepted > 0 {
+			o.Stat.Nrejected++
+		}
+		o.y.Apply(1, o.y0)
+		o.a.Apply(1, o.a0)
+		reject = true
+		last = false
+		h = h / utl.Min(dmin, d)
+	}
+}
+
+// step performs one step from x to x+h updating y and a
+//   rerr -- relative error estimate (rkn43 only)
+func (o *Symplectic) step(x, h float64) (rerr float64) {
+	n := o.ndim
+	q, v := o.y[:n], o.y[n:]
+	o.y0.Apply(1, o.y)
+	o.a0.Apply(1, o.a)
+
+	// splitting methods: v += bi⋅h⋅a(q) and q += ai⋅h⋅v
+	if !o.rkn {
+		xq := x // x corresponding to q
+		for i, b := range o.kick {
+			if b != 0 {
+				if i > 0 {
+					o.Stat.Nfeval++
+					o.acc(o.a, xq, q)
+				}
+				la.VecAdd(v, b*h, o.a, 1, v)
+			}
+			if i < len(o.drift) {
+				la.VecAdd(q, o.drift[i]*h, v, 1, q)
+				xq += o.drift[i] * h
+			}
+		}
+		if o.kick[len(o.kick)-1] == 0 { // keep a(x+h,q) for next step and dense output
+			o.Stat.Nfeval++
+			o.acc(o.a, x+h, q)
+		}
+		return
+	}
+
+	// Runge-Kutta-Nyström: c = {0, ½, 1}, a21 = ⅛, a32 = ½, b̄ = {⅙, ⅓, 0}, b = {⅙, ⅔, ⅙}
+	k1, k2, k3 := o.a0, o.k[0], o.k[1]
+	q0, v0 := o.y0[:n], o.y0[n:]
+	for m := 0; m < n; m++ {
+		o.w[m] = q0[m] + h*v0[m]/2 + h*h*k1[m]/8
+	}
+	o.Stat.Nfeval++
+	o.acc(k2, x+h/2, o.w)
+	for m := 0; m < n; m++ {
+		o.w[m] = q0[m] + h*v0[m] + h*h*k2[m]/2
+	}
+	o.Stat.Nfeval++
+	o.acc(k3, x+h, o.w)
+	for m := 0; m < n; m++ {
+		q[m] = q0[m] + h*v0[m] + h*h*(k1[m]+2*k2[m])/6
+		v[m] = v0[m] + h*(k1[m]+4*k2[m]+k3[m])/6
+	}
+	o.Stat.Nfeval++
+	o.acc(o.a, x+h, q) // k4 = a(x+h, q1) ⇒ FSAL
+	if !o.embed {
+		return
+	}
+
+	// error estimate: embedded 3rd order solution with b̄ = {⅓, 0, ⅙} and b = {⅙, ⅔, 0, ⅙}
+	var sum, sk float64
+	for m := 0; m < n; m++ {
+		o.yerr[m] = h * h * (-k1[m] + 2*k2[m] - k3[m]) / 6
+		o.yerr[n+m] = h * (k3[m] - o.a[m]) / 6
+	}
+	for m := 0; m < 2*n; m++ {
+		sk = o.conf.atol + o.conf.rtol*utl.Max(math.Abs(o.y0[m]), math.Abs(o.y[m]))
+		sum += (o.yerr[m] / sk) * (o.yerr[m] / sk)
+	}
+	rerr = utl.Max(math.Sqrt(sum/float64(2*n)), 1e-10)
+	return
+}
+
+// denseOut computes y = {q, v} @ xout within the last step by cubic Hermite interpolation
+func (o *Symplectic) denseOut(yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	n := o.ndim
+	t := (xout - x + h) / h
+	h00 := (1 + 2*t) * (1 - t) * (1 - t)
+	h10 := t * (1 - t) * (1 - t) * h
+	h01 := t * t * (3 - 2*t)
+	h11 := t * t * (t - 1) * h
+	for m := 0; m < n; m++ {
+		yout[m] = h00*o.y0[m] + h10*o.y0[n+m] + h01*y[m] + h11*y[n+m]
+		yout[n+m] = h00*o.y0[n+m] + h10*o.a0[m] + h01*y[n+m] + h11*o.a[m]
+	}
+}
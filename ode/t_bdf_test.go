@@ -0,0 +1,204 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/plt"
+)
+
+func TestBdf01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bdf01. Eq11 with dense output")
+
+	for _, method := range []string{"bdf", "ndf"} {
+
+		// problem
+		io.Pforan("\n. . . %s . . . \n", method)
+		p := ProbHwEq11()
+
+		// configuration
+		conf := NewConfig(method, "", nil)
+		conf.SetTols(1e-6, 1e-6)
+		conf.SetStepOut(true, nil)
+		conf.SetDenseOut(true, 0.1, p.Xf, nil)
+
+		// solver
+		sol := NewSolver(p.Ndim, conf, p.Fcn, p.Jac, nil)
+		defer sol.Free()
+
+		// solve ODE
+		sol.Solve(p.Y, 0.0, p.Xf)
+		sol.Stat.Print(false)
+
+		// check
+		chk.Float64(tst, "yFin", 1e-6, p.Y[0], p.CalcYana(0, p.Xf))
+		X := sol.Out.GetDenseX()
+		Y := sol.Out.GetDenseY(0)
+		chk.Int(tst, "number of dense outputs", len(X), 16)
+		for i, x := range X {
+			chk.Float64(tst, io.Sf("y(%.1f)", x), 2e-6, Y[i], p.CalcYana(0, x))
+		}
+
+		// plot
+		if chk.Verbose {
+			plt.Reset(true, nil)
+			p.Plot(method, 0, sol.Out, 101, true, nil, nil)
+			plt.Plot(X, Y, &plt.A{C: "b", M: "+", Ls: "none", L: "dense", NoClip: true})
+			plt.Save("/tmp/gosl/ode", "bdf01-"+method)
+		}
+	}
+}
+
+func TestBdf02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bdf02. Robertson and Van der Pol equations")
+
+	// reference solutions
+	solve := func(p *Problem, method string, numJac bool, atol, rtol float64) (stat *Stat) {
+		conf := NewConfig(method, "", nil)
+		conf.SetTols(atol, rtol)
+		conf.IniH = 1e-6
+		conf.NmaxSS = 5000
+		jac := p.Jac
+		if numJac {
+			jac = nil
+		}
+		sol := NewSolver(p.Ndim, conf, p.Fcn, jac, nil)
+		defer sol.Free()
+		sol.Solve(p.Y, 0.0, p.Xf)
+		return sol.Stat
+	}
+	rob := ProbRobertson()
+	vdp := ProbVanDerPol(0, false)
+	solve(rob, "radau5", false, 1e-12, 1e-12)
+	solve(vdp, "radau5", false, 1e-10, 1e-10)
+
+	// run
+	for _, method := range []string{"bdf", "ndf"} {
+		io.Pforan("\n. . . %s: Robertson . . . \n", method)
+		p := ProbRobertson()
+		stat := solve(p, method, false, 1e-10, 1e-7)
+		stat.Print(false)
+		chk.Array(tst, "y", 1e-7, p.Y, rob.Y)
+		if stat.Njeval > 10 {
+			tst.Errorf("the Jacobian should be reused by many steps. Njeval = %d\n", stat.Njeval)
+		}
+
+		io.Pforan("\n. . . %s: Van der Pol (numerical Jacobian) . . . \n", method)
+		p = ProbVanDerPol(0, false)
+		stat = solve(p, method, true, 1e-6, 1e-6)
+		stat.Print(false)
+		chk.Array(tst, "y", 1e-4, p.Y, vdp.Y)
+	}
+}
+
+func TestBdf03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bdf03. Transistor amplifier (mass matrix)")
+
+	// problem
+	p := ProbHwAmplifier()
+
+	// configurations
+	conf := NewConfig("bdf", "", nil)
+	conf.SetStepOut(true, nil)
+	conf.IniH = 1.0e-6
+	conf.NmaxSS = 2000
+	conf.SetTols(1e-11, 1e-5)
+
+	// ODE solver
+	sol := NewSolver(p.Ndim, conf, p.Fcn, p.Jac, p.M)
+	defer sol.Free()
+
+	// run
+	sol.Solve(p.Y, 0.0, p.Xf)
+	sol.Stat.Print(false)
+
+	// check
+	_, T := io.ReadTable("data/radau5_hwamplifier.dat")
+	yref := make([]float64, p.Ndim)
+	for j := 0; j < p.Ndim; j++ {
+		y := T[io.Sf("y%d", j)]
+		yref[j] = y[len(y)-1]
+	}
+	chk.Array(tst, "y", 1e-4, p.Y, yref)
+
+	// plot
+	if chk.Verbose {
+		plt.Reset(true, &plt.A{WidthPt: 450, Dpi: 150, Prop: 1.8, FszXtck: 6, FszYtck: 6})
+		X := sol.Out.GetStepX()
+		for j := 0; j < p.Ndim; j++ {
+			plt.Subplot(p.Ndim, 1, j+1)
+			plt.Plot(T["x"], T[io.Sf("y%d", j)], &plt.A{C: "k", M: "+", NoClip: true})
+			plt.Plot(X, sol.Out.GetStepY(j), &plt.A{C: "r", M: ".", Ms: 1, Ls: "none", NoClip: true})
+			plt.Gll("$x$", io.Sf("$y_%d$", j), nil)
+		}
+		plt.Save("/tmp/gosl/ode", "bdf03")
+	}
+}
+
+func TestBdf04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bdf04. Heat equation (method of lines)")
+
+	// du/dt = d²u/dx² with u(0,t) = u(1,t) = 0 and u(x,0) = sin(π⋅x)
+	n := 199 // number of interior nodes
+	dx := 1.0 / float64(n+1)
+	c := 1.0 / (dx * dx)
+	fcn := func(f la.Vector, h, t float64, u la.Vector) {
+		for i := 0; i < n; i++ {
+			f[i] = -2 * c * u[i]
+			if i > 0 {
+				f[i] += c * u[i-1]
+			}
+			if i < n-1 {
+				f[i] += c * u[i+1]
+			}
+		}
+	}
+	jac := func(dfdy *la.Triplet, h, t float64, u la.Vector) {
+		if dfdy.Max() == 0 {
+			dfdy.Init(n, n, 3*n)
+		}
+		dfdy.Start()
+		for i := 0; i < n; i++ {
+			dfdy.Put(i, i, -2*c)
+			if i > 0 {
+				dfdy.Put(i, i-1, c)
+			}
+			if i < n-1 {
+				dfdy.Put(i, i+1, c)
+			}
+		}
+	}
+	u := la.NewVectorMapped(n, func(i int) float64 { return math.Sin(math.Pi * float64(i+1) * dx) })
+
+	// solve
+	tf := 0.1
+	conf := NewConfig("ndf", "", nil)
+	conf.SetTols(1e-8, 1e-6)
+	sol := NewSolver(n, conf, fcn, jac, nil)
+	defer sol.Free()
+	sol.Solve(u, 0, tf)
+	sol.Stat.Print(false)
+
+	// check: λ = -4⋅c⋅sin²(π⋅dx/2) is the exact eigenvalue of the discrete problem
+	λ := -4 * c * math.Pow(math.Sin(math.Pi*dx/2), 2)
+	uana := la.NewVectorMapped(n, func(i int) float64 { return math.Sin(math.Pi*float64(i+1)*dx) * math.Exp(λ*tf) })
+	chk.Array(tst, "u", 1e-6, u, uana)
+	if sol.Stat.Njeval != 1 {
+		tst.Errorf("the Jacobian of a linear problem should be computed once. Njeval = %d\n", sol.Stat.Njeval)
+	}
+}
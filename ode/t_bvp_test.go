@@ -0,0 +1,235 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/plt"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+func TestBvp01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bvp01. linear problems: y'' = y and simply supported beam")
+
+	// y'' = y with y(0) = 0 and y(1) = 1 ⇒ y = sinh(x) / sinh(1)
+	fcn := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0], f[1] = y[1], y[0]
+	}
+	bc := func(g, ya, yb la.Vector) {
+		g[0], g[1] = ya[0], yb[0]-1
+	}
+	yana := func(x float64) (y, dydx float64) {
+		return math.Sinh(x) / math.Sinh(1), math.Cosh(x) / math.Sinh(1)
+	}
+	xs := utl.LinSpace(0, 1, 11)
+	y := la.NewVector(2)
+
+	// shooting
+	conf := NewConfig("dopri5", "", nil)
+	conf.SetTol(1e-10)
+	for _, nodes := range [][]float64{{0, 1}, {0, 0.3, 0.6, 1}} {
+		o := NewBvpShooting(2, conf, fcn, nil, bc)
+		o.Init(nodes, nil)
+		o.Solve()
+		io.Pforan("shooting: m = %d  Nit = %d  y(0) = %v\n", len(nodes)-1, o.Nit, o.Y[0])
+		for _, x := range xs {
+			o.Eval(y, x)
+			ya, dya := yana(x)
+			chk.Array(tst, io.Sf("y(%g)", x), 1e-9, y, []float64{ya, dya})
+		}
+		o.Free()
+	}
+
+	// collocation
+	for _, lsKind := range []string{la.SpDefaultKind(), "splu"} {
+		o := NewBvpColloc(2, fcn, nil, bc)
+		o.Rtol = 1e-6
+		o.LsKind = lsKind
+		o.Init(utl.LinSpace(0, 1, 5), nil)
+		o.Solve()
+		io.Pforan("collocation (%s): npts = %d  Nit = %d  Rmax = %g\n", lsKind, len(o.X), o.Nit, o.Rmax)
+		for _, x := range xs {
+			o.Eval(y, x)
+			ya, dya := yana(x)
+			chk.Array(tst, io.Sf("y(%g)", x), 1e-7, y, []float64{ya, dya})
+		}
+	}
+
+	// beam: w'''' = q with w = w'' = 0 at both ends ⇒ w = q (x⁴ - 2x³ + x) / 24
+	q := 2.0
+	fcn = func(f la.Vector, h, x float64, y la.Vector) {
+		f[0], f[1], f[2], f[3] = y[1], y[2], y[3], q
+	}
+	jac := func(dfdy *la.Triplet, h, x float64, y la.Vector) {
+		if dfdy.Max() == 0 {
+			dfdy.Init(4, 4, 3)
+		}
+		dfdy.Start()
+		dfdy.Put(0, 1, 1)
+		dfdy.Put(1, 2, 1)
+		dfdy.Put(2, 3, 1)
+	}
+	bc = func(g, ya, yb la.Vector) {
+		g[0], g[1], g[2], g[3] = ya[0], ya[2], yb[0], yb[2]
+	}
+	wana := func(x float64) float64 {
+		return q * (x*x*x*x - 2*x*x*x + x) / 24
+	}
+	y = la.NewVector(4)
+	sh := NewBvpShooting(4, conf, fcn, jac, bc)
+	defer sh.Free()
+	sh.Init([]float64{0, 0.5, 1}, nil)
+	sh.Solve()
+	co := NewBvpColloc(4, fcn, jac, bc)
+	co.Rtol = 1e-6
+	co.LsKind = "splu"
+	co.Init(utl.LinSpace(0, 1, 3), nil)
+	co.Solve()
+	io.Pforan("beam: shooting Nit = %d  collocation npts = %d\n", sh.Nit, len(co.X))
+	for _, x := range xs {
+		sh.Eval(y, x)
+		chk.Float64(tst, io.Sf("w(%g) shooting", x), 1e-10, y[0], wana(x))
+		co.Eval(y, x)
+		chk.Float64(tst, io.Sf("w(%g) collocation", x), 1e-7, y[0], wana(x))
+	}
+}
+
+func TestBvp02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bvp02. boundary layer with continuation")
+
+	// ε y'' = y' with y(0) = 0 and y(1) = 1
+	ε := 0.1
+	fcn := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0], f[1] = y[1], y[1]/ε
+	}
+	jac := func(dfdy *la.Triplet, h, x float64, y la.Vector) {
+		if dfdy.Max() == 0 {
+			dfdy.Init(2, 2, 2)
+		}
+		dfdy.Start()
+		dfdy.Put(0, 1, 1)
+		dfdy.Put(1, 1, 1/ε)
+	}
+	bc := func(g, ya, yb la.Vector) {
+		g[0], g[1] = ya[0], yb[0]-1
+	}
+	yana := func(x float64) float64 {
+		e := math.Exp(-1 / ε)
+		return (math.Exp((x-1)/ε) - e) / (1 - e)
+	}
+
+	// solve with decreasing ε
+	o := NewBvpColloc(2, fcn, jac, bc)
+	o.Atol, o.Rtol = 1e-8, 1e-5
+	o.Init(utl.LinSpace(0, 1, 11), func(y la.Vector, x float64) {
+		y[0], y[1] = x, 1
+	})
+	y := la.NewVector(2)
+	o.Continuation([]float64{0.1, 0.03, 0.01, 0.003}, func(p float64) {
+		ε = p
+	}, func(p float64) {
+		var emax float64
+		for _, x := range utl.LinSpace(0, 1, 101) {
+			o.Eval(y, x)
+			emax = utl.Max(emax, math.Abs(y[0]-yana(x)))
+		}
+		hlayer, hmax := 1.0, 0.0 // minimum spacing within the boundary layer and maximum spacing
+		for i := 1; i < len(o.X); i++ {
+			h := o.X[i] - o.X[i-1]
+			if o.X[i] > 1-ε {
+				hlayer = utl.Min(hlayer, h)
+			}
+			hmax = utl.Max(hmax, h)
+		}
+		io.Pforan("ε = %5g: npts = %4d  hlayer = %.2e  hmax = %.2e  Nmesh = %d  Nit = %2d  Rmax = %.2e  max error = %.2e\n", p, len(o.X), hlayer, hmax, o.Nmesh, o.Nit, o.Rmax, emax)
+		chk.Float64(tst, io.Sf("max error (ε=%g)", p), 1e-6, emax, 0)
+		if p < 0.1 && hlayer > 0.1*hmax {
+			tst.Errorf("the mesh should be refined within the boundary layer\n")
+		}
+	})
+
+	// plot
+	if chk.Verbose {
+		xx := utl.LinSpace(0, 1, 201)
+		yy := utl.GetMapped(xx, yana)
+		Y := make([]float64, len(o.X))
+		for i := range o.X {
+			Y[i] = o.Y[i][0]
+		}
+		plt.Reset(true, nil)
+		plt.Plot(xx, yy, &plt.A{C: "k", L: "analytical"})
+		plt.Plot(o.X, Y, &plt.A{C: "r", M: ".", Ls: "none", L: "collocation"})
+		plt.Gll("$x$", "$y$", nil)
+		plt.Save("/tmp/gosl/ode", "bvp02")
+	}
+}
+
+func TestBvp03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bvp03. Bratu's problem (steady reaction-diffusion)")
+
+	// y'' + λ exp(y) = 0 with y(0) = y(1) = 0
+	λ := 1.0
+	fcn := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0], f[1] = y[1], -λ*math.Exp(y[0])
+	}
+	bc := func(g, ya, yb la.Vector) {
+		g[0], g[1] = ya[0], yb[0]
+	}
+
+	// analytical solution (lower branch): y = -2 ln(cosh((x-½)θ/2) / cosh(θ/4)) with
+	// θ = √(2λ) cosh(θ/4)
+	ymid := func(λ float64) float64 {
+		θ := 1.0
+		for it := 0; it < 50; it++ {
+			r := θ - math.Sqrt(2*λ)*math.Cosh(θ/4)
+			θ -= r / (1 - math.Sqrt(2*λ)*math.Sinh(θ/4)/4)
+		}
+		return 2 * math.Log(math.Cosh(θ/4))
+	}
+
+	// shooting
+	conf := NewConfig("dopri5", "", nil)
+	conf.SetTol(1e-10)
+	sh := NewBvpShooting(2, conf, fcn, nil, bc)
+	defer sh.Free()
+	sh.Init([]float64{0, 0.5, 1}, nil)
+	sh.Solve()
+	io.Pforan("shooting: Nit = %d  y(½) = %v\n", sh.Nit, sh.Y[1][0])
+	chk.Float64(tst, "y(½) shooting", 1e-9, sh.Y[1][0], ymid(1))
+
+	// collocation with continuation in λ
+	o := NewBvpColloc(2, fcn, nil, bc)
+	o.Atol, o.Rtol = 1e-9, 1e-6
+	o.Init(utl.LinSpace(0, 1, 6), nil)
+	y := la.NewVector(2)
+	o.Continuation([]float64{0.5, 1, 2, 3, 3.5}, func(p float64) {
+		λ = p
+	}, func(p float64) {
+		o.Eval(y, 0.5)
+		io.Pforan("λ = %3g: npts = %3d  Nit = %2d  y(½) = %.10f\n", p, len(o.X), o.Nit, y[0])
+		chk.Float64(tst, io.Sf("y(½) (λ=%g)", p), 1e-6, y[0], ymid(p))
+	})
+}
+
+func TestBvp04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bvp04. invalid mesh")
+
+	defer chk.RecoverTstPanicIsOK(tst)
+	o := NewBvpColloc(1, nil, nil, nil)
+	o.Init([]float64{0, 1, 1}, nil)
+}
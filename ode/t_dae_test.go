@@ -0,0 +1,163 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/plt"
+)
+
+func TestDae01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dae01. simple DAE of index 1 with nonlinear algebraic equation")
+
+	p := ProbDaeSimple()
+	yana := la.NewVector(p.Ndim)
+	p.Yana(yana, 0)
+	chk.Array(tst, "Yana(0)", 1e-15, yana, p.Y)
+
+	// consistent initialisation
+	for _, numJac := range []bool{false, true} {
+		jac := p.Jac
+		if numJac {
+			jac = nil
+		}
+		conf := NewConfig("radau5", "", nil)
+		conf.SetAlgebraic(p.Alg)
+		y := la.Vector{1, 3}
+		ConsistentInit(y, 0, conf, p.Fcn, jac, p.M)
+		io.Pforan("numJac = %v: y(0) = %v\n", numJac, y)
+		chk.Array(tst, "y(0)", 1e-10, y, p.Y)
+	}
+
+	// solution
+	p.Yana(yana, p.Xf)
+	for _, method := range []string{"radau5", "bdf", "ndf"} {
+		for _, suppress := range []bool{false, true} {
+			conf := NewConfig(method, "", nil)
+			conf.SetTol(1e-8)
+			conf.SetAlgebraic(p.Alg)
+			conf.SuppressAlg = suppress
+			sol := NewSolver(p.Ndim, conf, p.Fcn, p.Jac, p.M)
+			y := p.Y.GetCopy()
+			sol.Solve(y, 0, p.Xf)
+			sol.Free()
+			io.Pforan("%6s (SuppressAlg = %5v): y(xf) = %v  nsteps = %d\n", method, suppress, y, sol.Stat.Nsteps)
+			chk.Array(tst, "y(xf)", 1e-6, y, yana)
+		}
+	}
+
+	// Problem.Solve uses the mass matrix
+	y, _, _ := p.Solve("radau5", false, false)
+	io.Pforan("Problem.Solve: y(xf) = %v\n", y)
+	chk.Array(tst, "y(xf) (Problem.Solve)", 1e-3, y, yana)
+}
+
+func TestDae02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dae02. Robertson's equation as DAE")
+
+	// reference: ODE version
+	rob := ProbRobertson()
+	conf := NewConfig("radau5", "", nil)
+	conf.SetTol(1e-10)
+	conf.IniH = 1e-6
+	sol := NewSolver(rob.Ndim, conf, rob.Fcn, rob.Jac, nil)
+	yref := rob.Y.GetCopy()
+	sol.Solve(yref, 0, rob.Xf)
+	sol.Free()
+	io.Pforan("yref = %v\n", yref)
+
+	// DAE version
+	p := ProbRobertsonDae()
+	for _, method := range []string{"radau5", "bdf"} {
+		conf := NewConfig(method, "", nil)
+		conf.SetTols(1e-10, 1e-8)
+		conf.IniH = 1e-6
+		conf.SetAlgebraic(p.Alg)
+		conf.SetStepOut(true, nil)
+		sol := NewSolver(p.Ndim, conf, p.Fcn, p.Jac, p.M)
+		y := p.Y.GetCopy()
+		sol.Solve(y, 0, p.Xf)
+		sol.Free()
+		io.Pforan("%6s: y = %v  nsteps = %d\n", method, y, sol.Stat.Nsteps)
+		chk.Array(tst, "y", 1e-7, y, yref)
+		var errSum float64
+		for i := 0; i < sol.Out.StepIdx; i++ {
+			Y := sol.Out.StepY[i]
+			errSum = math.Max(errSum, math.Abs(Y[0]+Y[1]+Y[2]-1))
+		}
+		io.Pforan("max |y0+y1+y2-1| = %v\n", errSum)
+		chk.Float64(tst, "mass conservation", 1e-12, errSum, 0)
+	}
+}
+
+func TestDae03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dae03. pendulum as DAE of index 1")
+
+	p := ProbPendulumDae()
+	g := 9.81
+	energy := func(y la.Vector) float64 {
+		return (y[2]*y[2]+y[3]*y[3])/2 + g*y[1]
+	}
+
+	// consistent initial value of λ
+	conf := NewConfig("radau5", "", nil)
+	conf.SetTol(1e-9)
+	conf.SetAlgebraic(p.Alg)
+	conf.SuppressAlg = true
+	conf.SetStepOut(true, nil)
+	y := p.Y.GetCopy()
+	ConsistentInit(y, 0, conf, p.Fcn, p.Jac, p.M)
+	io.Pforan("λ(0) = %v\n", y[4])
+	chk.Float64(tst, "λ(0)", 1e-12, y[4], g*math.Cos(math.Pi/4))
+	chk.Array(tst, "y(0): differential variables", 1e-17, y[:4], p.Y[:4])
+
+	// solution
+	E0 := energy(y)
+	sol := NewSolver(p.Ndim, conf, p.Fcn, p.Jac, p.M)
+	defer sol.Free()
+	sol.Solve(y, 0, p.Xf)
+	io.Pforan("y(xf) = %v  nsteps = %d\n", y, sol.Stat.Nsteps)
+	var errC, errE float64
+	for i := 0; i < sol.Out.StepIdx; i++ {
+		Y := sol.Out.StepY[i]
+		errC = math.Max(errC, math.Abs(Y[0]*Y[0]+Y[1]*Y[1]-1))
+		errE = math.Max(errE, math.Abs(energy(Y)-E0))
+	}
+	io.Pforan("max |x²+y²-1| = %v  max |E-E0| = %v\n", errC, errE)
+	chk.Float64(tst, "constraint", 1e-5, errC, 0)
+	chk.Float64(tst, "energy", 1e-4, errE, 0)
+
+	// plot
+	if chk.Verbose {
+		plt.Reset(true, &plt.A{Prop: 1})
+		plt.Plot(sol.Out.GetStepY(0), sol.Out.GetStepY(1), &plt.A{C: "r", M: ".", NoClip: true})
+		plt.Equal()
+		plt.Gll("$x$", "$y$", nil)
+		plt.Save("/tmp/gosl/ode", "dae03")
+	}
+}
+
+func TestDae04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dae04. inconsistent number of algebraic variables")
+
+	defer chk.RecoverTstPanicIsOK(tst)
+	p := ProbRobertsonDae()
+	conf := NewConfig("radau5", "", nil)
+	conf.SetAlgebraic([]bool{false, true, true})
+	ConsistentInit(p.Y.GetCopy(), 0, conf, p.Fcn, p.Jac, p.M)
+}
@@ -0,0 +1,137 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/fun"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/plt"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// ddeExp1 returns the solution of y'(x) = -y(x-1) with y(x) = 1 for x ≤ 0 (method of steps)
+//   y(x) = Σ_{k=0}^{n} (-1)ᵏ (x-k+1)ᵏ / k!     for     n-1 ≤ x ≤ n
+func ddeExp1(x float64) (y float64) {
+	if x < 0 {
+		return 1
+	}
+	for k := 0; float64(k)-1 < x; k++ {
+		y += math.Pow(-1, float64(k)) * math.Pow(x-float64(k)+1, float64(k)) / fun.Factorial22(k)
+	}
+	return
+}
+
+func TestDDE01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("DDE01. constant delay: y'(x) = -y(x-1)")
+
+	fcn := func(f la.Vector, h, x float64, y la.Vector, ylag []la.Vector) {
+		f[0] = -ylag[0][0]
+	}
+	hist := func(y la.Vector, x float64) {
+		y[0] = 1
+	}
+
+	xf := 3.5
+	for _, method := range []string{"dopri5", "dopri8", "radau5", "bdf"} {
+		conf := NewConfig(method, "", nil)
+		conf.SetTol(1e-9)
+		conf.NmaxSS = 5000
+		conf.SetStepOut(true, nil)
+		dde := NewDDE(1, conf, fcn, hist, &Delay{Tau: 1})
+		y := la.Vector{1}
+		dde.Solve(y, 0, xf)
+		dde.Free()
+		io.Pf("\n%s\n", method)
+		io.Pforan("y(xf) = %v  (exact = %v)\n", y[0], ddeExp1(xf))
+		io.Pforan("discontinuities: x = %v  level = %v\n", dde.DiscX, dde.DiscLevel)
+		io.Pforan("nfeval = %d  naccepted = %d  nrejected = %d\n", dde.Sol.Stat.Nfeval, dde.Sol.Stat.Naccepted, dde.Sol.Stat.Nrejected)
+		tol := 1e-7
+		if method == "bdf" {
+			tol = 1e-5
+		}
+		chk.Float64(tst, "y(xf)", tol, y[0], ddeExp1(xf))
+		chk.Array(tst, "DiscX", 1e-8, dde.DiscX, []float64{0, 1, 2, 3})
+		chk.Ints(tst, "DiscLevel", dde.DiscLevel, []int{0, 1, 2, 3})
+
+		// lagged values from recorded dense output
+		ylag := la.NewVector(1)
+		var err float64
+		for _, x := range utl.LinSpace(-1, xf, 46) {
+			dde.GetLagged(ylag, x)
+			err = utl.Max(err, math.Abs(ylag[0]-ddeExp1(x)))
+		}
+		io.Pforan("max error of lagged values = %v\n", err)
+		if err > 10*tol {
+			tst.Errorf("%s: lagged values are inaccurate: error = %g\n", method, err)
+		}
+
+		// plot
+		if chk.Verbose && method == "dopri5" {
+			X := utl.LinSpace(0, xf, 101)
+			Y := utl.GetMapped(X, ddeExp1)
+			plt.Reset(true, nil)
+			plt.Plot(X, Y, &plt.A{C: "b", L: "exact"})
+			plt.Plot(dde.Sol.Out.GetStepX(), dde.Sol.Out.GetStepY(0), &plt.A{C: "r", M: ".", Ls: "none", L: method})
+			plt.Gll("$x$", "$y$", nil)
+			plt.Save("/tmp/gosl/ode", "dde01")
+		}
+	}
+}
+
+func TestDDE02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("DDE02. state-dependent delay: y'(x) = -y(x-1-y(x))")
+
+	// y = 1-x for 0 ≤ x ≤ 1 and y = x-3+2exp(1-x) for 1 ≤ x ≤ 1+ln(2)
+	yana := func(x float64) float64 {
+		if x <= 1 {
+			return 1 - x
+		}
+		return x - 3 + 2*math.Exp(1-x)
+	}
+	fcn := func(f la.Vector, h, x float64, y la.Vector, ylag []la.Vector) {
+		f[0] = -ylag[0][0]
+	}
+	hist := func(y la.Vector, x float64) {
+		y[0] = 1
+	}
+	delay := &Delay{F: func(x float64, y la.Vector) float64 {
+		return 1 + y[0]
+	}}
+
+	for _, method := range []string{"dopri5", "radau5"} {
+		conf := NewConfig(method, "", nil)
+		conf.SetTol(1e-9)
+		conf.NmaxSS = 5000
+
+		// solution before second discontinuity
+		io.Pf("\n%s\n", method)
+		xf := 1.6
+		dde := NewDDE(1, conf, fcn, hist, delay)
+		y := la.Vector{1}
+		dde.Solve(y, 0, xf)
+		dde.Free()
+		io.Pforan("y(%g) = %v  (exact = %v)\n", xf, y[0], yana(xf))
+		chk.Float64(tst, "y(xf)", 1e-7, y[0], yana(xf))
+
+		// discontinuities
+		dde = NewDDE(1, conf, fcn, hist, delay)
+		y[0] = 1
+		dde.Solve(y, 0, 2)
+		dde.Free()
+		io.Pforan("discontinuities: x = %v  level = %v\n", dde.DiscX, dde.DiscLevel)
+		chk.Array(tst, "DiscX", 1e-7, dde.DiscX[:3], []float64{0, 1, 1 + math.Ln2})
+		chk.Ints(tst, "DiscLevel", dde.DiscLevel[:3], []int{0, 1, 2})
+		chk.Int(tst, "number of events", len(dde.Sol.Out.EventX), len(dde.DiscX)-1)
+	}
+}
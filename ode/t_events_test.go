@@ -0,0 +1,170 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/num"
+	"github.com/dicksontsai/gosl/plt"
+)
+
+func TestEvents01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Events01. threshold crossings (non-terminal)")
+
+	// y' = cos(x) ⇒ y = sin(x)
+	fcn := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0] = math.Cos(x)
+	}
+	g := func(x float64, y la.Vector) float64 {
+		return y[0] - 0.5
+	}
+
+	// configuration
+	conf := NewConfig("dopri5", "", nil)
+	conf.SetTol(1e-8)
+	conf.SetStepOut(true, nil)
+	conf.SetEvents(
+		&Event{G: g},               // any direction
+		&Event{G: g, Direction: 1}, // increasing only
+		&Event{G: g, Direction: -1, Terminal: true, Reset: func(k int, x float64, y la.Vector) bool {
+			return x > 6 // stop at the second crossing
+		}},
+	)
+
+	// solver
+	sol := NewSolver(1, conf, fcn, nil, nil)
+	defer sol.Free()
+	y := la.Vector{0}
+	sol.Solve(y, 0, 10)
+
+	// check
+	π := math.Pi
+	io.Pforan("events: k = %v\n", sol.Out.EventK)
+	io.Pforan("        x = %v\n", sol.Out.EventX)
+	chk.Ints(tst, "k", sol.Out.EventK, []int{0, 1, 0, 2, 0, 1, 0, 2})
+	chk.Array(tst, "x (any)", 1e-7, sol.Out.GetEventX(0), []float64{π / 6, 5 * π / 6, 13 * π / 6, 17 * π / 6})
+	chk.Array(tst, "x (up)", 1e-7, sol.Out.GetEventX(1), []float64{π / 6, 13 * π / 6})
+	chk.Array(tst, "x (down)", 1e-7, sol.Out.GetEventX(2), []float64{5 * π / 6, 17 * π / 6})
+	chk.Array(tst, "y (any)", 1e-8, sol.Out.GetEventY(-1, 0), []float64{0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5})
+
+	// stopped at terminal event
+	chk.Float64(tst, "y (final)", 1e-8, y[0], 0.5)
+	X := sol.Out.GetStepX()
+	chk.Float64(tst, "x (final)", 1e-7, X[len(X)-1], 17*π/6)
+}
+
+func TestEvents02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Events02. bouncing ball (terminal with reset)")
+
+	// y0'' = -g
+	grav, e, H := 9.81, 0.8, 10.0
+	fcn := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0] = y[1]
+		f[1] = -grav
+	}
+	impact := &Event{
+		G:         func(x float64, y la.Vector) float64 { return y[0] },
+		Direction: -1,
+		Terminal:  true,
+		Reset: func(k int, x float64, y la.Vector) bool {
+			y[0] = 0
+			y[1] = -e * y[1]
+			return false
+		},
+	}
+
+	// analytical impact times
+	nimp := 4
+	xImp := make([]float64, nimp)
+	v := math.Sqrt(2 * grav * H)
+	xImp[0] = v / grav
+	for i := 1; i < nimp; i++ {
+		v *= e
+		xImp[i] = xImp[i-1] + 2*v/grav
+	}
+	xf := (xImp[nimp-1] + xImp[nimp-2]) / 2
+
+	for _, method := range []string{"dopri5", "dopri8", "radau5", "bdf"} {
+
+		// configuration
+		conf := NewConfig(method, "", nil)
+		conf.SetTol(1e-7)
+		conf.SetStepOut(true, nil)
+		conf.SetDenseOut(true, 0.05, xf, nil)
+		conf.SetEvents(impact)
+
+		// solve
+		sol := NewSolver(2, conf, fcn, nil, nil)
+		defer sol.Free()
+		y := la.Vector{H, 0}
+		sol.Solve(y, 0, xf)
+
+		// check
+		io.Pforan("%s: x = %v\n", method, sol.Out.EventX)
+		chk.Array(tst, "x", 1e-6, sol.Out.EventX, xImp[:nimp-1])
+		chk.Array(tst, "y0", 1e-6, sol.Out.GetEventY(0, 0), []float64{0, 0, 0})
+		if sol.Out.GetDenseX()[sol.Out.DenseIdx-1] != xf {
+			tst.Errorf("dense output should reach the final x\n")
+		}
+
+		// plot
+		if chk.Verbose {
+			plt.Reset(true, nil)
+			plt.Plot(sol.Out.GetDenseX(), sol.Out.GetDenseY(0), &plt.A{C: "b", L: "dense", NoClip: true})
+			plt.Plot(sol.Out.GetStepX(), sol.Out.GetStepY(0), &plt.A{C: "r", M: ".", Ls: "none", L: "steps", NoClip: true})
+			plt.Plot(sol.Out.EventX, sol.Out.GetEventY(-1, 0), &plt.A{C: "k", M: "o", Ls: "none", L: "impacts", NoClip: true})
+			plt.Gll("$x$", "$y_0$", nil)
+			plt.Save("/tmp/gosl/ode", "events02-"+method)
+		}
+	}
+}
+
+func TestEvents03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Events03. stiff problem (terminal without reset)")
+
+	// reference: y(x) = 0.5
+	p := ProbHwEq11()
+	brent := num.NewBrent(func(x float64) float64 { return p.CalcYana(0, x) - 0.5 }, nil)
+	xRef := brent.Root(1.0, 1.5)
+
+	for _, method := range []string{"radau5", "bdf", "ndf"} {
+
+		// problem
+		p = ProbHwEq11()
+
+		// configuration
+		conf := NewConfig(method, "", nil)
+		conf.SetTol(1e-8)
+		conf.SetStepOut(true, nil)
+		conf.SetEvents(&Event{
+			G:         func(x float64, y la.Vector) float64 { return y[0] - 0.5 },
+			Direction: -1,
+			Terminal:  true,
+		})
+
+		// solve
+		sol := NewSolver(p.Ndim, conf, p.Fcn, p.Jac, nil)
+		defer sol.Free()
+		sol.Solve(p.Y, 0, p.Xf)
+
+		// check
+		X := sol.Out.GetStepX()
+		io.Pforan("%s: x = %v\n", method, sol.Out.EventX)
+		chk.Array(tst, "x", 1e-6, sol.Out.EventX, []float64{xRef})
+		chk.Float64(tst, "x (last step)", 1e-17, X[len(X)-1], sol.Out.EventX[0])
+		chk.Float64(tst, "y", 1e-6, p.Y[0], 0.5)
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/fun"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/plt"
+)
+
+func TestImex01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Imex01. order conditions of additive Runge-Kutta methods")
+
+	dot := func(u, v []float64) (res float64) {
+		for i := range u {
+			res += u[i] * v[i]
+		}
+		return
+	}
+	matvec := func(A [][]float64, v []float64) (res []float64) {
+		res = make([]float64, len(v))
+		for i := range A {
+			res[i] = dot(A[i], v)
+		}
+		return
+	}
+	sq := func(v []float64) (res []float64) {
+		res = make([]float64, len(v))
+		for i := range v {
+			res[i] = v[i] * v[i]
+		}
+		return
+	}
+	one := func(n int) (res []float64) {
+		res = make([]float64, n)
+		for i := range res {
+			res[i] = 1
+		}
+		return
+	}
+
+	for _, method := range []string{"ark3", "ark4"} {
+		o := newImexRK(method).(*ImexRK)
+		io.Pforan("%s: P = %d  Q = %d  Nstg = %d\n", method, o.P, o.Q, o.Nstg)
+		s := o.Nstg
+
+		// consistency and stiffly accurate
+		chk.Array(tst, method+": AN⋅1 = c", 1e-15, matvec(o.AN, one(s)), o.C)
+		chk.Array(tst, method+": AS⋅1 = c", 1e-15, matvec(o.AS, one(s)), o.C)
+		chk.Array(tst, method+": b = last row of AS", 1e-17, o.B, o.AS[s-1])
+
+		// order conditions (up to 3rd order with coupling)
+		for _, b := range [][]float64{o.B, o.Be} {
+			chk.Float64(tst, method+": b⋅1", 1e-15, dot(b, one(s)), 1)
+			chk.Float64(tst, method+": b⋅c", 1e-15, dot(b, o.C), 1.0/2.0)
+		}
+		chk.Float64(tst, method+": b⋅c²", 1e-15, dot(o.B, sq(o.C)), 1.0/3.0)
+		chk.Float64(tst, method+": b⋅AN⋅c", 1e-15, dot(o.B, matvec(o.AN, o.C)), 1.0/6.0)
+		chk.Float64(tst, method+": b⋅AS⋅c", 1e-15, dot(o.B, matvec(o.AS, o.C)), 1.0/6.0)
+
+		// 4th order conditions (explicit and implicit tables)
+		if o.P > 3 {
+			c3 := make([]float64, s)
+			bc := make([]float64, s)
+			for i := 0; i < s; i++ {
+				c3[i] = o.C[i] * o.C[i] * o.C[i]
+				bc[i] = o.B[i] * o.C[i]
+			}
+			chk.Float64(tst, method+": b⋅c³", 1e-15, dot(o.B, c3), 1.0/4.0)
+			for _, A := range [][][]float64{o.AN, o.AS} {
+				chk.Float64(tst, method+": (b∘c)⋅A⋅c", 1e-15, dot(bc, matvec(A, o.C)), 1.0/8.0)
+				chk.Float64(tst, method+": b⋅A⋅c²", 1e-15, dot(o.B, matvec(A, sq(o.C))), 1.0/12.0)
+				chk.Float64(tst, method+": b⋅A⋅A⋅c", 1e-15, dot(o.B, matvec(A, matvec(A, o.C))), 1.0/24.0)
+			}
+		}
+	}
+}
+
+func TestImex02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Imex02. convergence of IMEX and exponential methods")
+
+	// split problem: stiff linear part and non-stiff nonlinear part
+	//   y0' = -30 y0 + 10 y1 + y1² + cos(x)
+	//   y1' =  10 y0 - 20 y1 + sin(x) y0 + 1
+	fS := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0] = -30*y[0] + 10*y[1]
+		f[1] = 10*y[0] - 20*y[1]
+	}
+	jacS := func(dfdy *la.Triplet, h, x float64, y la.Vector) {
+		if dfdy.Max() == 0 {
+			dfdy.Init(2, 2, 4)
+		}
+		dfdy.Start()
+		dfdy.Put(0, 0, -30)
+		dfdy.Put(0, 1, 10)
+		dfdy.Put(1, 0, 10)
+		dfdy.Put(1, 1, -20)
+	}
+	fN := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0] = y[1]*y[1] + math.Cos(x)
+		f[1] = math.Sin(x)*y[0] + 1
+	}
+	y0 := []float64{1, 2}
+	xf := 2.0
+
+	// reference solution
+	conf := NewConfig("radau5", "", nil)
+	conf.SetTol(1e-13)
+	conf.SetSplit(fS, jacS, fN)
+	yref := la.NewVector(2)
+	copy(yref, y0)
+	sol := NewSolver(2, conf, nil, nil, nil)
+	sol.Solve(yref, 0, xf)
+	sol.Free()
+	io.Pforan("yref = %v\n", yref)
+
+	// convergence with fixed steps h = h0/2ᵏ (the exponential methods treat the linear part
+	// exactly and reach the asymptotic regime with larger steps)
+	h0s := map[string]float64{"ark3": 1.0 / 40.0, "ark4": 1.0 / 40.0, "etdrk4": 1.0 / 10.0, "exprb32": 1.0 / 10.0}
+	orders := map[string]float64{"ark3": 3, "ark4": 4, "etdrk4": 4, "exprb32": 3}
+	for _, method := range []string{"ark3", "ark4", "etdrk4", "exprb32"} {
+		hs := []float64{h0s[method], h0s[method] / 2, h0s[method] / 4, h0s[method] / 8}
+		errs := make([]float64, len(hs))
+		for k, h := range hs {
+			conf := NewConfig(method, "", nil)
+			conf.SetFixedH(h, xf)
+			conf.SetSplit(fS, jacS, fN)
+			y := la.NewVector(2)
+			copy(y, y0)
+			sol := NewSolver(2, conf, nil, nil, nil)
+			sol.Solve(y, 0, xf)
+			sol.Free()
+			errs[k] = math.Max(math.Abs(y[0]-yref[0]), math.Abs(y[1]-yref[1]))
+		}
+		n := len(hs) - 1
+		order := math.Log(errs[n-1]/errs[n]) / math.Log(2)
+		io.Pforan("%8s: errors = %.2e  order ≈ %.3f\n", method, errs, order)
+		chk.Float64(tst, method+": order", 0.25, order, orders[method])
+	}
+}
+
+func TestImex03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Imex03. advection-diffusion with Fourier pseudo-spectral method")
+
+	// u_t = -c u_x + ν u_xx with u(x,0) = sin(x) ⇒ u(x,t) = exp(-νt) sin(x - ct)
+	c, ν := 1.0, 0.5
+	N := 32
+	fou := fun.NewFourierInterp(N, "")
+	defer fou.Free()
+	fou.CalcU(math.Sin) // allocates U
+	uana := func(x, t float64) float64 {
+		return math.Exp(-ν*t) * math.Sin(x-c*t)
+	}
+
+	// stiff part: ν u_xx
+	fS := func(f la.Vector, h, t float64, u la.Vector) {
+		copy(fou.U, u)
+		fou.CalcA()
+		fou.CalcD2()
+		for j := 0; j < N; j++ {
+			f[j] = ν * fou.Du2[j]
+		}
+	}
+
+	// Jacobian of the stiff part: ν [D2] computed column by column
+	D2 := la.NewMatrix(N, N)
+	for k := 0; k < N; k++ {
+		fou.U.Fill(0)
+		fou.U[k] = 1
+		fou.CalcA()
+		fou.CalcD2()
+		for j := 0; j < N; j++ {
+			D2.Set(j, k, fou.Du2[j])
+		}
+	}
+	jacS := func(dfdy *la.Triplet, h, t float64, u la.Vector) {
+		if dfdy.Max() == 0 {
+			dfdy.Init(N, N, N*N)
+		}
+		dfdy.Start()
+		for i := 0; i < N; i++ {
+			for j := 0; j < N; j++ {
+				dfdy.Put(i, j, ν*D2.Get(i, j))
+			}
+		}
+	}
+
+	// non-stiff part: -c u_x
+	fN := func(f la.Vector, h, t float64, u la.Vector) {
+		copy(fou.U, u)
+		fou.CalcA()
+		fou.CalcD1()
+		for j := 0; j < N; j++ {
+			f[j] = -c * fou.Du1[j]
+		}
+	}
+
+	// solve
+	tf := 1.0
+	for _, method := range []string{"ark3", "ark4", "etdrk4", "exprb32"} {
+		conf := NewConfig(method, "", nil)
+		conf.SetSplit(fS, jacS, fN)
+		if method == "etdrk4" {
+			conf.SetFixedH(0.05, tf)
+		} else {
+			conf.SetTol(1e-8)
+		}
+		u := la.NewVector(N)
+		for j := 0; j < N; j++ {
+			u[j] = uana(fou.X[j], 0)
+		}
+		sol := NewSolver(N, conf, nil, nil, nil)
+		sol.Solve(u, 0, tf)
+		sol.Free()
+		var emax float64
+		for j := 0; j < N; j++ {
+			emax = math.Max(emax, math.Abs(u[j]-uana(fou.X[j], tf)))
+		}
+		io.Pforan("%8s: Nfeval = %4d  Nsteps = %3d  Nrejected = %2d  max error = %.2e\n", method, sol.Stat.Nfeval, sol.Stat.Nsteps, sol.Stat.Nrejected, emax)
+		chk.Float64(tst, method+": max error", 1e-5, emax, 0)
+
+		// plot
+		if chk.Verbose && method == "etdrk4" {
+			ua := make([]float64, N)
+			for j := 0; j < N; j++ {
+				ua[j] = uana(fou.X[j], tf)
+			}
+			plt.Reset(true, nil)
+			plt.Plot(fou.X, ua, &plt.A{C: "k", L: "analytical"})
+			plt.Plot(fou.X, u, &plt.A{C: "r", M: ".", Ls: "none", L: method})
+			plt.Gll("$x$", "$u$", nil)
+			plt.Save("/tmp/gosl/ode", "imex03")
+		}
+	}
+}
+
+func TestImex04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Imex04. split right-hand side is required")
+
+	defer chk.RecoverTstPanicIsOK(tst)
+	fcn := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0] = -y[0]
+	}
+	NewSolver(1, NewConfig("ark4", "", nil), fcn, nil, nil)
+}
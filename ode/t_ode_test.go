@@ -5,11 +5,13 @@
 package ode
 
 import (
+	"math"
 	"testing"
 	"time"
 
 	"github.com/dicksontsai/gosl/chk"
 	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
 	"github.com/dicksontsai/gosl/plt"
 )
 
@@ -275,3 +277,27 @@ func TestOde04(tst *testing.T) {
 		plt.Save("/tmp/gosl/ode", "ode4")
 	}
 }
+
+func TestOde05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("ode05: end exactly at xf despite round-off errors")
+
+	// solve y' = -y over many consecutive intervals with arbitrary ends; the sum x + h of the
+	// last step of each interval may differ from xf by round-off errors
+	conf := NewConfig("dopri5", "", nil)
+	conf.SetTol(1e-10)
+	sol := NewSolver(1, conf, func(f la.Vector, h, x float64, y la.Vector) {
+		f[0] = -y[0]
+	}, nil, nil)
+	defer sol.Free()
+	y := la.Vector{1}
+	x0 := -3.0
+	xa := x0
+	for k := 0; k < 200; k++ {
+		xb := xa + 0.01 + 0.001*math.Sqrt(float64(k))
+		sol.Solve(y, xa, xb)
+		xa = xb
+	}
+	chk.Float64(tst, "y(xf)", 1e-8, y[0], math.Exp(-(xa - x0)))
+}
@@ -0,0 +1,202 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/num"
+)
+
+// sensKinetics defines the consecutive reactions A → B → C with rates p0 and p1 and initial
+// concentration of A equal to p2
+//   y0' = -p0⋅y0             y0(0) = p2
+//   y1' =  p0⋅y0 - p1⋅y1     y1(0) = 0
+type sensKinetics struct {
+	p la.Vector // parameters
+}
+
+func (o *sensKinetics) fcn(f la.Vector, h, x float64, y la.Vector) {
+	f[0] = -o.p[0] * y[0]
+	f[1] = o.p[0]*y[0] - o.p[1]*y[1]
+}
+
+func (o *sensKinetics) jac(dfdy *la.Triplet, h, x float64, y la.Vector) {
+	if dfdy.Max() == 0 {
+		dfdy.Init(2, 2, 3)
+	}
+	dfdy.Start()
+	dfdy.Put(0, 0, -o.p[0])
+	dfdy.Put(1, 0, o.p[0])
+	dfdy.Put(1, 1, -o.p[1])
+}
+
+func (o *sensKinetics) dfdp(dfdp *la.Matrix, h, x float64, y la.Vector) {
+	dfdp.Set(0, 0, -y[0])
+	dfdp.Set(1, 0, y[0])
+	dfdp.Set(1, 1, -y[1])
+}
+
+func (o *sensKinetics) dy0dp() *la.Matrix {
+	return la.NewMatrixDeep2([][]float64{{0, 0, 1}, {0, 0, 0}})
+}
+
+func (o *sensKinetics) y0() la.Vector {
+	return la.Vector{o.p[2], 0}
+}
+
+// yana returns the analytical solution y(x) for parameters p
+func (o *sensKinetics) yana(p la.Vector, x float64) la.Vector {
+	e0, e1 := math.Exp(-p[0]*x), math.Exp(-p[1]*x)
+	return la.Vector{p[2] * e0, p[2] * p[0] * (e0 - e1) / (p[1] - p[0])}
+}
+
+// sana returns the analytical sensitivities dy/dp(x) computed by numerical differentiation
+func (o *sensKinetics) sana(x float64) (S *la.Matrix) {
+	S = la.NewMatrix(2, 3)
+	q := o.p.GetCopy()
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 3; j++ {
+			S.Set(i, j, num.DerivCen5(o.p[j], 1e-3, func(pj float64) float64 {
+				q[j] = pj
+				res := o.yana(q, x)[i]
+				q[j] = o.p[j]
+				return res
+			}))
+		}
+	}
+	return
+}
+
+func TestSens01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Sens01. forward sensitivities")
+
+	prob := &sensKinetics{la.Vector{2, 0.5, 1}}
+	xf := 3.0
+	Sana := prob.sana(xf)
+	io.Pforan("Sana =\n%v\n", Sana.Print("%14.8f"))
+
+	for _, method := range []string{"dopri5", "radau5", "bdf"} {
+		for _, numJac := range []bool{false, true} {
+			if method == "dopri5" && numJac {
+				continue
+			}
+			jac := prob.jac
+			if numJac {
+				jac = nil
+			}
+			io.Pf("\n%s (numJac = %v)\n", method, numJac)
+			conf := NewConfig(method, "", nil)
+			conf.SetTol(1e-10)
+			conf.NmaxSS = 5000
+			sens := NewSensFwd(2, 3, conf, prob.fcn, jac, prob.dfdp)
+			y := prob.y0()
+			S := prob.dy0dp()
+			sens.Solve(y, S, 0, xf)
+			sens.Free()
+			io.Pforan("S =\n%v\n", S.Print("%14.8f"))
+			chk.Array(tst, "y", 1e-7, y, prob.yana(prob.p, xf))
+			chk.Deep2(tst, "S", 1e-6, S.GetDeep2(), Sana.GetDeep2())
+		}
+	}
+}
+
+func TestSens02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Sens02. adjoint sensitivities (gradient of least-squares functional)")
+
+	// data from "true" parameters
+	prob := &sensKinetics{la.Vector{2, 0.5, 1}}
+	ptrue := la.Vector{1.5, 0.7, 1.2}
+	xobs := []float64{0, 0.5, 1, 2, 3}
+	dobs := make([]float64, len(xobs))
+	for k, x := range xobs {
+		dobs[k] = prob.yana(ptrue, x)[1]
+	}
+
+	// functional: Ψ = ½ Σ (y1(xk) - dk)² + ½ w p0²
+	w := 0.1
+	phi := func(dphidy, dphidp la.Vector, k int, x float64, y la.Vector) (φ float64) {
+		r := y[1] - dobs[k]
+		dphidy[1] = r
+		φ = r * r / 2
+		if k == 0 {
+			dphidp[0] = w * prob.p[0]
+			φ += w * prob.p[0] * prob.p[0] / 2
+		}
+		return
+	}
+
+	// reference
+	Ψfcn := func(p la.Vector) (Ψ float64) {
+		for k, x := range xobs {
+			r := prob.yana(p, x)[1] - dobs[k]
+			Ψ += r * r / 2
+		}
+		return Ψ + w*p[0]*p[0]/2
+	}
+	Ψref := Ψfcn(prob.p)
+	gref := la.NewVector(3)
+	q := prob.p.GetCopy()
+	for j := 0; j < 3; j++ {
+		gref[j] = num.DerivCen5(prob.p[j], 1e-3, func(pj float64) float64 {
+			q[j] = pj
+			res := Ψfcn(q)
+			q[j] = prob.p[j]
+			return res
+		})
+	}
+	io.Pforan("Ψref = %v\n", Ψref)
+	io.Pforan("gref = %v\n", gref)
+
+	// gradient using forward sensitivities
+	conf := NewConfig("dopri5", "", nil)
+	conf.SetTol(1e-10)
+	fwd := NewSensFwd(2, 3, conf, prob.fcn, prob.jac, prob.dfdp)
+	y := prob.y0()
+	S := prob.dy0dp()
+	gfwd := la.NewVector(3)
+	gfwd[0] = w * prob.p[0]
+	x := 0.0
+	for k, xk := range xobs {
+		if xk > x {
+			fwd.Solve(y, S, x, xk)
+			x = xk
+		}
+		for j := 0; j < 3; j++ {
+			gfwd[j] += (y[1] - dobs[k]) * S.Get(1, j)
+		}
+	}
+	fwd.Free()
+	io.Pforan("gfwd = %v\n", gfwd)
+	chk.Array(tst, "gfwd", 1e-7, gfwd, gref)
+
+	// adjoint
+	for _, method := range []string{"dopri5", "radau5", "bdf"} {
+		io.Pf("\n%s\n", method)
+		conf := NewConfig(method, "", nil)
+		conf.SetTol(1e-10)
+		conf.NmaxSS = 5000
+		adj := NewSensAdj(2, 3, conf, prob.fcn, prob.jac, prob.dfdp)
+		adj.Ncheck = 5
+		y := prob.y0()
+		grad := la.NewVector(3)
+		Ψ := adj.Gradient(grad, y, prob.dy0dp(), 0, xobs, phi)
+		io.Pforan("Ψ    = %v\n", Ψ)
+		io.Pforan("grad = %v\n", grad)
+		io.Pforan("number of checkpoints = %d\n", len(adj.chkX))
+		adj.Free()
+		chk.Float64(tst, "Ψ", 1e-9, Ψ, Ψref)
+		chk.Array(tst, "y", 1e-7, y, prob.yana(prob.p, xobs[len(xobs)-1]))
+		chk.Array(tst, "grad", 1e-6, grad, gref)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/plt"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// keplerAcc returns the acceleration of the Kepler problem: q'' = -q/|q|³
+func keplerAcc(a la.Vector, x float64, q la.Vector) {
+	r := math.Sqrt(q[0]*q[0] + q[1]*q[1])
+	r3 := r * r * r
+	a[0] = -q[0] / r3
+	a[1] = -q[1] / r3
+}
+
+// keplerEnergy returns the energy (Hamiltonian) of the Kepler problem
+func keplerEnergy(q, v la.Vector) float64 {
+	return (v[0]*v[0]+v[1]*v[1])/2 - 1/math.Sqrt(q[0]*q[0]+q[1]*q[1])
+}
+
+func TestSymplectic01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Symplectic01. convergence with harmonic oscillator")
+
+	// q'' = -q with q(0) = 1 and v(0) = 0 ⇒ q = cos(x)
+	acc := func(a la.Vector, x float64, q la.Vector) {
+		a[0] = -q[0]
+	}
+	xf := 2.0
+	methods := []string{"verlet", "ruth3", "yoshida4", "yoshida6", "rkn4", "rkn43"}
+	orders := []float64{2, 3, 4, 6, 4, 4}
+	for i, method := range methods {
+		var errs [2]float64
+		var nfe [2]int
+		for j, dx := range []float64{0.1, 0.05} {
+			conf := NewConfig("", "", nil)
+			conf.SetFixedH(dx, xf)
+			sol := NewSymplectic(method, 1, conf, acc)
+			q, v := la.Vector{1}, la.Vector{0}
+			sol.Solve(q, v, 0, xf)
+			errs[j] = math.Sqrt(math.Pow(q[0]-math.Cos(xf), 2) + math.Pow(v[0]+math.Sin(xf), 2))
+			nfe[j] = sol.Stat.Nfeval
+		}
+		order := math.Log2(errs[0] / errs[1])
+		io.Pforan("%9s: err = %.3e, %.3e  order = %.3f  nfeval = %d\n", method, errs[0], errs[1], order, nfe[1])
+		chk.Float64(tst, method+": order", 0.1, order, orders[i])
+	}
+}
+
+func TestSymplectic02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Symplectic02. energy of Kepler problem over many periods")
+
+	// Kepler problem with eccentricity e = 0.6 ⇒ period = 2π
+	e := 0.6
+	q0 := la.Vector{1 - e, 0}
+	v0 := la.Vector{0, math.Sqrt((1 + e) / (1 - e))}
+	H0 := keplerEnergy(q0, v0)
+	nper := 100.0
+	xf := nper * 2 * math.Pi
+
+	// drift of energy with dopri5
+	conf := NewConfig("dopri5", "", nil)
+	conf.SetTol(1e-6)
+	conf.NmaxSS = 100000
+	conf.SetStepOut(true, nil)
+	sol := NewSolver(4, conf, func(f la.Vector, h, x float64, y la.Vector) {
+		f[0], f[1] = y[2], y[3]
+		keplerAcc(f[2:], x, y[:2])
+	}, nil, nil)
+	y := la.Vector{q0[0], q0[1], v0[0], v0[1]}
+	sol.Solve(y, 0, xf)
+	nfeDopri := sol.Stat.Nfeval
+	errDopri := math.Abs(keplerEnergy(y[:2], y[2:]) - H0)
+	io.Pforan("%9s: |H-H0| (final) = %.3e  nfeval = %d\n", "dopri5", errDopri, nfeDopri)
+	sol.Free()
+
+	// symplectic methods
+	for _, method := range []string{"verlet", "yoshida4", "yoshida6"} {
+		conf := NewConfig("", "", nil)
+		conf.SetFixedH(2*math.Pi/400, xf)
+		var errMax, errFirst float64
+		conf.SetStepOut(false, func(istep int, h, x float64, y la.Vector) (stop bool) {
+			err := math.Abs(keplerEnergy(y[:2], y[2:]) - H0)
+			errMax = utl.Max(errMax, err)
+			if x <= 2*math.Pi {
+				errFirst = errMax
+			}
+			return
+		})
+		sym := NewSymplectic(method, 2, conf, keplerAcc)
+		q, v := q0.GetCopy(), v0.GetCopy()
+		sym.Solve(q, v, 0, xf)
+		errFinal := math.Abs(keplerEnergy(q, v) - H0)
+		io.Pforan("%9s: |H-H0| (final) = %.3e  max = %.3e  max(first period) = %.3e  nfeval = %d\n", method, errFinal, errMax, errFirst, sym.Stat.Nfeval)
+		if errMax > 1.01*errFirst {
+			tst.Errorf("%s: energy error should not grow: %g > %g\n", method, errMax, errFirst)
+		}
+		if method == "yoshida6" && errFinal > errDopri {
+			tst.Errorf("%s: energy error should be smaller than dopri5's: %g > %g\n", method, errFinal, errDopri)
+		}
+	}
+}
+
+func TestSymplectic03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Symplectic03. Arenstorf orbit in inertial frame")
+
+	// restricted three-body problem in inertial frame: the Earth and the Moon rotate with
+	// angular velocity ω = 1 about the centre of mass
+	μ := 0.012277471
+	μp := 1 - μ
+	acc := func(a la.Vector, x float64, q la.Vector) {
+		c, s := math.Cos(x), math.Sin(x)
+		d1x, d1y := q[0]+μ*c, q[1]+μ*s   // q - Earth
+		d2x, d2y := q[0]-μp*c, q[1]-μp*s // q - Moon
+		r1 := math.Pow(d1x*d1x+d1y*d1y, 1.5)
+		r2 := math.Pow(d2x*d2x+d2y*d2y, 1.5)
+		a[0] = -μp*d1x/r1 - μ*d2x/r2
+		a[1] = -μp*d1y/r1 - μ*d2y/r2
+	}
+
+	// rotating frame (ProbArenstorf) ⇔ inertial frame (x = time)
+	toInertial := func(q, v la.Vector, x float64, y la.Vector) {
+		c, s := math.Cos(x), math.Sin(x)
+		u, w := y[2]-y[1], y[3]+y[0] // velocity: y' + ω × y
+		q[0], q[1] = c*y[0]-s*y[1], s*y[0]+c*y[1]
+		v[0], v[1] = c*u-s*w, s*u+c*w
+	}
+	toRotating := func(y la.Vector, x float64, q, v la.Vector) {
+		c, s := math.Cos(x), math.Sin(x)
+		y[0], y[1] = c*q[0]+s*q[1], -s*q[0]+c*q[1]
+		u, w := c*v[0]+s*v[1], -s*v[0]+c*v[1]
+		y[2], y[3] = u+y[1], w-y[0]
+	}
+
+	// Jacobi constant (in rotating frame)
+	jacobi := func(y la.Vector) float64 {
+		r1 := math.Sqrt((y[0]+μ)*(y[0]+μ) + y[1]*y[1])
+		r2 := math.Sqrt((y[0]-μp)*(y[0]-μp) + y[1]*y[1])
+		return (y[0]*y[0] + y[1]*y[1]) + 2*μp/r1 + 2*μ/r2 - (y[2]*y[2] + y[3]*y[3])
+	}
+
+	// periodic orbit
+	prob := ProbArenstorf()
+	C0 := jacobi(prob.Y)
+	io.Pforan("C0 = %v\n", C0)
+
+	// rkn43 with variable steps
+	conf := NewConfig("", "", nil)
+	conf.SetTol(1e-10)
+	conf.NmaxSS = 100000
+	conf.SetStepOut(true, nil)
+	conf.SetDenseOut(true, 0.1, prob.Xf, nil)
+	sym := NewSymplectic("rkn43", 2, conf, acc)
+	q, v := la.NewVector(2), la.NewVector(2)
+	toInertial(q, v, 0, prob.Y)
+	sym.Solve(q, v, 0, prob.Xf)
+	y := la.NewVector(4)
+	toRotating(y, prob.Xf, q, v)
+	io.Pforan("y(xf) = %v\n", y)
+	io.Pforan("nfeval = %d  naccepted = %d  nrejected = %d\n", sym.Stat.Nfeval, sym.Stat.Naccepted, sym.Stat.Nrejected)
+	chk.Array(tst, "y(xf) = y(0)", 1e-5, y, prob.Y)
+	chk.Float64(tst, "C", 1e-8, jacobi(y), C0)
+
+	// dense output
+	chk.Int(tst, "DenseIdx", sym.Out.DenseIdx, 172)
+	var errC float64
+	for i := 0; i < sym.Out.DenseIdx; i++ {
+		x := sym.Out.DenseX[i]
+		toRotating(y, x, sym.Out.DenseY[i][:2], sym.Out.DenseY[i][2:])
+		errC = utl.Max(errC, math.Abs(jacobi(y)-C0))
+	}
+	io.Pforan("max error of Jacobi constant (dense output) = %v\n", errC)
+	if errC > 1e-6 {
+		tst.Errorf("dense output is inaccurate: error of Jacobi constant = %g\n", errC)
+	}
+
+	// plot
+	if chk.Verbose {
+		X := make([]float64, sym.Out.StepIdx)
+		Y := make([]float64, sym.Out.StepIdx)
+		for i := 0; i < sym.Out.StepIdx; i++ {
+			toRotating(y, sym.Out.StepX[i], sym.Out.StepY[i][:2], sym.Out.StepY[i][2:])
+			X[i], Y[i] = y[0], y[1]
+		}
+		plt.Reset(true, &plt.A{Prop: 1})
+		plt.Plot(X, Y, &plt.A{C: "r", M: ".", Ls: "-", NoClip: true})
+		plt.Equal()
+		plt.Save("/tmp/gosl/ode", "symplectic03")
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/fun/dbf"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// AugLag implements the minimization with equality and inequality constraints by the augmented
+// Lagrangian method (bound-constrained Lagrangian; Framework 17.3 and Section 17.4 of [1])
+//
+//   Solve:
+//          min f({x})   s.t.   h({x}) = 0,   c({x}) ≥ 0,   Xlo ≤ {x} ≤ Xhi
+//
+//   The inner problems are the minimization of the augmented Lagrangian
+//
+//     LA({x}; λ, μ, ρ) = f - λᵀh + (ρ/2) hᵀh + (1/2ρ) Σⱼ [max(0, μⱼ - ρ⋅cⱼ)² - μⱼ²]
+//
+//   with fixed multipliers and penalty ρ, subject to the bounds only. They are solved by the solver
+//   named Inner ("lbfgsb" by default; its backtracking line search copes well with the kinks of the
+//   max terms). After each inner solution, the multipliers are updated as λ := λ - ρ⋅h and
+//   μ := max(0, μ - ρ⋅c), and ρ is increased if the infeasibility is not sufficiently reduced.
+//
+//   NOTE: Check Convergence to see how to set convergence parameters, max iteration number (outer
+//         iterations), or to enable and access history of iterations. Check Constraints to see
+//         how to access the Lagrange multipliers and KKT residuals.
+//
+//   REFERENCES:
+//   [1] Nocedal, J. and Wright, S. (2006) Numerical Optimization.
+//       Springer Series in Operations Research. 2nd Edition. Springer. 664p
+//
+type AugLag struct {
+
+	// merge properties
+	Convergence // auxiliary object to check convergence
+	Constraints // constraints, multipliers and KKT residuals
+
+	// configuration
+	Inner  string  // kind of inner solver; e.g. "lbfgsb", "projgrad" or unconstrained ones if there are no bounds [default = "lbfgsb"]
+	Rho0   float64 // initial penalty ρ [default = 10]
+	RhoMax float64 // maximum penalty ρ [default = 1e10]
+
+	// statistics
+	Rho float64 // current penalty ρ
+
+	// internal
+	prob  *Problem     // inner problem
+	g     la.Vector    // gradient of f
+	xold  la.Vector    // previous x
+	λρ    la.Vector    // λ - ρ⋅h
+	μρ    la.Vector    // max(0, μ - ρ⋅c)
+	inner NonLinSolver // inner solver
+}
+
+// add optimizer to database
+func init() {
+	nlsMakersDB["auglag"] = func(prob *Problem) NonLinSolver { return NewAugLag(prob) }
+}
+
+// NewAugLag returns a new constrained optimizer using the augmented Lagrangian method
+func NewAugLag(prob *Problem) (o *AugLag) {
+	o = new(AugLag)
+	o.InitConvergence(prob.Ffcn, prob.Gfcn)
+	o.InitConstraints(prob)
+	o.Inner = "lbfgsb"
+	o.Rho0 = 10
+	o.RhoMax = 1e10
+	o.g = la.NewVector(prob.Ndim)
+	o.xold = la.NewVector(prob.Ndim)
+	o.λρ = la.NewVector(o.Neq)
+	o.μρ = la.NewVector(o.Nineq)
+
+	// inner problem: minimize the augmented Lagrangian with fixed λ, μ and ρ
+	o.prob = &Problem{Ndim: prob.Ndim, Xlo: o.Xlo, Xhi: o.Xhi}
+	o.prob.Ffcn = func(x la.Vector) (fa float64) {
+		fa = o.Ffcn(x)
+		o.evalConstraints(x)
+		for i := 0; i < o.Neq; i++ {
+			fa += -o.Lambda[i]*o.h[i] + 0.5*o.Rho*o.h[i]*o.h[i]
+		}
+		for j := 0; j < o.Nineq; j++ {
+			fa += (math.Pow(utl.Max(0, o.Mu[j]-o.Rho*o.c[j]), 2) - o.Mu[j]*o.Mu[j]) / (2 * o.Rho)
+		}
+		return
+	}
+	o.prob.Gfcn = func(ga, x la.Vector) {
+		o.Gfcn(ga, x)
+		o.evalConstraints(x)
+		o.evalJacobians(x)
+		o.multipliersEstimate()
+		o.lagGradient(ga, ga, o.λρ, o.μρ)
+	}
+	return
+}
+
+// Min solves minimization problem
+//
+//  Input:
+//    x -- [ndim] initial starting point (will be modified)
+//    params -- [may be nil] optional parameters. e.g. "rho0", "maxit". Example:
+//                 params := dbf.NewParams(
+//                     &dbf.P{N: "rho0", V: 10},
+//                     &dbf.P{N: "rhomax", V: 1e10},
+//                     &dbf.P{N: "ctol", V: 1e-6},
+//                     &dbf.P{N: "maxit", V: 100},
+//                     &dbf.P{N: "gtol", V: 1e-6},
+//                     &dbf.P{N: "hist", V: 1},
+//                     &dbf.P{N: "verb", V: 1},
+//                 )
+//
+//  Output:
+//    fmin -- f(x@min) minimum f({x}) found
+//    x -- [modify input] position of minimum f({x})
+//    o.Lambda -- multipliers of equality constraints
+//    o.Mu -- multipliers of inequality constraints
+//    o.ResStat, o.ResFeas, o.ResCompl -- KKT residuals
+//
+func (o *AugLag) Min(x la.Vector, params dbf.Params) (fmin float64) {
+
+	// set parameters
+	o.Convergence.SetParams(params)
+	o.SetConstraintsParams(params)
+	o.Rho0 = params.GetValueOrDefault("rho0", o.Rho0)
+	o.RhoMax = params.GetValueOrDefault("rhomax", o.RhoMax)
+
+	// inner solver
+	if o.inner == nil {
+		o.inner = GetNonLinSolver(o.Inner, o.prob)
+	}
+
+	// initializations
+	o.NumFeval, o.NumGeval, o.NumCeval, o.NumJeval = 0, 0, 0, 0
+	o.Lambda.Fill(0)
+	o.Mu.Fill(0)
+	o.Rho = o.Rho0
+	if o.HasBounds() {
+		projectOnBox(x, x, o.Xlo, o.Xhi)
+	}
+	fmin = o.Ffcn(x)
+
+	// history
+	if o.UseHist {
+		o.InitHist(x)
+	}
+
+	// exit point # 1: initial point satisfies the KKT conditions
+	o.Gfcn(o.g, x)
+	o.evalConstraints(x)
+	o.evalJacobians(x)
+	if o.KKTconvergence(x, o.g, o.Gtol) {
+		return
+	}
+
+	// iterations
+	feasPrev := math.Inf(1)
+	ω := 1e-2 // tolerance of inner problems
+	for o.NumIter = 0; o.NumIter < o.MaxIt; o.NumIter++ {
+
+		// solve inner problem
+		ω = utl.Max(0.1*o.Gtol, ω)
+		o.xold.Apply(1, x)
+		o.inner.Min(x, dbf.NewParams(
+			&dbf.P{N: "maxit", V: 10000},
+			&dbf.P{N: "ftol", V: 1e-15},
+			&dbf.P{N: "gtol", V: ω},
+		))
+		fmin = o.Ffcn(x)
+
+		// update multipliers
+		o.Gfcn(o.g, x)
+		o.evalConstraints(x)
+		o.evalJacobians(x)
+		o.multipliersEstimate()
+		o.Lambda.Apply(1, o.λρ)
+		o.Mu.Apply(1, o.μρ)
+
+		// history
+		if o.UseHist {
+			la.VecAdd(o.uhist, 1, x, -1, o.xold)
+			o.Hist.Append(fmin, x, o.uhist)
+		}
+
+		// exit point # 2: KKT conditions are satisfied
+		if o.KKTconvergence(x, o.g, o.Gtol) {
+			return
+		}
+
+		// update penalty and tolerance of inner problems
+		if o.ResFeas > o.Ctol && o.ResFeas > 0.1*feasPrev {
+			o.Rho = utl.Min(10*o.Rho, o.RhoMax)
+		}
+		feasPrev = o.ResFeas
+		ω *= 0.1
+	}
+
+	// did not converge
+	chk.Panic("fail to converge after %d iterations\n", o.NumIter)
+	return
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// multipliersEstimate computes λρ := λ - ρ⋅h and μρ := max(0, μ - ρ⋅c) using o.h and o.c
+func (o *AugLag) multipliersEstimate() {
+	for i := 0; i < o.Neq; i++ {
+		o.λρ[i] = o.Lambda[i] - o.Rho*o.h[i]
+	}
+	for j := 0; j < o.Nineq; j++ {
+		o.μρ[j] = utl.Max(0, o.Mu[j]-o.Rho*o.c[j])
+	}
+}
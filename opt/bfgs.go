@@ -0,0 +1,177 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/fun/dbf"
+	"github.com/dicksontsai/gosl/la"
+)
+
+// BFGS implements the multidimensional minimization by the Broyden-Fletcher-Goldfarb-Shanno
+// quasi-Newton method (dense approximation of the inverse Hessian)
+//
+//   The inverse Hessian approximation [H] is updated after each step {s} = {x}new - {x} with
+//   {y} = {g}new - {g} and ρ = 1/(yᵀs) according to
+//
+//     [H] ← ([I] - ρ {s}⊗{y}) [H] ([I] - ρ {y}⊗{s}) + ρ {s}⊗{s}
+//
+//   where the initial [H] is scaled by (yᵀs)/(yᵀy) after the first step (Eq. 6.20 of [1]). The
+//   update is skipped if yᵀs is not sufficiently positive. The step length along {u} = -[H]⋅{g}
+//   is computed by LineSearch.Wolfe.
+//
+//   NOTE: Check Convergence to see how to set convergence parameters,
+//         max iteration number, or to enable and access history of iterations
+//
+//   REFERENCES:
+//   [1] Nocedal, J. and Wright, S. (2006) Numerical Optimization.
+//       Springer Series in Operations Research. 2nd Edition. Springer. 664p
+//
+type BFGS struct {
+
+	// merge properties
+	Convergence // auxiliary object to check convergence
+
+	// access
+	Hmat *la.Matrix // approximation of the inverse Hessian
+
+	// internal
+	g    la.Vector // gradient
+	gnew la.Vector // new gradient
+	u    la.Vector // direction
+	s    la.Vector // step: xnew - x
+	y    la.Vector // change of gradient: gnew - g
+	hy   la.Vector // [H]⋅{y}
+	zero float64   // minimum yᵀs to update [H]
+
+	// line search
+	lines *LineSearch // line search
+}
+
+// add optimizer to database
+func init() {
+	nlsMakersDB["bfgs"] = func(prob *Problem) NonLinSolver { return NewBFGS(prob) }
+}
+
+// NewBFGS returns a new multidimensional optimizer using the BFGS quasi-Newton method
+func NewBFGS(prob *Problem) (o *BFGS) {
+	o = new(BFGS)
+	o.InitConvergence(prob.Ffcn, prob.Gfcn)
+	o.lines = NewLineSearch(prob.Ndim, o.Ffcn, o.Gfcn)
+	o.lines.Coef2 = 0.9 // recommended for quasi-Newton methods
+	o.Hmat = la.NewMatrix(prob.Ndim, prob.Ndim)
+	o.g = la.NewVector(prob.Ndim)
+	o.gnew = la.NewVector(prob.Ndim)
+	o.u = la.NewVector(prob.Ndim)
+	o.s = la.NewVector(prob.Ndim)
+	o.y = la.NewVector(prob.Ndim)
+	o.hy = la.NewVector(prob.Ndim)
+	o.zero = 1e-18
+	return
+}
+
+// Min solves minimization problem
+//
+//  Input:
+//    x -- [ndim] initial starting point (will be modified)
+//    params -- [may be nil] optional parameters. e.g. "maxit", "ftol". Example:
+//                 params := dbf.NewParams(
+//                     &dbf.P{N: "maxit", V: 1000},
+//                     &dbf.P{N: "maxitls", V: 20},
+//                     &dbf.P{N: "maxitzoom", V: 20},
+//                     &dbf.P{N: "ftol", V: 1e-2},
+//                     &dbf.P{N: "gtol", V: 1e-2},
+//                     &dbf.P{N: "hist", V: 1},
+//                     &dbf.P{N: "verb", V: 1},
+//                 )
+//
+//  Output:
+//    fmin -- f(x@min) minimum f({x}) found
+//    x -- [modify input] position of minimum f({x})
+//
+func (o *BFGS) Min(x la.Vector, params dbf.Params) (fmin float64) {
+
+	// set parameters
+	o.Convergence.SetParams(params)
+	o.lines.SetParams(params)
+
+	// initializations
+	ndim := len(x)
+	o.NumFeval, o.NumGeval = 0, 0
+	fx := o.Ffcn(x)
+	o.Gfcn(o.g, x)
+	o.Hmat.SetDiag(1)
+	fmin = fx
+
+	// history
+	var λ float64
+	if o.UseHist {
+		o.InitHist(x)
+	}
+
+	// exit point # 1: initial gradient is zero
+	if o.Gconvergence(fx, x, o.g) {
+		return
+	}
+
+	// estimate old f(x) for the first step length (as in ConjGrad)
+	fold := fx + o.g.Norm()/2.0
+
+	// iterations
+	var ys, yhy, ρ float64
+	for o.NumIter = 0; o.NumIter < o.MaxIt; o.NumIter++ {
+
+		// direction: u := -H⋅g
+		la.MatVecMul(o.u, -1, o.Hmat, o.g)
+
+		// line search
+		o.s.Apply(1, x)
+		λ, fmin = o.lines.Wolfe(x, o.u, o.NumIter == 0, fold) // x := x @ min
+
+		// history
+		if o.UseHist {
+			o.uhist.Apply(λ, o.u)
+			o.Hist.Append(fmin, x, o.uhist)
+		}
+
+		// exit point # 2: converged on f
+		if o.Fconvergence(fx, fmin) {
+			return
+		}
+		fx = fmin
+
+		// exit point # 3: converged on dy/dx
+		o.Gfcn(o.gnew, x)
+		if o.Gconvergence(fx, x, o.gnew) {
+			return
+		}
+
+		// step and change of gradient
+		la.VecAdd(o.s, 1, x, -1, o.s)      // s := xnew - x
+		la.VecAdd(o.y, 1, o.gnew, -1, o.g) // y := gnew - g
+		o.g.Apply(1, o.gnew)
+
+		// update inverse Hessian
+		ys = la.VecDot(o.y, o.s)
+		if ys <= o.zero {
+			continue // curvature condition not satisfied: skip update
+		}
+		if o.NumIter == 0 {
+			o.Hmat.SetDiag(ys / la.VecDot(o.y, o.y)) // Eq. (6.20) of [1]
+		}
+		ρ = 1.0 / ys
+		la.MatVecMul(o.hy, 1, o.Hmat, o.y)
+		yhy = la.VecDot(o.y, o.hy)
+		for i := 0; i < ndim; i++ {
+			for j := 0; j < ndim; j++ {
+				o.Hmat.Add(i, j, -ρ*(o.hy[i]*o.s[j]+o.s[i]*o.hy[j])+(ρ*ρ*yhy+ρ)*o.s[i]*o.s[j])
+			}
+		}
+	}
+
+	// did not converge
+	chk.Panic("fail to converge after %d iterations\n", o.NumIter)
+	return
+}
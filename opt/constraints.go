@@ -0,0 +1,187 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/fun"
+	"github.com/dicksontsai/gosl/fun/dbf"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// Constraints holds the equality and inequality constraints of a problem and assists in checking
+// the Karush-Kuhn-Tucker (KKT) conditions of constrained optimizers (e.g. AugLag and SQP)
+//
+//   The problem is
+//
+//     min f({x})   s.t.   h({x}) = 0,   c({x}) ≥ 0,   Xlo ≤ {x} ≤ Xhi
+//
+//   with Lagrangian L = f - λᵀh - μᵀc. The KKT residuals are:
+//
+//     ResStat  -- ‖P({x} - ∇L) - {x}‖∞ with P being the projection onto the box (stationarity)
+//     ResFeas  -- max(‖h‖∞, ‖max(0, -c)‖∞) (feasibility)
+//     ResCompl -- max(‖min(0, μ)‖∞, maxⱼ |μⱼ⋅cⱼ|) (dual feasibility and complementarity)
+//
+type Constraints struct {
+
+	// input
+	Neq    int       // number of equality constraints
+	Nineq  int       // number of inequality constraints
+	Eqfcn  fun.Vv    // equality constraints h({x}) = 0
+	EqJfcn fun.Mv    // Jacobian of equality constraints dh/d{x}
+	Infcn  fun.Vv    // inequality constraints c({x}) ≥ 0
+	InJfcn fun.Mv    // Jacobian of inequality constraints dc/d{x}
+	Xlo    la.Vector // lower bounds
+	Xhi    la.Vector // upper bounds
+
+	// configuration
+	Ctol float64 // tolerance for feasibility and complementarity [default = 1e-6]
+
+	// output
+	Lambda   la.Vector // [Neq] Lagrange multipliers of equality constraints
+	Mu       la.Vector // [Nineq] Lagrange multipliers of inequality constraints (≥ 0)
+	ResStat  float64   // KKT residual: stationarity of the Lagrangian
+	ResFeas  float64   // KKT residual: feasibility
+	ResCompl float64   // KKT residual: complementarity
+
+	// statistics
+	NumCeval int // number of calls to Eqfcn and Infcn (constraints evaluations)
+	NumJeval int // number of calls to EqJfcn and InJfcn (Jacobian evaluations)
+
+	// internal
+	h  la.Vector  // equality constraints
+	c  la.Vector  // inequality constraints
+	Jh *la.Matrix // Jacobian of equality constraints
+	Jc *la.Matrix // Jacobian of inequality constraints
+	gL la.Vector  // gradient of the Lagrangian
+}
+
+// InitConstraints initialises constraints
+func (o *Constraints) InitConstraints(prob *Problem) {
+	if prob.Neq > 0 && (prob.Eqfcn == nil || prob.EqJfcn == nil) {
+		chk.Panic("equality constraints require Eqfcn and EqJfcn\n")
+	}
+	if prob.Nineq > 0 && (prob.Infcn == nil || prob.InJfcn == nil) {
+		chk.Panic("inequality constraints require Infcn and InJfcn\n")
+	}
+	o.Neq, o.Nineq = prob.Neq, prob.Nineq
+	o.Eqfcn = func(h, x la.Vector) {
+		o.NumCeval++
+		prob.Eqfcn(h, x)
+	}
+	o.EqJfcn = func(J *la.Matrix, x la.Vector) {
+		o.NumJeval++
+		prob.EqJfcn(J, x)
+	}
+	o.Infcn = func(c, x la.Vector) {
+		o.NumCeval++
+		prob.Infcn(c, x)
+	}
+	o.InJfcn = func(J *la.Matrix, x la.Vector) {
+		o.NumJeval++
+		prob.InJfcn(J, x)
+	}
+	o.Xlo, o.Xhi = prob.GetBounds()
+	o.Ctol = 1e-6
+	o.Lambda = la.NewVector(o.Neq)
+	o.Mu = la.NewVector(o.Nineq)
+	o.h = la.NewVector(o.Neq)
+	o.c = la.NewVector(o.Nineq)
+	o.Jh = la.NewMatrix(o.Neq, prob.Ndim)
+	o.Jc = la.NewMatrix(o.Nineq, prob.Ndim)
+	o.gL = la.NewVector(prob.Ndim)
+}
+
+// SetConstraintsParams sets parameters. e.g. "ctol"
+func (o *Constraints) SetConstraintsParams(params dbf.Params) {
+	o.Ctol = params.GetValueOrDefault("ctol", o.Ctol)
+}
+
+// HasBounds returns whether at least one finite bound exists
+func (o *Constraints) HasBounds() bool {
+	for i := 0; i < len(o.Xlo); i++ {
+		if !math.IsInf(o.Xlo[i], -1) || !math.IsInf(o.Xhi[i], +1) {
+			return true
+		}
+	}
+	return false
+}
+
+// KKTconvergence computes the KKT residuals and returns true if they are small
+//   Input:
+//     x -- current {x} value
+//     g -- gradient of f @ x
+//     gtol -- tolerance for the stationarity residual; e.g. Convergence.Gtol
+//   NOTE: o.h, o.c, o.Jh and o.Jc must be computed @ x before calling this function
+func (o *Constraints) KKTconvergence(x, g la.Vector, gtol float64) bool {
+	o.kktResiduals(x, g)
+	return o.ResStat < gtol && o.ResFeas < o.Ctol && o.ResCompl < o.Ctol
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// evalConstraints computes o.h and o.c @ x
+func (o *Constraints) evalConstraints(x la.Vector) {
+	if o.Neq > 0 {
+		o.Eqfcn(o.h, x)
+	}
+	if o.Nineq > 0 {
+		o.Infcn(o.c, x)
+	}
+}
+
+// evalJacobians computes o.Jh and o.Jc @ x
+func (o *Constraints) evalJacobians(x la.Vector) {
+	if o.Neq > 0 {
+		o.EqJfcn(o.Jh, x)
+	}
+	if o.Nineq > 0 {
+		o.InJfcn(o.Jc, x)
+	}
+}
+
+// infeasibility returns max(‖h‖∞, ‖max(0, -c)‖∞) using o.h and o.c
+func (o *Constraints) infeasibility() (res float64) {
+	for i := 0; i < o.Neq; i++ {
+		res = utl.Max(res, math.Abs(o.h[i]))
+	}
+	for j := 0; j < o.Nineq; j++ {
+		res = utl.Max(res, -o.c[j])
+	}
+	return
+}
+
+// lagGradient computes gL := g - Jhᵀ⋅λ - Jcᵀ⋅μ using o.Jh and o.Jc
+func (o *Constraints) lagGradient(gL, g, λ, μ la.Vector) {
+	gL.Apply(1, g)
+	for i := 0; i < o.Neq; i++ {
+		for k := 0; k < len(g); k++ {
+			gL[k] -= o.Jh.Get(i, k) * λ[i]
+		}
+	}
+	for j := 0; j < o.Nineq; j++ {
+		for k := 0; k < len(g); k++ {
+			gL[k] -= o.Jc.Get(j, k) * μ[j]
+		}
+	}
+}
+
+// kktResiduals computes the KKT residuals @ x
+func (o *Constraints) kktResiduals(x, g la.Vector) {
+	o.lagGradient(o.gL, g, o.Lambda, o.Mu)
+	o.ResStat = 0
+	for k := 0; k < len(x); k++ {
+		pg := utl.Min(utl.Max(x[k]-o.gL[k], o.Xlo[k]), o.Xhi[k]) - x[k]
+		o.ResStat = utl.Max(o.ResStat, math.Abs(pg))
+	}
+	o.ResFeas = o.infeasibility()
+	o.ResCompl = 0
+	for j := 0; j < o.Nineq; j++ {
+		o.ResCompl = utl.Max(o.ResCompl, utl.Max(-o.Mu[j], math.Abs(o.Mu[j]*o.c[j])))
+	}
+}
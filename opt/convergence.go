@@ -140,3 +140,37 @@ func (o *Convergence) Gconvergence(fprev float64, x, u la.Vector) bool {
 	}
 	return false // not yet
 }
+
+// PGconvergence performs the check for the projected gradient when box bounds are present
+//
+//   The projected gradient is {pg} = P({x} - {g}) - {x} where P is the projection onto the box
+//   xlo ≤ {x} ≤ xhi. Thus, {pg} = -{g} for variables away from bounds and the components of {g}
+//   pointing outwards at active bounds are disregarded (first-order optimality conditions).
+//
+//   Input:
+//     fprev -- a previous f({x}) value (for normalization purposes)
+//     x -- current {x} value (feasible)
+//     g -- current gradient dfdx
+//     xlo -- lower bounds
+//     xhi -- upper bounds
+//
+//   Output:
+//     returns true if the projected gradient is (nearly) zero
+//
+func (o *Convergence) PGconvergence(fprev float64, x, g, xlo, xhi la.Vector) bool {
+	var pg, temp float64
+	size := len(x)
+	test := 0.0
+	coef := utl.Max(fprev, 1.0)
+	for j := 0; j < size; j++ {
+		pg = utl.Min(utl.Max(x[j]-g[j], xlo[j]), xhi[j]) - x[j]
+		temp = math.Abs(pg) * utl.Max(math.Abs(x[j]), 1.0) / coef
+		if temp > test {
+			test = temp
+		}
+	}
+	if test < o.Gtol {
+		return true // projected gradient is zero
+	}
+	return false // not yet
+}
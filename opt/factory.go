@@ -94,6 +94,14 @@ func (o FactoryType) Rosenbrock2d(a, b float64) (p *Problem) {
 		g[1] = 2.0 * b * (x[1] - x[0]*x[0])
 	}
 
+	// Hessian function d²f/d{x}d{x}!(x)
+	p.Hfcn = func(h *la.Matrix, x la.Vector) {
+		h.Set(0, 0, 2.0-4.0*b*x[1]+12.0*b*x[0]*x[0])
+		h.Set(0, 1, -4.0*b*x[0])
+		h.Set(1, 0, -4.0*b*x[0])
+		h.Set(1, 1, 2.0*b)
+	}
+
 	// known solution
 	p.Fref = 0.0
 	p.Xref = la.NewVectorSlice([]float64{a, a * a})
@@ -138,6 +146,17 @@ func (o FactoryType) RosenbrockMulti(N int) (p *Problem) {
 		g[n-1] = 200.0 * (x[n-1] - x[n-2]*x[n-2])
 	}
 
+	// Hessian function d²f/d{x}d{x}!(x) (tridiagonal)
+	p.Hfcn = func(h *la.Matrix, x la.Vector) {
+		h.Fill(0)
+		for i := 1; i < len(x); i++ {
+			h.Add(i-1, i-1, 1200.0*x[i-1]*x[i-1]-400.0*x[i]+2.0)
+			h.Add(i-1, i, -400.0*x[i-1])
+			h.Add(i, i-1, -400.0*x[i-1])
+			h.Add(i, i, 200.0)
+		}
+	}
+
 	// known solution
 	p.Fref = 0.0
 	p.Xref = la.NewVector(N)
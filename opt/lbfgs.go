@@ -0,0 +1,219 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/fun/dbf"
+	"github.com/dicksontsai/gosl/la"
+)
+
+// LBFGS implements the multidimensional minimization by the limited-memory BFGS method
+//
+//   Only the last Mem pairs {s}k = {x}k+1 - {x}k and {y}k = {g}k+1 - {g}k are stored and the
+//   product -[H]⋅{g} is computed by the two-loop recursion (Algorithm 7.4 of [1]) with the initial
+//   inverse Hessian [H]0 = γ[I] where γ = (yᵀs)/(yᵀy) corresponds to the most recent pair. Thus,
+//   memory and cost per iteration are proportional to Mem⋅ndim; i.e. this method is appropriate
+//   to problems with many variables. The step length is computed by LineSearch.Wolfe.
+//
+//   NOTE: Check Convergence to see how to set convergence parameters,
+//         max iteration number, or to enable and access history of iterations
+//
+//   REFERENCES:
+//   [1] Nocedal, J. and Wright, S. (2006) Numerical Optimization.
+//       Springer Series in Operations Research. 2nd Edition. Springer. 664p
+//
+type LBFGS struct {
+
+	// merge properties
+	Convergence // auxiliary object to check convergence
+
+	// configuration
+	Mem int // number of stored pairs {s,y} (history size) [default = 10]
+
+	// internal
+	g     la.Vector   // gradient
+	gnew  la.Vector   // new gradient
+	u     la.Vector   // direction
+	xold  la.Vector   // previous x
+	S     []la.Vector // [mem][ndim] steps {s}k (circular buffer)
+	Y     []la.Vector // [mem][ndim] changes of gradient {y}k (circular buffer)
+	ρ     []float64   // [mem] 1/(yᵀs)
+	α     []float64   // [mem] coefficients of the two-loop recursion
+	npair int         // number of stored pairs
+	next  int         // index of the next pair in the circular buffer
+	zero  float64     // minimum yᵀs to store a pair
+
+	// line search
+	lines *LineSearch // line search
+}
+
+// add optimizer to database
+func init() {
+	nlsMakersDB["lbfgs"] = func(prob *Problem) NonLinSolver { return NewLBFGS(prob) }
+}
+
+// NewLBFGS returns a new multidimensional optimizer using the limited-memory BFGS method
+func NewLBFGS(prob *Problem) (o *LBFGS) {
+	o = new(LBFGS)
+	o.InitConvergence(prob.Ffcn, prob.Gfcn)
+	o.lines = NewLineSearch(prob.Ndim, o.Ffcn, o.Gfcn)
+	o.lines.Coef2 = 0.9 // recommended for quasi-Newton methods
+	o.Mem = 10
+	o.g = la.NewVector(prob.Ndim)
+	o.gnew = la.NewVector(prob.Ndim)
+	o.u = la.NewVector(prob.Ndim)
+	o.xold = la.NewVector(prob.Ndim)
+	o.zero = 1e-18
+	return
+}
+
+// Min solves minimization problem
+//
+//  Input:
+//    x -- [ndim] initial starting point (will be modified)
+//    params -- [may be nil] optional parameters. e.g. "mem", "maxit". Example:
+//                 params := dbf.NewParams(
+//                     &dbf.P{N: "mem", V: 5},
+//                     &dbf.P{N: "maxit", V: 1000},
+//                     &dbf.P{N: "maxitls", V: 20},
+//                     &dbf.P{N: "maxitzoom", V: 20},
+//                     &dbf.P{N: "ftol", V: 1e-2},
+//                     &dbf.P{N: "gtol", V: 1e-2},
+//                     &dbf.P{N: "hist", V: 1},
+//                     &dbf.P{N: "verb", V: 1},
+//                 )
+//
+//  Output:
+//    fmin -- f(x@min) minimum f({x}) found
+//    x -- [modify input] position of minimum f({x})
+//
+func (o *LBFGS) Min(x la.Vector, params dbf.Params) (fmin float64) {
+
+	// set parameters
+	o.Convergence.SetParams(params)
+	o.lines.SetParams(params)
+	o.Mem = params.GetIntOrDefault("mem", o.Mem)
+	if o.Mem < 1 {
+		chk.Panic("history size must be at least 1. Mem = %d is invalid\n", o.Mem)
+	}
+
+	// allocate pairs
+	ndim := len(x)
+	if len(o.S) != o.Mem {
+		o.S = make([]la.Vector, o.Mem)
+		o.Y = make([]la.Vector, o.Mem)
+		for k := 0; k < o.Mem; k++ {
+			o.S[k] = la.NewVector(ndim)
+			o.Y[k] = la.NewVector(ndim)
+		}
+		o.ρ = make([]float64, o.Mem)
+		o.α = make([]float64, o.Mem)
+	}
+	o.npair, o.next = 0, 0
+
+	// initializations
+	o.NumFeval, o.NumGeval = 0, 0
+	fx := o.Ffcn(x)
+	o.Gfcn(o.g, x)
+	fmin = fx
+
+	// history
+	var λ float64
+	if o.UseHist {
+		o.InitHist(x)
+	}
+
+	// exit point # 1: initial gradient is zero
+	if o.Gconvergence(fx, x, o.g) {
+		return
+	}
+
+	// estimate old f(x) for the first step length (as in ConjGrad)
+	fold := fx + o.g.Norm()/2.0
+
+	// iterations
+	var ys float64
+	for o.NumIter = 0; o.NumIter < o.MaxIt; o.NumIter++ {
+
+		// direction: u := -H⋅g
+		o.direction()
+
+		// line search
+		o.xold.Apply(1, x)
+		λ, fmin = o.lines.Wolfe(x, o.u, o.NumIter == 0, fold) // x := x @ min
+
+		// history
+		if o.UseHist {
+			o.uhist.Apply(λ, o.u)
+			o.Hist.Append(fmin, x, o.uhist)
+		}
+
+		// exit point # 2: converged on f
+		if o.Fconvergence(fx, fmin) {
+			return
+		}
+		fx = fmin
+
+		// exit point # 3: converged on dy/dx
+		o.Gfcn(o.gnew, x)
+		if o.Gconvergence(fx, x, o.gnew) {
+			return
+		}
+
+		// store new pair, discarding the oldest one if the buffer is full
+		k := o.next
+		la.VecAdd(o.S[k], 1, x, -1, o.xold)   // s := xnew - x
+		la.VecAdd(o.Y[k], 1, o.gnew, -1, o.g) // y := gnew - g
+		o.g.Apply(1, o.gnew)
+		ys = la.VecDot(o.Y[k], o.S[k])
+		if ys <= o.zero {
+			continue // curvature condition not satisfied: skip pair
+		}
+		o.ρ[k] = 1.0 / ys
+		o.next = (k + 1) % o.Mem
+		if o.npair < o.Mem {
+			o.npair++
+		}
+	}
+
+	// did not converge
+	chk.Panic("fail to converge after %d iterations\n", o.NumIter)
+	return
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// direction computes u := -H⋅g using the two-loop recursion (Algorithm 7.4 of [1])
+func (o *LBFGS) direction() {
+	o.u.Apply(-1, o.g) // q := -g
+	if o.npair == 0 {
+		return
+	}
+	m := o.Mem
+	newest := (o.next - 1 + m) % m
+
+	// first loop: from newest to oldest pair
+	for l := 0; l < o.npair; l++ {
+		k := (newest - l + m) % m
+		o.α[k] = o.ρ[k] * la.VecDot(o.S[k], o.u)
+		for i := 0; i < len(o.u); i++ {
+			o.u[i] -= o.α[k] * o.Y[k][i] // q := q - αk⋅yk
+		}
+	}
+
+	// initial inverse Hessian: r := H0⋅q with H0 = γ I and γ = sᵀy / yᵀy
+	γ := 1.0 / (o.ρ[newest] * la.VecDot(o.Y[newest], o.Y[newest]))
+	o.u.Apply(γ, o.u)
+
+	// second loop: from oldest to newest pair
+	for l := o.npair - 1; l >= 0; l-- {
+		k := (newest - l + m) % m
+		β := o.ρ[k] * la.VecDot(o.Y[k], o.u)
+		for i := 0; i < len(o.u); i++ {
+			o.u[i] += (o.α[k] - β) * o.S[k][i] // r := r + sk⋅(αk - β)
+		}
+	}
+}
@@ -0,0 +1,515 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+	"sort"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/fun/dbf"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// LBFGSB implements the minimization with box bounds xlo ≤ {x} ≤ xhi by the limited-memory BFGS
+// method for bound constrained problems (L-BFGS-B) [1]
+//
+//   The Hessian approximation is written in the compact form [B] = θ[I] - [W]⋅[M]⋅[W]ᵀ with
+//   [W] = [[Y] θ[S]] built with the last Mem pairs {s,y}. At each iteration:
+//     1. the generalized Cauchy point {xc} is found by following the projected steepest descent
+//        path P({x} - t⋅{g}) up to the first minimizer of the quadratic model
+//     2. the quadratic model is minimized with respect to the variables that are free at {xc}
+//        (direct primal method) and the result {x̄} is truncated to remain within the box
+//     3. a backtracking line search with sufficient decrease is performed along {x̄} - {x}
+//   The bounds are taken from Problem.Xlo and Problem.Xhi (see Problem.SetBounds).
+//
+//   NOTE: Check Convergence to see how to set convergence parameters,
+//         max iteration number, or to enable and access history of iterations.
+//         The projected gradient is employed in the zero gradient test (PGconvergence).
+//
+//   REFERENCES:
+//   [1] Byrd RH, Lu P, Nocedal J and Zhu C (1995) A limited memory algorithm for bound constrained
+//       optimization. SIAM Journal on Scientific Computing, 16(5):1190-1208
+//
+type LBFGSB struct {
+
+	// merge properties
+	Convergence // auxiliary object to check convergence
+
+	// input
+	Xlo la.Vector // lower bounds
+	Xhi la.Vector // upper bounds
+
+	// configuration
+	Mem     int     // number of stored pairs {s,y} (history size) [default = 10]
+	Coef1   float64 // sufficient decrease coefficient [default = 1e-4]
+	MaxItLs int     // max number of step reductions in the line search [default = 20]
+
+	// internal
+	g     la.Vector   // gradient
+	gnew  la.Vector   // new gradient
+	xold  la.Vector   // previous x
+	xc    la.Vector   // generalized Cauchy point
+	xbar  la.Vector   // minimizer of the quadratic model (search point)
+	d     la.Vector   // search direction / steepest descent path direction
+	t     []float64   // [ndim] breakpoints
+	brk   []int       // indices of breakpoints sorted by t
+	free  []int       // indices of free variables at the Cauchy point
+	S     []la.Vector // [npair][ndim] steps {s}k (oldest first)
+	Y     []la.Vector // [npair][ndim] changes of gradient {y}k (oldest first)
+	npair int         // number of stored pairs
+	θ     float64     // scaling of [B]
+	M     *la.Matrix  // [2⋅npair][2⋅npair] middle matrix of the compact representation
+	zero  float64     // minimum yᵀs to store a pair
+
+	// workspace with size 2⋅npair
+	p  la.Vector  // Wᵀ⋅d along the Cauchy path
+	c  la.Vector  // Wᵀ⋅(xc - x)
+	w  la.Vector  // row of W
+	mp la.Vector  // M⋅p
+	mc la.Vector  // M⋅c
+	v  la.Vector  // auxiliary vector
+	WW *la.Matrix // WᵀZ⋅ZᵀW (free variables only)
+
+	// line search
+	lsx la.Vector // trial x
+}
+
+// add optimizer to database
+func init() {
+	nlsMakersDB["lbfgsb"] = func(prob *Problem) NonLinSolver { return NewLBFGSB(prob) }
+}
+
+// NewLBFGSB returns a new optimizer with box bounds using the L-BFGS-B method
+func NewLBFGSB(prob *Problem) (o *LBFGSB) {
+	o = new(LBFGSB)
+	o.InitConvergence(prob.Ffcn, prob.Gfcn)
+	o.Xlo, o.Xhi = prob.GetBounds()
+	o.Mem = 10
+	o.Coef1 = 1e-4
+	o.MaxItLs = 20
+	o.g = la.NewVector(prob.Ndim)
+	o.gnew = la.NewVector(prob.Ndim)
+	o.xold = la.NewVector(prob.Ndim)
+	o.xc = la.NewVector(prob.Ndim)
+	o.xbar = la.NewVector(prob.Ndim)
+	o.d = la.NewVector(prob.Ndim)
+	o.t = make([]float64, prob.Ndim)
+	o.brk = make([]int, 0, prob.Ndim)
+	o.free = make([]int, 0, prob.Ndim)
+	o.lsx = la.NewVector(prob.Ndim)
+	o.zero = 1e-18
+	return
+}
+
+// Min solves minimization problem
+//
+//  Input:
+//    x -- [ndim] initial starting point (will be modified and projected onto the box)
+//    params -- [may be nil] optional parameters. e.g. "mem", "maxit". Example:
+//                 params := dbf.NewParams(
+//                     &dbf.P{N: "mem", V: 5},
+//                     &dbf.P{N: "coef1", V: 1e-4},
+//                     &dbf.P{N: "maxitls", V: 20},
+//                     &dbf.P{N: "maxit", V: 1000},
+//                     &dbf.P{N: "ftol", V: 1e-2},
+//                     &dbf.P{N: "gtol", V: 1e-2},
+//                     &dbf.P{N: "hist", V: 1},
+//                     &dbf.P{N: "verb", V: 1},
+//                 )
+//
+//  Output:
+//    fmin -- f(x@min) minimum f({x}) found
+//    x -- [modify input] position of minimum f({x})
+//
+func (o *LBFGSB) Min(x la.Vector, params dbf.Params) (fmin float64) {
+
+	// set parameters
+	o.Convergence.SetParams(params)
+	o.Mem = params.GetIntOrDefault("mem", o.Mem)
+	o.Coef1 = params.GetValueOrDefault("coef1", o.Coef1)
+	o.MaxItLs = params.GetIntOrDefault("maxitls", o.MaxItLs)
+	if o.Mem < 1 {
+		chk.Panic("history size must be at least 1. Mem = %d is invalid\n", o.Mem)
+	}
+
+	// allocate pairs and workspace
+	ndim := len(x)
+	if len(o.p) != 2*o.Mem {
+		o.S = make([]la.Vector, o.Mem)
+		o.Y = make([]la.Vector, o.Mem)
+		for k := 0; k < o.Mem; k++ {
+			o.S[k] = la.NewVector(ndim)
+			o.Y[k] = la.NewVector(ndim)
+		}
+		o.p = la.NewVector(2 * o.Mem)
+		o.c = la.NewVector(2 * o.Mem)
+		o.w = la.NewVector(2 * o.Mem)
+		o.mp = la.NewVector(2 * o.Mem)
+		o.mc = la.NewVector(2 * o.Mem)
+		o.v = la.NewVector(2 * o.Mem)
+	}
+	o.npair = 0
+	o.θ = 1
+
+	// initializations
+	o.NumFeval, o.NumGeval = 0, 0
+	projectOnBox(x, x, o.Xlo, o.Xhi)
+	fx := o.Ffcn(x)
+	o.Gfcn(o.g, x)
+	fmin = fx
+
+	// history
+	if o.UseHist {
+		o.InitHist(x)
+	}
+
+	// exit point # 1: initial projected gradient is zero
+	if o.PGconvergence(fx, x, o.g, o.Xlo, o.Xhi) {
+		return
+	}
+
+	// iterations
+	var gd, λ, ys, yy float64
+	for o.NumIter = 0; o.NumIter < o.MaxIt; o.NumIter++ {
+
+		// search direction: d := x̄ - x
+		o.cauchyPoint(x)
+		o.subspaceMin(x)
+		la.VecAdd(o.d, 1, o.xbar, -1, x)
+		gd = la.VecDot(o.g, o.d)
+		if gd >= 0 {
+			if o.npair == 0 {
+				return // cannot decrease f any further (e.g. Gtol too small)
+			}
+			o.npair, o.θ = 0, 1 // not a descent direction: restart with steepest descent
+			o.NumIter--
+			continue
+		}
+
+		// backtracking line search
+		λ = 1
+		if o.npair == 0 {
+			λ = utl.Min(1, 1.0/o.d.Norm())
+		}
+		o.xold.Apply(1, x)
+		fmin = o.lineSearch(x, fx, gd, λ)
+
+		// history
+		if o.UseHist {
+			la.VecAdd(o.uhist, 1, x, -1, o.xold)
+			o.Hist.Append(fmin, x, o.uhist)
+		}
+
+		// exit point # 2: converged on f
+		if o.Fconvergence(fx, fmin) {
+			return
+		}
+		fx = fmin
+
+		// exit point # 3: converged on projected gradient
+		o.Gfcn(o.gnew, x)
+		if o.PGconvergence(fx, x, o.gnew, o.Xlo, o.Xhi) {
+			return
+		}
+
+		// store new pair, discarding the oldest one if the buffer is full
+		if o.npair == o.Mem {
+			s0, y0 := o.S[0], o.Y[0]
+			copy(o.S, o.S[1:])
+			copy(o.Y, o.Y[1:])
+			o.S[o.Mem-1], o.Y[o.Mem-1] = s0, y0
+			o.npair--
+		}
+		k := o.npair
+		la.VecAdd(o.S[k], 1, x, -1, o.xold)   // s := xnew - x
+		la.VecAdd(o.Y[k], 1, o.gnew, -1, o.g) // y := gnew - g
+		o.g.Apply(1, o.gnew)
+		ys = la.VecDot(o.Y[k], o.S[k])
+		yy = la.VecDot(o.Y[k], o.Y[k])
+		if ys > o.zero*utl.Max(yy, 1) { // otherwise, curvature condition is not satisfied: skip pair
+			o.npair++
+			o.θ = yy / ys
+		}
+		if o.npair > 0 {
+			o.formM()
+		}
+	}
+
+	// did not converge
+	chk.Panic("fail to converge after %d iterations\n", o.NumIter)
+	return
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// wRow computes the i-th row of [W] = [[Y] θ[S]] and stores it in o.w
+func (o *LBFGSB) wRow(i int) {
+	m := o.npair
+	for j := 0; j < m; j++ {
+		o.w[j] = o.Y[j][i]
+		o.w[m+j] = o.θ * o.S[j][i]
+	}
+}
+
+// mMul computes res := [M]⋅u (with size 2⋅npair)
+func (o *LBFGSB) mMul(res, u la.Vector) {
+	m2 := 2 * o.npair
+	for i := 0; i < m2; i++ {
+		res[i] = 0
+		for j := 0; j < m2; j++ {
+			res[i] += o.M.Get(i, j) * u[j]
+		}
+	}
+}
+
+// dot2m computes uᵀv with size 2⋅npair
+func (o *LBFGSB) dot2m(u, v la.Vector) (res float64) {
+	for i := 0; i < 2*o.npair; i++ {
+		res += u[i] * v[i]
+	}
+	return
+}
+
+// formM computes [M] = inv([[-D Lᵀ] [L θSᵀS]]) where D = diag(sᵢᵀyᵢ) and Lij = sᵢᵀyⱼ (i > j)
+func (o *LBFGSB) formM() {
+	m := o.npair
+	K := la.NewMatrix(2*m, 2*m)
+	for i := 0; i < m; i++ {
+		K.Set(i, i, -la.VecDot(o.S[i], o.Y[i]))
+		for j := 0; j < i; j++ {
+			sy := la.VecDot(o.S[i], o.Y[j])
+			K.Set(m+i, j, sy) // L
+			K.Set(j, m+i, sy) // Lᵀ
+		}
+		for j := 0; j <= i; j++ {
+			ss := o.θ * la.VecDot(o.S[i], o.S[j])
+			K.Set(m+i, m+j, ss)
+			K.Set(m+j, m+i, ss)
+		}
+	}
+	o.M = la.NewMatrix(2*m, 2*m)
+	la.MatInv(o.M, K, false)
+	o.WW = la.NewMatrix(2*m, 2*m)
+}
+
+// cauchyPoint computes the generalized Cauchy point o.xc (Algorithm CP of [1])
+//   Output: o.xc, o.c = [W]ᵀ⋅(xc - x)
+func (o *LBFGSB) cauchyPoint(x la.Vector) {
+
+	// breakpoints and steepest descent direction
+	ndim := len(x)
+	m2 := 2 * o.npair
+	o.brk = o.brk[:0]
+	fp := 0.0
+	for i := 0; i < ndim; i++ {
+		switch {
+		case o.g[i] < 0:
+			o.t[i] = (x[i] - o.Xhi[i]) / o.g[i]
+		case o.g[i] > 0:
+			o.t[i] = (x[i] - o.Xlo[i]) / o.g[i]
+		default:
+			o.t[i] = math.Inf(1)
+		}
+		o.d[i] = 0
+		if o.t[i] > 0 {
+			o.d[i] = -o.g[i]
+			fp -= o.d[i] * o.d[i]
+			if !math.IsInf(o.t[i], 1) {
+				o.brk = append(o.brk, i)
+			}
+		}
+		o.xc[i] = x[i]
+	}
+	sort.Slice(o.brk, func(a, b int) bool { return o.t[o.brk[a]] < o.t[o.brk[b]] })
+
+	// p := Wᵀ⋅d and c := 0
+	for j := 0; j < m2; j++ {
+		o.p[j], o.c[j] = 0, 0
+	}
+	for i := 0; i < ndim; i++ {
+		if o.d[i] != 0 {
+			o.wRow(i)
+			for j := 0; j < m2; j++ {
+				o.p[j] += o.w[j] * o.d[i]
+			}
+		}
+	}
+
+	// first and second derivatives of the quadratic model along the path
+	o.mMul(o.mp, o.p)
+	fpp := -o.θ*fp - o.dot2m(o.p, o.mp)
+	fpp0 := fpp
+	Δtmin := 0.0
+	if fpp > 0 {
+		Δtmin = -fp / fpp
+	}
+
+	// examine segments between breakpoints
+	var gb, zb, Δt float64
+	told := 0.0
+	for _, b := range o.brk {
+		Δt = o.t[b] - told
+		if Δtmin < Δt {
+			break
+		}
+
+		// fix variable b at its bound
+		if o.d[b] > 0 {
+			o.xc[b] = o.Xhi[b]
+		} else {
+			o.xc[b] = o.Xlo[b]
+		}
+		gb = o.g[b]
+		zb = o.xc[b] - x[b]
+
+		// update derivatives
+		for j := 0; j < m2; j++ {
+			o.c[j] += Δt * o.p[j]
+		}
+		o.wRow(b)
+		o.mMul(o.mc, o.c)
+		o.mMul(o.mp, o.p)
+		wmw := 0.0
+		if m2 > 0 {
+			o.mMul(o.v, o.w)
+			wmw = o.dot2m(o.w, o.v)
+		}
+		fp += Δt*fpp + gb*gb + o.θ*gb*zb - gb*o.dot2m(o.w, o.mc)
+		fpp -= o.θ*gb*gb + 2*gb*o.dot2m(o.w, o.mp) + gb*gb*wmw
+		fpp = utl.Max(fpp, 1e-15*fpp0)
+		for j := 0; j < m2; j++ {
+			o.p[j] += gb * o.w[j]
+		}
+		o.d[b] = 0
+		Δtmin = -fp / fpp
+		told = o.t[b]
+	}
+
+	// Cauchy point
+	Δtmin = utl.Max(Δtmin, 0)
+	told += Δtmin
+	for i := 0; i < ndim; i++ {
+		if o.d[i] != 0 {
+			o.xc[i] = utl.Min(utl.Max(x[i]+told*o.d[i], o.Xlo[i]), o.Xhi[i])
+		}
+	}
+	for j := 0; j < m2; j++ {
+		o.c[j] += Δtmin * o.p[j]
+	}
+}
+
+// subspaceMin minimizes the quadratic model with respect to the free variables at the Cauchy
+// point by the direct primal method (Section 5.1 of [1])
+//   Output: o.xbar
+func (o *LBFGSB) subspaceMin(x la.Vector) {
+
+	// free variables
+	o.xbar.Apply(1, o.xc)
+	o.free = o.free[:0]
+	for i := 0; i < len(x); i++ {
+		if o.xc[i] > o.Xlo[i] && o.xc[i] < o.Xhi[i] {
+			o.free = append(o.free, i)
+		}
+	}
+	if len(o.free) == 0 {
+		return
+	}
+
+	// reduced gradient: r = Zᵀ⋅(g + θ⋅(xc - x) - W⋅M⋅c); stored in o.d
+	m2 := 2 * o.npair
+	o.mMul(o.mc, o.c)
+	for _, i := range o.free {
+		o.d[i] = o.g[i] + o.θ*(o.xc[i]-x[i])
+		if m2 > 0 {
+			o.wRow(i)
+			o.d[i] -= o.dot2m(o.w, o.mc)
+		}
+	}
+
+	// solve reduced system using the Sherman-Morrison-Woodbury formula:
+	//   du = -(1/θ)⋅r - (1/θ²)⋅ZᵀW⋅inv(I - (1/θ)⋅M⋅WᵀZ⋅ZᵀW)⋅M⋅WᵀZ⋅r
+	if m2 > 0 {
+		o.WW.Fill(0)
+		for j := 0; j < m2; j++ {
+			o.v[j] = 0
+		}
+		for _, i := range o.free {
+			o.wRow(i)
+			for j := 0; j < m2; j++ {
+				o.v[j] += o.w[j] * o.d[i]
+				for k := 0; k < m2; k++ {
+					o.WW.Add(j, k, o.w[j]*o.w[k])
+				}
+			}
+		}
+		N := la.NewMatrix(m2, m2)
+		for j := 0; j < m2; j++ {
+			for k := 0; k < m2; k++ {
+				sum := 0.0
+				for l := 0; l < m2; l++ {
+					sum += o.M.Get(j, l) * o.WW.Get(l, k)
+				}
+				N.Set(j, k, -sum/o.θ)
+			}
+			N.Add(j, j, 1)
+		}
+		o.mMul(o.mp, o.v[:m2])
+		u := la.NewVector(m2)
+		la.DenSolve(u, N, o.mp[:m2], false)
+		for _, i := range o.free {
+			o.wRow(i)
+			o.d[i] = -o.d[i]/o.θ - o.dot2m(o.w, u)/(o.θ*o.θ)
+		}
+	} else {
+		for _, i := range o.free {
+			o.d[i] = -o.d[i] / o.θ
+		}
+	}
+
+	// truncate step to remain within the box
+	α := 1.0
+	for _, i := range o.free {
+		if o.d[i] > 0 {
+			α = utl.Min(α, (o.Xhi[i]-o.xc[i])/o.d[i])
+		} else if o.d[i] < 0 {
+			α = utl.Min(α, (o.Xlo[i]-o.xc[i])/o.d[i])
+		}
+	}
+	for _, i := range o.free {
+		o.xbar[i] = o.xc[i] + α*o.d[i]
+	}
+}
+
+// lineSearch performs a backtracking line search along o.d with sufficient decrease condition
+//   Input:
+//     x -- current point (will be modified)
+//     fx -- f(x)
+//     gd -- gᵀd (negative)
+//     λ -- initial step length (≤ 1 to remain within the box)
+//   Output:
+//     fnew -- f(x + λ⋅d)
+func (o *LBFGSB) lineSearch(x la.Vector, fx, gd, λ float64) (fnew float64) {
+	for k := 0; k < o.MaxItLs; k++ {
+		for i := 0; i < len(x); i++ {
+			o.lsx[i] = x[i] + λ*o.d[i]
+		}
+		projectOnBox(o.lsx, o.lsx, o.Xlo, o.Xhi) // remove round-off errors
+		fnew = o.Ffcn(o.lsx)
+		if fnew <= fx+o.Coef1*λ*gd {
+			x.Apply(1, o.lsx)
+			return
+		}
+		// safeguarded quadratic interpolation
+		λq := -gd * λ * λ / (2 * (fnew - fx - λ*gd))
+		if math.IsNaN(λq) || math.IsInf(λq, 0) {
+			λq = 0.5 * λ
+		}
+		λ = utl.Min(0.5*λ, utl.Max(0.1*λ, λq))
+	}
+	chk.Panic("line search failed after %d reductions of step λ\n", o.MaxItLs)
+	return
+}
@@ -58,7 +58,8 @@ type LinIpm struct {
 	J  *la.Triplet // [ny][ny] Jacobian matrix
 
 	// linear solver
-	Lis la.SparseSolver // linear solver
+	LsKind string          // kind of linear solver: "umfpack" or native "splu", ... [default = la.SpDefaultKind()]
+	Lis    la.SparseSolver // linear solver
 }
 
 // Free frees allocated memory
@@ -67,6 +68,7 @@ func (o *LinIpm) Free() {
 }
 
 // Init initialises LinIpm
+//  NOTE: LsKind may be set before calling Init; otherwise, the default kind of linear solver is used
 func (o *LinIpm) Init(A *la.CCMatrix, b, c la.Vector, prms dbf.Params) {
 
 	// problem
@@ -110,44 +112,17 @@ func (o *LinIpm) Init(A *la.CCMatrix, b, c la.Vector, prms dbf.Params) {
 	o.J.Init(o.Ny, o.Ny, nnz)
 
 	// linear solver
-	o.Lis = la.NewSparseSolver("umfpack")
+	if o.LsKind == "" {
+		o.LsKind = la.SpDefaultKind()
+	}
+	o.Lis = la.NewSparseSolver(o.LsKind)
 }
 
 // Solve solves linear programming problem
 func (o *LinIpm) Solve(verbose bool) {
 
 	// starting point
-	AAt := la.NewMatrix(o.Nl, o.Nl)               // A*Aᵀ
-	d := la.NewVector(o.Nl)                       // inv(AAt) * b
-	e := la.NewVector(o.Nl)                       // A * c
-	la.SpMatMatTrMul(AAt, 1, o.A)                 // AAt := A*Aᵀ
-	la.SpMatVecMul(e, 1, o.A, o.C)                // e := A * c
-	la.SolveTwoRealLinSysSPD(d, o.L, AAt, o.B, e) // d := inv(AAt) * b  and  L := inv(AAt) * e
-	la.SpMatTrVecMul(o.X, 1, o.A, d)              // x := Aᵀ * d
-	o.S.Apply(1, o.C)                             // s := c
-	la.SpMatTrVecMulAdd(o.S, -1, o.A, o.L)        // s -= Aᵀλ
-	xmin := o.X[0]
-	smin := o.S[0]
-	for i := 1; i < o.Nx; i++ {
-		xmin = utl.Min(xmin, o.X[i])
-		smin = utl.Min(smin, o.S[i])
-	}
-	δx := utl.Max(-1.5*xmin, 0)
-	δs := utl.Max(-1.5*smin, 0)
-	var xdots, xsum, ssum float64
-	for i := 0; i < o.Nx; i++ {
-		o.X[i] += δx
-		o.S[i] += δs
-		xdots += o.X[i] * o.S[i]
-		xsum += o.X[i]
-		ssum += o.S[i]
-	}
-	δx = 0.5 * xdots / ssum
-	δs = 0.5 * xdots / xsum
-	for i := 0; i < o.Nx; i++ {
-		o.X[i] += δx
-		o.S[i] += δs
-	}
+	ipmStartingPoint(o.X, o.L, o.S, o.A, o.B, o.C)
 
 	// constants for linear solver
 	symmetric := false
@@ -277,3 +252,41 @@ func (o *LinIpm) calcMinRatios() (xrmin, srmin float64) {
 	}
 	return
 }
+
+// ipmStartingPoint computes the starting point of the primal-dual interior-point methods using the
+// heuristic of Mehrotra; i.e. the least-squares solutions of A⋅x = b and Aᵀ⋅λ + s = c are shifted
+// such that x > 0 and s > 0
+func ipmStartingPoint(x, λ, s la.Vector, A *la.CCMatrix, b, c la.Vector) {
+	nx, nl := len(x), len(λ)
+	AAt := la.NewMatrix(nl, nl)               // A*Aᵀ
+	d := la.NewVector(nl)                     // inv(AAt) * b
+	e := la.NewVector(nl)                     // A * c
+	la.SpMatMatTrMul(AAt, 1, A)               // AAt := A*Aᵀ
+	la.SpMatVecMul(e, 1, A, c)                // e := A * c
+	la.SolveTwoRealLinSysSPD(d, λ, AAt, b, e) // d := inv(AAt) * b  and  λ := inv(AAt) * e
+	la.SpMatTrVecMul(x, 1, A, d)              // x := Aᵀ * d
+	s.Apply(1, c)                             // s := c
+	la.SpMatTrVecMulAdd(s, -1, A, λ)          // s -= Aᵀλ
+	xmin := x[0]
+	smin := s[0]
+	for i := 1; i < nx; i++ {
+		xmin = utl.Min(xmin, x[i])
+		smin = utl.Min(smin, s[i])
+	}
+	δx := utl.Max(-1.5*xmin, 0)
+	δs := utl.Max(-1.5*smin, 0)
+	var xdots, xsum, ssum float64
+	for i := 0; i < nx; i++ {
+		x[i] += δx
+		s[i] += δs
+		xdots += x[i] * s[i]
+		xsum += x[i]
+		ssum += s[i]
+	}
+	δx = 0.5 * xdots / ssum
+	δs = 0.5 * xdots / xsum
+	for i := 0; i < nx; i++ {
+		x[i] += δx
+		s[i] += δs
+	}
+}
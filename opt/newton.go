@@ -0,0 +1,234 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/fun"
+	"github.com/dicksontsai/gosl/fun/dbf"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// Newton implements the multidimensional minimization by the line-search Newton method with
+// modified Cholesky factorisation (requires the Hessian function Problem.Hfcn)
+//
+//   The direction {u} is computed by solving ([H] + τ[I])⋅{u} = -{g} where [H] is the Hessian
+//   and τ ≥ 0 is the smallest value (found by successive increments) such that [H] + τ[I] is
+//   sufficiently positive definite (Algorithm 3.3 of [1]). Thus, {u} is always a descent
+//   direction. The step length is computed by LineSearch.Wolfe with initial trial step equal
+//   to 1; hence, quadratic convergence is achieved near the solution.
+//
+//   NOTE: Check Convergence to see how to set convergence parameters,
+//         max iteration number, or to enable and access history of iterations
+//
+//   REFERENCES:
+//   [1] Nocedal, J. and Wright, S. (2006) Numerical Optimization.
+//       Springer Series in Operations Research. 2nd Edition. Springer. 664p
+//
+type Newton struct {
+
+	// merge properties
+	Convergence // auxiliary object to check convergence
+
+	// input
+	Hfcn fun.Mv // Hessian function d²f/d{x}d{x}|(x)
+
+	// configuration
+	Beta float64 // minimum shift τ, relative to ‖H‖, when [H] is not positive definite [default = 1e-3]
+
+	// statistics
+	NumHeval int     // number of calls to Hfcn (Hessian evaluations)
+	Tau      float64 // shift τ of the last iteration
+
+	// internal
+	Hmat *la.Matrix // Hessian
+	Lmat *la.Matrix // Cholesky factor of [H] + τ[I]
+	g    la.Vector  // gradient
+	u    la.Vector  // direction
+
+	// line search
+	lines *LineSearch // line search
+}
+
+// add optimizer to database
+func init() {
+	nlsMakersDB["newton"] = func(prob *Problem) NonLinSolver { return NewNewton(prob) }
+}
+
+// NewNewton returns a new multidimensional optimizer using the line-search Newton method
+func NewNewton(prob *Problem) (o *Newton) {
+	if prob.Hfcn == nil {
+		chk.Panic("Newton's method requires the Hessian function Hfcn\n")
+	}
+	o = new(Newton)
+	o.InitConvergence(prob.Ffcn, prob.Gfcn)
+	o.Hfcn = func(h *la.Matrix, x la.Vector) {
+		o.NumHeval++
+		prob.Hfcn(h, x)
+	}
+	o.lines = NewLineSearch(prob.Ndim, o.Ffcn, o.Gfcn)
+	o.lines.Coef2 = 0.9 // recommended for Newton methods
+	o.Beta = 1e-3
+	o.Hmat = la.NewMatrix(prob.Ndim, prob.Ndim)
+	o.Lmat = la.NewMatrix(prob.Ndim, prob.Ndim)
+	o.g = la.NewVector(prob.Ndim)
+	o.u = la.NewVector(prob.Ndim)
+	return
+}
+
+// Min solves minimization problem
+//
+//  Input:
+//    x -- [ndim] initial starting point (will be modified)
+//    params -- [may be nil] optional parameters. e.g. "beta", "maxit". Example:
+//                 params := dbf.NewParams(
+//                     &dbf.P{N: "beta", V: 1e-3},
+//                     &dbf.P{N: "maxit", V: 1000},
+//                     &dbf.P{N: "maxitls", V: 20},
+//                     &dbf.P{N: "maxitzoom", V: 20},
+//                     &dbf.P{N: "ftol", V: 1e-2},
+//                     &dbf.P{N: "gtol", V: 1e-2},
+//                     &dbf.P{N: "hist", V: 1},
+//                     &dbf.P{N: "verb", V: 1},
+//                 )
+//
+//  Output:
+//    fmin -- f(x@min) minimum f({x}) found
+//    x -- [modify input] position of minimum f({x})
+//
+func (o *Newton) Min(x la.Vector, params dbf.Params) (fmin float64) {
+
+	// set parameters
+	o.Convergence.SetParams(params)
+	o.lines.SetParams(params)
+	o.Beta = params.GetValueOrDefault("beta", o.Beta)
+
+	// initializations
+	o.NumFeval, o.NumGeval, o.NumHeval = 0, 0, 0
+	fx := o.Ffcn(x)
+	o.Gfcn(o.g, x)
+	fmin = fx
+
+	// history
+	var λ float64
+	if o.UseHist {
+		o.InitHist(x)
+	}
+
+	// exit point # 1: initial gradient is zero
+	if o.Gconvergence(fx, x, o.g) {
+		return
+	}
+
+	// iterations
+	for o.NumIter = 0; o.NumIter < o.MaxIt; o.NumIter++ {
+
+		// direction: u := -inv(H + τI)⋅g
+		o.Hfcn(o.Hmat, x)
+		o.Tau = modifiedCholesky(o.Lmat, o.Hmat, o.Beta)
+		o.u.Apply(-1, o.g)
+		cholSolve(o.u, o.Lmat, o.u)
+
+		// line search
+		λ, fmin = o.lines.Wolfe(x, o.u, false, 0) // x := x @ min
+
+		// history
+		if o.UseHist {
+			o.uhist.Apply(λ, o.u)
+			o.Hist.Append(fmin, x, o.uhist)
+		}
+
+		// exit point # 2: converged on f
+		if o.Fconvergence(fx, fmin) {
+			return
+		}
+		fx = fmin
+
+		// exit point # 3: converged on dy/dx
+		o.Gfcn(o.g, x)
+		if o.Gconvergence(fx, x, o.g) {
+			return
+		}
+	}
+
+	// did not converge
+	chk.Panic("fail to converge after %d iterations\n", o.NumIter)
+	return
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// modifiedCholesky computes the Cholesky factor L of [a] + τ[I] where τ ≥ 0 is found by
+// successive increments such that [a] + τ[I] is positive definite (Algorithm 3.3 of [1])
+//   Input:
+//     a -- symmetric matrix
+//     β -- minimum (nonzero) shift relative to the Frobenius norm of [a]; e.g. 1e-3
+//   Output:
+//     L -- lower triangular matrix such that L⋅Lᵀ = [a] + τ[I]
+//     τ -- shift
+func modifiedCholesky(L, a *la.Matrix, β float64) (τ float64) {
+	amin := a.Get(0, 0)
+	for i := 1; i < a.M; i++ {
+		amin = utl.Min(amin, a.Get(i, i))
+	}
+	β *= utl.Max(a.NormFrob(), 1) // scale shift with the magnitude of [a]
+	if amin <= 0 {
+		τ = -amin + β
+	}
+	for !cholesky(L, a, τ) {
+		τ = utl.Max(2*τ, β)
+	}
+	return
+}
+
+// cholesky tries to compute the Cholesky factor L of [a] + τ[I]
+//   Output: false if [a] + τ[I] is not positive definite
+func cholesky(L, a *la.Matrix, τ float64) (ok bool) {
+	var sum float64
+	for j := 0; j < a.M; j++ {
+		for i := j; i < a.M; i++ {
+			sum = a.Get(i, j)
+			if i == j {
+				sum += τ
+			}
+			for k := 0; k < j; k++ {
+				sum -= L.Get(i, k) * L.Get(j, k)
+			}
+			if i == j {
+				if sum <= 0 {
+					return false
+				}
+				L.Set(i, j, math.Sqrt(sum))
+			} else {
+				L.Set(i, j, sum/L.Get(j, j))
+			}
+		}
+	}
+	return true
+}
+
+// cholSolve solves L⋅Lᵀ⋅{x} = {b} where L is the Cholesky factor
+//   NOTE: x and b may be the same vector
+func cholSolve(x la.Vector, L *la.Matrix, b la.Vector) {
+	n := L.M
+	var sum float64
+	for i := 0; i < n; i++ { // L⋅y = b
+		sum = b[i]
+		for k := 0; k < i; k++ {
+			sum -= L.Get(i, k) * x[k]
+		}
+		x[i] = sum / L.Get(i, i)
+	}
+	for i := n - 1; i >= 0; i-- { // Lᵀ⋅x = y
+		sum = x[i]
+		for k := i + 1; k < n; k++ {
+			sum -= L.Get(k, i) * x[k]
+		}
+		x[i] = sum / L.Get(i, i)
+	}
+}
@@ -5,18 +5,75 @@
 package opt
 
 import (
+	"math"
+
+	"github.com/dicksontsai/gosl/chk"
 	"github.com/dicksontsai/gosl/fun"
 	"github.com/dicksontsai/gosl/la"
 )
 
 // Problem holds the functions defining an optimization problem
+//
+//   NOTE: the box bounds Xlo ≤ {x} ≤ Xhi are only considered by the bound-constrained solvers
+//         ProjGrad and LBFGSB, and by the constrained solvers AugLag and SQP; use SetBounds to
+//         set them. The equality h({x}) = 0 and inequality c({x}) ≥ 0 constraints are only
+//         considered by AugLag and SQP
+//
 type Problem struct {
-	Ndim int       // dimension of x == len(x)
-	Ffcn fun.Sv    // objective function f({x})
-	Gfcn fun.Vv    // gradient function df/d{x}|(x)
-	Hfcn fun.Mv    // Hessian function d²f/d{x}d{x}|(x)
-	Fref float64   // known solution fmin = f({x})
-	Xref la.Vector // known solution {x} @ min
+	Ndim   int       // dimension of x == len(x)
+	Ffcn   fun.Sv    // objective function f({x})
+	Gfcn   fun.Vv    // gradient function df/d{x}|(x)
+	Hfcn   fun.Mv    // Hessian function d²f/d{x}d{x}|(x)
+	Xlo    la.Vector // lower bounds on {x} [may be nil ⇒ -∞]
+	Xhi    la.Vector // upper bounds on {x} [may be nil ⇒ +∞]
+	Neq    int       // number of equality constraints
+	Nineq  int       // number of inequality constraints
+	Eqfcn  fun.Vv    // equality constraints h({x}) = 0 [Neq]
+	EqJfcn fun.Mv    // Jacobian of equality constraints dh/d{x} [Neq][Ndim]
+	Infcn  fun.Vv    // inequality constraints c({x}) ≥ 0 [Nineq]
+	InJfcn fun.Mv    // Jacobian of inequality constraints dc/d{x} [Nineq][Ndim]
+	Fref   float64   // known solution fmin = f({x})
+	Xref   la.Vector // known solution {x} @ min
+}
+
+// SetBounds sets the box bounds xlo ≤ {x} ≤ xhi
+//   xlo -- [ndim] lower bounds. use math.Inf(-1) for unbounded variables [may be nil ⇒ -∞]
+//   xhi -- [ndim] upper bounds. use math.Inf(+1) for unbounded variables [may be nil ⇒ +∞]
+func (o *Problem) SetBounds(xlo, xhi []float64) {
+	o.Xlo, o.Xhi = nil, nil
+	if xlo != nil {
+		if len(xlo) != o.Ndim {
+			chk.Panic("size of lower bounds must be equal to ndim. %d != %d\n", len(xlo), o.Ndim)
+		}
+		o.Xlo = la.NewVectorSlice(xlo).GetCopy()
+	}
+	if xhi != nil {
+		if len(xhi) != o.Ndim {
+			chk.Panic("size of upper bounds must be equal to ndim. %d != %d\n", len(xhi), o.Ndim)
+		}
+		o.Xhi = la.NewVectorSlice(xhi).GetCopy()
+	}
+	if xlo != nil && xhi != nil {
+		for i := 0; i < o.Ndim; i++ {
+			if xlo[i] > xhi[i] {
+				chk.Panic("lower bound must not be greater than upper bound. xlo[%d] = %g > xhi[%d] = %g\n", i, xlo[i], i, xhi[i])
+			}
+		}
+	}
+}
+
+// GetBounds returns (new) vectors with lower and upper bounds, filled with ∓∞ if not set
+func (o *Problem) GetBounds() (xlo, xhi la.Vector) {
+	xlo, xhi = la.NewVector(o.Ndim), la.NewVector(o.Ndim)
+	xlo.Fill(math.Inf(-1))
+	xhi.Fill(math.Inf(+1))
+	if o.Xlo != nil {
+		xlo.Apply(1, o.Xlo)
+	}
+	if o.Xhi != nil {
+		xhi.Apply(1, o.Xhi)
+	}
+	return
 }
 
 // NewQuadraticProblem returns a quadratic optimization problem such that f(x) = xᵀ A x
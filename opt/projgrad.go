@@ -0,0 +1,193 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/fun/dbf"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// ProjGrad implements the minimization with box bounds xlo ≤ {x} ≤ xhi by the (spectral)
+// projected gradient method
+//
+//   The trial points are {x}(α) = P({x} - α⋅{g}) where P is the projection onto the box. The step
+//   α starts with the Barzilai-Borwein value α = (sᵀs)/(sᵀy) and is halved until the sufficient
+//   decrease condition f({x}(α)) ≤ f({x}) + Coef1⋅{g}ᵀ⋅({x}(α) - {x}) is satisfied [1,2]. The
+//   bounds are taken from Problem.Xlo and Problem.Xhi (see Problem.SetBounds).
+//
+//   NOTE: Check Convergence to see how to set convergence parameters,
+//         max iteration number, or to enable and access history of iterations.
+//         The projected gradient is employed in the zero gradient test (PGconvergence).
+//
+//   REFERENCES:
+//   [1] Nocedal, J. and Wright, S. (2006) Numerical Optimization.
+//       Springer Series in Operations Research. 2nd Edition. Springer. 664p
+//   [2] Birgin EG, Martínez JM and Raydan M (2000) Nonmonotone spectral projected gradient methods
+//       on convex sets. SIAM Journal on Optimization, 10(4):1196-1211
+//
+type ProjGrad struct {
+
+	// merge properties
+	Convergence // auxiliary object to check convergence
+
+	// input
+	Xlo la.Vector // lower bounds
+	Xhi la.Vector // upper bounds
+
+	// configuration
+	Coef1    float64 // sufficient decrease coefficient [default = 1e-4]
+	MaxItLs  int     // max number of step reductions in the line search [default = 40]
+	AlphaMin float64 // minimum step α [default = 1e-10]
+	AlphaMax float64 // maximum step α [default = 1e10]
+
+	// internal
+	g      la.Vector // gradient
+	gnew   la.Vector // new gradient
+	xtrial la.Vector // trial x
+	s      la.Vector // step
+}
+
+// add optimizer to database
+func init() {
+	nlsMakersDB["projgrad"] = func(prob *Problem) NonLinSolver { return NewProjGrad(prob) }
+}
+
+// NewProjGrad returns a new optimizer with box bounds using the projected gradient method
+func NewProjGrad(prob *Problem) (o *ProjGrad) {
+	o = new(ProjGrad)
+	o.InitConvergence(prob.Ffcn, prob.Gfcn)
+	o.Xlo, o.Xhi = prob.GetBounds()
+	o.Coef1 = 1e-4
+	o.MaxItLs = 40
+	o.AlphaMin = 1e-10
+	o.AlphaMax = 1e10
+	o.g = la.NewVector(prob.Ndim)
+	o.gnew = la.NewVector(prob.Ndim)
+	o.xtrial = la.NewVector(prob.Ndim)
+	o.s = la.NewVector(prob.Ndim)
+	return
+}
+
+// Min solves minimization problem
+//
+//  Input:
+//    x -- [ndim] initial starting point (will be modified and projected onto the box)
+//    params -- [may be nil] optional parameters. e.g. "coef1", "maxit". Example:
+//                 params := dbf.NewParams(
+//                     &dbf.P{N: "coef1", V: 1e-4},
+//                     &dbf.P{N: "maxitls", V: 40},
+//                     &dbf.P{N: "maxit", V: 1000},
+//                     &dbf.P{N: "ftol", V: 1e-2},
+//                     &dbf.P{N: "gtol", V: 1e-2},
+//                     &dbf.P{N: "hist", V: 1},
+//                     &dbf.P{N: "verb", V: 1},
+//                 )
+//
+//  Output:
+//    fmin -- f(x@min) minimum f({x}) found
+//    x -- [modify input] position of minimum f({x})
+//
+func (o *ProjGrad) Min(x la.Vector, params dbf.Params) (fmin float64) {
+
+	// set parameters
+	o.Convergence.SetParams(params)
+	o.Coef1 = params.GetValueOrDefault("coef1", o.Coef1)
+	o.MaxItLs = params.GetIntOrDefault("maxitls", o.MaxItLs)
+
+	// initializations
+	o.NumFeval, o.NumGeval = 0, 0
+	projectOnBox(x, x, o.Xlo, o.Xhi)
+	fx := o.Ffcn(x)
+	o.Gfcn(o.g, x)
+	fmin = fx
+
+	// history
+	if o.UseHist {
+		o.InitHist(x)
+	}
+
+	// exit point # 1: initial projected gradient is zero
+	if o.PGconvergence(fx, x, o.g, o.Xlo, o.Xhi) {
+		return
+	}
+
+	// initial step: unit length of the projected gradient P(x - g) - x
+	la.VecAdd(o.xtrial, 1, x, -1, o.g)
+	projectOnBox(o.xtrial, o.xtrial, o.Xlo, o.Xhi)
+	la.VecAdd(o.s, 1, o.xtrial, -1, x)
+	α := utl.Min(o.AlphaMax, 1.0/o.s.NormInf())
+
+	// iterations
+	var gs, ss, sy float64
+	for o.NumIter = 0; o.NumIter < o.MaxIt; o.NumIter++ {
+
+		// backtracking along the projection arc
+		ok := false
+		for k := 0; k < o.MaxItLs; k++ {
+			for i := 0; i < len(x); i++ {
+				o.xtrial[i] = x[i] - α*o.g[i]
+			}
+			projectOnBox(o.xtrial, o.xtrial, o.Xlo, o.Xhi)
+			la.VecAdd(o.s, 1, o.xtrial, -1, x)
+			gs = la.VecDot(o.g, o.s)
+			fmin = o.Ffcn(o.xtrial)
+			if fmin <= fx+o.Coef1*gs {
+				ok = true
+				break
+			}
+			α *= 0.5
+		}
+		if !ok {
+			chk.Panic("line search failed after %d reductions of step α\n", o.MaxItLs)
+		}
+		x.Apply(1, o.xtrial)
+
+		// history
+		if o.UseHist {
+			o.Hist.Append(fmin, x, o.s)
+		}
+
+		// exit point # 2: converged on f
+		if o.Fconvergence(fx, fmin) {
+			return
+		}
+		fx = fmin
+
+		// exit point # 3: converged on projected gradient
+		o.Gfcn(o.gnew, x)
+		if o.PGconvergence(fx, x, o.gnew, o.Xlo, o.Xhi) {
+			return
+		}
+
+		// Barzilai-Borwein step: α = sᵀs / sᵀy with y = gnew - g
+		ss, sy = 0, 0
+		for i := 0; i < len(x); i++ {
+			ss += o.s[i] * o.s[i]
+			sy += o.s[i] * (o.gnew[i] - o.g[i])
+		}
+		if sy > 0 {
+			α = utl.Min(o.AlphaMax, utl.Max(o.AlphaMin, ss/sy))
+		} else {
+			α = o.AlphaMax
+		}
+		o.g.Apply(1, o.gnew)
+	}
+
+	// did not converge
+	chk.Panic("fail to converge after %d iterations\n", o.NumIter)
+	return
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// projectOnBox computes res := P(x) where P is the projection onto the box xlo ≤ x ≤ xhi
+//   NOTE: res and x may be the same vector
+func projectOnBox(res, x, xlo, xhi la.Vector) {
+	for i := 0; i < len(x); i++ {
+		res[i] = utl.Min(utl.Max(x[i], xlo[i]), xhi[i])
+	}
+}
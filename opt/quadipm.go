@@ -0,0 +1,328 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/fun/dbf"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// QuadIpm implements the interior-point method for convex quadratic programming problems
+//  Solve:
+//          min ½ xᵀQx + cᵀx   s.t.   Aᵀx = b, Gᵀx ≤ h, x ≥ 0
+//           x
+//
+//  where Q is symmetric positive semi-definite. The inequalities are converted into equalities
+//  Gᵀx + w = h with slack variables w ≥ 0. Thus, with z := [x, w], the problem becomes
+//
+//          min ½ zᵀQz + cᵀz   s.t.   Eᵀz = f, z ≥ 0   with   Eᵀ := [[Aᵀ, 0], [Gᵀ, I]], f := [b, h]
+//           z
+//
+//  which is solved by the predictor-corrector primal-dual method as in LinIpm. Nonetheless, the
+//  same step length is used for the primal and dual variables because they are coupled by Q.
+//
+//  NOTE: free variables must be split as x = x⁺ - x⁻ with x⁺, x⁻ ≥ 0; upper bounds can be given
+//        as rows of G
+type QuadIpm struct {
+
+	// problem
+	Q *la.CCMatrix // [nx][nx] symmetric positive semi-definite matrix (both triangles) [may be nil]
+	A *la.CCMatrix // [Na][nx] equality constraints [may be nil]
+	B la.Vector    // [Na]
+	C la.Vector    // [nx]
+	G *la.CCMatrix // [Ng][nx] inequality constraints [may be nil]
+	H la.Vector    // [Ng]
+
+	// constants
+	NmaxIt int     // max number of iterations
+	Tol    float64 // tolerance ϵ for stopping iterations
+
+	// dimensions
+	Nx int // number of x
+	Na int // number of equality constraints
+	Ng int // number of inequality constraints == number of w
+	Nz int // number of z = nx + ng
+	Nl int // number of λ = na + ng
+	Ny int // number of y = nz + ns + nl = 2 * nz + nl
+
+	// solution vector
+	Y   la.Vector // y := [z, λ, s] with z := [x, w]
+	Z   la.Vector // subset of y
+	X   la.Vector // subset of z
+	W   la.Vector // subset of z: slack variables of inequalities
+	L   la.Vector // subset of y: multipliers of equalities followed by the ones of inequalities (≤ 0)
+	S   la.Vector // subset of y
+	Mdy la.Vector // -Δy
+	Mdz la.Vector // subset of Mdy == -Δz
+	Mdl la.Vector // subset of Mdy == -Δλ
+	Mds la.Vector // subset of Mdy == -Δs
+
+	// affine solution
+	R  la.Vector   // residual
+	Rz la.Vector   // subset of R
+	Rl la.Vector   // subset of R
+	Rs la.Vector   // subset of R
+	J  *la.Triplet // [ny][ny] Jacobian matrix
+
+	// linear solver
+	LsKind string          // kind of linear solver: "umfpack" or native "splu", ... [default = la.SpDefaultKind()]
+	Lis    la.SparseSolver // linear solver
+
+	// internal
+	e  *la.CCMatrix // [nl][nz] matrix of the standard problem
+	f  la.Vector    // [nl] right-hand side of the standard problem
+	cz la.Vector    // [nz] c of the standard problem
+	qx la.Vector    // [nx] Q x
+}
+
+// Free frees allocated memory
+func (o *QuadIpm) Free() {
+	o.Lis.Free()
+}
+
+// Init initialises QuadIpm
+//  Q -- [nx][nx] quadratic term [may be nil ⇒ linear programming problem]
+//  A, b -- [na][nx] and [na] equality constraints [may be nil]
+//  c -- [nx] linear term
+//  G, h -- [ng][nx] and [ng] inequality constraints [may be nil]
+//  prms -- [may be nil] parameters: "nmaxit" and "tol"
+//  NOTE: LsKind may be set before calling Init; otherwise, the default kind of linear solver is used
+func (o *QuadIpm) Init(Q, A *la.CCMatrix, b, c la.Vector, G *la.CCMatrix, h la.Vector, prms dbf.Params) {
+
+	// problem
+	o.Q, o.A, o.B, o.C, o.G, o.H = Q, A, b, c, G, h
+
+	// constants
+	o.NmaxIt = 50
+	o.Tol = 1e-8
+	for _, p := range prms {
+		switch p.N {
+		case "nmaxit":
+			o.NmaxIt = int(p.V)
+		case "tol":
+			o.Tol = p.V
+		}
+	}
+
+	// dimensions
+	o.Nx = len(o.C)
+	o.Na = len(o.B)
+	o.Ng = len(o.H)
+	o.Nz = o.Nx + o.Ng
+	o.Nl = o.Na + o.Ng
+	o.Ny = 2*o.Nz + o.Nl
+	if o.Nl == 0 {
+		chk.Panic("QuadIpm requires at least one equality or inequality constraint\n")
+	}
+	nnzQ, nnzA, nnzG := 0, 0, 0
+	if o.Q != nil {
+		m, n := o.Q.Dims()
+		if m != o.Nx || n != o.Nx {
+			chk.Panic("Q must be (%d,%d). (%d,%d) is invalid\n", o.Nx, o.Nx, m, n)
+		}
+		nnzQ = o.Q.Nnz()
+	}
+	if o.Na > 0 {
+		m, n := o.A.Dims()
+		if m != o.Na || n != o.Nx {
+			chk.Panic("A must be (%d,%d). (%d,%d) is invalid\n", o.Na, o.Nx, m, n)
+		}
+		nnzA = o.A.Nnz()
+	}
+	if o.Ng > 0 {
+		m, n := o.G.Dims()
+		if m != o.Ng || n != o.Nx {
+			chk.Panic("G must be (%d,%d). (%d,%d) is invalid\n", o.Ng, o.Nx, m, n)
+		}
+		nnzG = o.G.Nnz()
+	}
+	iz, jz := 0, o.Nz
+	il, jl := o.Nz, o.Nz+o.Nl
+	is, js := o.Nz+o.Nl, o.Ny
+
+	// standard problem
+	et := la.NewTriplet(o.Nl, o.Nz, nnzA+nnzG+o.Ng)
+	if o.Na > 0 {
+		et.PutCCMat(0, 0, 1, o.A)
+	}
+	if o.Ng > 0 {
+		et.PutCCMat(o.Na, 0, 1, o.G)
+	}
+	for i := 0; i < o.Ng; i++ {
+		et.Put(o.Na+i, o.Nx+i, 1)
+	}
+	o.e = et.ToMatrix(nil)
+	o.f = la.NewVector(o.Nl)
+	copy(o.f, o.B)
+	copy(o.f[o.Na:], o.H)
+	o.cz = la.NewVector(o.Nz)
+	copy(o.cz, o.C)
+	o.qx = la.NewVector(o.Nx)
+
+	// solution vector
+	o.Y = make([]float64, o.Ny)
+	o.Z = o.Y[iz:jz]
+	o.X = o.Z[:o.Nx]
+	o.W = o.Z[o.Nx:]
+	o.L = o.Y[il:jl]
+	o.S = o.Y[is:js]
+	o.Mdy = make([]float64, o.Ny)
+	o.Mdz = o.Mdy[iz:jz]
+	o.Mdl = o.Mdy[il:jl]
+	o.Mds = o.Mdy[is:js]
+
+	// affine solution
+	o.R = make([]float64, o.Ny)
+	o.Rz = o.R[iz:jz]
+	o.Rl = o.R[il:jl]
+	o.Rs = o.R[is:js]
+	o.J = new(la.Triplet)
+	nnz := nnzQ + 2*o.e.Nnz() + 3*o.Nz
+	o.J.Init(o.Ny, o.Ny, nnz)
+
+	// linear solver
+	if o.LsKind == "" {
+		o.LsKind = la.SpDefaultKind()
+	}
+	o.Lis = la.NewSparseSolver(o.LsKind)
+}
+
+// Solve solves quadratic programming problem
+func (o *QuadIpm) Solve(verbose bool) {
+
+	// starting point
+	ipmStartingPoint(o.Z, o.L, o.S, o.e, o.f, o.cz)
+
+	// constants for linear solver
+	symmetric := false
+
+	// auxiliary
+	I := o.Nz + o.Nl
+	cnorm := 1.0 + o.cz.NormInf()
+	fnorm := 1.0 + o.f.NormInf()
+
+	// control variables
+	var μ, σ float64          // μ and σ
+	var α float64             // step length
+	var μaff float64          // μ_affine
+	var xqx, ctx, ftl float64 // xᵀQx, cᵀx and fᵀl
+	var pobj, dobj float64    // primal and dual objective values
+	var lerr float64          // error
+
+	// message
+	if verbose {
+		io.Pf("%3s%16s%16s\n", "it", "f(x)", "error")
+	}
+
+	// perform iterations
+	it := 0
+	for it = 0; it < o.NmaxIt; it++ {
+
+		// compute residual
+		o.qx.Fill(0)
+		if o.Q != nil {
+			la.SpMatVecMul(o.qx, 1, o.Q, o.X) // qx := Q x
+		}
+		la.SpMatTrVecMul(o.Rz, 1, o.e, o.L) // rz := Eᵀλ
+		la.SpMatVecMul(o.Rl, 1, o.e, o.Z)   // rλ := E z
+		xqx, ctx, ftl, μ = 0, 0, 0, 0
+		for i := 0; i < o.Nz; i++ {
+			o.Rz[i] += o.S[i] - o.cz[i]
+			o.Rs[i] = o.Z[i] * o.S[i]
+			ctx += o.cz[i] * o.Z[i]
+			μ += o.Z[i] * o.S[i]
+		}
+		for i := 0; i < o.Nx; i++ {
+			o.Rz[i] -= o.qx[i]
+			xqx += o.X[i] * o.qx[i]
+		}
+		for i := 0; i < o.Nl; i++ {
+			o.Rl[i] -= o.f[i]
+			ftl += o.f[i] * o.L[i]
+		}
+		μ /= float64(o.Nz)
+
+		// check convergence
+		pobj = 0.5*xqx + ctx
+		dobj = ftl - 0.5*xqx
+		lerr = math.Abs(pobj-dobj) / (1.0 + math.Abs(pobj))
+		lerr = utl.Max(lerr, o.Rz.NormInf()/cnorm)
+		lerr = utl.Max(lerr, o.Rl.NormInf()/fnorm)
+		if verbose {
+			io.Pf("%3d%16.8e%16.8e\n", it, pobj, lerr)
+		}
+		if lerr < o.Tol {
+			break
+		}
+
+		// assemble Jacobian
+		o.J.Start()
+		if o.Q != nil {
+			o.J.PutCCMat(0, 0, -1, o.Q)
+		}
+		o.J.PutCCMatAndMatT(o.e)
+		for i := 0; i < o.Nz; i++ {
+			o.J.Put(i, I+i, 1.0)
+			o.J.Put(I+i, i, o.S[i])
+			o.J.Put(I+i, I+i, o.Z[i])
+		}
+
+		// solve linear system
+		if it == 0 {
+			o.Lis.Init(o.J, &la.SpArgs{Symmetric: symmetric, Verbose: false, Ordering: "", Scaling: "", Guess: nil, Communicator: nil})
+		}
+		o.Lis.Fact()
+		o.Lis.Solve(o.Mdy, o.R, false) // mdy := inv(J) * R
+
+		// control variables
+		α = utl.Min(1, o.calcMinRatio())
+		μaff = 0
+		for i := 0; i < o.Nz; i++ {
+			μaff += (o.Z[i] - α*o.Mdz[i]) * (o.S[i] - α*o.Mds[i])
+		}
+		μaff /= float64(o.Nz)
+		σ = math.Pow(μaff/μ, 3)
+
+		// update residual
+		for i := 0; i < o.Nz; i++ {
+			o.Rs[i] += o.Mdz[i]*o.Mds[i] - σ*μ
+		}
+
+		// solve linear system again
+		o.Lis.Solve(o.Mdy, o.R, false) // mdy := inv(J) * R
+
+		// step length
+		α = utl.Min(1, 0.99*o.calcMinRatio())
+
+		// update
+		for i := 0; i < o.Ny; i++ {
+			o.Y[i] -= α * o.Mdy[i]
+		}
+	}
+
+	// check convergence
+	if it == o.NmaxIt {
+		chk.Panic("iterations did not converge")
+	}
+}
+
+// calcMinRatio returns min{ z_i / (-Δz_i), s_i / (-Δs_i) }
+func (o *QuadIpm) calcMinRatio() (rmin float64) {
+	rmin = math.Inf(1)
+	for i := 0; i < o.Nz; i++ {
+		if o.Mdz[i] > 0 {
+			rmin = utl.Min(rmin, o.Z[i]/o.Mdz[i])
+		}
+		if o.Mds[i] > 0 {
+			rmin = utl.Min(rmin, o.S[i]/o.Mds[i])
+		}
+	}
+	return
+}
@@ -0,0 +1,527 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/fun/dbf"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// SQP implements the minimization with equality and inequality constraints by the line-search
+// sequential quadratic programming method (Algorithm 18.3 of [1])
+//
+//   Solve:
+//          min f({x})   s.t.   h({x}) = 0,   c({x}) ≥ 0,   Xlo ≤ {x} ≤ Xhi
+//
+//   At each iteration, the step {p} and the multipliers estimates are obtained from the QP
+//   subproblem
+//
+//          min ½ pᵀ⋅[B]⋅p + ∇fᵀ⋅p   s.t.   [∇h]⋅p + h = 0,   [∇c]⋅p + c ≥ 0,   Xlo ≤ {x} + p ≤ Xhi
+//
+//   which is solved by a primal-dual interior-point method with dense matrices. [B] is the damped
+//   BFGS approximation of the Hessian of the Lagrangian (Procedure 18.2 of [1]) and the step
+//   length is computed by backtracking on the ℓ1 merit function f + ν⋅(‖h‖₁ + ‖max(0, -c)‖₁).
+//
+//   NOTE: Check Convergence to see how to set convergence parameters, max iteration number, or to
+//         enable and access history of iterations. Check Constraints to see how to access the
+//         Lagrange multipliers and KKT residuals.
+//
+//   REFERENCES:
+//   [1] Nocedal, J. and Wright, S. (2006) Numerical Optimization.
+//       Springer Series in Operations Research. 2nd Edition. Springer. 664p
+//
+type SQP struct {
+
+	// merge properties
+	Convergence // auxiliary object to check convergence
+	Constraints // constraints, multipliers and KKT residuals
+
+	// configuration
+	Coef1   float64 // sufficient decrease coefficient of the merit function [default = 1e-4]
+	MaxItLs int     // max number of step reductions in the line search [default = 30]
+
+	// statistics
+	Nu float64 // penalty parameter ν of the merit function
+
+	// internal
+	Bmat *la.Matrix // approximation of the Hessian of the Lagrangian
+	g    la.Vector  // gradient of f
+	gL   la.Vector  // gradient of the Lagrangian @ x (with new multipliers)
+	gLn  la.Vector  // gradient of the Lagrangian @ xnew (with new multipliers)
+	p    la.Vector  // step
+	xnew la.Vector  // trial x
+	s    la.Vector  // accepted step
+	bs   la.Vector  // [B]⋅s
+	r    la.Vector  // damped change of gradient of the Lagrangian
+	qp   *denseQP   // QP subproblem solver
+}
+
+// add optimizer to database
+func init() {
+	nlsMakersDB["sqp"] = func(prob *Problem) NonLinSolver { return NewSQP(prob) }
+}
+
+// NewSQP returns a new constrained optimizer using the sequential quadratic programming method
+func NewSQP(prob *Problem) (o *SQP) {
+	o = new(SQP)
+	o.InitConvergence(prob.Ffcn, prob.Gfcn)
+	o.InitConstraints(prob)
+	o.Coef1 = 1e-4
+	o.MaxItLs = 30
+	o.Bmat = la.NewMatrix(prob.Ndim, prob.Ndim)
+	o.g = la.NewVector(prob.Ndim)
+	o.gL = la.NewVector(prob.Ndim)
+	o.gLn = la.NewVector(prob.Ndim)
+	o.p = la.NewVector(prob.Ndim)
+	o.xnew = la.NewVector(prob.Ndim)
+	o.s = la.NewVector(prob.Ndim)
+	o.bs = la.NewVector(prob.Ndim)
+	o.r = la.NewVector(prob.Ndim)
+
+	// QP subproblem: finite bounds become inequalities
+	nb := 0
+	for i := 0; i < prob.Ndim; i++ {
+		if !math.IsInf(o.Xlo[i], -1) {
+			nb++
+		}
+		if !math.IsInf(o.Xhi[i], +1) {
+			nb++
+		}
+	}
+	o.qp = newDenseQP(prob.Ndim, o.Neq, o.Nineq+nb)
+	return
+}
+
+// Min solves minimization problem
+//
+//  Input:
+//    x -- [ndim] initial starting point (will be modified)
+//    params -- [may be nil] optional parameters. e.g. "ctol", "maxit". Example:
+//                 params := dbf.NewParams(
+//                     &dbf.P{N: "coef1", V: 1e-4},
+//                     &dbf.P{N: "maxitls", V: 30},
+//                     &dbf.P{N: "ctol", V: 1e-6},
+//                     &dbf.P{N: "maxit", V: 100},
+//                     &dbf.P{N: "gtol", V: 1e-6},
+//                     &dbf.P{N: "hist", V: 1},
+//                     &dbf.P{N: "verb", V: 1},
+//                 )
+//
+//  Output:
+//    fmin -- f(x@min) minimum f({x}) found
+//    x -- [modify input] position of minimum f({x})
+//    o.Lambda -- multipliers of equality constraints
+//    o.Mu -- multipliers of inequality constraints
+//    o.ResStat, o.ResFeas, o.ResCompl -- KKT residuals
+//
+func (o *SQP) Min(x la.Vector, params dbf.Params) (fmin float64) {
+
+	// set parameters
+	o.Convergence.SetParams(params)
+	o.SetConstraintsParams(params)
+	o.Coef1 = params.GetValueOrDefault("coef1", o.Coef1)
+	o.MaxItLs = params.GetIntOrDefault("maxitls", o.MaxItLs)
+
+	// initializations
+	o.NumFeval, o.NumGeval, o.NumCeval, o.NumJeval = 0, 0, 0, 0
+	o.Lambda.Fill(0)
+	o.Mu.Fill(0)
+	o.Nu = 0
+	o.Bmat.Fill(0)
+	o.Bmat.SetDiag(1)
+	if o.HasBounds() {
+		projectOnBox(x, x, o.Xlo, o.Xhi)
+	}
+	fmin = o.Ffcn(x)
+	o.Gfcn(o.g, x)
+	o.evalConstraints(x)
+	o.evalJacobians(x)
+
+	// history
+	if o.UseHist {
+		o.InitHist(x)
+	}
+
+	// exit point # 1: initial point satisfies the KKT conditions
+	if o.KKTconvergence(x, o.g, o.Gtol) {
+		return
+	}
+
+	// iterations
+	var α, φ, φnew, dφ, θ, sBs, sy float64
+	for o.NumIter = 0; o.NumIter < o.MaxIt; o.NumIter++ {
+
+		// solve QP subproblem
+		o.setQP(x)
+		o.qp.solve()
+		o.p.Apply(1, o.qp.X)
+
+		// update penalty parameter of the merit function
+		for i := 0; i < o.Neq; i++ {
+			o.Nu = utl.Max(o.Nu, 1.1*math.Abs(o.qp.Y[i]))
+		}
+		for j := 0; j < o.Nineq; j++ {
+			o.Nu = utl.Max(o.Nu, 1.1*math.Abs(o.qp.Z[j]))
+		}
+
+		// backtracking line search on the merit function
+		φ = fmin + o.Nu*o.violation()
+		dφ = la.VecDot(o.g, o.p) - o.Nu*o.violation()
+		α = 1
+		ok := false
+		for k := 0; k < o.MaxItLs; k++ {
+			la.VecAdd(o.xnew, 1, x, α, o.p)
+			projectOnBox(o.xnew, o.xnew, o.Xlo, o.Xhi) // remove round-off errors
+			fmin = o.Ffcn(o.xnew)
+			o.evalConstraints(o.xnew)
+			φnew = fmin + o.Nu*o.violation()
+			if φnew <= φ+o.Coef1*α*dφ {
+				ok = true
+				break
+			}
+			α *= 0.5
+		}
+		if !ok {
+			chk.Panic("line search failed after %d reductions of step α\n", o.MaxItLs)
+		}
+
+		// update multipliers
+		for i := 0; i < o.Neq; i++ {
+			o.Lambda[i] += α * (o.qp.Y[i] - o.Lambda[i])
+		}
+		for j := 0; j < o.Nineq; j++ {
+			o.Mu[j] += α * (o.qp.Z[j] - o.Mu[j])
+		}
+
+		// gradient of the Lagrangian @ x with new multipliers (Jacobians are still @ x)
+		o.lagGradient(o.gL, o.g, o.Lambda, o.Mu)
+
+		// accept step
+		la.VecAdd(o.s, 1, o.xnew, -1, x)
+		x.Apply(1, o.xnew)
+		o.Gfcn(o.g, x)
+		o.evalJacobians(x)
+		o.lagGradient(o.gLn, o.g, o.Lambda, o.Mu)
+
+		// history
+		if o.UseHist {
+			o.Hist.Append(fmin, x, o.s)
+		}
+
+		// exit point # 2: KKT conditions are satisfied
+		if o.KKTconvergence(x, o.g, o.Gtol) {
+			return
+		}
+
+		// damped BFGS update of [B] with y = ∇L(xnew) - ∇L(x)
+		la.MatVecMul(o.bs, 1, o.Bmat, o.s)
+		sBs = la.VecDot(o.s, o.bs)
+		if sBs <= 0 {
+			continue
+		}
+		sy = 0
+		for i := 0; i < len(x); i++ {
+			sy += o.s[i] * (o.gLn[i] - o.gL[i])
+		}
+		θ = 1
+		if sy < 0.2*sBs {
+			θ = 0.8 * sBs / (sBs - sy)
+		}
+		for i := 0; i < len(x); i++ {
+			o.r[i] = θ*(o.gLn[i]-o.gL[i]) + (1-θ)*o.bs[i]
+		}
+		sr := la.VecDot(o.s, o.r)
+		for i := 0; i < len(x); i++ {
+			for j := 0; j < len(x); j++ {
+				o.Bmat.Add(i, j, -o.bs[i]*o.bs[j]/sBs+o.r[i]*o.r[j]/sr)
+			}
+		}
+	}
+
+	// did not converge
+	chk.Panic("fail to converge after %d iterations\n", o.NumIter)
+	return
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// violation returns ‖h‖₁ + ‖max(0, -c)‖₁ using o.h and o.c
+func (o *SQP) violation() (res float64) {
+	for i := 0; i < o.Neq; i++ {
+		res += math.Abs(o.h[i])
+	}
+	for j := 0; j < o.Nineq; j++ {
+		res += utl.Max(0, -o.c[j])
+	}
+	return
+}
+
+// setQP sets the matrices of the QP subproblem @ x
+func (o *SQP) setQP(x la.Vector) {
+	n := len(x)
+	o.Bmat.CopyInto(o.qp.G, 1)
+	o.qp.D.Apply(1, o.g)
+	for i := 0; i < o.Neq; i++ {
+		for k := 0; k < n; k++ {
+			o.qp.A.Set(i, k, o.Jh.Get(i, k))
+		}
+		o.qp.B[i] = -o.h[i]
+	}
+	for j := 0; j < o.Nineq; j++ {
+		for k := 0; k < n; k++ {
+			o.qp.C.Set(j, k, o.Jc.Get(j, k))
+		}
+		o.qp.E[j] = -o.c[j]
+	}
+	j := o.Nineq
+	for k := 0; k < n; k++ {
+		if !math.IsInf(o.Xlo[k], -1) { // p ≥ xlo - x
+			o.qp.C.Set(j, k, 1)
+			o.qp.E[j] = o.Xlo[k] - x[k]
+			j++
+		}
+		if !math.IsInf(o.Xhi[k], +1) { // -p ≥ x - xhi
+			o.qp.C.Set(j, k, -1)
+			o.qp.E[j] = x[k] - o.Xhi[k]
+			j++
+		}
+	}
+}
+
+// denseQP solves the convex quadratic programming problem with dense matrices
+//
+//          min ½ xᵀ⋅G⋅x + dᵀ⋅x   s.t.   A⋅x = b,   C⋅x ≥ e
+//
+//   by the predictor-corrector primal-dual interior-point method (Algorithm 16.4 of [1]). G must
+//   be positive definite. The multipliers are Y (equalities) and Z ≥ 0 (inequalities) with
+//   G⋅x + d - Aᵀ⋅y - Cᵀ⋅z = 0.
+type denseQP struct {
+
+	// problem
+	G *la.Matrix // [n][n]
+	D la.Vector  // [n]
+	A *la.Matrix // [me][n]
+	B la.Vector  // [me]
+	C *la.Matrix // [mi][n]
+	E la.Vector  // [mi]
+
+	// configuration
+	MaxIt int     // max number of iterations
+	Tol   float64 // tolerance
+
+	// solution
+	X la.Vector // [n] primal variables
+	Y la.Vector // [me] multipliers of equalities
+	Z la.Vector // [mi] multipliers of inequalities
+	S la.Vector // [mi] slack variables: s = C⋅x - e
+
+	// workspace
+	n, me, mi int
+	K         *la.Matrix // [n+me][n+me] reduced KKT matrix
+	rhs       la.Vector  // [n+me] right-hand side
+	sol       la.Vector  // [n+me] solution {Δx, -Δy}
+	rd        la.Vector  // [n] dual residual
+	rp        la.Vector  // [me] primal residual (equalities)
+	rc        la.Vector  // [mi] primal residual (inequalities)
+	rsz       la.Vector  // [mi] complementarity residual
+	dz, ds    la.Vector  // [mi] increments
+	dzA, dsA  la.Vector  // [mi] affine increments
+	dx        la.Vector  // [n] increment
+	dy        la.Vector  // [me] increment
+}
+
+// newDenseQP returns a new dense QP solver
+func newDenseQP(n, me, mi int) (o *denseQP) {
+	o = new(denseQP)
+	o.n, o.me, o.mi = n, me, mi
+	o.G = la.NewMatrix(n, n)
+	o.D = la.NewVector(n)
+	o.A = la.NewMatrix(me, n)
+	o.B = la.NewVector(me)
+	o.C = la.NewMatrix(mi, n)
+	o.E = la.NewVector(mi)
+	o.MaxIt = 100
+	o.Tol = 1e-12
+	o.X = la.NewVector(n)
+	o.Y = la.NewVector(me)
+	o.Z = la.NewVector(mi)
+	o.S = la.NewVector(mi)
+	o.K = la.NewMatrix(n+me, n+me)
+	o.rhs = la.NewVector(n + me)
+	o.sol = la.NewVector(n + me)
+	o.rd = la.NewVector(n)
+	o.rp = la.NewVector(me)
+	o.rc = la.NewVector(mi)
+	o.rsz = la.NewVector(mi)
+	o.dz = la.NewVector(mi)
+	o.ds = la.NewVector(mi)
+	o.dzA = la.NewVector(mi)
+	o.dsA = la.NewVector(mi)
+	o.dx = la.NewVector(n)
+	o.dy = la.NewVector(me)
+	return
+}
+
+// solve solves the QP problem
+func (o *denseQP) solve() {
+
+	// initial values
+	o.X.Fill(0)
+	o.Y.Fill(0)
+	for j := 0; j < o.mi; j++ {
+		o.S[j] = utl.Max(-o.E[j], 1) // s = max(C⋅x - e, 1) with x = 0
+		o.Z[j] = 1
+	}
+
+	// scaling for the tolerance
+	scale := 1 + utl.Max(o.D.NormInf(), utl.Max(o.B.NormInf(), o.E.NormInf()))
+
+	// iterations
+	var μ, μA, σ, αA, α float64
+	for it := 0; it < o.MaxIt; it++ {
+
+		// residuals
+		la.MatVecMul(o.rd, 1, o.G, o.X)
+		for i := 0; i < o.n; i++ {
+			o.rd[i] += o.D[i]
+			for k := 0; k < o.me; k++ {
+				o.rd[i] -= o.A.Get(k, i) * o.Y[k]
+			}
+			for j := 0; j < o.mi; j++ {
+				o.rd[i] -= o.C.Get(j, i) * o.Z[j]
+			}
+		}
+		μ = 0
+		if o.me > 0 {
+			la.MatVecMul(o.rp, 1, o.A, o.X)
+			la.VecAdd(o.rp, 1, o.rp, -1, o.B)
+		}
+		if o.mi > 0 {
+			la.MatVecMul(o.rc, 1, o.C, o.X)
+			for j := 0; j < o.mi; j++ {
+				o.rc[j] -= o.S[j] + o.E[j]
+				μ += o.S[j] * o.Z[j]
+			}
+			μ /= float64(o.mi)
+		}
+
+		// check convergence
+		res := utl.Max(utl.Max(o.rd.NormInf(), μ), utl.Max(o.rp.NormInf(), o.rc.NormInf()))
+		if res < o.Tol*scale {
+			return
+		}
+
+		// factorisation of the reduced KKT matrix: [[G + Cᵀ⋅(Z/S)⋅C, Aᵀ], [A, 0]]
+		o.K.Fill(0)
+		for i := 0; i < o.n; i++ {
+			for k := 0; k < o.n; k++ {
+				v := o.G.Get(i, k)
+				for j := 0; j < o.mi; j++ {
+					v += o.C.Get(j, i) * o.Z[j] / o.S[j] * o.C.Get(j, k)
+				}
+				o.K.Set(i, k, v)
+			}
+			for k := 0; k < o.me; k++ {
+				o.K.Set(i, o.n+k, o.A.Get(k, i))
+				o.K.Set(o.n+k, i, o.A.Get(k, i))
+			}
+		}
+
+		// predictor (affine) step
+		for j := 0; j < o.mi; j++ {
+			o.rsz[j] = o.S[j] * o.Z[j]
+		}
+		o.newtonStep()
+		o.dsA.Apply(1, o.ds)
+		o.dzA.Apply(1, o.dz)
+		αA = o.stepLength(1)
+		if o.mi == 0 {
+			o.update(αA)
+			continue
+		}
+
+		// centering parameter
+		μA = 0
+		for j := 0; j < o.mi; j++ {
+			μA += (o.S[j] + αA*o.dsA[j]) * (o.Z[j] + αA*o.dzA[j])
+		}
+		μA /= float64(o.mi)
+		σ = math.Pow(μA/μ, 3)
+
+		// corrector step
+		for j := 0; j < o.mi; j++ {
+			o.rsz[j] = o.S[j]*o.Z[j] + o.dsA[j]*o.dzA[j] - σ*μ
+		}
+		o.newtonStep()
+		α = o.stepLength(0.995)
+		o.update(α)
+	}
+	chk.Panic("QP subproblem did not converge after %d iterations (infeasible linearized constraints?)\n", o.MaxIt)
+}
+
+// newtonStep solves the Newton system using o.K and the residuals
+func (o *denseQP) newtonStep() {
+
+	// right-hand side
+	for i := 0; i < o.n; i++ {
+		o.rhs[i] = -o.rd[i]
+		for j := 0; j < o.mi; j++ {
+			o.rhs[i] -= o.C.Get(j, i) * (o.rsz[j] + o.Z[j]*o.rc[j]) / o.S[j]
+		}
+	}
+	for k := 0; k < o.me; k++ {
+		o.rhs[o.n+k] = -o.rp[k]
+	}
+
+	// solve
+	la.DenSolve(o.sol, o.K, o.rhs, true)
+	for i := 0; i < o.n; i++ {
+		o.dx[i] = o.sol[i]
+	}
+	for k := 0; k < o.me; k++ {
+		o.dy[k] = -o.sol[o.n+k]
+	}
+
+	// increments of slack variables and multipliers of inequalities
+	if o.mi > 0 {
+		la.MatVecMul(o.ds, 1, o.C, o.dx)
+	}
+	for j := 0; j < o.mi; j++ {
+		o.ds[j] += o.rc[j]
+		o.dz[j] = -(o.rsz[j] + o.Z[j]*o.ds[j]) / o.S[j]
+	}
+}
+
+// stepLength returns the maximum step α ≤ 1 such that s + α⋅Δs ≥ (1-τ)⋅s and z + α⋅Δz ≥ (1-τ)⋅z
+func (o *denseQP) stepLength(τ float64) (α float64) {
+	α = 1
+	for j := 0; j < o.mi; j++ {
+		if o.ds[j] < 0 {
+			α = utl.Min(α, -τ*o.S[j]/o.ds[j])
+		}
+		if o.dz[j] < 0 {
+			α = utl.Min(α, -τ*o.Z[j]/o.dz[j])
+		}
+	}
+	return
+}
+
+// update updates the solution with step α
+func (o *denseQP) update(α float64) {
+	for i := 0; i < o.n; i++ {
+		o.X[i] += α * o.dx[i]
+	}
+	for k := 0; k < o.me; k++ {
+		o.Y[k] += α * o.dy[k]
+	}
+	for j := 0; j < o.mi; j++ {
+		o.S[j] += α * o.ds[j]
+		o.Z[j] += α * o.dz[j]
+	}
+}
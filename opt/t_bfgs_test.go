@@ -0,0 +1,110 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/fun/dbf"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/plt"
+)
+
+func runBfgsTest(tst *testing.T, fnkey string, p *Problem, x0 la.Vector, tolf, tolx float64, params dbf.Params) {
+
+	// solve using BFGS
+	xmin1 := x0.GetCopy()
+	sol1 := NewBFGS(p)
+	sol1.UseHist = true
+	fmin1 := sol1.Min(xmin1, params)
+	io.Pforan("BFGS: NumIter = %v  NumFeval = %v  NumGeval = %v\n", sol1.NumIter, sol1.NumFeval, sol1.NumGeval)
+	chk.Float64(tst, "BFGS: fmin", tolf, fmin1, p.Fref)
+	chk.Array(tst, "BFGS: xmin", tolx, xmin1, p.Xref)
+
+	// solve using L-BFGS
+	xmin2 := x0.GetCopy()
+	sol2 := NewLBFGS(p)
+	sol2.UseHist = true
+	fmin2 := sol2.Min(xmin2, params)
+	io.Pforan("L-BFGS: NumIter = %v  NumFeval = %v  NumGeval = %v\n", sol2.NumIter, sol2.NumFeval, sol2.NumGeval)
+	chk.Float64(tst, "L-BFGS: fmin", tolf, fmin2, p.Fref)
+	chk.Array(tst, "L-BFGS: xmin", tolx, xmin2, p.Xref)
+	io.Pl()
+
+	// plot
+	if chk.Verbose {
+		if p.Ndim > 2 {
+			plt.Reset(true, &plt.A{WidthPt: 600, Dpi: 150, Prop: 0.8})
+			CompareHistory3d("BFGS", "L-BFGS", sol1.Hist, sol2.Hist, xmin1, xmin2)
+			plt.Save("/tmp/gosl/opt", fnkey)
+		} else {
+			plt.Reset(true, &plt.A{WidthPt: 300, Dpi: 150, Prop: 1.5})
+			CompareHistory2d("BFGS", "L-BFGS", sol1.Hist, sol2.Hist, xmin1, xmin2)
+			plt.Save("/tmp/gosl/opt", fnkey)
+		}
+	}
+}
+
+func TestBfgs01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bfgs01. quadratic optimization in 2D and 3D")
+
+	runBfgsTest(tst, "bfgs01a", Factory.SimpleQuadratic2d(), la.NewVectorSlice([]float64{1.5, -0.75}), 1e-13, 1e-7, nil)
+	runBfgsTest(tst, "bfgs01b", Factory.SimpleQuadratic3d(), la.NewVectorSlice([]float64{1, 2, 3}), 1e-13, 1e-7, nil)
+}
+
+func TestBfgs02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bfgs02. Rosenbrock functions")
+
+	params := dbf.NewParams(
+		&dbf.P{N: "ftol", V: 1e-15},
+		&dbf.P{N: "gtol", V: 1e-10},
+	)
+	runBfgsTest(tst, "bfgs02a", Factory.Rosenbrock2d(1, 100), la.NewVectorSlice([]float64{-1.2, 1}), 1e-15, 1e-7, params)
+	runBfgsTest(tst, "bfgs02b", Factory.RosenbrockMulti(5), la.NewVectorSlice([]float64{1.3, 0.7, 0.8, 1.9, 1.2}), 1e-15, 1e-7, params)
+}
+
+func TestBfgs03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bfgs03. L-BFGS with many variables and small history")
+
+	// problem
+	N := 1000
+	p := Factory.RosenbrockMulti(N)
+	x := la.NewVector(N)
+	for i := 0; i < N; i++ {
+		x[i] = 0.5 + 0.5*float64(i%3)
+	}
+
+	// solve
+	sol := NewLBFGS(p)
+	fmin := sol.Min(x, dbf.NewParams(
+		&dbf.P{N: "mem", V: 5},
+		&dbf.P{N: "maxit", V: 10000},
+		&dbf.P{N: "ftol", V: 1e-12},
+		&dbf.P{N: "gtol", V: 1e-7},
+	))
+	io.Pforan("NumIter = %v  NumFeval = %v  NumGeval = %v\n", sol.NumIter, sol.NumFeval, sol.NumGeval)
+	chk.Int(tst, "Mem", sol.Mem, 5)
+	chk.Float64(tst, "fmin", 1e-12, fmin, p.Fref)
+	chk.Array(tst, "xmin", 1e-6, x, p.Xref)
+}
+
+func TestBfgs04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bfgs04. invalid history size")
+
+	defer chk.RecoverTstPanicIsOK(tst)
+	p := Factory.SimpleParaboloid()
+	sol := NewLBFGS(p)
+	sol.Min(la.NewVectorSlice([]float64{1, 1}), dbf.NewParams(&dbf.P{N: "mem", V: 0}))
+}
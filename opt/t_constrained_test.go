@@ -0,0 +1,207 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/fun/dbf"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
+)
+
+func runConstrainedTest(tst *testing.T, p *Problem, x0 la.Vector, tolf, tolx float64, λref, μref []float64, params dbf.Params) {
+
+	// solve using the augmented Lagrangian method
+	xmin1 := x0.GetCopy()
+	sol1 := NewAugLag(p)
+	sol1.UseHist = true
+	fmin1 := sol1.Min(xmin1, params)
+	io.Pforan("AugLag: NumIter = %v  NumFeval = %v  NumCeval = %v  Rho = %v\n", sol1.NumIter, sol1.NumFeval, sol1.NumCeval, sol1.Rho)
+	chk.Float64(tst, "AugLag: fmin", tolf, fmin1, p.Fref)
+	chk.Array(tst, "AugLag: xmin", tolx, xmin1, p.Xref)
+	chk.Array(tst, "AugLag: λ", tolx, sol1.Lambda, λref)
+	chk.Array(tst, "AugLag: μ", tolx, sol1.Mu, μref)
+
+	// solve using SQP
+	xmin2 := x0.GetCopy()
+	sol2 := NewSQP(p)
+	sol2.UseHist = true
+	fmin2 := sol2.Min(xmin2, params)
+	io.Pforan("SQP: NumIter = %v  NumFeval = %v  NumCeval = %v  Nu = %v\n", sol2.NumIter, sol2.NumFeval, sol2.NumCeval, sol2.Nu)
+	chk.Float64(tst, "SQP: fmin", tolf, fmin2, p.Fref)
+	chk.Array(tst, "SQP: xmin", tolx, xmin2, p.Xref)
+	chk.Array(tst, "SQP: λ", tolx, sol2.Lambda, λref)
+	chk.Array(tst, "SQP: μ", tolx, sol2.Mu, μref)
+	io.Pl()
+
+	// check KKT residuals and history
+	for _, c := range []*Constraints{&sol1.Constraints, &sol2.Constraints} {
+		if c.ResFeas > c.Ctol || c.ResCompl > c.Ctol {
+			tst.Errorf("KKT residuals are too large: ResFeas = %g  ResCompl = %g\n", c.ResFeas, c.ResCompl)
+			return
+		}
+	}
+	chk.Int(tst, "AugLag: len(Hist.HistF)", len(sol1.Hist.HistF), sol1.NumIter+2)
+	chk.Int(tst, "SQP: len(Hist.HistF)", len(sol2.Hist.HistF), sol2.NumIter+2)
+}
+
+func TestConstrained01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Constrained01. quadratic function with linear inequality")
+
+	// min x0² + x1²  s.t.  x0 + x1 - 1 ≥ 0  and  2 - x0 ≥ 0 (inactive)
+	p := &Problem{Ndim: 2, Nineq: 2, Fref: 0.5, Xref: []float64{0.5, 0.5}}
+	p.Ffcn = func(x la.Vector) float64 { return x[0]*x[0] + x[1]*x[1] }
+	p.Gfcn = func(g, x la.Vector) { g[0], g[1] = 2*x[0], 2*x[1] }
+	p.Infcn = func(c, x la.Vector) { c[0], c[1] = x[0]+x[1]-1, 2-x[0] }
+	p.InJfcn = func(J *la.Matrix, x la.Vector) {
+		J.Set(0, 0, 1)
+		J.Set(0, 1, 1)
+		J.Set(1, 0, -1)
+		J.Set(1, 1, 0)
+	}
+	runConstrainedTest(tst, p, la.NewVectorSlice([]float64{3, -1}), 1e-7, 1e-6, []float64{}, []float64{1, 0}, dbf.NewParams(
+		&dbf.P{N: "gtol", V: 1e-8},
+		&dbf.P{N: "ctol", V: 1e-8},
+	))
+}
+
+func TestConstrained02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Constrained02. equality and nonlinear inequality")
+
+	// min (x0-2)² + (x1-1)²  s.t.  x0 - 2 x1 + 1 = 0  and  1 - x0²/4 - x1² ≥ 0
+	s7 := math.Sqrt(7)
+	p := &Problem{Ndim: 2, Neq: 1, Nineq: 1}
+	p.Xref = []float64{(s7 - 1) / 2, (s7 + 1) / 4}
+	p.Fref = math.Pow(p.Xref[0]-2, 2) + math.Pow(p.Xref[1]-1, 2)
+	p.Ffcn = func(x la.Vector) float64 { return math.Pow(x[0]-2, 2) + math.Pow(x[1]-1, 2) }
+	p.Gfcn = func(g, x la.Vector) { g[0], g[1] = 2*(x[0]-2), 2*(x[1]-1) }
+	p.Eqfcn = func(h, x la.Vector) { h[0] = x[0] - 2*x[1] + 1 }
+	p.EqJfcn = func(J *la.Matrix, x la.Vector) {
+		J.Set(0, 0, 1)
+		J.Set(0, 1, -2)
+	}
+	p.Infcn = func(c, x la.Vector) { c[0] = 1 - x[0]*x[0]/4 - x[1]*x[1] }
+	p.InJfcn = func(J *la.Matrix, x la.Vector) {
+		J.Set(0, 0, -x[0]/2)
+		J.Set(0, 1, -2*x[1])
+	}
+
+	// multipliers from ∇f = λ⋅∇h + μ⋅∇c
+	x0, x1 := p.Xref[0], p.Xref[1]
+	μ := (2*(x0-2) + (x1 - 1)) / (-x0/2 - x1)
+	λ := 2*(x0-2) + μ*x0/2
+	runConstrainedTest(tst, p, la.NewVectorSlice([]float64{2, 2}), 1e-7, 1e-6, []float64{λ}, []float64{μ}, dbf.NewParams(
+		&dbf.P{N: "gtol", V: 1e-8},
+		&dbf.P{N: "ctol", V: 1e-8},
+	))
+}
+
+func TestConstrained03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Constrained03. Example 18.2 of Nocedal and Wright")
+
+	// min exp(x0 x1 x2 x3 x4) - ½ (x0³ + x1³ + 1)²
+	// s.t. Σ xᵢ² - 10 = 0,  x1 x2 - 5 x3 x4 = 0,  x0³ + x1³ + 1 = 0
+	p := &Problem{Ndim: 5, Neq: 3}
+	p.Xref = []float64{-1.717143, 1.595709, 1.827247, -0.7636413, -0.7636450} // 7 digits from the book
+	p.Fref = 0.053950
+	p.Ffcn = func(x la.Vector) float64 {
+		return math.Exp(x[0]*x[1]*x[2]*x[3]*x[4]) - 0.5*math.Pow(x[0]*x[0]*x[0]+x[1]*x[1]*x[1]+1, 2)
+	}
+	p.Gfcn = func(g, x la.Vector) {
+		e := math.Exp(x[0] * x[1] * x[2] * x[3] * x[4])
+		q := x[0]*x[0]*x[0] + x[1]*x[1]*x[1] + 1
+		for i := 0; i < 5; i++ {
+			g[i] = e
+			for j := 0; j < 5; j++ {
+				if j != i {
+					g[i] *= x[j]
+				}
+			}
+		}
+		g[0] -= 3 * x[0] * x[0] * q
+		g[1] -= 3 * x[1] * x[1] * q
+	}
+	p.Eqfcn = func(h, x la.Vector) {
+		h[0] = la.VecDot(x, x) - 10
+		h[1] = x[1]*x[2] - 5*x[3]*x[4]
+		h[2] = x[0]*x[0]*x[0] + x[1]*x[1]*x[1] + 1
+	}
+	p.EqJfcn = func(J *la.Matrix, x la.Vector) {
+		J.Fill(0)
+		for i := 0; i < 5; i++ {
+			J.Set(0, i, 2*x[i])
+		}
+		J.Set(1, 1, x[2])
+		J.Set(1, 2, x[1])
+		J.Set(1, 3, -5*x[4])
+		J.Set(1, 4, -5*x[3])
+		J.Set(2, 0, 3*x[0]*x[0])
+		J.Set(2, 1, 3*x[1]*x[1])
+	}
+
+	// check Jacobian
+	x := la.NewVectorSlice([]float64{-1.71, 1.59, 1.82, -0.763, -0.763})
+	J := la.NewMatrix(3, 5)
+	p.EqJfcn(J, x)
+	chk.DerivVecVec(tst, "dh/dx", 1e-8, J.GetDeep2(), x, 1e-6, chk.Verbose, func(h, x []float64) {
+		p.Eqfcn(h, x)
+	})
+
+	// solve
+	params := dbf.NewParams(
+		&dbf.P{N: "gtol", V: 1e-8},
+		&dbf.P{N: "ctol", V: 1e-8},
+	)
+	for _, kind := range []string{"auglag", "sqp"} {
+		xmin := x.GetCopy()
+		sol := GetNonLinSolver(kind, p)
+		fmin := sol.Min(xmin, params)
+		io.Pforan("%s: fmin = %v\n", kind, fmin)
+		chk.Float64(tst, kind+": fmin", 1e-6, fmin, p.Fref)
+		chk.Array(tst, kind+": xmin", 1e-5, xmin, p.Xref)
+	}
+}
+
+func TestConstrained04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Constrained04. equality constraint and bounds")
+
+	// min (x0-3)² + (x1-3)²  s.t.  x0 + x1 - 2 = 0  and  x0 ≤ ½  ⇒  x = (½, 3/2) and λ = -3
+	p := &Problem{Ndim: 2, Neq: 1, Fref: 6.25 + 2.25, Xref: []float64{0.5, 1.5}}
+	p.Ffcn = func(x la.Vector) float64 { return math.Pow(x[0]-3, 2) + math.Pow(x[1]-3, 2) }
+	p.Gfcn = func(g, x la.Vector) { g[0], g[1] = 2*(x[0]-3), 2*(x[1]-3) }
+	p.Eqfcn = func(h, x la.Vector) { h[0] = x[0] + x[1] - 2 }
+	p.EqJfcn = func(J *la.Matrix, x la.Vector) {
+		J.Set(0, 0, 1)
+		J.Set(0, 1, 1)
+	}
+	p.SetBounds([]float64{math.Inf(-1), math.Inf(-1)}, []float64{0.5, math.Inf(1)})
+	runConstrainedTest(tst, p, la.NewVectorSlice([]float64{-1, -1}), 1e-7, 1e-6, []float64{-3}, []float64{}, dbf.NewParams(
+		&dbf.P{N: "gtol", V: 1e-8},
+		&dbf.P{N: "ctol", V: 1e-8},
+	))
+}
+
+func TestConstrained05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Constrained05. missing Jacobian")
+
+	defer chk.RecoverTstPanicIsOK(tst)
+	p := Factory.SimpleParaboloid()
+	p.Neq = 1
+	p.Eqfcn = func(h, x la.Vector) { h[0] = x[0] }
+	NewAugLag(p)
+}
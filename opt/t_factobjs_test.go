@@ -28,6 +28,10 @@ func TestFactObjs01(tst *testing.T) {
 	p.Gfcn(gAna, x)
 	chk.DerivScaVec(tst, "Gfcn", 1e-7, gAna, x, 1e-3, chk.Verbose, func(xx []float64) float64 { return p.Ffcn(xx) })
 
+	hAna := la.NewMatrix(2, 2)
+	p.Hfcn(hAna, x)
+	chk.DerivVecVec(tst, "Hfcn", 1e-6, hAna.GetDeep2(), x, 1e-3, chk.Verbose, func(gg, xx []float64) { p.Gfcn(gg, xx) })
+
 	// plot
 	if chk.Verbose {
 		xvec := la.NewVector(2)
@@ -58,4 +62,8 @@ func TestFactObjs02(tst *testing.T) {
 	gAna := la.NewVector(N)
 	p.Gfcn(gAna, x)
 	chk.DerivScaVec(tst, "Gfcn", 1e-6, gAna, x, 1e-3, chk.Verbose, func(xx []float64) float64 { return p.Ffcn(xx) })
+
+	hAna := la.NewMatrix(N, N)
+	p.Hfcn(hAna, x)
+	chk.DerivVecVec(tst, "Hfcn", 1e-6, hAna.GetDeep2(), x, 1e-3, chk.Verbose, func(gg, xx []float64) { p.Gfcn(gg, xx) })
 }
@@ -0,0 +1,200 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/fun/dbf"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/plt"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+func runBoundsTest(tst *testing.T, fnkey string, p *Problem, x0 la.Vector, tolf, tolx float64, params dbf.Params) {
+
+	// solve using the projected gradient method
+	xmin1 := x0.GetCopy()
+	sol1 := NewProjGrad(p)
+	sol1.UseHist = true
+	fmin1 := sol1.Min(xmin1, params)
+	io.Pforan("ProjGrad: NumIter = %v  NumFeval = %v  NumGeval = %v\n", sol1.NumIter, sol1.NumFeval, sol1.NumGeval)
+	chk.Float64(tst, "ProjGrad: fmin", tolf, fmin1, p.Fref)
+	chk.Array(tst, "ProjGrad: xmin", tolx, xmin1, p.Xref)
+
+	// solve using L-BFGS-B
+	xmin2 := x0.GetCopy()
+	sol2 := NewLBFGSB(p)
+	sol2.UseHist = true
+	fmin2 := sol2.Min(xmin2, params)
+	io.Pforan("L-BFGS-B: NumIter = %v  NumFeval = %v  NumGeval = %v\n", sol2.NumIter, sol2.NumFeval, sol2.NumGeval)
+	chk.Float64(tst, "L-BFGS-B: fmin", tolf, fmin2, p.Fref)
+	chk.Array(tst, "L-BFGS-B: xmin", tolx, xmin2, p.Xref)
+	io.Pl()
+
+	// check bounds
+	for _, x := range [][]float64{xmin1, xmin2} {
+		for i := 0; i < p.Ndim; i++ {
+			if (p.Xlo != nil && x[i] < p.Xlo[i]) || (p.Xhi != nil && x[i] > p.Xhi[i]) {
+				tst.Errorf("x[%d] = %g is out of bounds\n", i, x[i])
+				return
+			}
+		}
+	}
+
+	// plot
+	if chk.Verbose && p.Ndim == 2 {
+		plt.Reset(true, &plt.A{WidthPt: 300, Dpi: 150, Prop: 1.5})
+		CompareHistory2d("ProjGrad", "L-BFGS-B", sol1.Hist, sol2.Hist, xmin1, xmin2)
+		plt.Save("/tmp/gosl/opt", fnkey)
+	}
+}
+
+func TestLbfgsb01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Lbfgsb01. Rosenbrock function with bounds")
+
+	// inactive bounds
+	params := dbf.NewParams(
+		&dbf.P{N: "maxit", V: 20000},
+		&dbf.P{N: "ftol", V: 1e-15},
+		&dbf.P{N: "gtol", V: 1e-9},
+	)
+	p := Factory.Rosenbrock2d(1, 100)
+	p.SetBounds([]float64{-2, -2}, []float64{2, 2})
+	runBoundsTest(tst, "lbfgsb01a", p, la.NewVectorSlice([]float64{-1.2, 1}), 1e-12, 1e-6, params)
+
+	// active upper bound on x0 ⇒ min{(1-½)² + b(x1-¼)²} @ x1 = ¼
+	p = Factory.Rosenbrock2d(1, 100)
+	p.SetBounds([]float64{-2, -1}, []float64{0.5, 2})
+	p.Fref = 0.25
+	p.Xref = la.NewVectorSlice([]float64{0.5, 0.25})
+	runBoundsTest(tst, "lbfgsb01b", p, la.NewVectorSlice([]float64{-1.2, 1}), 1e-12, 1e-7, params)
+}
+
+func TestLbfgsb02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Lbfgsb02. separable quadratic function with bounds")
+
+	// f(x) = Σ ½ aᵢ (xᵢ - cᵢ)²  with  0 ≤ x ≤ 1  ⇒  xmin = clamp(c)
+	N := 50
+	a := la.NewVector(N)
+	c := la.NewVector(N)
+	p := &Problem{Ndim: N, Xref: la.NewVector(N)}
+	for i := 0; i < N; i++ {
+		a[i] = 1 + float64(i%7)
+		c[i] = -1 + 3*float64(i)/float64(N-1)
+		p.Xref[i] = utl.Min(utl.Max(c[i], 0), 1)
+		p.Fref += 0.5 * a[i] * math.Pow(p.Xref[i]-c[i], 2)
+	}
+	p.Ffcn = func(x la.Vector) (f float64) {
+		for i := 0; i < N; i++ {
+			f += 0.5 * a[i] * math.Pow(x[i]-c[i], 2)
+		}
+		return
+	}
+	p.Gfcn = func(g, x la.Vector) {
+		for i := 0; i < N; i++ {
+			g[i] = a[i] * (x[i] - c[i])
+		}
+	}
+	p.SetBounds(utl.Vals(N, 0), utl.Vals(N, 1))
+	x0 := la.NewVector(N)
+	x0.Fill(0.5)
+	runBoundsTest(tst, "lbfgsb02", p, x0, 1e-12, 1e-6, dbf.NewParams(
+		&dbf.P{N: "ftol", V: 1e-15},
+		&dbf.P{N: "gtol", V: 1e-10},
+	))
+}
+
+func TestLbfgsb03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Lbfgsb03. multi-dimensional Rosenbrock function with bounds")
+
+	// bounds: x ≥ 1.2 for every third variable ⇒ some bounds are active at the solution
+	N := 30
+	p := Factory.RosenbrockMulti(N)
+	xlo := utl.Vals(N, -10)
+	xhi := utl.Vals(N, 10)
+	for i := 0; i < N; i += 3 {
+		xlo[i] = 1.2
+	}
+	p.SetBounds(xlo, xhi)
+
+	// solve
+	x := la.NewVector(N)
+	x.Fill(3)
+	sol := NewLBFGSB(p)
+	fmin := sol.Min(x, dbf.NewParams(
+		&dbf.P{N: "mem", V: 5},
+		&dbf.P{N: "maxit", V: 2000},
+		&dbf.P{N: "ftol", V: 1e-15},
+		&dbf.P{N: "gtol", V: 1e-9},
+	))
+	io.Pforan("NumIter = %v  NumFeval = %v  NumGeval = %v\n", sol.NumIter, sol.NumFeval, sol.NumGeval)
+	io.Pforan("fmin = %v\n", fmin)
+
+	// check first-order optimality conditions (fmin ≈ 110 ⇒ tolerance larger than gtol)
+	g := la.NewVector(N)
+	p.Gfcn(g, x)
+	for i := 0; i < N; i++ {
+		switch {
+		case x[i] == xlo[i]:
+			if g[i] < -1e-5 {
+				tst.Errorf("g[%d] = %g must be non-negative at lower bound\n", i, g[i])
+			}
+		case x[i] == xhi[i]:
+			if g[i] > 1e-5 {
+				tst.Errorf("g[%d] = %g must be non-positive at upper bound\n", i, g[i])
+			}
+		default:
+			chk.Float64(tst, io.Sf("g[%d]", i), 1e-5, g[i], 0)
+		}
+	}
+	chk.Float64(tst, "x[0]", 1e-15, x[0], 1.2)
+}
+
+func TestLbfgsb04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Lbfgsb04. projected gradient convergence test")
+
+	var c Convergence
+	c.Gtol = 1e-6
+	xlo := la.NewVectorSlice([]float64{0, 0})
+	xhi := la.NewVectorSlice([]float64{1, 1})
+	x := la.NewVectorSlice([]float64{0, 1})
+	gOut := la.NewVectorSlice([]float64{2, -3})
+	gIn := la.NewVectorSlice([]float64{-2, 3})
+
+	// infinite bounds ⇒ same as Gconvergence
+	p := Factory.SimpleParaboloid()
+	xloInf, xhiInf := p.GetBounds()
+	xInf := la.NewVectorSlice([]float64{0.5, 0.5})
+	gInf := la.NewVectorSlice([]float64{1e-7, -1e-7})
+
+	chk.Bools(tst, "converged", []bool{
+		c.PGconvergence(0, x, gOut, xlo, xhi),          // gradient pointing outwards at active bounds
+		c.Gconvergence(0, x, gOut),                     // unconstrained test
+		c.PGconvergence(0, x, gIn, xlo, xhi),           // gradient pointing inwards
+		c.PGconvergence(0, xInf, gInf, xloInf, xhiInf), // infinite bounds
+	}, []bool{true, false, false, true})
+}
+
+func TestLbfgsb05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Lbfgsb05. invalid bounds")
+
+	defer chk.RecoverTstPanicIsOK(tst)
+	p := Factory.SimpleParaboloid()
+	p.SetBounds([]float64{0, 1}, []float64{1, 0})
+}
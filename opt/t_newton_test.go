@@ -0,0 +1,152 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/fun/dbf"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/plt"
+)
+
+func runNewtonTest(tst *testing.T, fnkey string, p *Problem, x0 la.Vector, tolf, tolx float64, params dbf.Params) {
+
+	// solve using Newton's method
+	xmin1 := x0.GetCopy()
+	sol1 := NewNewton(p)
+	sol1.UseHist = true
+	fmin1 := sol1.Min(xmin1, params)
+	io.Pforan("Newton: NumIter = %v  NumFeval = %v  NumGeval = %v  NumHeval = %v\n", sol1.NumIter, sol1.NumFeval, sol1.NumGeval, sol1.NumHeval)
+	chk.Float64(tst, "Newton: fmin", tolf, fmin1, p.Fref)
+	chk.Array(tst, "Newton: xmin", tolx, xmin1, p.Xref)
+
+	// solve using trust-region methods
+	var sols []*TrustRegion
+	var xmins []la.Vector
+	for _, method := range []string{"dogleg", "steihaug"} {
+		xmin := x0.GetCopy()
+		sol := NewTrustRegion(p, method)
+		sol.UseHist = true
+		fmin := sol.Min(xmin, params)
+		io.Pforan("%s: NumIter = %v  NumFeval = %v  NumGeval = %v  NumHeval = %v\n", method, sol.NumIter, sol.NumFeval, sol.NumGeval, sol.NumHeval)
+		chk.Float64(tst, method+": fmin", tolf, fmin, p.Fref)
+		chk.Array(tst, method+": xmin", tolx, xmin, p.Xref)
+		sols = append(sols, sol)
+		xmins = append(xmins, xmin)
+	}
+	io.Pl()
+
+	// plot
+	if chk.Verbose {
+		if p.Ndim > 2 {
+			plt.Reset(true, &plt.A{WidthPt: 600, Dpi: 150, Prop: 0.8})
+			CompareHistory3d("Newton", "dogleg", sol1.Hist, sols[0].Hist, xmin1, xmins[0])
+			plt.Save("/tmp/gosl/opt", fnkey)
+		} else {
+			plt.Reset(true, &plt.A{WidthPt: 300, Dpi: 150, Prop: 1.5})
+			CompareHistory2d("Newton", "dogleg", sol1.Hist, sols[0].Hist, xmin1, xmins[0])
+			plt.Save("/tmp/gosl/opt", fnkey)
+		}
+	}
+}
+
+func TestNewton01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Newton01. quadratic optimization in 2D and 3D")
+
+	runNewtonTest(tst, "newton01a", Factory.SimpleQuadratic2d(), la.NewVectorSlice([]float64{1.5, -0.75}), 1e-14, 1e-7, nil)
+	runNewtonTest(tst, "newton01b", Factory.SimpleQuadratic3d(), la.NewVectorSlice([]float64{1, 2, 3}), 1e-14, 1e-7, nil)
+
+	// Newton's method must converge in a single iteration with a full step
+	p := Factory.SimpleQuadratic3d()
+	x := la.NewVectorSlice([]float64{1, 2, 3})
+	sol := NewNewton(p)
+	sol.Min(x, nil)
+	chk.Int(tst, "NumHeval", sol.NumHeval, 1)
+	chk.Float64(tst, "Tau", 1e-15, sol.Tau, 0)
+	chk.Array(tst, "xmin", 1e-14, x, p.Xref)
+}
+
+func TestNewton02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Newton02. Rosenbrock functions")
+
+	params := dbf.NewParams(
+		&dbf.P{N: "ftol", V: 1e-15},
+		&dbf.P{N: "gtol", V: 1e-10},
+	)
+	runNewtonTest(tst, "newton02a", Factory.Rosenbrock2d(1, 100), la.NewVectorSlice([]float64{-1.2, 1}), 1e-15, 1e-7, params)
+	runNewtonTest(tst, "newton02b", Factory.RosenbrockMulti(5), la.NewVectorSlice([]float64{1.3, 0.7, 0.8, 1.9, 1.2}), 1e-15, 1e-7, params)
+}
+
+func TestNewton03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Newton03. indefinite Hessian at starting point")
+
+	// the Hessian of the Rosenbrock function is indefinite at (0,1)
+	p := Factory.Rosenbrock2d(1, 100)
+	x0 := la.NewVectorSlice([]float64{0, 1})
+	H := la.NewMatrix(2, 2)
+	p.Hfcn(H, x0)
+	chk.Float64(tst, "H00", 1e-15, H.Get(0, 0), -398)
+
+	params := dbf.NewParams(
+		&dbf.P{N: "ftol", V: 1e-15},
+		&dbf.P{N: "gtol", V: 1e-10},
+	)
+	runNewtonTest(tst, "newton03", p, x0, 1e-15, 1e-7, params)
+}
+
+func TestNewton04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Newton04. Steihaug-Toint CG with many variables")
+
+	// problem
+	N := 200
+	p := Factory.RosenbrockMulti(N)
+	x := la.NewVector(N)
+	for i := 0; i < N; i++ {
+		x[i] = 0.5 + 0.5*float64(i%3)
+	}
+
+	// solve
+	sol := NewTrustRegion(p, "steihaug")
+	fmin := sol.Min(x, dbf.NewParams(
+		&dbf.P{N: "maxit", V: 1000},
+		&dbf.P{N: "ftol", V: 1e-15},
+		&dbf.P{N: "gtol", V: 1e-10},
+	))
+	io.Pforan("NumIter = %v  NumFeval = %v  NumGeval = %v  NumHeval = %v  NumItCG = %v\n", sol.NumIter, sol.NumFeval, sol.NumGeval, sol.NumHeval, sol.NumItCG)
+	chk.Float64(tst, "fmin", 1e-15, fmin, p.Fref)
+	chk.Array(tst, "xmin", 1e-8, x, p.Xref)
+}
+
+func TestNewton05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Newton05. missing Hessian function")
+
+	p := Factory.SimpleParaboloid()
+	p.Hfcn = nil
+	defer chk.RecoverTstPanicIsOK(tst)
+	NewNewton(p)
+}
+
+func TestNewton06(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Newton06. invalid trust-region method")
+
+	p := Factory.SimpleParaboloid()
+	defer chk.RecoverTstPanicIsOK(tst)
+	NewTrustRegion(p, "cauchy")
+}
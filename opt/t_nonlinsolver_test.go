@@ -21,7 +21,7 @@ func TestNLS01(tst *testing.T) {
 	p := Factory.SimpleParaboloid()
 	x := la.NewVectorSlice([]float64{1, 1})
 
-	for _, kind := range []string{"conjgrad", "powell", "graddesc"} {
+	for _, kind := range []string{"conjgrad", "powell", "graddesc", "bfgs", "lbfgs", "newton", "dogleg", "steihaug", "projgrad", "lbfgsb", "auglag", "sqp"} {
 		io.Pf(">>>>>>>>>>>>>>>>>>> running %q <<<<<<<<<<<<<<<<<<<<\n", kind)
 		sol := GetNonLinSolver(kind, p)
 		fmin := sol.Min(x, nil)
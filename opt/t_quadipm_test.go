@@ -0,0 +1,181 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/fun/dbf"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
+)
+
+func TestQuadIpm01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("QuadIpm01. Small quadratic program with inequality")
+
+	// quadratic programming problem
+	//   min  x0² + x1² - 2*x0 - 5*x1
+	//   s.t. x0 + x1 ≤ 2
+	//        x0,x1 ≥ 0
+	// solution: projection of (1, 2.5) onto x0 + x1 = 2
+	Q := la.NewTriplet(2, 2, 2)
+	Q.Put(0, 0, 2)
+	Q.Put(1, 1, 2)
+	G := la.NewTriplet(1, 2, 2)
+	G.Put(0, 0, 1)
+	G.Put(0, 1, 1)
+	c := []float64{-2, -5}
+	h := []float64{2}
+
+	// solve QP
+	var ipm QuadIpm
+	defer ipm.Free()
+	ipm.Init(Q.ToMatrix(nil), nil, nil, c, G.ToMatrix(nil), h, dbf.NewParams(
+		&dbf.P{N: "tol", V: 1e-10},
+	))
+	ipm.Solve(chk.Verbose)
+
+	// check
+	io.Pforan("x = %v\n", ipm.X)
+	io.Pforan("w = %v\n", ipm.W)
+	io.Pfcyan("λ = %v\n", ipm.L)
+	chk.Array(tst, "x", 1e-8, ipm.X, []float64{0.25, 1.75})
+	chk.Array(tst, "w", 1e-8, ipm.W, []float64{0})
+	chk.Array(tst, "λ", 1e-7, ipm.L, []float64{-1.5})
+}
+
+func TestQuadIpm02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("QuadIpm02. Portfolio problem with active bound")
+
+	// quadratic programming problem
+	//   min  ½ (x0² + 2*x1² + 4*x2²) + 2*x0
+	//   s.t. x0 + x1 + x2 = 1
+	//        x0,x1,x2 ≥ 0
+	// solution: x0 = 0 (active) ⇒ 2*x1 = 4*x2 = λ ⇒ λ = 4/3
+	Q := la.NewTriplet(3, 3, 3)
+	Q.Put(0, 0, 1)
+	Q.Put(1, 1, 2)
+	Q.Put(2, 2, 4)
+	A := la.NewTriplet(1, 3, 3)
+	A.Put(0, 0, 1)
+	A.Put(0, 1, 1)
+	A.Put(0, 2, 1)
+	b := []float64{1}
+	c := []float64{2, 0, 0}
+
+	// solve QP
+	var ipm QuadIpm
+	defer ipm.Free()
+	ipm.Init(Q.ToMatrix(nil), A.ToMatrix(nil), b, c, nil, nil, nil)
+	ipm.Solve(chk.Verbose)
+
+	// check
+	io.Pforan("x = %v\n", ipm.X)
+	io.Pfcyan("λ = %v\n", ipm.L)
+	io.Pforan("s = %v\n", ipm.S)
+	chk.Array(tst, "x", 1e-8, ipm.X, []float64{0, 2.0 / 3.0, 1.0 / 3.0})
+	chk.Array(tst, "λ", 1e-7, ipm.L, []float64{4.0 / 3.0})
+	chk.Array(tst, "s", 1e-7, ipm.S, []float64{2.0 / 3.0, 0, 0})
+}
+
+func TestQuadIpm03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("QuadIpm03. Linear program (Q = nil)")
+
+	// linear programming problem from Linipm01 with inequalities given by G
+	//   min  -4*x0 - 5*x1
+	//   s.t.  2*x0 +   x1 ≤ 3
+	//           x0 + 2*x1 ≤ 3
+	//         x0,x1 ≥ 0
+	G := la.NewTriplet(2, 2, 4)
+	G.Put(0, 0, 2.0)
+	G.Put(0, 1, 1.0)
+	G.Put(1, 0, 1.0)
+	G.Put(1, 1, 2.0)
+	c := []float64{-4, -5}
+	h := []float64{3, 3}
+
+	// solve LP
+	var ipm QuadIpm
+	defer ipm.Free()
+	ipm.Init(nil, nil, nil, c, G.ToMatrix(nil), h, nil)
+	ipm.Solve(chk.Verbose)
+
+	// check
+	io.Pforan("x = %v\n", ipm.X)
+	io.Pforan("w = %v\n", ipm.W)
+	chk.Array(tst, "x", 1e-8, ipm.X, []float64{1, 1})
+	chk.Array(tst, "w", 1e-8, ipm.W, []float64{0, 0})
+}
+
+func TestQuadIpm04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("QuadIpm04. Sparse quadratic program with box constraints")
+
+	// quadratic programming problem
+	//   min  ½ xᵀQx + cᵀx   with   Q = tridiag(-1, 2, -1)
+	//   s.t. Σ xᵢ = N/4
+	//        0 ≤ xᵢ ≤ ½
+	N := 40
+	Qt := la.NewTriplet(N, N, 3*N)
+	At := la.NewTriplet(1, N, N)
+	Gt := la.NewTriplet(N, N, N)
+	c := la.NewVector(N)
+	h := la.NewVector(N)
+	for i := 0; i < N; i++ {
+		Qt.Put(i, i, 2)
+		if i > 0 {
+			Qt.Put(i, i-1, -1)
+			Qt.Put(i-1, i, -1)
+		}
+		At.Put(0, i, 1)
+		Gt.Put(i, i, 1)
+		c[i] = float64(i%5) - 2
+		h[i] = 0.5
+	}
+	b := []float64{float64(N) / 4}
+	Q, A, G := Qt.ToMatrix(nil), At.ToMatrix(nil), Gt.ToMatrix(nil)
+
+	// solve QP
+	var ipm QuadIpm
+	defer ipm.Free()
+	ipm.LsKind = "splu"
+	ipm.Init(Q, A, b, c, G, h, nil)
+	ipm.Solve(chk.Verbose)
+	io.Pforan("x = %v\n", ipm.X)
+
+	// check feasibility
+	ax := la.NewVector(1)
+	la.SpMatVecMul(ax, 1, A, ipm.X)
+	chk.Array(tst, "A x = b", 1e-8, ax, b)
+	for i := 0; i < N; i++ {
+		if ipm.X[i] < 0 || ipm.X[i] > 0.5+1e-8 {
+			tst.Errorf("x[%d] = %g is out of bounds\n", i, ipm.X[i])
+			return
+		}
+	}
+
+	// check stationarity: Q x + c - Aᵀλa - Gᵀλg - s = 0
+	r := la.NewVector(N)
+	la.SpMatVecMul(r, 1, Q, ipm.X)
+	la.SpMatTrVecMulAdd(r, -1, A, ipm.L[:1])
+	la.SpMatTrVecMulAdd(r, -1, G, ipm.L[1:])
+	for i := 0; i < N; i++ {
+		r[i] += c[i] - ipm.S[i]
+	}
+	chk.Array(tst, "stationarity", 1e-7, r, nil)
+
+	// check complementarity
+	for i := 0; i < ipm.Nz; i++ {
+		chk.Float64(tst, io.Sf("z[%d]⋅s[%d]", i, i), 1e-7, ipm.Z[i]*ipm.S[i], 0)
+	}
+}
@@ -0,0 +1,336 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/fun"
+	"github.com/dicksontsai/gosl/fun/dbf"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/utl"
+)
+
+// TrustRegion implements the multidimensional minimization by trust-region methods using the
+// Hessian function Problem.Hfcn
+//
+//   At each iteration, the quadratic model
+//
+//     m({p}) = f + {g}ᵀ⋅{p} + ½ {p}ᵀ⋅[H]⋅{p}     with     ‖{p}‖ ≤ Δ
+//
+//   is approximately minimized and the step is accepted if the ratio ρ between the actual and
+//   the predicted reductions is greater than Eta. The radius Δ is reduced if ρ < ¼ and enlarged
+//   if ρ > ¾ and the step reaches the boundary (Algorithm 4.1 of [1]). The subproblem is solved
+//   by one of the following methods:
+//
+//     "dogleg"   -- dogleg method (Section 4.1 of [1]); the Newton step is computed with the
+//                   Cholesky factorisation of [H]; if [H] is not positive definite, the Cauchy
+//                   point is used instead
+//     "steihaug" -- Steihaug-Toint truncated conjugate gradients (Algorithm 7.2 of [1]); only
+//                   products [H]⋅{d} are required and negative curvature directions are followed
+//                   up to the boundary
+//
+//   NOTE: Check Convergence to see how to set convergence parameters,
+//         max iteration number, or to enable and access history of iterations
+//
+//   REFERENCES:
+//   [1] Nocedal, J. and Wright, S. (2006) Numerical Optimization.
+//       Springer Series in Operations Research. 2nd Edition. Springer. 664p
+//
+type TrustRegion struct {
+
+	// merge properties
+	Convergence // auxiliary object to check convergence
+
+	// input
+	Hfcn   fun.Mv // Hessian function d²f/d{x}d{x}|(x)
+	Method string // method to solve the subproblem: "dogleg" or "steihaug"
+
+	// configuration
+	Delta0   float64 // initial radius Δ [default = 1]
+	DeltaMax float64 // maximum radius [default = 1000]
+	Eta      float64 // minimum ratio ρ to accept step; 0 ≤ η < ¼ [default = 0.15]
+
+	// statistics
+	NumHeval int     // number of calls to Hfcn (Hessian evaluations)
+	NumItCG  int     // total number of iterations of the Steihaug-Toint CG method
+	Delta    float64 // current radius Δ
+
+	// internal
+	Hmat *la.Matrix // Hessian
+	Lmat *la.Matrix // Cholesky factor of Hessian (dogleg)
+	g    la.Vector  // gradient
+	p    la.Vector  // step
+	pB   la.Vector  // Newton step (dogleg)
+	hp   la.Vector  // [H]⋅{p}
+	r    la.Vector  // residual (Steihaug-Toint)
+	d    la.Vector  // direction (Steihaug-Toint)
+	xnew la.Vector  // trial x
+	gnew la.Vector  // gradient at trial x
+}
+
+// add optimizers to database
+func init() {
+	nlsMakersDB["dogleg"] = func(prob *Problem) NonLinSolver { return NewTrustRegion(prob, "dogleg") }
+	nlsMakersDB["steihaug"] = func(prob *Problem) NonLinSolver { return NewTrustRegion(prob, "steihaug") }
+}
+
+// NewTrustRegion returns a new multidimensional optimizer using a trust-region method
+//   method -- "dogleg" or "steihaug"
+func NewTrustRegion(prob *Problem, method string) (o *TrustRegion) {
+	if prob.Hfcn == nil {
+		chk.Panic("trust-region methods require the Hessian function Hfcn\n")
+	}
+	if method != "dogleg" && method != "steihaug" {
+		chk.Panic("trust-region method %q is not available. use \"dogleg\" or \"steihaug\"\n", method)
+	}
+	o = new(TrustRegion)
+	o.InitConvergence(prob.Ffcn, prob.Gfcn)
+	o.Hfcn = func(h *la.Matrix, x la.Vector) {
+		o.NumHeval++
+		prob.Hfcn(h, x)
+	}
+	o.Method = method
+	o.Delta0 = 1
+	o.DeltaMax = 1000
+	o.Eta = 0.15
+	o.Hmat = la.NewMatrix(prob.Ndim, prob.Ndim)
+	o.Lmat = la.NewMatrix(prob.Ndim, prob.Ndim)
+	o.g = la.NewVector(prob.Ndim)
+	o.p = la.NewVector(prob.Ndim)
+	o.pB = la.NewVector(prob.Ndim)
+	o.hp = la.NewVector(prob.Ndim)
+	o.r = la.NewVector(prob.Ndim)
+	o.d = la.NewVector(prob.Ndim)
+	o.xnew = la.NewVector(prob.Ndim)
+	o.gnew = la.NewVector(prob.Ndim)
+	return
+}
+
+// Min solves minimization problem
+//
+//  Input:
+//    x -- [ndim] initial starting point (will be modified)
+//    params -- [may be nil] optional parameters. e.g. "delta0", "maxit". Example:
+//                 params := dbf.NewParams(
+//                     &dbf.P{N: "delta0", V: 1},
+//                     &dbf.P{N: "deltamax", V: 1000},
+//                     &dbf.P{N: "eta", V: 0.15},
+//                     &dbf.P{N: "maxit", V: 1000},
+//                     &dbf.P{N: "ftol", V: 1e-2},
+//                     &dbf.P{N: "gtol", V: 1e-2},
+//                     &dbf.P{N: "hist", V: 1},
+//                     &dbf.P{N: "verb", V: 1},
+//                 )
+//
+//  Output:
+//    fmin -- f(x@min) minimum f({x}) found
+//    x -- [modify input] position of minimum f({x})
+//
+func (o *TrustRegion) Min(x la.Vector, params dbf.Params) (fmin float64) {
+
+	// set parameters
+	o.Convergence.SetParams(params)
+	o.Delta0 = params.GetValueOrDefault("delta0", o.Delta0)
+	o.DeltaMax = params.GetValueOrDefault("deltamax", o.DeltaMax)
+	o.Eta = params.GetValueOrDefault("eta", o.Eta)
+
+	// initializations
+	o.NumFeval, o.NumGeval, o.NumHeval, o.NumItCG = 0, 0, 0, 0
+	o.Delta = utl.Min(o.Delta0, o.DeltaMax)
+	fmin = o.Ffcn(x)
+	o.Gfcn(o.g, x)
+
+	// history
+	if o.UseHist {
+		o.InitHist(x)
+	}
+
+	// exit point # 1: initial gradient is zero
+	if o.Gconvergence(fmin, x, o.g) {
+		return
+	}
+
+	// iterations
+	var fnew, pnorm, predicted, ρ float64
+	computeH := true
+	for o.NumIter = 0; o.NumIter < o.MaxIt; o.NumIter++ {
+
+		// solve subproblem
+		if computeH {
+			o.Hfcn(o.Hmat, x)
+		}
+		if o.Method == "dogleg" {
+			o.dogleg(computeH)
+		} else {
+			o.steihaug()
+		}
+
+		// predicted reduction: m(0) - m(p) = -gᵀp - ½ pᵀHp
+		la.MatVecMul(o.hp, 1, o.Hmat, o.p)
+		predicted = -la.VecDot(o.g, o.p) - 0.5*la.VecDot(o.p, o.hp)
+
+		// actual reduction and ratio
+		la.VecAdd(o.xnew, 1, x, 1, o.p)
+		fnew = o.Ffcn(o.xnew)
+		ρ = -1
+		if predicted > 0 {
+			ρ = (fmin - fnew) / predicted
+		}
+
+		// update radius
+		pnorm = o.p.Norm()
+		if ρ < 0.25 {
+			o.Delta = 0.25 * pnorm
+		} else if ρ > 0.75 && math.Abs(pnorm-o.Delta) <= 1e-8*o.Delta {
+			o.Delta = utl.Min(2*o.Delta, o.DeltaMax)
+		}
+
+		// reject step
+		if ρ <= o.Eta {
+			computeH = false
+			if o.Delta < 1e-15*utl.Max(1, x.Norm()) {
+				return // cannot improve any further
+			}
+			continue
+		}
+		computeH = true
+
+		// accept step
+		x.Apply(1, o.xnew)
+		o.Gfcn(o.gnew, x)
+
+		// history
+		if o.UseHist {
+			o.Hist.Append(fnew, x, o.p)
+		}
+
+		// exit point # 2: converged on f
+		if o.Fconvergence(fmin, fnew) {
+			fmin = fnew
+			return
+		}
+		fmin = fnew
+
+		// exit point # 3: converged on dy/dx
+		o.g.Apply(1, o.gnew)
+		if o.Gconvergence(fmin, x, o.g) {
+			return
+		}
+	}
+
+	// did not converge
+	chk.Panic("fail to converge after %d iterations\n", o.NumIter)
+	return
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// dogleg computes the step p using the dogleg method
+//   newH -- Hessian has changed ⇒ factorise and compute the Newton step
+func (o *TrustRegion) dogleg(newH bool) {
+
+	// Newton step: pB = -inv(H)⋅g
+	if newH {
+		if cholesky(o.Lmat, o.Hmat, 0) {
+			o.pB.Apply(-1, o.g)
+			cholSolve(o.pB, o.Lmat, o.pB)
+		} else {
+			o.pB[0] = math.NaN() // flag: Hessian is not positive definite
+		}
+	}
+
+	// curvature along g
+	la.MatVecMul(o.hp, 1, o.Hmat, o.g)
+	gHg := la.VecDot(o.g, o.hp)
+	gg := la.VecDot(o.g, o.g)
+	gnorm := math.Sqrt(gg)
+
+	// Hessian is not positive definite: Cauchy point (Eq. 4.12 of [1])
+	if math.IsNaN(o.pB[0]) {
+		τ := 1.0
+		if gHg > 0 {
+			τ = utl.Min(gnorm*gg/(o.Delta*gHg), 1)
+		}
+		o.p.Apply(-τ*o.Delta/gnorm, o.g)
+		return
+	}
+
+	// full Newton step
+	if o.pB.Norm() <= o.Delta {
+		o.p.Apply(1, o.pB)
+		return
+	}
+
+	// unconstrained minimizer along steepest descent: pU = -(gᵀg / gᵀHg)⋅g
+	o.p.Apply(-gg/gHg, o.g)
+	if o.p.Norm() >= o.Delta {
+		o.p.Apply(-o.Delta/gnorm, o.g)
+		return
+	}
+
+	// intersection of the dogleg path pU + s⋅(pB - pU) with the boundary
+	la.VecAdd(o.d, 1, o.pB, -1, o.p)
+	s := boundaryStep(o.p, o.d, o.Delta)
+	la.VecAdd(o.p, 1, o.p, s, o.d)
+}
+
+// steihaug computes the step p using the Steihaug-Toint truncated CG method
+func (o *TrustRegion) steihaug() {
+
+	// initialization
+	o.p.Fill(0)
+	o.r.Apply(1, o.g)
+	o.d.Apply(-1, o.g)
+	rr := la.VecDot(o.r, o.r)
+	rnorm := math.Sqrt(rr)
+	ε := utl.Min(0.5, math.Sqrt(rnorm)) * rnorm // Eq. (7.3) of [1]
+
+	// iterations
+	var dHd, α, β, τ, rrNew float64
+	for j := 0; j < 2*len(o.p); j++ {
+		o.NumItCG++
+
+		// negative curvature: go to the boundary
+		la.MatVecMul(o.hp, 1, o.Hmat, o.d)
+		dHd = la.VecDot(o.d, o.hp)
+		if dHd <= 0 {
+			τ = boundaryStep(o.p, o.d, o.Delta)
+			la.VecAdd(o.p, 1, o.p, τ, o.d)
+			return
+		}
+
+		// step reaches the boundary
+		α = rr / dHd
+		la.VecAdd(o.xnew, 1, o.p, α, o.d) // xnew works here as workspace
+		if o.xnew.Norm() >= o.Delta {
+			τ = boundaryStep(o.p, o.d, o.Delta)
+			la.VecAdd(o.p, 1, o.p, τ, o.d)
+			return
+		}
+		o.p.Apply(1, o.xnew)
+
+		// update residual and check convergence
+		la.VecAdd(o.r, 1, o.r, α, o.hp)
+		rrNew = la.VecDot(o.r, o.r)
+		if math.Sqrt(rrNew) < ε {
+			return
+		}
+
+		// update direction
+		β = rrNew / rr
+		rr = rrNew
+		la.VecAdd(o.d, -1, o.r, β, o.d)
+	}
+}
+
+// boundaryStep returns τ ≥ 0 such that ‖{z} + τ⋅{d}‖ = Δ where ‖{z}‖ ≤ Δ
+func boundaryStep(z, d la.Vector, Δ float64) (τ float64) {
+	a := la.VecDot(d, d)
+	b := 2 * la.VecDot(z, d)
+	c := la.VecDot(z, z) - Δ*Δ
+	return (-b + math.Sqrt(utl.Max(b*b-4*a*c, 0))) / (2 * a)
+}
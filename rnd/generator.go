@@ -0,0 +1,57 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rnd
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Generator implements a generator of pseudo random numbers with its own state (source). Thus,
+// the sequences can be reproduced by means of the seed, independently of other generators, and
+// each goroutine can use its own generator
+//  NOTE: a Generator must not be shared by concurrent goroutines
+type Generator struct {
+	r *rand.Rand // generator with its own source
+}
+
+// NewGenerator returns a new generator of pseudo random numbers
+//  Input:
+//   seed -- seed value; use seed <= 0 to use current time
+func NewGenerator(seed int) (o *Generator) {
+	if seed <= 0 {
+		seed = int(time.Now().Unix())
+	}
+	return &Generator{rand.New(rand.NewSource(int64(seed)))}
+}
+
+// Int generates pseudo random integer between low and high
+func (o *Generator) Int(low, high int) int {
+	return o.r.Int()%(high-low+1) + low
+}
+
+// Float64 generates a pseudo random real number between low and high; i.e. in [low, right)
+func (o *Generator) Float64(low, high float64) float64 {
+	return low + (high-low)*o.r.Float64()
+}
+
+// Float64s generates pseudo random real numbers between low and high; i.e. in [low, right)
+func (o *Generator) Float64s(values []float64, low, high float64) {
+	for i := 0; i < len(values); i++ {
+		values[i] = low + (high-low)*o.r.Float64()
+	}
+}
+
+// Normal returns a random number belonging to a normal distribution
+func (o *Generator) Normal(μ, σ float64) float64 {
+	return μ + σ*o.r.NormFloat64()
+}
+
+// Normals generates random numbers belonging to a normal distribution
+func (o *Generator) Normals(values []float64, μ, σ float64) {
+	for i := 0; i < len(values); i++ {
+		values[i] = μ + σ*o.r.NormFloat64()
+	}
+}
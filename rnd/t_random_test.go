@@ -201,6 +201,35 @@ func Test_groups01(tst *testing.T) {
 	}
 }
 
+func Test_generator01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("generator01. independent generators")
+
+	// reproducible sequences
+	a, b := NewGenerator(1234), NewGenerator(1234)
+	Init(4321) // must not affect the generators
+	for i := 0; i < Nsamples; i++ {
+		if a.Int(0, 100) != b.Int(0, 100) || a.Float64(-1, 1) != b.Float64(-1, 1) {
+			tst.Errorf("generators with the same seed must produce the same sequences\n")
+			return
+		}
+	}
+	x, y := make([]float64, Nsamples), make([]float64, Nsamples)
+	a.Normals(x, 0, 1)
+	b.Normals(y, 0, 1)
+	chk.Array(tst, "normals", 1e-17, x, y)
+
+	// normal distribution
+	μ, σ := 2.0, 0.5
+	x = make([]float64, 20000)
+	NewGenerator(1).Normals(x, μ, σ)
+	xave, xdev := StatAveDev(x, true)
+	io.Pforan("ave = %v  dev = %v\n", xave, xdev)
+	chk.Float64(tst, "ave", 0.02, xave, μ)
+	chk.Float64(tst, "dev", 0.02, xdev, σ)
+}
+
 func checkRepeated(v []int) {
 	for i := 1; i < len(v); i++ {
 		if v[i] == v[i-1] {
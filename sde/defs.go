@@ -0,0 +1,47 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sde
+
+import "github.com/dicksontsai/gosl/la"
+
+// DriftF defines the drift function {a}(x, {y}) of
+//
+//   d{y} = {a}(x, {y}) dx + [B](x, {y}) d{W}
+//
+//   INPUT:
+//     x -- current x
+//     y -- current {y}
+//
+//   OUTPUT:
+//     a -- {a}(x, {y}) [ndim]
+//
+type DriftF func(a la.Vector, x float64, y la.Vector)
+
+// DiagF defines the diffusion function of systems with diagonal noise; i.e. [B] is diagonal and
+// each component of {y} is driven by its own Wiener process
+//
+//   dy_i = a_i(x, {y}) dx + b_i(x, y_i) dW_i
+//
+//   INPUT:
+//     x -- current x
+//     y -- current {y}
+//
+//   OUTPUT:
+//     b -- diagonal of [B]; b_i must depend on y_i only [ndim]
+//
+type DiagF func(b la.Vector, x float64, y la.Vector)
+
+// DiffF defines the diffusion matrix of systems with general noise
+//
+//   dy_i = a_i(x, {y}) dx + Σ_j B_ij(x, {y}) dW_j
+//
+//   INPUT:
+//     x -- current x
+//     y -- current {y}
+//
+//   OUTPUT:
+//     B -- diffusion matrix [ndim][nnoise]
+//
+type DiffF func(B *la.Matrix, x float64, y la.Vector)
@@ -0,0 +1,108 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sde
+
+import (
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/rnd"
+)
+
+// Ensemble holds the statistics of an ensemble of sample paths
+type Ensemble struct {
+	X   []float64   // x values at output points [nout+1]
+	Ave [][]float64 // mean of y at output points [nout+1][ndim]
+	Dev [][]float64 // standard deviation of y at output points [nout+1][ndim]; variance = Dev²
+	Y   [][]float64 // values of all paths at xf [npaths][ndim]
+}
+
+// Ensemble computes many sample paths in parallel and the statistics of y at nout+1 equally
+// spaced points
+//  INPUT:
+//    y0     -- initial values {y}(x)
+//    x, xf  -- initial and final x
+//    nsteps -- number of steps of each path; it must be a multiple of nout
+//    nout   -- number of output intervals
+//    npaths -- number of sample paths
+//    seed   -- seed of random numbers: path p uses rnd.NewGenerator(seed+p) [seed ≤ 0 ⇒ current time]
+//    ncpu   -- number of goroutines [ncpu ≤ 0 ⇒ runtime.NumCPU()]
+//  NOTE: the results do not depend on ncpu
+func (o *Solver) Ensemble(y0 la.Vector, x, xf float64, nsteps, nout, npaths, seed, ncpu int) (ens *Ensemble) {
+
+	// check
+	if nout < 1 || nsteps%nout != 0 {
+		chk.Panic("the number of steps (%d) must be a multiple of the number of output intervals (%d)\n", nsteps, nout)
+	}
+	if npaths < 2 {
+		chk.Panic("at least 2 sample paths are required. npaths = %d is invalid\n", npaths)
+	}
+	if seed <= 0 {
+		seed = int(time.Now().Unix())
+	}
+	if ncpu <= 0 {
+		ncpu = runtime.NumCPU()
+	}
+	if ncpu > npaths {
+		ncpu = npaths
+	}
+
+	// samples: values[iout][idim][ipath]
+	every := nsteps / nout
+	values := make([][][]float64, nout+1)
+	for k := 0; k <= nout; k++ {
+		values[k] = make([][]float64, o.ndim)
+		for i := 0; i < o.ndim; i++ {
+			values[k][i] = make([]float64, npaths)
+		}
+	}
+
+	// run paths
+	var wg sync.WaitGroup
+	for cpu := 0; cpu < ncpu; cpu++ {
+		wg.Add(1)
+		go func(cpu int) {
+			defer wg.Done()
+			y := la.NewVector(o.ndim)
+			for p := cpu; p < npaths; p += ncpu {
+				w := o.newWorkspace(rnd.NewGenerator(seed + p))
+				y.Apply(1, y0)
+				o.solve(w, y, x, xf, nsteps, func(istep int, x float64, y, W la.Vector) {
+					if istep%every == 0 {
+						for i := 0; i < o.ndim; i++ {
+							values[istep/every][i][p] = y[i]
+						}
+					}
+				})
+			}
+		}(cpu)
+	}
+	wg.Wait()
+
+	// statistics
+	ens = new(Ensemble)
+	ens.X = make([]float64, nout+1)
+	ens.Ave = make([][]float64, nout+1)
+	ens.Dev = make([][]float64, nout+1)
+	for k := 0; k <= nout; k++ {
+		ens.X[k] = x + float64(k)*(xf-x)/float64(nout)
+		ens.Ave[k] = make([]float64, o.ndim)
+		ens.Dev[k] = make([]float64, o.ndim)
+		for i := 0; i < o.ndim; i++ {
+			ens.Ave[k][i], ens.Dev[k][i] = rnd.StatAveDev(values[k][i], true)
+		}
+	}
+	ens.Y = make([][]float64, npaths)
+	for p := 0; p < npaths; p++ {
+		ens.Y[p] = make([]float64, o.ndim)
+		for i := 0; i < o.ndim; i++ {
+			ens.Y[p][i] = values[nout][i][p]
+		}
+	}
+	return
+}
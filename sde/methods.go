@@ -0,0 +1,159 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sde
+
+import (
+	"math"
+
+	"github.com/dicksontsai/gosl/la"
+)
+
+// stepEM implements the Euler-Maruyama method
+//
+//   y ← y + {a}⋅h + [B]⋅{ΔW}
+//
+func stepEM(o *Solver, w *workspace, h, x float64, y, ΔW, ΔZ la.Vector) {
+	o.calcA(w, w.a, x, y)
+	o.calcB(w.B, x, y)
+	for i := 0; i < o.ndim; i++ {
+		y[i] += h * w.a[i]
+	}
+	o.addNoise(y, 1, w.B, ΔW)
+}
+
+// stepMilstein implements the derivative-free Milstein method [1, Chap. 11]
+//
+//   y ← y + {a}⋅h + [B]⋅{ΔW} + Σ_j Σ_r ({b}_r(Υ_j) - {b}_r) I_jr / √h
+//
+//   where Υ_j = y + {a}⋅h + {b}_j⋅√h and I_jr = ½ (ΔW_j⋅ΔW_r - δ_jr⋅h). {b}_j is the j-th column
+//   of [B]. With diagonal noise, only the terms with j = r are non-zero and all Υ_j are combined
+//
+func stepMilstein(o *Solver, w *workspace, h, x float64, y, ΔW, ΔZ la.Vector) {
+
+	// drift and diffusion
+	sqh := math.Sqrt(h)
+	o.calcA(w, w.a, x, y)
+	o.calcB(w.B, x, y)
+
+	// diagonal noise
+	if o.diag {
+		for i := 0; i < o.ndim; i++ {
+			w.ys[i] = y[i] + h*w.a[i] + sqh*w.B.Data[i]
+		}
+		Bs := w.Bs[0]
+		o.calcB(Bs, x, w.ys)
+		for i := 0; i < o.ndim; i++ {
+			y[i] += h*w.a[i] + w.B.Data[i]*ΔW[i] + (Bs.Data[i]-w.B.Data[i])*(ΔW[i]*ΔW[i]-h)/(2*sqh)
+		}
+		return
+	}
+
+	// general noise: supporting values
+	for j := 0; j < o.nnoise; j++ {
+		for i := 0; i < o.ndim; i++ {
+			w.ys[i] = y[i] + h*w.a[i]
+		}
+		o.addCol(w.ys, sqh, w.B, j)
+		o.calcB(w.Bs[j], x, w.ys)
+	}
+
+	// update
+	for i := 0; i < o.ndim; i++ {
+		y[i] += h * w.a[i]
+	}
+	o.addNoise(y, 1, w.B, ΔW)
+	for j := 0; j < o.nnoise; j++ {
+		for r := 0; r < o.nnoise; r++ {
+			I := ΔW[j] * ΔW[r]
+			if j == r {
+				I -= h
+			}
+			for i := 0; i < o.ndim; i++ {
+				y[i] += (w.Bs[j].Get(i, r) - w.B.Get(i, r)) * I / (2 * sqh)
+			}
+		}
+	}
+}
+
+// stepSRK15 implements the explicit strong order 1.5 scheme [1, Chap. 11] for diagonal or
+// scalar noise (m columns of [B] with m = ndim or m = 1, respectively). For component i driven by
+// the Wiener process j (j = i or j = 0, respectively):
+//
+//   y_i ← y_i + a_i⋅h + b_i⋅ΔW_j
+//         + Σ_k (a_i(Υ⁺_k) - a_i(Υ⁻_k)) ΔZ_k / (2√h) + Σ_k (a_i(Υ⁺_k) - 2a_i + a_i(Υ⁻_k)) h / 4
+//         + (b_i(Ῡ⁺) - b_i(Ῡ⁻)) (ΔW_j² - h) / (4√h)
+//         + (b_i(Ῡ⁺) - 2b_i + b_i(Ῡ⁻)) (ΔW_j⋅h - ΔZ_j) / (2h)
+//         + (b_i(Φ⁺) - b_i(Φ⁻) - b_i(Ῡ⁺) + b_i(Ῡ⁻)) (ΔW_j²/3 - h) ΔW_j / (4h)
+//
+//   where Υ±_k = y + {a}⋅h/m ± {b}_k⋅√h (drift evaluated at x + h/m), Ῡ± = y + {a}⋅h ± Σ_k {b}_k⋅√h,
+//   and Φ± = Ῡ⁺ ± Σ_k {b}_k(Ῡ⁺)⋅√h (diffusion evaluated at x + h). With m = 1, these are the
+//   supporting values of [1]; with diagonal noise, the sums over k yield the mixed terms L^k a_i
+//   and the terms ½ L⁰ a_i of the Itô-Taylor expansion, whereas b_i only depends on y_i
+//
+func stepSRK15(o *Solver, w *workspace, h, x float64, y, ΔW, ΔZ la.Vector) {
+
+	// drift and diffusion
+	m := o.nnoise
+	sqh := math.Sqrt(h)
+	o.calcA(w, w.a, x, y)
+	o.calcB(w.B, x, y)
+
+	// drift at supporting values Υ±_k
+	hm := h / float64(m)
+	for k := 0; k < m; k++ {
+		for s, sgn := range []float64{1, -1} {
+			for i := 0; i < o.ndim; i++ {
+				w.ys[i] = y[i] + hm*w.a[i]
+			}
+			o.addCol(w.ys, sgn*sqh, w.B, k)
+			o.calcA(w, w.as[2*k+s], x+hm, w.ys)
+		}
+	}
+
+	// diffusion at supporting values Ῡ± and Φ±
+	Bp, Bm, Φp, Φm := w.Bs[0], w.Bs[1], w.Bs[2], w.Bs[3]
+	for s, Bs := range []*la.Matrix{Bp, Bm} {
+		for i := 0; i < o.ndim; i++ {
+			w.ys[i] = y[i] + h*w.a[i]
+		}
+		for k := 0; k < m; k++ {
+			o.addCol(w.ys, float64(1-2*s)*sqh, w.B, k)
+		}
+		o.calcB(Bs, x+h, w.ys)
+	}
+	for s, Bs := range []*la.Matrix{Φp, Φm} {
+		for i := 0; i < o.ndim; i++ {
+			w.ys[i] = y[i] + h*w.a[i]
+		}
+		for k := 0; k < m; k++ {
+			o.addCol(w.ys, sqh, w.B, k)
+			o.addCol(w.ys, float64(1-2*s)*sqh, Bp, k)
+		}
+		o.calcB(Bs, x+h, w.ys)
+	}
+
+	// update
+	for i := 0; i < o.ndim; i++ {
+		j := 0
+		if o.diag {
+			j = i
+		}
+		dw := ΔW[j]
+		dz := ΔZ[j]
+		ai := w.a[i]
+		bi := o.bij(w.B, i, j)
+		bp, bm := o.bij(Bp, i, j), o.bij(Bm, i, j)
+		φp, φm := o.bij(Φp, i, j), o.bij(Φm, i, j)
+		δy := ai*h + bi*dw
+		for k := 0; k < m; k++ {
+			ap, am := w.as[2*k][i], w.as[2*k+1][i]
+			δy += (ap-am)*ΔZ[k]/(2*sqh) + (ap-2*ai+am)*h/4
+		}
+		δy += (bp - bm) * (dw*dw - h) / (4 * sqh)
+		δy += (bp - 2*bi + bm) * (dw*h - dz) / (2 * h)
+		δy += (φp - φm - bp + bm) * (dw*dw/3 - h) * dw / (4 * h)
+		y[i] += δy
+	}
+}
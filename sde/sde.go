@@ -0,0 +1,295 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sde implements integrators for stochastic differential equations
+//
+//   d{y} = {a}(x, {y}) dx + [B](x, {y}) d{W}
+//
+// where {W} is a vector of independent Wiener processes. The Euler-Maruyama, Milstein and the
+// strong order 1.5 stochastic Runge-Kutta schemes by Kloeden & Platen [1] are implemented for
+// systems in the Itô or Stratonovich sense with diagonal or general noise.
+//   References:
+//     [1] Kloeden PE, Platen E (1992) Numerical Solution of Stochastic Differential Equations.
+//         Springer, Berlin, 632p
+//     [2] Higham DJ (2001) An algorithmic introduction to numerical simulation of stochastic
+//         differential equations. SIAM Review, 43(3):525-546
+package sde
+
+import (
+	"math"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/num"
+	"github.com/dicksontsai/gosl/rnd"
+)
+
+// Solver implements integrators of stochastic differential equations with fixed steps
+//
+//   Methods (strong order of convergence):
+//     "em"       -- Euler-Maruyama (0.5; or 1.0 with additive noise)
+//     "milstein" -- derivative-free Milstein (1.0) [1, Chap. 11]
+//     "srk15"    -- explicit stochastic Runge-Kutta (1.5) [1, Chap. 11]
+//
+//   NOTE: (1) Stratonovich systems are converted to the equivalent Itô systems by adding the
+//             (numerical) correction ½ Σ_j ([B]_j ⋅ ∇)[B]_j to the drift, where [B]_j is the
+//             j-th column of [B]
+//         (2) with general noise, "milstein" approximates the double stochastic integrals by
+//             ½ ΔW_j ΔW_k; i.e. the strong order 1.0 is only achieved with commutative noise
+//         (3) "srk15" requires diagonal noise or general noise with nnoise = 1 (scalar noise)
+//         (4) the drift and diffusion functions must be safe for concurrent use if Ensemble is
+//             called with more than one goroutine
+//
+type Solver struct {
+
+	// input
+	Stratonovich bool // the system is given in the Stratonovich sense [default = Itô]
+
+	// problem definition
+	method string  // method
+	ndim   int     // dimension of y
+	nnoise int     // number of Wiener processes
+	diag   bool    // diagonal noise
+	drift  DriftF  // drift function
+	diagF  DiagF   // diffusion function of diagonal noise
+	diffF  DiffF   // diffusion matrix of general noise
+	step   stepF   // step function
+	nextra int     // number of extra workspace vectors for the drift and diffusion
+	δrel   float64 // relative perturbation for numerical derivatives (Stratonovich correction)
+}
+
+// stepF defines the function to update y over one step with increments ΔW and ΔZ
+type stepF func(o *Solver, w *workspace, h, x float64, y, ΔW, ΔZ la.Vector)
+
+// workspace holds auxiliary data for one sample path
+type workspace struct {
+	a   la.Vector      // drift at (x,y)
+	B   *la.Matrix     // diffusion at (x,y) [ndim][ncols] (ncols = 1 for diagonal noise)
+	ys  la.Vector      // stage y
+	as  []la.Vector    // drift at stages
+	Bs  []*la.Matrix   // diffusion at stages
+	ΔW  la.Vector      // Wiener increments [nnoise]
+	ΔZ  la.Vector      // double integrals ∫∫dW ds [nnoise]
+	gen *rnd.Generator // generator of random numbers
+
+	// Stratonovich correction
+	yp         la.Vector  // perturbed y
+	Bc, Bp, Bm *la.Matrix // diffusion at y and perturbed y
+}
+
+// Path holds the values of a sample path
+type Path struct {
+	X []float64   // x values [nsteps+1]
+	Y []la.Vector // y values [nsteps+1][ndim]
+	W []la.Vector // Wiener processes [nsteps+1][nnoise]
+}
+
+// NewDiag returns a new solver for systems with diagonal noise
+//  method -- "em", "milstein" or "srk15"
+//  ndim   -- dimension of y; it is also the number of Wiener processes
+//  drift  -- drift function {a}(x,{y})
+//  diff   -- diagonal of diffusion matrix; b_i(x,y_i)
+func NewDiag(method string, ndim int, drift DriftF, diff DiagF) (o *Solver) {
+	o = newSolver(method, ndim, ndim, drift)
+	o.diag = true
+	o.diagF = diff
+	return
+}
+
+// NewGeneral returns a new solver for systems with general noise
+//  method -- "em", "milstein" or "srk15" (nnoise = 1 only)
+//  ndim   -- dimension of y
+//  nnoise -- number of Wiener processes
+//  drift  -- drift function {a}(x,{y})
+//  diff   -- diffusion matrix [B](x,{y}) [ndim][nnoise]
+func NewGeneral(method string, ndim, nnoise int, drift DriftF, diff DiffF) (o *Solver) {
+	o = newSolver(method, ndim, nnoise, drift)
+	o.diffF = diff
+	if method == "srk15" && nnoise > 1 {
+		chk.Panic("method %q requires diagonal noise or general noise with nnoise = 1. nnoise = %d is invalid\n", method, nnoise)
+	}
+	return
+}
+
+// newSolver allocates a new solver
+func newSolver(method string, ndim, nnoise int, drift DriftF) (o *Solver) {
+	o = new(Solver)
+	o.method = method
+	o.ndim = ndim
+	o.nnoise = nnoise
+	o.drift = drift
+	o.δrel = math.Cbrt(num.MACHEPS)
+	switch method {
+	case "em":
+		o.step = stepEM
+	case "milstein":
+		o.step, o.nextra = stepMilstein, nnoise
+	case "srk15":
+		o.step, o.nextra = stepSRK15, 2*nnoise+2
+	default:
+		chk.Panic("cannot find method %q. methods available: em, milstein, srk15\n", method)
+	}
+	return
+}
+
+// Solve computes one sample path from x to xf using nsteps fixed steps
+//  INPUT:
+//    y      -- initial values {y}(x)
+//    x, xf  -- initial and final x
+//    nsteps -- number of steps
+//    gen    -- generator of random numbers; e.g. rnd.NewGenerator(seed) [nil ⇒ time-based seed]
+//    path   -- records the sample path [may be nil]
+//  OUTPUT:
+//    y -- values at xf
+func (o *Solver) Solve(y la.Vector, x, xf float64, nsteps int, gen *rnd.Generator, path *Path) {
+	var output func(istep int, x float64, y, W la.Vector)
+	if path != nil {
+		path.X = make([]float64, nsteps+1)
+		path.Y = make([]la.Vector, nsteps+1)
+		path.W = make([]la.Vector, nsteps+1)
+		output = func(istep int, x float64, y, W la.Vector) {
+			path.X[istep] = x
+			path.Y[istep] = y.GetCopy()
+			path.W[istep] = W.GetCopy()
+		}
+	}
+	o.solve(o.newWorkspace(gen), y, x, xf, nsteps, output)
+}
+
+// newWorkspace allocates a new workspace
+func (o *Solver) newWorkspace(gen *rnd.Generator) (w *workspace) {
+	if gen == nil {
+		gen = rnd.NewGenerator(0)
+	}
+	ncols := o.nnoise
+	if o.diag {
+		ncols = 1
+	}
+	w = new(workspace)
+	w.a = la.NewVector(o.ndim)
+	w.B = la.NewMatrix(o.ndim, ncols)
+	w.ys = la.NewVector(o.ndim)
+	w.as = make([]la.Vector, o.nextra)
+	w.Bs = make([]*la.Matrix, o.nextra)
+	for i := 0; i < o.nextra; i++ {
+		w.as[i] = la.NewVector(o.ndim)
+		w.Bs[i] = la.NewMatrix(o.ndim, ncols)
+	}
+	w.ΔW = la.NewVector(o.nnoise)
+	w.ΔZ = la.NewVector(o.nnoise)
+	w.gen = gen
+	if o.Stratonovich {
+		w.yp = la.NewVector(o.ndim)
+		w.Bc = la.NewMatrix(o.ndim, ncols)
+		w.Bp = la.NewMatrix(o.ndim, ncols)
+		w.Bm = la.NewMatrix(o.ndim, ncols)
+	}
+	return
+}
+
+// solve computes one sample path calling output (if not nil) after each step
+func (o *Solver) solve(w *workspace, y la.Vector, x, xf float64, nsteps int, output func(istep int, x float64, y, W la.Vector)) {
+	if nsteps < 1 {
+		chk.Panic("the number of steps must be at least 1. nsteps = %d is invalid\n", nsteps)
+	}
+	x0 := x
+	h := (xf - x0) / float64(nsteps)
+	sqh := math.Sqrt(h)
+	W := la.NewVector(o.nnoise)
+	if output != nil {
+		output(0, x, y, W)
+	}
+	for istep := 1; istep <= nsteps; istep++ {
+		for j := 0; j < o.nnoise; j++ {
+			u1 := w.gen.Normal(0, 1)
+			w.ΔW[j] = sqh * u1
+			if o.method == "srk15" {
+				u2 := w.gen.Normal(0, 1)
+				w.ΔZ[j] = h * sqh * (u1 + u2/math.Sqrt(3)) / 2
+			}
+			W[j] += w.ΔW[j]
+		}
+		o.step(o, w, h, x, y, w.ΔW, w.ΔZ)
+		x = x0 + float64(istep)*h // avoid accumulation of round-off errors
+		if output != nil {
+			output(istep, x, y, W)
+		}
+	}
+}
+
+// calcA computes the Itô drift; i.e. including the correction of Stratonovich systems
+func (o *Solver) calcA(w *workspace, a la.Vector, x float64, y la.Vector) {
+	o.drift(a, x, y)
+	if !o.Stratonovich {
+		return
+	}
+	B, Bp, Bm, yp := w.Bc, w.Bp, w.Bm, w.yp
+	o.calcB(B, x, y)
+	ncols := B.N
+	for j := 0; j < ncols; j++ {
+
+		// perturbation: δ ⋅ {b}_j
+		var bnorm, ynorm float64
+		for i := 0; i < o.ndim; i++ {
+			bnorm = math.Max(bnorm, math.Abs(B.Get(i, j)))
+			ynorm = math.Max(ynorm, math.Abs(y[i]))
+		}
+		if bnorm == 0 {
+			continue
+		}
+		δ := o.δrel * math.Max(1, ynorm) / bnorm
+
+		// central differences: ({b}_j ⋅ ∇){b}_j
+		for i := 0; i < o.ndim; i++ {
+			yp[i] = y[i] + δ*B.Get(i, j)
+		}
+		o.calcB(Bp, x, yp)
+		for i := 0; i < o.ndim; i++ {
+			yp[i] = y[i] - δ*B.Get(i, j)
+		}
+		o.calcB(Bm, x, yp)
+		for i := 0; i < o.ndim; i++ {
+			a[i] += (Bp.Get(i, j) - Bm.Get(i, j)) / (4 * δ)
+		}
+	}
+}
+
+// calcB computes the diffusion matrix; or its diagonal (ndim×1 matrix) with diagonal noise
+func (o *Solver) calcB(B *la.Matrix, x float64, y la.Vector) {
+	if o.diag {
+		o.diagF(B.Data, x, y)
+		return
+	}
+	o.diffF(B, x, y)
+}
+
+// bij returns the (i,j) component of the diffusion matrix (diagonal or general)
+func (o *Solver) bij(B *la.Matrix, i, j int) float64 {
+	if o.diag {
+		if i == j {
+			return B.Data[i]
+		}
+		return 0
+	}
+	return B.Get(i, j)
+}
+
+// addCol computes v += c ⋅ {b}_j where {b}_j is the j-th column of the diffusion matrix
+func (o *Solver) addCol(v la.Vector, c float64, B *la.Matrix, j int) {
+	if o.diag {
+		v[j] += c * B.Data[j]
+		return
+	}
+	col := B.Data[j*B.M : (j+1)*B.M]
+	for i := 0; i < o.ndim; i++ {
+		v[i] += c * col[i]
+	}
+}
+
+// addNoise computes v += [B] ⋅ {ΔW}
+func (o *Solver) addNoise(v la.Vector, c float64, B *la.Matrix, ΔW la.Vector) {
+	for j := 0; j < o.nnoise; j++ {
+		o.addCol(v, c*ΔW[j], B, j)
+	}
+}
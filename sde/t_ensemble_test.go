@@ -0,0 +1,64 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sde
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/plt"
+)
+
+func TestEnsemble01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Ensemble01. mean and variance of Ornstein-Uhlenbeck process")
+
+	// dy = θ (μ - y) dx + σ dW
+	//   E[y]   = μ + (y0 - μ) exp(-θ x)
+	//   Var[y] = σ² (1 - exp(-2 θ x)) / (2 θ)
+	θ, μ, σ, y0 := 1.5, 1.0, 0.4, 3.0
+	o := NewDiag("milstein", 1, func(a la.Vector, x float64, y la.Vector) {
+		a[0] = θ * (μ - y[0])
+	}, func(b la.Vector, x float64, y la.Vector) {
+		b[0] = σ
+	})
+	npaths := 4000
+	ens := o.Ensemble(la.Vector{y0}, 0, 2, 200, 10, npaths, 123, 4)
+
+	// check
+	io.Pf("%6s%12s%12s%12s%12s\n", "x", "ave", "ave(ana)", "dev", "dev(ana)")
+	for k, x := range ens.X {
+		ave := μ + (y0-μ)*math.Exp(-θ*x)
+		dev := math.Sqrt(σ * σ * (1 - math.Exp(-2*θ*x)) / (2 * θ))
+		io.Pf("%6.2f%12.6f%12.6f%12.6f%12.6f\n", x, ens.Ave[k][0], ave, ens.Dev[k][0], dev)
+		tol := 4 * dev / math.Sqrt(float64(npaths)) // ≈ 4 standard errors
+		chk.Float64(tst, io.Sf("ave(%g)", x), tol+0.01, ens.Ave[k][0], ave)
+		chk.Float64(tst, io.Sf("dev(%g)", x), tol+0.01, ens.Dev[k][0], dev)
+	}
+	chk.Float64(tst, "x (final)", 1e-15, ens.X[10], 2)
+	chk.Int(tst, "len(Y)", len(ens.Y), npaths)
+
+	// same results with any number of goroutines
+	ens1 := o.Ensemble(la.Vector{y0}, 0, 2, 200, 10, npaths, 123, 1)
+	chk.Deep2(tst, "Ave (ncpu = 1)", 1e-15, ens1.Ave, ens.Ave)
+	chk.Deep2(tst, "Y (ncpu = 1)", 1e-17, ens1.Y, ens.Y)
+
+	// plot
+	if chk.Verbose {
+		A, D := make([]float64, len(ens.X)), make([]float64, len(ens.X))
+		for k := range ens.X {
+			A[k], D[k] = ens.Ave[k][0], ens.Dev[k][0]
+		}
+		plt.Reset(true, nil)
+		plt.Plot(ens.X, A, &plt.A{C: "r", M: ".", L: "ave"})
+		plt.Plot(ens.X, D, &plt.A{C: "b", M: ".", L: "dev"})
+		plt.Gll("$x$", "$y$", nil)
+		plt.Save("/tmp/gosl/sde", "ensemble01")
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sde
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dicksontsai/gosl/chk"
+	"github.com/dicksontsai/gosl/io"
+	"github.com/dicksontsai/gosl/la"
+	"github.com/dicksontsai/gosl/plt"
+	"github.com/dicksontsai/gosl/rnd"
+)
+
+// strongOrder estimates the strong order of convergence by computing the mean errors at xf with
+// nsteps = 8, 16, 32 and 64 steps. The increments of coarse steps are obtained by combining the
+// increments of the finest steps; i.e. the same Wiener paths are used for all stepsizes
+func strongOrder(o *Solver, y0 la.Vector, xf float64, npaths int, yana func(y la.Vector, x float64, W la.Vector)) (errs []float64, order float64) {
+	nlevels, nfine := 4, 64
+	hf := xf / float64(nfine)
+	gen := rnd.NewGenerator(1234)
+	w := o.newWorkspace(gen)
+	dW, dZ := make([]la.Vector, nfine), make([]la.Vector, nfine)
+	for i := 0; i < nfine; i++ {
+		dW[i], dZ[i] = la.NewVector(o.nnoise), la.NewVector(o.nnoise)
+	}
+	y, ye, W := la.NewVector(o.ndim), la.NewVector(o.ndim), la.NewVector(o.nnoise)
+	ΔW, ΔZ := la.NewVector(o.nnoise), la.NewVector(o.nnoise)
+	errs = make([]float64, nlevels)
+	for p := 0; p < npaths; p++ {
+
+		// fine increments and exact solution
+		W.Fill(0)
+		for i := 0; i < nfine; i++ {
+			for j := 0; j < o.nnoise; j++ {
+				u1, u2 := gen.Normal(0, 1), gen.Normal(0, 1)
+				dW[i][j] = math.Sqrt(hf) * u1
+				dZ[i][j] = hf * math.Sqrt(hf) * (u1 + u2/math.Sqrt(3)) / 2
+				W[j] += dW[i][j]
+			}
+		}
+		yana(ye, xf, W)
+
+		// coarse steps
+		for l := 0; l < nlevels; l++ {
+			nsteps := 8 << uint(l)
+			r := nfine / nsteps
+			h := xf / float64(nsteps)
+			y.Apply(1, y0)
+			for s := 0; s < nsteps; s++ {
+				for j := 0; j < o.nnoise; j++ {
+					ΔW[j], ΔZ[j] = 0, 0
+					for i := s * r; i < (s+1)*r; i++ {
+						ΔZ[j] += dZ[i][j] + ΔW[j]*hf // ∫ (W(t) - W(x_s)) dt
+						ΔW[j] += dW[i][j]
+					}
+				}
+				o.step(o, w, h, float64(s)*h, y, ΔW, ΔZ)
+			}
+			errs[l] += la.VecMaxDiff(y, ye) / float64(npaths)
+		}
+	}
+	order = math.Log(errs[0]/errs[nlevels-1]) / math.Log(float64(int(1)<<uint(nlevels-1)))
+	return
+}
+
+func TestSde01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Sde01. strong order of convergence: diagonal noise")
+
+	// geometric Brownian motion: dy_i = μ_i y_i dx + σ_i y_i dW_i
+	μ, σ := []float64{0.5, -0.3}, []float64{0.4, 0.6}
+	y0 := la.Vector{1, 2}
+	drift := func(a la.Vector, x float64, y la.Vector) {
+		a[0], a[1] = μ[0]*y[0], μ[1]*y[1]
+	}
+	diff := func(b la.Vector, x float64, y la.Vector) {
+		b[0], b[1] = σ[0]*y[0], σ[1]*y[1]
+	}
+
+	// Itô: y_i = y0_i exp((μ_i - σ_i²/2) x + σ_i W_i); Stratonovich: y_i = y0_i exp(μ_i x + σ_i W_i)
+	for _, strat := range []bool{false, true} {
+		yana := func(y la.Vector, x float64, W la.Vector) {
+			for i := 0; i < 2; i++ {
+				c := μ[i]
+				if !strat {
+					c -= σ[i] * σ[i] / 2
+				}
+				y[i] = y0[i] * math.Exp(c*x+σ[i]*W[i])
+			}
+		}
+		for k, method := range []string{"em", "milstein", "srk15"} {
+			o := NewDiag(method, 2, drift, diff)
+			o.Stratonovich = strat
+			errs, order := strongOrder(o, y0, 1, 200, yana)
+			io.Pforan("Stratonovich = %5v  %8s: errors = %.2e  order = %.3f\n", strat, method, errs, order)
+			chk.Float64(tst, method+": order", 0.2, order, []float64{0.5, 1, 1.5}[k])
+		}
+	}
+}
+
+func TestSde02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Sde02. strong order of convergence: general noise")
+
+	// commutative noise: dy = μ y dx + σ0 y dW0 + σ1 y dW1
+	μ, σ := 0.2, []float64{0.5, 0.3}
+	y0 := la.Vector{1}
+	drift := func(a la.Vector, x float64, y la.Vector) {
+		a[0] = μ * y[0]
+	}
+	diff := func(B *la.Matrix, x float64, y la.Vector) {
+		B.Set(0, 0, σ[0]*y[0])
+		B.Set(0, 1, σ[1]*y[0])
+	}
+	yana := func(y la.Vector, x float64, W la.Vector) {
+		y[0] = y0[0] * math.Exp((μ-(σ[0]*σ[0]+σ[1]*σ[1])/2)*x+σ[0]*W[0]+σ[1]*W[1])
+	}
+	for k, method := range []string{"em", "milstein"} {
+		o := NewGeneral(method, 1, 2, drift, diff)
+		errs, order := strongOrder(o, y0, 1, 200, yana)
+		io.Pforan("commutative noise: %8s: errors = %.2e  order = %.3f\n", method, errs, order)
+		chk.Float64(tst, method+": order", 0.2, order, []float64{0.5, 1}[k])
+	}
+
+	// scalar noise: dy_i = μ_i y_i dx + σ_i y_i dW  (Stratonovich)
+	μs, σs := []float64{0.5, -0.3}, []float64{0.4, 0.6}
+	y0 = la.Vector{1, 2}
+	drift = func(a la.Vector, x float64, y la.Vector) {
+		a[0], a[1] = μs[0]*y[0], μs[1]*y[1]
+	}
+	diff = func(B *la.Matrix, x float64, y la.Vector) {
+		B.Set(0, 0, σs[0]*y[0])
+		B.Set(1, 0, σs[1]*y[1])
+	}
+	yana = func(y la.Vector, x float64, W la.Vector) {
+		y[0] = y0[0] * math.Exp(μs[0]*x+σs[0]*W[0])
+		y[1] = y0[1] * math.Exp(μs[1]*x+σs[1]*W[0])
+	}
+	for k, method := range []string{"em", "milstein", "srk15"} {
+		o := NewGeneral(method, 2, 1, drift, diff)
+		o.Stratonovich = true
+		errs, order := strongOrder(o, y0, 1, 200, yana)
+		io.Pforan("scalar noise:      %8s: errors = %.2e  order = %.3f\n", method, errs, order)
+		chk.Float64(tst, method+": order", 0.2, order, []float64{0.5, 1, 1.5}[k])
+	}
+}
+
+func TestSde03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Sde03. sample paths and reproducible seeding")
+
+	// Ornstein-Uhlenbeck process with time-dependent mean: dy = θ (sin(x) - y) dx + σ dW
+	θ, σ := 2.0, 0.3
+	o := NewDiag("srk15", 1, func(a la.Vector, x float64, y la.Vector) {
+		a[0] = θ * (math.Sin(x) - y[0])
+	}, func(b la.Vector, x float64, y la.Vector) {
+		b[0] = σ
+	})
+
+	// same seed ⇒ same path
+	var p1, p2 Path
+	y1, y2 := la.Vector{1}, la.Vector{1}
+	o.Solve(y1, 0, 5, 500, rnd.NewGenerator(7), &p1)
+	o.Solve(y2, 0, 5, 500, rnd.NewGenerator(7), &p2)
+	chk.Array(tst, "y (same seed)", 1e-17, y1, y2)
+	chk.Float64(tst, "x (final)", 1e-15, p1.X[500], 5)
+	chk.Array(tst, "y (path)", 1e-17, p1.Y[500], y1)
+	chk.Deep2(tst, "W", 1e-17, [][]float64{p1.W[100], p1.W[500]}, [][]float64{p2.W[100], p2.W[500]})
+
+	// different seed ⇒ different path
+	y3 := la.Vector{1}
+	o.Solve(y3, 0, 5, 500, rnd.NewGenerator(8), nil)
+	io.Pforan("y1 = %v  y3 = %v\n", y1, y3)
+	if y1[0] == y3[0] {
+		tst.Errorf("paths with different seeds should differ\n")
+	}
+
+	// plot
+	if chk.Verbose {
+		Y := make([]float64, len(p1.X))
+		for i := range p1.X {
+			Y[i] = p1.Y[i][0]
+		}
+		plt.Reset(true, nil)
+		plt.Plot(p1.X, Y, &plt.A{C: "r", L: "srk15"})
+		plt.Gll("$x$", "$y$", nil)
+		plt.Save("/tmp/gosl/sde", "sde03")
+	}
+}
+
+func TestSde04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Sde04. srk15 with non-scalar general noise")
+
+	defer chk.RecoverTstPanicIsOK(tst)
+	NewGeneral("srk15", 1, 2, nil, nil)
+}